@@ -0,0 +1,31 @@
+// Package buildinfo holds build metadata injected at link time, e.g.
+//
+//	go build -ldflags "-X digital-recipes/api-service/buildinfo.Version=1.4.0 \
+//	  -X digital-recipes/api-service/buildinfo.Commit=$(git rev-parse --short HEAD) \
+//	  -X digital-recipes/api-service/buildinfo.BuildTime=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+//
+// Builds without ldflags report the defaults below.
+package buildinfo
+
+// Build metadata; set with -ldflags "-X" and never modified at runtime
+var (
+	Version   = "dev"
+	Commit    = "unknown"
+	BuildTime = "unknown"
+)
+
+// Info is the build metadata as reported by the health endpoint
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildTime string `json:"build_time"`
+}
+
+// Get returns the build metadata of the running binary
+func Get() Info {
+	return Info{
+		Version:   Version,
+		Commit:    Commit,
+		BuildTime: BuildTime,
+	}
+}
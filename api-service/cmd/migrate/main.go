@@ -23,10 +23,15 @@ func main() {
 	switch *command {
 	case "up":
 		log.Println("Running migrations...")
-		if err := database.RunMigrations(migrationsDir); err != nil {
+		applied, err := database.ApplyMigrations(migrationsDir)
+		if err != nil {
 			log.Fatalf("Failed to run migrations: %v", err)
 		}
-		log.Println("Migrations completed successfully")
+		if applied == 0 {
+			log.Println("Database schema is already up to date")
+		} else {
+			log.Printf("Migrations completed successfully (%d applied)", applied)
+		}
 	case "status":
 		log.Println("Migration status checking not implemented yet")
 	case "down":
@@ -13,6 +13,17 @@ import (
 // Database represents our database connection
 type Database struct {
 	DB *sql.DB
+	// ReadDB is an optional replica pool for read-only queries; nil sends reads to DB
+	ReadDB *sql.DB
+	// SlowQueryThreshold is how long a logged query may take before a warning; zero disables it
+	SlowQueryThreshold time.Duration
+	// TxMaxAttempts is how many times WithRetryTx runs a transaction; one or less disables retries
+	TxMaxAttempts int
+	// TxRetryBackoff is how long WithRetryTx waits before its first retry
+	TxRetryBackoff time.Duration
+
+	statements        statementCache
+	replicaStatements statementCache
 }
 
 // NewConnection creates a new database connection
@@ -22,6 +33,34 @@ func NewConnection() (*Database, error) {
 		return nil, fmt.Errorf("DATABASE_URL environment variable is required")
 	}
 
+	db, err := openPool(databaseURL)
+	if err != nil {
+		return nil, err
+	}
+	log.Println("Database connection established successfully")
+
+	// An optional replica takes the read-heavy queries off the primary
+	var readDB *sql.DB
+	if readURL := os.Getenv("READ_DATABASE_URL"); readURL != "" {
+		readDB, err = openPool(readURL)
+		if err != nil {
+			db.Close()
+			return nil, fmt.Errorf("read replica: %w", err)
+		}
+		log.Println("Read replica connection established successfully")
+	}
+
+	return &Database{
+		DB:                 db,
+		ReadDB:             readDB,
+		SlowQueryThreshold: GetSlowQueryThreshold(),
+		TxMaxAttempts:      DefaultTxMaxAttempts,
+		TxRetryBackoff:     DefaultTxRetryBackoff,
+	}, nil
+}
+
+// openPool opens and pings a connection pool to databaseURL
+func openPool(databaseURL string) (*sql.DB, error) {
 	db, err := sql.Open("postgres", databaseURL)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database connection: %w", err)
@@ -34,19 +73,35 @@ func NewConnection() (*Database, error) {
 
 	// Test the connection
 	if err := db.Ping(); err != nil {
+		db.Close()
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
+	return db, nil
+}
 
-	log.Println("Database connection established successfully")
-	return &Database{DB: db}, nil
+// Read returns the pool for read-only queries: the replica when one is configured, otherwise the primary
+// Replicas can lag behind, so a request reading back its own writes should use DB instead
+func (d *Database) Read() *sql.DB {
+	if d.ReadDB != nil {
+		return d.ReadDB
+	}
+	return d.DB
 }
 
-// Close closes the database connection
+// Close closes any cached prepared statements and then the database connections
 func (d *Database) Close() error {
+	stmtErr := d.closeStatements()
+	if d.ReadDB != nil {
+		if err := d.ReadDB.Close(); err != nil {
+			return err
+		}
+	}
 	if d.DB != nil {
-		return d.DB.Close()
+		if err := d.DB.Close(); err != nil {
+			return err
+		}
 	}
-	return nil
+	return stmtErr
 }
 
 // HealthCheck verifies database connectivity
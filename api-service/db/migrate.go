@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"log"
+	"os"
 	"path/filepath"
 	"sort"
 	"strconv"
@@ -20,37 +21,72 @@ type Migration struct {
 
 // RunMigrations executes all pending migrations
 func (d *Database) RunMigrations(migrationsDir string) error {
+	_, err := d.ApplyMigrations(migrationsDir)
+	return err
+}
+
+// ApplyMigrations executes all pending migrations and returns how many were applied
+// Zero applied with a nil error means the schema was already up to date
+func (d *Database) ApplyMigrations(migrationsDir string) (int, error) {
 	// Create migrations table if it doesn't exist
 	if err := d.createMigrationsTable(); err != nil {
-		return fmt.Errorf("failed to create migrations table: %w", err)
+		return 0, fmt.Errorf("failed to create migrations table: %w", err)
 	}
 
 	// Load migration files
 	migrations, err := loadMigrations(migrationsDir)
 	if err != nil {
-		return fmt.Errorf("failed to load migrations: %w", err)
+		return 0, fmt.Errorf("failed to load migrations: %w", err)
 	}
 
 	// Get applied migrations
 	appliedVersions, err := d.getAppliedMigrations()
 	if err != nil {
-		return fmt.Errorf("failed to get applied migrations: %w", err)
+		return 0, fmt.Errorf("failed to get applied migrations: %w", err)
 	}
 
 	// Execute pending migrations
+	applied := 0
 	for _, migration := range migrations {
-		if _, applied := appliedVersions[migration.Version]; applied {
+		if _, done := appliedVersions[migration.Version]; done {
 			log.Printf("Migration %d_%s already applied, skipping", migration.Version, migration.Name)
 			continue
 		}
 
 		log.Printf("Applying migration %d_%s", migration.Version, migration.Name)
 		if err := d.applyMigration(migration); err != nil {
-			return fmt.Errorf("failed to apply migration %d_%s: %w", migration.Version, migration.Name, err)
+			return applied, fmt.Errorf("failed to apply migration %d_%s: %w", migration.Version, migration.Name, err)
 		}
+		applied++
+	}
+
+	return applied, nil
+}
+
+// MigrateOnStartEnabled reports whether the service should migrate its own schema at startup
+// MIGRATE_ON_START=false leaves migrations to an out-of-band job; unset or invalid values keep the default of true
+func MigrateOnStartEnabled() bool {
+	value := os.Getenv("MIGRATE_ON_START")
+	if value == "" {
+		return true
+	}
+
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Printf("Invalid MIGRATE_ON_START %q, running migrations", value)
+		return true
 	}
+	return enabled
+}
 
-	return nil
+// StartupMigrations applies pending migrations at service start unless MIGRATE_ON_START disables it
+// skipped reports that the database was not touched because migrations run elsewhere
+func (d *Database) StartupMigrations(migrationsDir string) (skipped bool, applied int, err error) {
+	if !MigrateOnStartEnabled() {
+		return true, 0, nil
+	}
+	applied, err = d.ApplyMigrations(migrationsDir)
+	return false, applied, err
 }
 
 // createMigrationsTable creates the schema_migrations table
@@ -0,0 +1,4 @@
+-- Rollback recipe revision history
+
+DROP INDEX IF EXISTS idx_recipe_revisions_recipe_id;
+DROP TABLE IF EXISTS recipe_revisions;
@@ -0,0 +1,15 @@
+-- Revision history for recipe edits
+-- Each row holds the recipe and its ingredients as they were before an update,
+-- along with who made that update and when
+
+CREATE TABLE recipe_revisions (
+    id SERIAL PRIMARY KEY,
+    recipe_id INTEGER NOT NULL REFERENCES recipes(id) ON DELETE CASCADE,
+    revision_number INTEGER NOT NULL,
+    snapshot JSONB NOT NULL,
+    changed_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (recipe_id, revision_number)
+);
+
+CREATE INDEX idx_recipe_revisions_recipe_id ON recipe_revisions(recipe_id);
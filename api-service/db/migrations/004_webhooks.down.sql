@@ -0,0 +1,3 @@
+-- Rollback webhook subscriptions
+
+DROP TABLE IF EXISTS webhooks;
@@ -0,0 +1,3 @@
+-- Rollback password credentials
+
+ALTER TABLE users DROP COLUMN IF EXISTS password_hash;
@@ -0,0 +1,4 @@
+-- Password credentials for users
+-- Accounts created before passwords existed keep a NULL hash until one is set
+
+ALTER TABLE users ADD COLUMN password_hash VARCHAR(255);
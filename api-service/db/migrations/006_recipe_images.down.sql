@@ -0,0 +1,3 @@
+-- Rollback recipe images
+
+DROP TABLE IF EXISTS recipe_images;
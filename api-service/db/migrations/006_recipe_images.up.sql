@@ -0,0 +1,13 @@
+-- Images stored for a recipe in object storage
+-- object_name is the object key inside the configured bucket
+
+CREATE TABLE recipe_images (
+    id SERIAL PRIMARY KEY,
+    recipe_id INTEGER NOT NULL REFERENCES recipes(id) ON DELETE CASCADE,
+    object_name TEXT NOT NULL UNIQUE,
+    content_type VARCHAR(100),
+    size_bytes BIGINT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_recipe_images_recipe_id ON recipe_images(recipe_id);
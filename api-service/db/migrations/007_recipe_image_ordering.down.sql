@@ -0,0 +1,8 @@
+-- Rollback recipe image ordering
+
+DROP INDEX IF EXISTS idx_recipe_images_position;
+DROP INDEX IF EXISTS idx_recipe_images_primary;
+
+ALTER TABLE recipe_images
+    DROP COLUMN IF EXISTS is_primary,
+    DROP COLUMN IF EXISTS position;
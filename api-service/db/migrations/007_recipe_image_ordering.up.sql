@@ -0,0 +1,20 @@
+-- Display order and cover image for recipe images
+-- Existing images keep their insertion order and the first one becomes the primary image
+
+ALTER TABLE recipe_images
+    ADD COLUMN position INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN is_primary BOOLEAN NOT NULL DEFAULT FALSE;
+
+UPDATE recipe_images img
+SET position = ordered.position,
+    is_primary = ordered.position = 0
+FROM (
+    SELECT id, ROW_NUMBER() OVER (PARTITION BY recipe_id ORDER BY id) - 1 AS position
+    FROM recipe_images
+) ordered
+WHERE img.id = ordered.id;
+
+-- At most one primary image per recipe; the API moves the flag rather than clearing it
+CREATE UNIQUE INDEX idx_recipe_images_primary ON recipe_images(recipe_id) WHERE is_primary;
+
+CREATE INDEX idx_recipe_images_position ON recipe_images(recipe_id, position);
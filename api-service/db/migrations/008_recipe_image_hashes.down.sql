@@ -0,0 +1,5 @@
+-- Rollback recipe image hashes
+
+DROP INDEX IF EXISTS idx_recipe_images_content_hash;
+
+ALTER TABLE recipe_images DROP COLUMN IF EXISTS content_hash;
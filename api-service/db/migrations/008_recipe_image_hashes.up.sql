@@ -0,0 +1,7 @@
+-- Content hash of each confirmed image, used to skip storing the same photo twice for a recipe
+-- Images recorded before hashing have no hash and are never treated as duplicates
+
+ALTER TABLE recipe_images ADD COLUMN content_hash TEXT;
+
+CREATE UNIQUE INDEX idx_recipe_images_content_hash ON recipe_images(recipe_id, content_hash)
+    WHERE content_hash IS NOT NULL;
@@ -0,0 +1,4 @@
+-- Rollback case-insensitive canonical ingredient names
+-- Merged duplicates are not restored
+
+DROP INDEX IF EXISTS idx_canonical_ingredients_lower_name;
@@ -0,0 +1,25 @@
+-- Case-insensitive uniqueness for canonical ingredient names so "Eggs" and "eggs" cannot coexist
+-- Existing case-only duplicates are merged into the approved (or oldest) row before indexing
+
+WITH ranked AS (
+    SELECT id, FIRST_VALUE(id) OVER (
+        PARTITION BY LOWER(name) ORDER BY is_approved DESC NULLS LAST, id ASC
+    ) AS keep_id
+    FROM canonical_ingredients
+)
+UPDATE recipe_ingredients ri
+SET canonical_ingredient_id = ranked.keep_id
+FROM ranked
+WHERE ri.canonical_ingredient_id = ranked.id AND ranked.id <> ranked.keep_id;
+
+WITH ranked AS (
+    SELECT id, FIRST_VALUE(id) OVER (
+        PARTITION BY LOWER(name) ORDER BY is_approved DESC NULLS LAST, id ASC
+    ) AS keep_id
+    FROM canonical_ingredients
+)
+DELETE FROM canonical_ingredients c
+USING ranked
+WHERE c.id = ranked.id AND ranked.id <> ranked.keep_id;
+
+CREATE UNIQUE INDEX idx_canonical_ingredients_lower_name ON canonical_ingredients(LOWER(name));
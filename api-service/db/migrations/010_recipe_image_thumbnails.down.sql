@@ -0,0 +1,4 @@
+-- Rollback recipe image thumbnails
+
+ALTER TABLE recipe_images DROP COLUMN IF EXISTS thumbnail_failed;
+ALTER TABLE recipe_images DROP COLUMN IF EXISTS thumbnail_object_name;
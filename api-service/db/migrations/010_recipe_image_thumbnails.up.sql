@@ -0,0 +1,5 @@
+-- Thumbnails generated when an image is confirmed, used by recipe lists for fast grid rendering
+-- thumbnail_failed marks images whose content could not be decoded so they are not retried
+
+ALTER TABLE recipe_images ADD COLUMN thumbnail_object_name TEXT;
+ALTER TABLE recipe_images ADD COLUMN thumbnail_failed BOOLEAN NOT NULL DEFAULT false;
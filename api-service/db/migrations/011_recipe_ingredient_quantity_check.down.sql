@@ -0,0 +1,3 @@
+-- Rollback non-negative ingredient quantity check
+
+ALTER TABLE recipe_ingredients DROP CONSTRAINT IF EXISTS recipe_ingredients_quantity_non_negative;
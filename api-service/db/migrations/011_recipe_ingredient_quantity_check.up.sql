@@ -0,0 +1,7 @@
+-- Ingredient quantities can never be negative
+-- Existing negative values are cleared rather than guessed at, matching lines the parser could not read
+
+UPDATE recipe_ingredients SET quantity = NULL WHERE quantity < 0;
+
+ALTER TABLE recipe_ingredients
+    ADD CONSTRAINT recipe_ingredients_quantity_non_negative CHECK (quantity >= 0);
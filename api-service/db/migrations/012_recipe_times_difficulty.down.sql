@@ -0,0 +1,5 @@
+-- Rollback recipe times and difficulty
+
+ALTER TABLE recipes DROP COLUMN IF EXISTS difficulty;
+ALTER TABLE recipes DROP COLUMN IF EXISTS cook_time_minutes;
+ALTER TABLE recipes DROP COLUMN IF EXISTS prep_time_minutes;
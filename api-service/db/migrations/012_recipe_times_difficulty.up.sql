@@ -0,0 +1,10 @@
+-- Optional preparation and cooking times, in minutes, and a difficulty level for recipes
+
+ALTER TABLE recipes ADD COLUMN prep_time_minutes INTEGER;
+ALTER TABLE recipes ADD COLUMN cook_time_minutes INTEGER;
+ALTER TABLE recipes ADD COLUMN difficulty VARCHAR(10);
+
+ALTER TABLE recipes
+    ADD CONSTRAINT recipes_prep_time_non_negative CHECK (prep_time_minutes >= 0),
+    ADD CONSTRAINT recipes_cook_time_non_negative CHECK (cook_time_minutes >= 0),
+    ADD CONSTRAINT recipes_difficulty_check CHECK (difficulty IN ('easy', 'medium', 'hard'));
@@ -0,0 +1,2 @@
+-- Rollback updated_at trigger backfill
+-- Nothing to undo: the triggers and function belong to 001_initial_schema, whose rollback drops them
@@ -0,0 +1,40 @@
+-- Every table with an updated_at column keeps it current through a BEFORE UPDATE trigger
+-- 001_initial_schema wired users, recipes, canonical_ingredients and recipe_ingredients by hand;
+-- this fills in any table with the column that is missing its trigger, reusing the same function
+-- Tables added later still need their own CREATE TRIGGER
+
+CREATE OR REPLACE FUNCTION update_updated_at_column()
+RETURNS TRIGGER AS $$
+BEGIN
+    NEW.updated_at = CURRENT_TIMESTAMP;
+    RETURN NEW;
+END;
+$$ language 'plpgsql';
+
+DO $$
+DECLARE
+    target RECORD;
+BEGIN
+    FOR target IN
+        SELECT c.table_name
+        FROM information_schema.columns c
+        JOIN information_schema.tables t
+            ON t.table_schema = c.table_schema AND t.table_name = c.table_name
+        WHERE c.table_schema = current_schema()
+            AND c.column_name = 'updated_at'
+            AND t.table_type = 'BASE TABLE'
+    LOOP
+        IF NOT EXISTS (
+            SELECT 1 FROM pg_trigger
+            WHERE tgrelid = quote_ident(target.table_name)::regclass
+                AND tgname = 'update_' || target.table_name || '_updated_at'
+        ) THEN
+            EXECUTE format(
+                'CREATE TRIGGER %I BEFORE UPDATE ON %I FOR EACH ROW EXECUTE FUNCTION update_updated_at_column()',
+                'update_' || target.table_name || '_updated_at',
+                target.table_name
+            );
+        END IF;
+    END LOOP;
+END
+$$;
@@ -0,0 +1,5 @@
+-- Rollback recipe storage prefixes
+-- Objects uploaded under a random prefix are not moved back under recipes/{id}/
+
+DROP INDEX IF EXISTS idx_recipes_storage_prefix;
+ALTER TABLE recipes DROP COLUMN IF EXISTS storage_prefix;
@@ -0,0 +1,9 @@
+-- Unguessable per-recipe prefix for stored object keys: recipes/{storage_prefix}/images/...
+-- Existing recipes keep NULL so their files stay addressable under recipes/{id}/
+
+ALTER TABLE recipes ADD COLUMN storage_prefix VARCHAR(32);
+
+-- Set after the column exists so only new rows get a random prefix
+ALTER TABLE recipes ALTER COLUMN storage_prefix SET DEFAULT replace(gen_random_uuid()::text, '-', '');
+
+CREATE UNIQUE INDEX idx_recipes_storage_prefix ON recipes(storage_prefix);
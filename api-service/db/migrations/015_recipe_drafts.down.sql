@@ -0,0 +1,4 @@
+-- Rollback recipe drafts
+
+DROP TRIGGER IF EXISTS update_recipe_drafts_updated_at ON recipe_drafts;
+DROP TABLE IF EXISTS recipe_drafts;
@@ -0,0 +1,12 @@
+-- Autosaved draft content for recipes, kept apart from the live fields until promoted
+-- content holds a partial recipe in the same shape as a PATCH /recipes/:id body
+
+CREATE TABLE recipe_drafts (
+    recipe_id INTEGER PRIMARY KEY REFERENCES recipes(id) ON DELETE CASCADE,
+    content JSONB NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TRIGGER update_recipe_drafts_updated_at BEFORE UPDATE ON recipe_drafts
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
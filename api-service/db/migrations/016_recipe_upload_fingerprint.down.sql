@@ -0,0 +1,4 @@
+-- Rollback recipe upload fingerprints
+
+DROP INDEX IF EXISTS idx_recipes_upload_fingerprint;
+ALTER TABLE recipes DROP COLUMN IF EXISTS upload_fingerprint;
@@ -0,0 +1,8 @@
+-- Fingerprint of the upload request that created a recipe, used to answer a quick repeat
+-- of the same request (a double-click) with the recipe it already created
+-- Recipes created before this migration or by other means keep NULL and are never matched
+
+ALTER TABLE recipes ADD COLUMN upload_fingerprint VARCHAR(64);
+
+CREATE INDEX idx_recipes_upload_fingerprint ON recipes(user_id, upload_fingerprint, created_at)
+    WHERE status = 'processing';
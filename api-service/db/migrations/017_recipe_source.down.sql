@@ -0,0 +1,4 @@
+-- Rollback recipe source attribution
+
+ALTER TABLE recipes DROP COLUMN IF EXISTS source_url;
+ALTER TABLE recipes DROP COLUMN IF EXISTS source_name;
@@ -0,0 +1,4 @@
+-- Optional attribution for recipes transcribed or imported from a book or website
+
+ALTER TABLE recipes ADD COLUMN source_name VARCHAR(255);
+ALTER TABLE recipes ADD COLUMN source_url VARCHAR(2048);
@@ -0,0 +1,3 @@
+-- Rollback recipe last editor tracking
+
+ALTER TABLE recipes DROP COLUMN IF EXISTS last_modified_by;
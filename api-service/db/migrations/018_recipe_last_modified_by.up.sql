@@ -0,0 +1,3 @@
+-- Who last edited a recipe, which differs from its owner once reviewers and admins edit it
+
+ALTER TABLE recipes ADD COLUMN last_modified_by INTEGER REFERENCES users(id) ON DELETE SET NULL;
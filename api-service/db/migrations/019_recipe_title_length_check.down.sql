@@ -0,0 +1,3 @@
+-- Rollback recipe title length check
+
+ALTER TABLE recipes DROP CONSTRAINT IF EXISTS recipes_title_length;
@@ -0,0 +1,9 @@
+-- Recipe titles are stored trimmed, cannot be blank and are at most 200 characters
+-- Existing titles are trimmed and cut to fit; blank ones get a placeholder the owner can rename
+
+UPDATE recipes SET title = BTRIM(title) WHERE title <> BTRIM(title);
+UPDATE recipes SET title = RTRIM(LEFT(title, 200)) WHERE char_length(title) > 200;
+UPDATE recipes SET title = 'Untitled Recipe' WHERE title = '';
+
+ALTER TABLE recipes
+    ADD CONSTRAINT recipes_title_length CHECK (char_length(BTRIM(title)) BETWEEN 1 AND 200);
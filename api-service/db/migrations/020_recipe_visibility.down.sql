@@ -0,0 +1,3 @@
+-- Rollback recipe visibility
+
+ALTER TABLE recipes DROP COLUMN IF EXISTS visibility;
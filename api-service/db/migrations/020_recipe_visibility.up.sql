@@ -0,0 +1,8 @@
+-- Who can see a recipe, separate from its workflow status
+-- public recipes are listed for everyone, unlisted ones open by direct link only and private ones to their owner only
+-- Existing recipes stay public, which matches how they were shown before
+
+ALTER TABLE recipes ADD COLUMN visibility VARCHAR(10) NOT NULL DEFAULT 'public';
+
+ALTER TABLE recipes
+    ADD CONSTRAINT recipes_visibility_check CHECK (visibility IN ('public', 'private', 'unlisted'));
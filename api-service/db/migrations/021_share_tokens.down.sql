@@ -0,0 +1,3 @@
+-- Rollback recipe share links
+
+DROP TABLE IF EXISTS share_tokens;
@@ -0,0 +1,14 @@
+-- Share links that open a recipe whatever its visibility, without signing in
+-- Only a SHA-256 hash of each token is stored, so the tokens themselves cannot be read back
+
+CREATE TABLE share_tokens (
+    id SERIAL PRIMARY KEY,
+    recipe_id INTEGER NOT NULL REFERENCES recipes(id) ON DELETE CASCADE,
+    token_hash CHAR(64) NOT NULL UNIQUE,
+    created_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    expires_at TIMESTAMP WITH TIME ZONE,
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_share_tokens_recipe_id ON share_tokens(recipe_id);
@@ -0,0 +1,5 @@
+-- Rollback featured recipes
+
+DROP INDEX IF EXISTS idx_recipes_featured_at;
+ALTER TABLE recipes DROP COLUMN IF EXISTS featured_at;
+ALTER TABLE recipes DROP COLUMN IF EXISTS featured;
@@ -0,0 +1,7 @@
+-- Recipes picked by admins for the homepage
+-- featured_at records when the current feature began and orders the featured listing, newest first
+
+ALTER TABLE recipes ADD COLUMN featured BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE recipes ADD COLUMN featured_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX idx_recipes_featured_at ON recipes(featured_at DESC) WHERE featured;
@@ -0,0 +1,100 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"digital-recipes/api-service/logctx"
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultSlowQueryThreshold is used when SLOW_QUERY_MS is not set
+const DefaultSlowQueryThreshold = 500 * time.Millisecond
+
+// GetSlowQueryThreshold returns the slow-query threshold from SLOW_QUERY_MS
+// A value of 0 disables slow-query logging
+func GetSlowQueryThreshold() time.Duration {
+	value := os.Getenv("SLOW_QUERY_MS")
+	if value == "" {
+		return DefaultSlowQueryThreshold
+	}
+
+	ms, err := strconv.Atoi(value)
+	if err != nil || ms < 0 {
+		logrus.WithField("slow_query_ms", value).Warn("Invalid SLOW_QUERY_MS, using default")
+		return DefaultSlowQueryThreshold
+	}
+	return time.Duration(ms) * time.Millisecond
+}
+
+// QueryContextLogged runs a query and logs a warning when it takes longer than the slow-query threshold
+// The warning uses the request logger carried by ctx and includes the SQL template, never the arguments
+func (d *Database) QueryContextLogged(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return d.queryLogged(ctx, d.DB, query, args...)
+}
+
+// QueryRowContextLogged is the single-row counterpart of QueryContextLogged
+func (d *Database) QueryRowContextLogged(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return d.queryRowLogged(ctx, d.DB, query, args...)
+}
+
+// ReadQueryContextLogged is QueryContextLogged on the Read pool, for reads that need not see the latest writes
+func (d *Database) ReadQueryContextLogged(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return d.queryLogged(ctx, d.Read(), query, args...)
+}
+
+// ReadQueryRowContextLogged is the single-row counterpart of ReadQueryContextLogged
+func (d *Database) ReadQueryRowContextLogged(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return d.queryRowLogged(ctx, d.Read(), query, args...)
+}
+
+func (d *Database) queryLogged(ctx context.Context, conn *sql.DB, query string, args ...interface{}) (*sql.Rows, error) {
+	start := time.Now()
+	rows, err := conn.QueryContext(ctx, query, args...)
+	d.logIfSlow(ctx, query, time.Since(start))
+	return rows, err
+}
+
+func (d *Database) queryRowLogged(ctx context.Context, conn *sql.DB, query string, args ...interface{}) *sql.Row {
+	start := time.Now()
+	row := conn.QueryRowContext(ctx, query, args...)
+	d.logIfSlow(ctx, query, time.Since(start))
+	return row
+}
+
+// logIfSlow records queries that exceeded the threshold
+func (d *Database) logIfSlow(ctx context.Context, query string, duration time.Duration) {
+	if d.SlowQueryThreshold <= 0 || duration < d.SlowQueryThreshold {
+		return
+	}
+	logctx.FromContext(ctx).WithFields(logrus.Fields{
+		"query":        strings.Join(strings.Fields(query), " "),
+		"duration_ms":  duration.Milliseconds(),
+		"threshold_ms": d.SlowQueryThreshold.Milliseconds(),
+	}).Warn("Slow database query")
+}
+
+// LoggedQuerier runs Query and QueryRow through the slow-query logging wrappers with a fixed context
+type LoggedQuerier struct {
+	db  *Database
+	ctx context.Context
+}
+
+// Logged returns a querier whose queries are timed and logged with ctx
+func (d *Database) Logged(ctx context.Context) LoggedQuerier {
+	return LoggedQuerier{db: d, ctx: ctx}
+}
+
+// Query runs a query with slow-query logging
+func (q LoggedQuerier) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return q.db.QueryContextLogged(q.ctx, query, args...)
+}
+
+// QueryRow runs a single-row query with slow-query logging
+func (q LoggedQuerier) QueryRow(query string, args ...interface{}) *sql.Row {
+	return q.db.QueryRowContextLogged(q.ctx, query, args...)
+}
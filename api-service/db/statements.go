@@ -0,0 +1,136 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+	"time"
+
+	"digital-recipes/api-service/logctx"
+)
+
+// preparedStatement is a cached statement together with the SQL it was prepared from
+type preparedStatement struct {
+	stmt  *sql.Stmt
+	query string
+}
+
+// statementCache holds statements prepared once and reused for every request
+type statementCache struct {
+	mu         sync.Mutex
+	statements map[string]preparedStatement
+	closed     bool
+}
+
+// PrepareCached prepares query once and caches it under name; later calls return the cached statement
+// Reusing a name for different SQL is a programming error and is reported rather than silently served
+func (d *Database) PrepareCached(ctx context.Context, name, query string) (*sql.Stmt, error) {
+	return d.statements.prepare(ctx, d.DB, name, query)
+}
+
+// prepare returns the statement cached under name, preparing query on conn the first time
+func (cache *statementCache) prepare(ctx context.Context, conn *sql.DB, name, query string) (*sql.Stmt, error) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	if cache.closed {
+		return nil, fmt.Errorf("statement %s: database is closed", name)
+	}
+	if cached, ok := cache.statements[name]; ok {
+		if cached.query != query {
+			return nil, fmt.Errorf("statement %s is already prepared with different SQL", name)
+		}
+		return cached.stmt, nil
+	}
+
+	stmt, err := conn.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare statement %s: %w", name, err)
+	}
+	if cache.statements == nil {
+		cache.statements = make(map[string]preparedStatement)
+	}
+	cache.statements[name] = preparedStatement{stmt: stmt, query: query}
+	return stmt, nil
+}
+
+// readStatements returns the cache for statements prepared on the Read pool
+func (d *Database) readStatements() *statementCache {
+	if d.ReadDB == nil {
+		return &d.statements
+	}
+	return &d.replicaStatements
+}
+
+// QueryPrepared runs the statement cached under name, preparing query on first use
+// If preparing fails the query runs unprepared so a cache problem never fails the request by itself
+func (d *Database) QueryPrepared(ctx context.Context, name, query string, args ...interface{}) (*sql.Rows, error) {
+	return d.queryPrepared(ctx, &d.statements, d.DB, name, query, args...)
+}
+
+// QueryRowPrepared is the single-row counterpart of QueryPrepared
+func (d *Database) QueryRowPrepared(ctx context.Context, name, query string, args ...interface{}) *sql.Row {
+	return d.queryRowPrepared(ctx, &d.statements, d.DB, name, query, args...)
+}
+
+// ReadQueryPrepared is QueryPrepared on the Read pool, with statements cached separately from the primary's
+func (d *Database) ReadQueryPrepared(ctx context.Context, name, query string, args ...interface{}) (*sql.Rows, error) {
+	return d.queryPrepared(ctx, d.readStatements(), d.Read(), name, query, args...)
+}
+
+// ReadQueryRowPrepared is the single-row counterpart of ReadQueryPrepared
+func (d *Database) ReadQueryRowPrepared(ctx context.Context, name, query string, args ...interface{}) *sql.Row {
+	return d.queryRowPrepared(ctx, d.readStatements(), d.Read(), name, query, args...)
+}
+
+func (d *Database) queryPrepared(ctx context.Context, cache *statementCache, conn *sql.DB, name, query string, args ...interface{}) (*sql.Rows, error) {
+	stmt, err := cache.prepare(ctx, conn, name, query)
+	if err != nil {
+		logctx.FromContext(ctx).WithError(err).Warn("Running query without prepared statement")
+		return d.queryLogged(ctx, conn, query, args...)
+	}
+
+	start := time.Now()
+	rows, err := stmt.QueryContext(ctx, args...)
+	d.logIfSlow(ctx, query, time.Since(start))
+	return rows, err
+}
+
+func (d *Database) queryRowPrepared(ctx context.Context, cache *statementCache, conn *sql.DB, name, query string, args ...interface{}) *sql.Row {
+	stmt, err := cache.prepare(ctx, conn, name, query)
+	if err != nil {
+		logctx.FromContext(ctx).WithError(err).Warn("Running query without prepared statement")
+		return d.queryRowLogged(ctx, conn, query, args...)
+	}
+
+	start := time.Now()
+	row := stmt.QueryRowContext(ctx, args...)
+	d.logIfSlow(ctx, query, time.Since(start))
+	return row
+}
+
+// closeStatements closes every cached statement and stops new ones from being prepared
+func (d *Database) closeStatements() error {
+	firstErr := d.statements.close()
+	if err := d.replicaStatements.close(); err != nil && firstErr == nil {
+		firstErr = err
+	}
+	return firstErr
+}
+
+// close closes the cached statements and marks the cache closed
+func (cache *statementCache) close() error {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	var firstErr error
+	for name, cached := range cache.statements {
+		if err := cached.stmt.Close(); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("failed to close statement %s: %w", name, err)
+		}
+	}
+	cache.statements = nil
+	cache.closed = true
+	return firstErr
+}
@@ -0,0 +1,129 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"time"
+
+	"digital-recipes/api-service/logctx"
+	"github.com/lib/pq"
+	"github.com/sirupsen/logrus"
+)
+
+// Retry settings NewConnection gives WithRetryTx
+const (
+	DefaultTxMaxAttempts  = 3
+	DefaultTxRetryBackoff = 50 * time.Millisecond
+)
+
+// transientSQLStates are the Postgres error codes after which running the whole transaction again can succeed
+var transientSQLStates = map[pq.ErrorCode]bool{
+	"40001": true, // serialization_failure
+	"40P01": true, // deadlock_detected
+	"08000": true, // connection_exception
+	"08003": true, // connection_does_not_exist
+	"08006": true, // connection_failure
+}
+
+// conflictSQLStates are the transient error codes for losing to a concurrent transaction, which Postgres only
+// reports after rolling the transaction back, so even a failed commit is known not to have applied
+var conflictSQLStates = map[pq.ErrorCode]bool{
+	"40001": true, // serialization_failure
+	"40P01": true, // deadlock_detected
+}
+
+// commitError is returned by WithTx when fn succeeded but the commit failed
+type commitError struct {
+	err error
+}
+
+func (e *commitError) Error() string {
+	return "failed to commit transaction: " + e.err.Error()
+}
+
+func (e *commitError) Unwrap() error {
+	return e.err
+}
+
+// WithTx runs fn inside a transaction, committing when fn returns nil and rolling back otherwise
+// A panic in fn still rolls the transaction back and then propagates to the recovery middleware,
+// so a failing handler can never leave a transaction open
+func (d *Database) WithTx(ctx context.Context, fn func(*sql.Tx) error) error {
+	tx, err := d.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	committed := false
+	defer func() {
+		if committed {
+			return
+		}
+		if rbErr := tx.Rollback(); rbErr != nil && !errors.Is(rbErr, sql.ErrTxDone) {
+			logctx.FromContext(ctx).WithError(rbErr).Warn("Failed to roll back transaction")
+		}
+	}()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return &commitError{err: err}
+	}
+	committed = true
+	return nil
+}
+
+// IsTransientError reports whether err is a serialization failure, deadlock or dropped connection,
+// which a fresh attempt at the same transaction may not hit again
+func IsTransientError(err error) bool {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return transientSQLStates[pqErr.Code]
+	}
+	return errors.Is(err, driver.ErrBadConn)
+}
+
+// retryableTxError reports whether a transaction WithTx failed with can run again
+// A commit that fails for any reason other than a conflict is never retried: when the connection drops during
+// the commit, the transaction may have been committed before it did, and running it again would apply it twice
+func retryableTxError(err error) bool {
+	var commitErr *commitError
+	if !errors.As(err, &commitErr) {
+		return IsTransientError(err)
+	}
+	var pqErr *pq.Error
+	return errors.As(commitErr.err, &pqErr) && conflictSQLStates[pqErr.Code]
+}
+
+// WithRetryTx is WithTx for transactions that can safely run again from the start
+// When the transaction fails with a transient error it is rolled back and retried, up to TxMaxAttempts
+// attempts in total, waiting TxRetryBackoff before the first retry and twice as long before each next one.
+// A failed commit is only retried for a serialization failure or deadlock, never for a lost connection.
+// Other errors, and the last transient one, are returned unchanged. fn must not have effects outside the
+// transaction, since an attempt that is rolled back may already have run part of it
+func (d *Database) WithRetryTx(ctx context.Context, fn func(*sql.Tx) error) error {
+	backoff := d.TxRetryBackoff
+	for attempt := 1; ; attempt++ {
+		err := d.WithTx(ctx, fn)
+		if err == nil || attempt >= d.TxMaxAttempts || !retryableTxError(err) {
+			return err
+		}
+
+		logctx.FromContext(ctx).WithError(err).WithFields(logrus.Fields{
+			"attempt":    attempt,
+			"backoff_ms": backoff.Milliseconds(),
+		}).Warn("Retrying transaction after transient database error")
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"time"
+
+	"digital-recipes/api-service/middleware"
+	"digital-recipes/api-service/models"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// copyTitleSuffix is appended to the title of duplicated recipes
+const copyTitleSuffix = " (Copy)"
+
+// DuplicateRecipe handles POST /recipes/:id/duplicate requests
+// The copy belongs to the caller and goes back to review so variations can be edited
+func (h *RecipeHandler) DuplicateRecipe(c *gin.Context) {
+	logger := middleware.LogWithContext(c)
+
+	sourceID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		BadRequestError(c, "invalid recipe ID")
+		return
+	}
+
+	userID := middleware.GetUserID(c)
+	if userID == 0 {
+		AuthenticationError(c, "Authentication required to duplicate recipes")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	tx, err := h.db.DB.BeginTx(ctx, nil)
+	if err != nil {
+		logger.WithError(err).Error("Failed to begin database transaction")
+		InternalServerError(c, "Failed to duplicate recipe")
+		return
+	}
+	defer tx.Rollback()
+
+	// Lock the source so ingredients cannot change between the two copies
+	var status, visibility string
+	var ownerID int
+	err = tx.QueryRow(`SELECT status, user_id, visibility FROM recipes WHERE id = $1 FOR SHARE`, sourceID).Scan(&status, &ownerID, &visibility)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			NotFoundError(c, "recipe not found")
+			return
+		}
+		DatabaseError(c, err, "load recipe to duplicate")
+		return
+	}
+
+	if (status != "published" || visibility == models.VisibilityPrivate) && !canModifyRecipe(c, ownerID) {
+		NotFoundError(c, "recipe not found")
+		return
+	}
+
+	// Truncate long titles so the suffix still fits within the title length limit
+	// The copy keeps the source's visibility so duplicating never exposes a hidden recipe
+	var copyID int
+	err = tx.QueryRow(`
+		INSERT INTO recipes (title, servings, instructions, tips, status, user_id, prep_time_minutes, cook_time_minutes, difficulty,
+			source_name, source_url, visibility)
+		SELECT LEFT(title, $2) || $3, servings, instructions, tips, 'review_required', $4, prep_time_minutes, cook_time_minutes, difficulty,
+			source_name, source_url, visibility
+		FROM recipes
+		WHERE id = $1
+		RETURNING id
+	`, sourceID, models.MaxTitleLength-len(copyTitleSuffix), copyTitleSuffix, userID).Scan(&copyID)
+	if err != nil {
+		DatabaseError(c, err, "duplicate recipe")
+		return
+	}
+
+	result, err := tx.Exec(`
+		INSERT INTO recipe_ingredients (recipe_id, canonical_ingredient_id, original_text, quantity, unit)
+		SELECT $1, canonical_ingredient_id, original_text, quantity, unit
+		FROM recipe_ingredients
+		WHERE recipe_id = $2
+		ORDER BY id
+	`, copyID, sourceID)
+	if err != nil {
+		DatabaseError(c, err, "duplicate recipe ingredients")
+		return
+	}
+
+	if err = tx.Commit(); err != nil {
+		DatabaseError(c, err, "commit recipe duplicate")
+		return
+	}
+
+	copied, _ := result.RowsAffected()
+	logger.WithFields(logrus.Fields{
+		"source_recipe_id": sourceID,
+		"recipe_id":        copyID,
+		"ingredient_count": copied,
+	}).Info("Recipe duplicated")
+
+	h.respondWithRecipe(c, copyID)
+}
@@ -1,11 +1,15 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
 
+	"digital-recipes/api-service/middleware"
+	"digital-recipes/api-service/models"
 	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
 	"github.com/sirupsen/logrus"
 )
 
@@ -30,6 +34,9 @@ type AppError struct {
 	Message string    `json:"message"`
 	Details string    `json:"details,omitempty"`
 	Field   string    `json:"field,omitempty"`
+
+	// Errors lists every failing field when a request is rejected for several at once
+	Errors models.FieldErrors `json:"errors,omitempty"`
 }
 
 // Error implements the error interface
@@ -67,7 +74,7 @@ func NewInternalError(code, publicMessage string) AppError {
 
 // SafeErrorResponse sends an error response with safe error messages
 func SafeErrorResponse(c *gin.Context, err error, statusCode int) {
-	requestID := c.GetHeader("X-Request-ID")
+	requestID := middleware.GetRequestID(c)
 	userID := getUserIDSafe(c)
 
 	// Log the actual error with full details
@@ -94,12 +101,19 @@ func SafeErrorResponse(c *gin.Context, err error, statusCode int) {
 			logrus.WithFields(logFields).Error("Application error occurred")
 		}
 
-		c.JSON(statusCode, gin.H{
+		body := gin.H{
 			"error":      appErr.Message,
 			"type":       appErr.Type,
 			"code":       appErr.Code,
 			"request_id": requestID,
-		})
+		}
+		if appErr.Field != "" {
+			body["field"] = appErr.Field
+		}
+		if len(appErr.Errors) > 0 {
+			body["errors"] = appErr.Errors
+		}
+		c.JSON(statusCode, body)
 		return
 	}
 
@@ -123,6 +137,28 @@ func ValidationError(c *gin.Context, message string, field ...string) {
 	SafeErrorResponse(c, err, http.StatusBadRequest)
 }
 
+// FieldValidationError sends a validation error, keeping the field name of a models.FieldError
+func FieldValidationError(c *gin.Context, err error) {
+	var fieldErr *models.FieldError
+	if errors.As(err, &fieldErr) {
+		ValidationError(c, fieldErr.Message, fieldErr.Field)
+		return
+	}
+	ValidationError(c, err.Error())
+}
+
+// FieldValidationErrors rejects a request with 422, listing every field that failed validation
+// A single failure also fills message and field, so clients reading only those keep working
+func FieldValidationErrors(c *gin.Context, errs models.FieldErrors) {
+	err := NewValidationError("VALIDATION_FAILED", fmt.Sprintf("%d fields are invalid", len(errs)), "")
+	if len(errs) == 1 {
+		err.Message = errs[0].Message
+		err.Field = errs[0].Field
+	}
+	err.Errors = errs
+	SafeErrorResponse(c, err, http.StatusUnprocessableEntity)
+}
+
 func AuthenticationError(c *gin.Context, message string) {
 	err := NewAuthenticationError("AUTH_REQUIRED", message)
 	SafeErrorResponse(c, err, http.StatusUnauthorized)
@@ -146,6 +182,16 @@ func NotFoundError(c *gin.Context, message string) {
 	SafeErrorResponse(c, err, http.StatusNotFound)
 }
 
+// NotAcceptableError reports that none of the representations the client accepts can be produced
+func NotAcceptableError(c *gin.Context, message string) {
+	err := AppError{
+		Type:    ErrorTypeValidation,
+		Code:    "NOT_ACCEPTABLE",
+		Message: message,
+	}
+	SafeErrorResponse(c, err, http.StatusNotAcceptable)
+}
+
 func ConflictError(c *gin.Context, message string) {
 	err := AppError{
 		Type:    ErrorTypeConflict,
@@ -155,6 +201,27 @@ func ConflictError(c *gin.Context, message string) {
 	SafeErrorResponse(c, err, http.StatusConflict)
 }
 
+// PreconditionFailedError reports that a conditional request's precondition did not hold, so nothing was changed
+func PreconditionFailedError(c *gin.Context, message string) {
+	err := AppError{
+		Type:    ErrorTypeConflict,
+		Code:    "PRECONDITION_FAILED",
+		Message: message,
+	}
+	SafeErrorResponse(c, err, http.StatusPreconditionFailed)
+}
+
+func RequestTooLargeError(c *gin.Context, message string) {
+	err := NewValidationError("PAYLOAD_TOO_LARGE", message, "")
+	SafeErrorResponse(c, err, http.StatusRequestEntityTooLarge)
+}
+
+// isBodyTooLarge reports whether a binding error was caused by the request body limit
+func isBodyTooLarge(err error) bool {
+	var maxBytesErr *http.MaxBytesError
+	return errors.As(err, &maxBytesErr)
+}
+
 func InternalServerError(c *gin.Context, publicMessage string) {
 	err := NewInternalError("INTERNAL_ERROR", publicMessage)
 	SafeErrorResponse(c, err, http.StatusInternalServerError)
@@ -162,7 +229,7 @@ func InternalServerError(c *gin.Context, publicMessage string) {
 
 // DatabaseError specifically handles database errors with proper classification
 func DatabaseError(c *gin.Context, dbErr error, operation string) {
-	requestID := c.GetHeader("X-Request-ID")
+	requestID := middleware.GetRequestID(c)
 	userID := getUserIDSafe(c)
 
 	// Log detailed database error
@@ -183,6 +250,8 @@ func DatabaseError(c *gin.Context, dbErr error, operation string) {
 		ValidationError(c, "Invalid reference to related resource")
 	case strings.Contains(errorMsg, "not null constraint"):
 		ValidationError(c, "Required field is missing")
+	case strings.Contains(errorMsg, "check constraint"):
+		checkConstraintError(c, dbErr)
 	case strings.Contains(errorMsg, "connection"):
 		InternalServerError(c, "Service temporarily unavailable")
 	default:
@@ -190,9 +259,22 @@ func DatabaseError(c *gin.Context, dbErr error, operation string) {
 	}
 }
 
+// quantityNonNegativeConstraint is the CHECK constraint keeping recipe ingredient quantities at zero or above
+const quantityNonNegativeConstraint = "recipe_ingredients_quantity_non_negative"
+
+// checkConstraintError reports a CHECK constraint violation as a validation error on the field it guards
+func checkConstraintError(c *gin.Context, dbErr error) {
+	var pqErr *pq.Error
+	if errors.As(dbErr, &pqErr) && pqErr.Constraint == quantityNonNegativeConstraint {
+		ValidationError(c, "quantity cannot be negative", "quantity")
+		return
+	}
+	ValidationError(c, "Value is outside the allowed range")
+}
+
 // StorageError handles storage service errors
 func StorageError(c *gin.Context, storageErr error, operation string) {
-	requestID := c.GetHeader("X-Request-ID")
+	requestID := middleware.GetRequestID(c)
 	userID := getUserIDSafe(c)
 
 	logrus.WithFields(logrus.Fields{
@@ -212,6 +294,17 @@ func StorageError(c *gin.Context, storageErr error, operation string) {
 	SafeErrorResponse(c, err, http.StatusInternalServerError)
 }
 
+// ServiceUnavailableError reports that a dependency needed for the request is not available
+// Clients can retry later; nothing was changed on the server
+func ServiceUnavailableError(c *gin.Context, message string) {
+	err := AppError{
+		Type:    ErrorTypeExternal,
+		Code:    "SERVICE_UNAVAILABLE",
+		Message: message,
+	}
+	SafeErrorResponse(c, err, http.StatusServiceUnavailable)
+}
+
 // getUserIDSafe safely extracts user ID for logging
 func getUserIDSafe(c *gin.Context) int {
 	if userID, exists := c.Get("user_id"); exists {
@@ -233,12 +326,22 @@ func getSafeErrorMessage(statusCode int) string {
 		return "Access forbidden"
 	case http.StatusNotFound:
 		return "Resource not found"
+	case http.StatusNotAcceptable:
+		return "Not acceptable"
 	case http.StatusConflict:
 		return "Resource conflict"
+	case http.StatusPreconditionFailed:
+		return "Precondition failed"
+	case http.StatusRequestEntityTooLarge:
+		return "Request body too large"
+	case http.StatusUnprocessableEntity:
+		return "Validation failed"
 	case http.StatusTooManyRequests:
 		return "Too many requests"
 	case http.StatusInternalServerError:
 		return "Internal server error"
+	case http.StatusServiceUnavailable:
+		return "Service unavailable"
 	default:
 		return "An error occurred"
 	}
@@ -0,0 +1,282 @@
+package handlers
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"digital-recipes/api-service/middleware"
+	"digital-recipes/api-service/models"
+	"github.com/gin-gonic/gin"
+	"github.com/go-pdf/fpdf"
+	"golang.org/x/text/unicode/norm"
+)
+
+// loadExportableRecipe loads a recipe and its ingredients for export
+// Unpublished and private recipes are only exportable by their owner or an admin; everyone
+// else gets a 404 so draft and private recipes do not leak. It writes the error response
+// itself and returns false when the caller should stop.
+func (h *RecipeHandler) loadExportableRecipe(c *gin.Context) (models.RecipeWithIngredients, bool) {
+	var result models.RecipeWithIngredients
+
+	recipeID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		BadRequestError(c, "invalid recipe ID")
+		return result, false
+	}
+
+	recipe, err := h.loadRecipe(c.Request.Context(), recipeID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			NotFoundError(c, "recipe not found")
+			return result, false
+		}
+		DatabaseError(c, err, "load recipe for export")
+		return result, false
+	}
+
+	if (recipe.Status != "published" || recipe.Visibility == models.VisibilityPrivate) && !canModifyRecipe(c, recipe.UserID) {
+		middleware.LogWithContext(c).WithField("recipe_id", recipeID).Debug("Export of unpublished or private recipe denied")
+		NotFoundError(c, "recipe not found")
+		return result, false
+	}
+
+	ingredients, err := h.loadRecipeIngredients(c.Request.Context(), recipeID)
+	if err != nil {
+		DatabaseError(c, err, "load recipe ingredients for export")
+		return result, false
+	}
+
+	result.Recipe = recipe
+	result.Ingredients = ingredients
+	return result, true
+}
+
+// toJSONLD maps a recipe onto the schema.org Recipe vocabulary
+// Recipes with a source are credited to it rather than to the user who added them
+func toJSONLD(recipe models.RecipeWithIngredients, authorName string) models.JSONLDRecipe {
+	document := models.JSONLDRecipe{
+		Context:            models.SchemaOrgContext,
+		Type:               "Recipe",
+		Identifier:         strconv.Itoa(recipe.ID),
+		Name:               recipe.Title,
+		RecipeYield:        recipe.Servings,
+		RecipeIngredient:   make([]string, 0, len(recipe.Ingredients)),
+		RecipeInstructions: recipe.Instructions,
+		PrepTime:           models.FormatDuration(recipe.PrepTimeMinutes),
+		CookTime:           models.FormatDuration(recipe.CookTimeMinutes),
+		DateCreated:        recipe.CreatedAt.UTC().Format(time.RFC3339),
+		DateModified:       recipe.UpdatedAt.UTC().Format(time.RFC3339),
+		URL:                recipe.SourceURL,
+	}
+
+	if recipe.SourceName != nil {
+		authorName = *recipe.SourceName
+	}
+	if authorName != "" {
+		document.Author = &models.JSONLDPerson{Type: "Person", Name: authorName}
+	}
+
+	for _, ingredient := range recipe.Ingredients {
+		document.RecipeIngredient = append(document.RecipeIngredient, ingredient.OriginalText)
+	}
+
+	return document
+}
+
+// GetRecipeJSONLD handles GET /recipes/:id/jsonld requests
+func (h *RecipeHandler) GetRecipeJSONLD(c *gin.Context) {
+	recipe, ok := h.loadExportableRecipe(c)
+	if !ok {
+		return
+	}
+
+	var authorName string
+	err := h.db.DB.QueryRow(`SELECT name FROM users WHERE id = $1`, recipe.UserID).Scan(&authorName)
+	if err != nil && err != sql.ErrNoRows {
+		DatabaseError(c, err, "load recipe author")
+		return
+	}
+
+	body, err := json.Marshal(toJSONLD(recipe, authorName))
+	if err != nil {
+		InternalServerError(c, "failed to serialize recipe")
+		return
+	}
+
+	c.Data(http.StatusOK, "application/ld+json; charset=utf-8", body)
+}
+
+// Patterns used when rendering exported recipes
+var (
+	filenameUnsafePattern = regexp.MustCompile(`[^a-z0-9]+`)
+	stepNumberPattern     = regexp.MustCompile(`(?i)^\s*(\d+[.)]|step\s+\d+:?)\s*`)
+)
+
+// exportFilename derives a download filename such as "banana-bread.md" from the recipe title
+// Accents are dropped ("Crème Brûlée" becomes "creme-brulee") to keep the header ASCII
+func exportFilename(recipe models.Recipe, extension string) string {
+	plain := strings.Map(func(r rune) rune {
+		if unicode.Is(unicode.Mn, r) {
+			return -1
+		}
+		return r
+	}, norm.NFD.String(strings.ToLower(recipe.Title)))
+
+	slug := strings.Trim(filenameUnsafePattern.ReplaceAllString(plain, "-"), "-")
+	if len(slug) > 80 {
+		slug = strings.Trim(slug[:80], "-")
+	}
+	if slug == "" {
+		slug = fmt.Sprintf("recipe-%d", recipe.ID)
+	}
+	return slug + "." + extension
+}
+
+// instructionSteps splits instructions into non-empty lines with any existing numbering removed
+func instructionSteps(instructions *string) []string {
+	if instructions == nil {
+		return nil
+	}
+
+	var steps []string
+	for _, line := range strings.Split(*instructions, "\n") {
+		line = strings.TrimSpace(stepNumberPattern.ReplaceAllString(line, ""))
+		if line != "" {
+			steps = append(steps, line)
+		}
+	}
+	return steps
+}
+
+// renderMarkdown renders a recipe as Markdown for note-taking apps
+func renderMarkdown(recipe models.RecipeWithIngredients) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# %s\n\n", strings.TrimSpace(recipe.Title))
+
+	if recipe.Servings != nil && strings.TrimSpace(*recipe.Servings) != "" {
+		fmt.Fprintf(&b, "**Servings:** %s\n\n", strings.TrimSpace(*recipe.Servings))
+	}
+
+	if len(recipe.Ingredients) > 0 {
+		b.WriteString("## Ingredients\n\n")
+		for _, ingredient := range recipe.Ingredients {
+			fmt.Fprintf(&b, "- %s\n", strings.TrimSpace(ingredient.OriginalText))
+		}
+		b.WriteString("\n")
+	}
+
+	if steps := instructionSteps(recipe.Instructions); len(steps) > 0 {
+		b.WriteString("## Instructions\n\n")
+		for i, step := range steps {
+			fmt.Fprintf(&b, "%d. %s\n", i+1, step)
+		}
+		b.WriteString("\n")
+	}
+
+	if recipe.Tips != nil && strings.TrimSpace(*recipe.Tips) != "" {
+		fmt.Fprintf(&b, "## Tips\n\n%s\n", strings.TrimSpace(*recipe.Tips))
+	}
+
+	return strings.TrimRight(b.String(), "\n") + "\n"
+}
+
+// renderPDF lays out a recipe on A4 pages, breaking long instructions across pages
+func renderPDF(recipe models.RecipeWithIngredients) ([]byte, error) {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(20, 20, 20)
+	pdf.SetAutoPageBreak(true, 20)
+	pdf.SetTitle(recipe.Title, true)
+	pdf.AddPage()
+
+	// Core fonts are Latin-1; translate so accented ingredient names render correctly
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+
+	heading := func(text string) {
+		pdf.Ln(4)
+		pdf.SetFont("Helvetica", "B", 14)
+		pdf.CellFormat(0, 8, tr(text), "", 1, "L", false, 0, "")
+		pdf.SetFont("Helvetica", "", 11)
+	}
+
+	pdf.SetFont("Helvetica", "B", 20)
+	pdf.MultiCell(0, 10, tr(strings.TrimSpace(recipe.Title)), "", "L", false)
+
+	pdf.SetFont("Helvetica", "", 11)
+	if recipe.Servings != nil && strings.TrimSpace(*recipe.Servings) != "" {
+		pdf.MultiCell(0, 6, tr("Servings: "+strings.TrimSpace(*recipe.Servings)), "", "L", false)
+	}
+
+	if len(recipe.Ingredients) > 0 {
+		heading("Ingredients")
+		for _, ingredient := range recipe.Ingredients {
+			pdf.MultiCell(0, 6, tr("- "+strings.TrimSpace(ingredient.OriginalText)), "", "L", false)
+		}
+	}
+
+	if steps := instructionSteps(recipe.Instructions); len(steps) > 0 {
+		heading("Instructions")
+		for i, step := range steps {
+			pdf.MultiCell(0, 6, tr(fmt.Sprintf("%d. %s", i+1, step)), "", "L", false)
+			pdf.Ln(1)
+		}
+	}
+
+	if recipe.Tips != nil && strings.TrimSpace(*recipe.Tips) != "" {
+		heading("Tips")
+		pdf.MultiCell(0, 6, tr(strings.TrimSpace(*recipe.Tips)), "", "L", false)
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// getRecipeMarkdown responds with a recipe rendered as Markdown, shown inline rather than as a download
+func (h *RecipeHandler) getRecipeMarkdown(c *gin.Context) {
+	recipe, ok := h.loadExportableRecipe(c)
+	if !ok {
+		return
+	}
+	c.Data(http.StatusOK, "text/markdown; charset=utf-8", []byte(renderMarkdown(recipe)))
+}
+
+// ExportRecipe handles GET /recipes/:id/export requests
+// The format query parameter selects the output and defaults to markdown
+func (h *RecipeHandler) ExportRecipe(c *gin.Context) {
+	format := c.DefaultQuery("format", "markdown")
+	if format != "markdown" && format != "pdf" {
+		BadRequestError(c, "invalid format parameter. Must be one of: markdown, pdf")
+		return
+	}
+
+	recipe, ok := h.loadExportableRecipe(c)
+	if !ok {
+		return
+	}
+
+	if format == "pdf" {
+		document, err := renderPDF(recipe)
+		if err != nil {
+			middleware.LogWithContext(c).WithError(err).Error("Failed to render recipe PDF")
+			InternalServerError(c, "failed to render recipe PDF")
+			return
+		}
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, exportFilename(recipe.Recipe, "pdf")))
+		c.Data(http.StatusOK, "application/pdf", document)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, exportFilename(recipe.Recipe, "md")))
+	c.Data(http.StatusOK, "text/markdown; charset=utf-8", []byte(renderMarkdown(recipe)))
+}
@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"digital-recipes/api-service/buildinfo"
+	"digital-recipes/api-service/db"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultHealthCacheSeconds is how long dependency check results are reused when HEALTH_CACHE_SECONDS is not set
+const DefaultHealthCacheSeconds = 5
+
+// GetHealthCacheTTL returns how long /health reuses dependency check results, from HEALTH_CACHE_SECONDS
+// Zero is allowed and checks the dependencies on every request
+func GetHealthCacheTTL() time.Duration {
+	value := os.Getenv("HEALTH_CACHE_SECONDS")
+	if value == "" {
+		return DefaultHealthCacheSeconds * time.Second
+	}
+
+	seconds, err := strconv.Atoi(value)
+	if err != nil || seconds < 0 {
+		logrus.WithField("HEALTH_CACHE_SECONDS", value).Warn("Invalid HEALTH_CACHE_SECONDS, using default")
+		return DefaultHealthCacheSeconds * time.Second
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// dependencyHealth is the outcome of one round of dependency checks
+type dependencyHealth struct {
+	database  string
+	storage   string
+	checkedAt time.Time
+}
+
+// HealthHandler reports service and dependency health
+type HealthHandler struct {
+	db             *db.Database
+	storageService *StorageService
+	cacheTTL       time.Duration
+
+	mu     sync.Mutex
+	cached *dependencyHealth
+}
+
+// NewHealthHandler creates a new health handler; storageService may be nil when storage is not configured
+func NewHealthHandler(database *db.Database, storageService *StorageService) *HealthHandler {
+	return &HealthHandler{
+		db:             database,
+		storageService: storageService,
+		cacheTTL:       DefaultHealthCacheSeconds * time.Second,
+	}
+}
+
+// SetCacheTTL sets how long dependency check results are reused; zero checks on every request
+func (h *HealthHandler) SetCacheTTL(ttl time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.cacheTTL = ttl
+	h.cached = nil
+}
+
+// dependencies returns the cached dependency results, checking again once they are older than the cache TTL
+// Concurrent requests after expiry wait for a single check rather than each probing the dependencies
+func (h *HealthHandler) dependencies() dependencyHealth {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.cached != nil && time.Since(h.cached.checkedAt) < h.cacheTTL {
+		return *h.cached
+	}
+
+	result := h.checkDependencies()
+	h.cached = &result
+	return result
+}
+
+// checkDependencies pings the database and storage
+// It does not use the request context because the result is shared with other requests
+func (h *HealthHandler) checkDependencies() dependencyHealth {
+	result := dependencyHealth{database: "healthy", storage: "not_configured"}
+
+	// Check database health
+	if err := h.db.HealthCheck(); err != nil {
+		result.database = "unhealthy"
+		logrus.WithError(err).Error("Database health check failed")
+	}
+
+	// Check storage health if available
+	if h.storageService != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := h.storageService.HealthCheck(ctx); err != nil {
+			result.storage = "unhealthy"
+			logrus.WithError(err).Warn("Storage health check failed")
+		} else {
+			result.storage = "healthy"
+		}
+	}
+
+	result.checkedAt = time.Now().UTC()
+	return result
+}
+
+// GetHealth handles GET /health requests
+// Dependency results are cached briefly so frequent probes do not each hit the database and storage
+func (h *HealthHandler) GetHealth(c *gin.Context) {
+	dependencies := h.dependencies()
+
+	build := buildinfo.Get()
+	c.JSON(http.StatusOK, gin.H{
+		"status":     "healthy",
+		"service":    "digital-recipes-api",
+		"database":   dependencies.database,
+		"storage":    dependencies.storage,
+		"checked_at": dependencies.checkedAt.Format(time.RFC3339),
+		"version":    build.Version,
+		"commit":     build.Commit,
+		"build_time": build.BuildTime,
+	})
+}
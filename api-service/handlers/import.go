@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"digital-recipes/api-service/middleware"
+	"digital-recipes/api-service/models"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// ImportRecipe handles POST /recipes/import requests
+// It accepts a schema.org Recipe JSON-LD document and creates a recipe awaiting review
+func (h *RecipeHandler) ImportRecipe(c *gin.Context) {
+	logger := middleware.LogWithContext(c)
+
+	body, err := c.GetRawData()
+	if err != nil {
+		if isBodyTooLarge(err) {
+			RequestTooLargeError(c, "Request body too large")
+			return
+		}
+		ValidationError(c, "Failed to read request body")
+		return
+	}
+
+	document, err := models.ParseJSONLDRecipe(body)
+	if err != nil {
+		logger.WithError(err).Warn("Recipe import rejected")
+		FieldValidationError(c, err)
+		return
+	}
+
+	if errs := h.ingredientLimitErrors("recipeIngredient", len(document.Ingredients())); len(errs) > 0 {
+		FieldValidationErrors(c, errs)
+		return
+	}
+
+	userID := middleware.GetUserID(c)
+	if userID == 0 {
+		AuthenticationError(c, "Authentication required to import recipes")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	tx, err := h.db.DB.BeginTx(ctx, nil)
+	if err != nil {
+		logger.WithError(err).Error("Failed to begin database transaction")
+		InternalServerError(c, "Failed to import recipe")
+		return
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO recipes (title, servings, instructions, status, user_id, prep_time_minutes, cook_time_minutes,
+			source_name, source_url)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING ` + recipeColumns + `
+	`
+
+	var recipe models.Recipe
+	err = tx.QueryRow(query,
+		strings.TrimSpace(document.Name),
+		document.Yield(),
+		document.Instructions(),
+		"review_required",
+		userID,
+		document.PrepTimeMinutes(),
+		document.CookTimeMinutes(),
+		document.SourceName(),
+		document.SourceURL(),
+	).Scan(recipeScanTargets(&recipe)...)
+	if err != nil {
+		DatabaseError(c, err, "create imported recipe")
+		return
+	}
+
+	lines := document.Ingredients()
+	inputs := make([]models.IngredientInput, 0, len(lines))
+	for _, line := range lines {
+		inputs = append(inputs, models.IngredientInput{OriginalText: line})
+	}
+
+	ingredients, err := insertRecipeIngredients(tx, recipe.ID, inputs)
+	if err != nil {
+		DatabaseError(c, err, "create imported ingredients")
+		return
+	}
+
+	if err = tx.Commit(); err != nil {
+		DatabaseError(c, err, "commit recipe import")
+		return
+	}
+
+	logger.WithFields(logrus.Fields{
+		"recipe_id":        recipe.ID,
+		"ingredient_count": len(ingredients),
+	}).Info("Recipe imported from JSON-LD")
+
+	SuccessResponseWithWarnings(c, models.RecipeWithIngredients{
+		Recipe:      recipe,
+		Ingredients: ingredients,
+	}, ingredientWarnings(ingredients))
+}
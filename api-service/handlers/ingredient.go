@@ -0,0 +1,470 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"digital-recipes/api-service/db"
+	"digital-recipes/api-service/ingredients"
+	"digital-recipes/api-service/logctx"
+	"digital-recipes/api-service/middleware"
+	"digital-recipes/api-service/models"
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+	"github.com/sirupsen/logrus"
+)
+
+// IngredientHandler handles canonical ingredient HTTP requests
+type IngredientHandler struct {
+	db         *db.Database
+	pagination PaginationConfig
+}
+
+// NewIngredientHandler creates a new ingredient handler
+func NewIngredientHandler(database *db.Database) *IngredientHandler {
+	return &IngredientHandler{db: database, pagination: DefaultPaginationConfig()}
+}
+
+// SetPagination replaces the page size bounds used by the ingredient listing
+func (h *IngredientHandler) SetPagination(config PaginationConfig) {
+	h.pagination = config
+}
+
+// errEmptyIngredientName is returned when normalization leaves nothing to match on
+var errEmptyIngredientName = errors.New("ingredient text has no name after removing quantities and units")
+
+// queryRower is satisfied by both *sql.DB and *sql.Tx
+type queryRower interface {
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// dbQuerier is satisfied by both *sql.DB and *sql.Tx for helpers reading several rows
+type dbQuerier interface {
+	queryRower
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// scanCanonicalIngredient scans a canonical_ingredients row selected in column order
+func scanCanonicalIngredient(row *sql.Row, ingredient *models.CanonicalIngredient) error {
+	return row.Scan(
+		&ingredient.ID,
+		&ingredient.Name,
+		&ingredient.IsApproved,
+		&ingredient.CreatedAt,
+		&ingredient.UpdatedAt,
+	)
+}
+
+// findOrCreateCanonicalIngredient resolves free-form text to a canonical ingredient
+// An existing ingredient matching the normalized name (case-insensitively, singular or plural)
+// is returned as is; otherwise a new unapproved ingredient is created for admin review
+func findOrCreateCanonicalIngredient(q queryRower, originalText string) (models.CanonicalIngredient, string, bool, error) {
+	var ingredient models.CanonicalIngredient
+
+	name := ingredients.NormalizeName(originalText)
+	if name == "" {
+		return ingredient, name, false, errEmptyIngredientName
+	}
+
+	selectQuery := `
+		SELECT id, name, is_approved, created_at, updated_at
+		FROM canonical_ingredients
+		WHERE LOWER(name) = ANY($1)
+		ORDER BY is_approved DESC, id ASC
+		LIMIT 1
+	`
+
+	err := scanCanonicalIngredient(q.QueryRow(selectQuery, pq.Array(ingredients.NameCandidates(name))), &ingredient)
+	if err == nil {
+		return ingredient, name, false, nil
+	}
+	if err != sql.ErrNoRows {
+		return ingredient, name, false, err
+	}
+
+	// A concurrent request may insert the same name between the lookup and the insert;
+	// ON CONFLICT turns that into an empty result and we re-read the winner's row
+	insertQuery := `
+		INSERT INTO canonical_ingredients (name, is_approved)
+		VALUES ($1, false)
+		ON CONFLICT DO NOTHING
+		RETURNING id, name, is_approved, created_at, updated_at
+	`
+
+	err = scanCanonicalIngredient(q.QueryRow(insertQuery, name), &ingredient)
+	if err == nil {
+		return ingredient, name, true, nil
+	}
+	if err != sql.ErrNoRows {
+		return ingredient, name, false, err
+	}
+
+	err = scanCanonicalIngredient(q.QueryRow(selectQuery, pq.Array([]string{name})), &ingredient)
+	return ingredient, name, false, err
+}
+
+// LinkOrCreate handles POST /ingredients/link-or-create requests
+func (h *IngredientHandler) LinkOrCreate(c *gin.Context) {
+	logger := middleware.LogWithContext(c)
+
+	var request models.LinkOrCreateRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		logger.WithError(err).Warn("Link-or-create binding failed")
+		if isBodyTooLarge(err) {
+			RequestTooLargeError(c, "Request body too large")
+			return
+		}
+		ValidationError(c, "Invalid request format. Check original_text field.", "original_text")
+		return
+	}
+
+	if middleware.GetUserID(c) == 0 {
+		AuthenticationError(c, "Authentication required to create ingredients")
+		return
+	}
+
+	ingredient, name, created, err := findOrCreateCanonicalIngredient(h.db.DB, request.OriginalText)
+	if err != nil {
+		if err == errEmptyIngredientName {
+			ValidationError(c, err.Error(), "original_text")
+			return
+		}
+		DatabaseError(c, err, "link or create canonical ingredient")
+		return
+	}
+
+	logger.WithFields(logrus.Fields{
+		"canonical_ingredient_id": ingredient.ID,
+		"normalized_name":         name,
+		"created":                 created,
+	}).Info("Canonical ingredient resolved")
+
+	SuccessResponse(c, models.LinkOrCreateResponse{
+		Ingredient:     ingredient,
+		NormalizedName: name,
+		Created:        created,
+	})
+}
+
+// cleanIngredientName trims an admin-entered ingredient name and collapses its inner whitespace
+func cleanIngredientName(name string) string {
+	return strings.Join(strings.Fields(name), " ")
+}
+
+// CreateIngredient handles POST /ingredients requests from admins curating the canonical list
+// Names are compared case-insensitively so "Eggs" conflicts with an existing "eggs"
+func (h *IngredientHandler) CreateIngredient(c *gin.Context) {
+	logger := middleware.LogWithContext(c)
+
+	var request models.CreateIngredientRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		logger.WithError(err).Warn("Create ingredient binding failed")
+		if isBodyTooLarge(err) {
+			RequestTooLargeError(c, "Request body too large")
+			return
+		}
+		ValidationError(c, "Invalid request format. Check name field.", "name")
+		return
+	}
+
+	name := cleanIngredientName(request.Name)
+	if name == "" {
+		ValidationError(c, "Ingredient name cannot be empty", "name")
+		return
+	}
+
+	isApproved := true
+	if request.IsApproved != nil {
+		isApproved = *request.IsApproved
+	}
+
+	var existingID int
+	err := h.db.DB.QueryRow(`SELECT id FROM canonical_ingredients WHERE LOWER(name) = LOWER($1)`, name).Scan(&existingID)
+	if err == nil {
+		logger.WithField("canonical_ingredient_id", existingID).Info("Canonical ingredient already exists")
+		ConflictError(c, "An ingredient with this name already exists")
+		return
+	}
+	if err != sql.ErrNoRows {
+		DatabaseError(c, err, "check canonical ingredient name")
+		return
+	}
+
+	// The lower(name) unique index still catches a concurrent insert of the same name
+	var ingredient models.CanonicalIngredient
+	err = scanCanonicalIngredient(h.db.DB.QueryRow(`
+		INSERT INTO canonical_ingredients (name, is_approved)
+		VALUES ($1, $2)
+		RETURNING id, name, is_approved, created_at, updated_at
+	`, name, isApproved), &ingredient)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == uniqueViolation {
+			ConflictError(c, "An ingredient with this name already exists")
+			return
+		}
+		DatabaseError(c, err, "create canonical ingredient")
+		return
+	}
+
+	logger.WithField("canonical_ingredient_id", ingredient.ID).Info("Canonical ingredient created")
+	SuccessResponse(c, ingredient)
+}
+
+// RenameIngredient handles PATCH /ingredients/:id requests from admins correcting a name
+// Recipe ingredients link to the ingredient by ID, so they show the new name straight away.
+// Changing only the case of a name is allowed; taking another ingredient's name is a conflict
+func (h *IngredientHandler) RenameIngredient(c *gin.Context) {
+	logger := middleware.LogWithContext(c)
+
+	ingredientID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		BadRequestError(c, "invalid ingredient ID")
+		return
+	}
+
+	var request models.RenameIngredientRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		logger.WithError(err).Warn("Rename ingredient binding failed")
+		if isBodyTooLarge(err) {
+			RequestTooLargeError(c, "Request body too large")
+			return
+		}
+		ValidationError(c, "Invalid request format. Check name field.", "name")
+		return
+	}
+
+	name := cleanIngredientName(request.Name)
+	if name == "" {
+		ValidationError(c, "Ingredient name cannot be empty", "name")
+		return
+	}
+
+	var existingID int
+	err = h.db.DB.QueryRow(`
+		SELECT id FROM canonical_ingredients WHERE LOWER(name) = LOWER($1) AND id <> $2
+	`, name, ingredientID).Scan(&existingID)
+	if err == nil {
+		logger.WithField("canonical_ingredient_id", existingID).Info("Canonical ingredient name already taken")
+		ConflictError(c, "An ingredient with this name already exists")
+		return
+	}
+	if err != sql.ErrNoRows {
+		DatabaseError(c, err, "check canonical ingredient name")
+		return
+	}
+
+	// The lower(name) unique index still catches a concurrent create or rename to the same name
+	var ingredient models.CanonicalIngredient
+	err = scanCanonicalIngredient(h.db.DB.QueryRow(`
+		UPDATE canonical_ingredients SET name = $1
+		WHERE id = $2
+		RETURNING id, name, is_approved, created_at, updated_at
+	`, name, ingredientID), &ingredient)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			NotFoundError(c, "ingredient not found")
+			return
+		}
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == uniqueViolation {
+			ConflictError(c, "An ingredient with this name already exists")
+			return
+		}
+		DatabaseError(c, err, "rename canonical ingredient")
+		return
+	}
+
+	logger.WithFields(logrus.Fields{
+		"canonical_ingredient_id": ingredient.ID,
+		"name":                    ingredient.Name,
+	}).Info("Canonical ingredient renamed")
+	SuccessResponse(c, ingredient)
+}
+
+// ingredientNotFoundError aborts an ingredient merge when one of its ingredients does not exist
+type ingredientNotFoundError struct {
+	message string
+}
+
+func (e *ingredientNotFoundError) Error() string {
+	return e.message
+}
+
+// MergeIngredients handles POST /ingredients/merge requests from admins removing duplicate canonical ingredients
+// Every recipe ingredient linked to the source is linked to the target instead and the source is deleted,
+// all in one transaction so no link is lost to the source's ON DELETE SET NULL
+func (h *IngredientHandler) MergeIngredients(c *gin.Context) {
+	logger := middleware.LogWithContext(c)
+
+	var request models.MergeIngredientsRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		logger.WithError(err).Warn("Merge ingredients binding failed")
+		if isBodyTooLarge(err) {
+			RequestTooLargeError(c, "Request body too large")
+			return
+		}
+		ValidationError(c, "Invalid request format. Check source_id and target_id fields.")
+		return
+	}
+
+	if request.SourceID == request.TargetID {
+		ValidationError(c, "An ingredient cannot be merged into itself", "target_id")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(logctx.WithLogger(context.Background(), logger), 30*time.Second)
+	defer cancel()
+
+	var target models.CanonicalIngredient
+	var repointed int64
+	err := h.db.WithRetryTx(ctx, func(tx *sql.Tx) error {
+		// Lock both rows in ID order so two merges of the same pair cannot deadlock
+		first, second := request.SourceID, request.TargetID
+		if first > second {
+			first, second = second, first
+		}
+		for _, id := range []int{first, second} {
+			var locked int
+			err := tx.QueryRow(`SELECT id FROM canonical_ingredients WHERE id = $1 FOR UPDATE`, id).Scan(&locked)
+			if err == sql.ErrNoRows {
+				if id == request.SourceID {
+					return &ingredientNotFoundError{message: "source ingredient not found"}
+				}
+				return &ingredientNotFoundError{message: "target ingredient not found"}
+			}
+			if err != nil {
+				return err
+			}
+		}
+
+		result, err := tx.Exec(`
+			UPDATE recipe_ingredients SET canonical_ingredient_id = $2
+			WHERE canonical_ingredient_id = $1
+		`, request.SourceID, request.TargetID)
+		if err != nil {
+			return err
+		}
+		if repointed, err = result.RowsAffected(); err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec(`DELETE FROM canonical_ingredients WHERE id = $1`, request.SourceID); err != nil {
+			return err
+		}
+
+		return scanCanonicalIngredient(tx.QueryRow(`
+			SELECT id, name, is_approved, created_at, updated_at
+			FROM canonical_ingredients
+			WHERE id = $1
+		`, request.TargetID), &target)
+	})
+	var notFound *ingredientNotFoundError
+	if errors.As(err, &notFound) {
+		NotFoundError(c, notFound.message)
+		return
+	}
+	if err != nil {
+		DatabaseError(c, err, "merge canonical ingredients")
+		return
+	}
+
+	logger.WithFields(logrus.Fields{
+		"source_id":                    request.SourceID,
+		"target_id":                    request.TargetID,
+		"recipe_ingredients_repointed": repointed,
+	}).Info("Canonical ingredients merged")
+
+	SuccessResponse(c, models.MergeIngredientsResponse{
+		Target:                     target,
+		RecipeIngredientsRepointed: int(repointed),
+	})
+}
+
+// ingredientSortOrders maps the sort values accepted by GetIngredients to ORDER BY clauses
+// Ties are broken by name and ID so pages stay stable
+var ingredientSortOrders = map[string]string{
+	"name":  "LOWER(ci.name) ASC, ci.id ASC",
+	"usage": "usage_count DESC, LOWER(ci.name) ASC, ci.id ASC",
+}
+
+// GetIngredients handles GET /ingredients requests from admins reviewing the canonical list
+// sort=usage lists the most used ingredients first so the ones worth approving surface early,
+// and approved=false narrows the list to ingredients still awaiting review.
+// Usage is counted from recipe_ingredients on every request rather than kept in a trigger-maintained column:
+// the count is only needed by this admin listing, the join uses idx_recipe_ingredients_canonical_id,
+// and a computed count can never drift from the links that merges, relinks and ON DELETE SET NULL change
+func (h *IngredientHandler) GetIngredients(c *gin.Context) {
+	page, perPage, paginationErr := parsePagination(c, h.pagination)
+	if paginationErr != nil {
+		SafeErrorResponse(c, *paginationErr, http.StatusBadRequest)
+		return
+	}
+
+	sort := c.DefaultQuery("sort", "name")
+	order, ok := ingredientSortOrders[sort]
+	if !ok {
+		ValidationError(c, "invalid sort parameter. Must be one of name, usage", "sort")
+		return
+	}
+
+	var approved *bool
+	if value, set := c.GetQuery("approved"); set {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			ValidationError(c, "invalid approved parameter. Must be true or false", "approved")
+			return
+		}
+		approved = &parsed
+	}
+
+	rows, err := h.db.QueryContextLogged(c.Request.Context(), `
+		SELECT ci.id, ci.name, ci.is_approved, ci.created_at, ci.updated_at,
+			COUNT(ri.id) AS usage_count,
+			COUNT(*) OVER() AS total_count
+		FROM canonical_ingredients ci
+		LEFT JOIN recipe_ingredients ri ON ri.canonical_ingredient_id = ci.id
+		WHERE $1::boolean IS NULL OR ci.is_approved = $1
+		GROUP BY ci.id
+		ORDER BY `+order+`
+		LIMIT $2 OFFSET $3
+	`, approved, perPage, (page-1)*perPage)
+	if err != nil {
+		DatabaseError(c, err, "list canonical ingredients")
+		return
+	}
+	defer rows.Close()
+
+	listed := []models.IngredientUsage{}
+	var total int
+	for rows.Next() {
+		var ingredient models.IngredientUsage
+		if err := rows.Scan(
+			&ingredient.ID,
+			&ingredient.Name,
+			&ingredient.IsApproved,
+			&ingredient.CreatedAt,
+			&ingredient.UpdatedAt,
+			&ingredient.UsageCount,
+			&total,
+		); err != nil {
+			DatabaseError(c, err, "read canonical ingredient")
+			return
+		}
+		listed = append(listed, ingredient)
+	}
+	if err := rows.Err(); err != nil {
+		DatabaseError(c, err, "read canonical ingredients")
+		return
+	}
+
+	SuccessResponseWithPagination(c, listed, &Pagination{
+		Page:       page,
+		PerPage:    perPage,
+		Total:      total,
+		TotalPages: (total + perPage - 1) / perPage,
+	})
+}
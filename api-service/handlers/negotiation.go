@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"mime"
+	"strconv"
+	"strings"
+)
+
+// Media types GET /recipes/:id can respond with, chosen from the Accept header
+const (
+	mediaTypeJSON     = "application/json"
+	mediaTypeJSONLD   = "application/ld+json"
+	mediaTypeMarkdown = "text/markdown"
+)
+
+// acceptRange is one entry of an Accept header, such as text/* with its quality
+type acceptRange struct {
+	mediaType string
+	quality   float64
+}
+
+// parseAccept splits an Accept header into its media ranges, skipping entries that cannot be parsed
+func parseAccept(header string) []acceptRange {
+	var ranges []acceptRange
+	for _, part := range strings.Split(header, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		quality := 1.0
+		if q, ok := params["q"]; ok {
+			quality, err = strconv.ParseFloat(q, 64)
+			if err != nil || quality < 0 || quality > 1 {
+				continue
+			}
+		}
+		ranges = append(ranges, acceptRange{mediaType: mediaType, quality: quality})
+	}
+	return ranges
+}
+
+// acceptQuality returns the quality the ranges give offer, taken from the most specific matching range
+// An offer no range matches gets zero
+func acceptQuality(ranges []acceptRange, offer string) float64 {
+	offerType, _, _ := strings.Cut(offer, "/")
+	quality, specificity := 0.0, -1
+	for _, r := range ranges {
+		rangeType, rangeSubtype, _ := strings.Cut(r.mediaType, "/")
+		var matched int
+		switch {
+		case r.mediaType == offer:
+			matched = 2
+		case rangeType == offerType && rangeSubtype == "*":
+			matched = 1
+		case r.mediaType == "*/*":
+			matched = 0
+		default:
+			continue
+		}
+		if matched > specificity {
+			quality, specificity = r.quality, matched
+		}
+	}
+	return quality
+}
+
+// negotiateMediaType picks the offer the Accept header rates highest, preferring earlier offers on ties
+// A missing header, or one where no entry parses, accepts the first offer. ok is false when the client
+// accepts none of the offers and should get 406 Not Acceptable
+func negotiateMediaType(accept string, offers ...string) (string, bool) {
+	ranges := parseAccept(accept)
+	if len(ranges) == 0 {
+		return offers[0], true
+	}
+
+	best, bestQuality := "", 0.0
+	for _, offer := range offers {
+		if quality := acceptQuality(ranges, offer); quality > bestQuality {
+			best, bestQuality = offer, quality
+		}
+	}
+	return best, best != ""
+}
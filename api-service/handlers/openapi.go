@@ -0,0 +1,19 @@
+package handlers
+
+import (
+	_ "embed"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// openAPISpec is the hand-maintained OpenAPI 3 document for the public API
+// Keep openapi.json in sync when routes or response schemas change
+//
+//go:embed openapi.json
+var openAPISpec []byte
+
+// GetOpenAPISpec handles GET /openapi.json requests
+func GetOpenAPISpec(c *gin.Context) {
+	c.Data(http.StatusOK, "application/json; charset=utf-8", openAPISpec)
+}
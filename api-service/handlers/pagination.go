@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// Built-in pagination bounds, used when PAGINATION_DEFAULT, PAGINATION_MAX and PAGINATION_MAX_OFFSET are not set
+const (
+	DefaultPerPage    = 10
+	DefaultMaxPerPage = 100
+	DefaultMaxOffset  = 100000
+	maxPage           = 10000 // Deepest page queried, to bound offset cost
+)
+
+// PaginationConfig bounds the page sizes accepted by paginated endpoints
+// MaxOffset caps how many rows a page may skip, since Postgres reads every row an OFFSET skips.
+// Zero, as in a config built without it, applies no cap
+type PaginationConfig struct {
+	DefaultPerPage int
+	MaxPerPage     int
+	MaxOffset      int
+}
+
+// DefaultPaginationConfig returns the built-in pagination bounds
+func DefaultPaginationConfig() PaginationConfig {
+	return PaginationConfig{
+		DefaultPerPage: DefaultPerPage,
+		MaxPerPage:     DefaultMaxPerPage,
+		MaxOffset:      DefaultMaxOffset,
+	}
+}
+
+// Validate checks that both bounds are positive and the default fits under the max
+func (p PaginationConfig) Validate() error {
+	if p.DefaultPerPage < 1 || p.MaxPerPage < 1 {
+		return fmt.Errorf("pagination sizes must be positive (default %d, max %d)", p.DefaultPerPage, p.MaxPerPage)
+	}
+	if p.DefaultPerPage > p.MaxPerPage {
+		return fmt.Errorf("PAGINATION_DEFAULT (%d) must not exceed PAGINATION_MAX (%d)", p.DefaultPerPage, p.MaxPerPage)
+	}
+	return nil
+}
+
+// GetPaginationConfig reads PAGINATION_DEFAULT, PAGINATION_MAX and PAGINATION_MAX_OFFSET
+// Unparseable values fall back to the built-in bounds; a combination that fails Validate is returned as an error
+func GetPaginationConfig() (PaginationConfig, error) {
+	config := DefaultPaginationConfig()
+	config.DefaultPerPage = positiveIntEnv("PAGINATION_DEFAULT", DefaultPerPage)
+	config.MaxPerPage = positiveIntEnv("PAGINATION_MAX", DefaultMaxPerPage)
+	config.MaxOffset = positiveIntEnv("PAGINATION_MAX_OFFSET", DefaultMaxOffset)
+	return config, config.Validate()
+}
+
+// positiveIntEnv reads a positive integer setting such as a page size from the environment
+func positiveIntEnv(name string, fallback int) int {
+	value := os.Getenv(name)
+	if value == "" {
+		return fallback
+	}
+
+	size, err := strconv.Atoi(value)
+	if err != nil || size < 1 {
+		logrus.WithField(name, value).Warnf("Invalid %s, using default", name)
+		return fallback
+	}
+	return size
+}
+
+// parsePagination reads and validates the page and per_page query parameters against config
+// The returned error names the offending parameter in its Field so clients know which one to fix
+func parsePagination(c *gin.Context, config PaginationConfig) (page, perPage int, appErr *AppError) {
+	return parsePaginationParams(c, config, "page", "per_page")
+}
+
+// parsePaginationParams is parsePagination for endpoints that name their page parameters differently
+func parsePaginationParams(c *gin.Context, config PaginationConfig, pageParam, perPageParam string) (page, perPage int, appErr *AppError) {
+	page, perPage, appErr = parseOpenEndedPagination(c, config, pageParam, perPageParam)
+	if appErr == nil && page > maxPage {
+		paginationErr := NewValidationError("INVALID_INPUT", fmt.Sprintf("invalid %s parameter. Must be between 1 and %d", pageParam, maxPage), pageParam)
+		return 0, 0, &paginationErr
+	}
+	if appErr == nil {
+		appErr = checkPageOffset(c, config, page, perPage, pageParam, filtersOffsetHint)
+	}
+	if appErr != nil {
+		return 0, 0, appErr
+	}
+	return page, perPage, nil
+}
+
+// Offset hints tell clients refused by checkPageOffset how to reach deeper results
+const (
+	filtersOffsetHint = "Narrow the results with filters instead"
+	// recipesOffsetHint points GET /recipes clients at keyset paging: recipes are listed newest first,
+	// so each further page starts at the created_at of the last recipe already received
+	recipesOffsetHint = "Page through recipes with created_before instead, set to the created_at of the last recipe received"
+)
+
+// checkPageOffset rejects a page that would skip more than config.MaxOffset rows to reach
+// Clients paging that deep are usually scanning by accident, so hint tells them what to do instead
+func checkPageOffset(c *gin.Context, config PaginationConfig, page, perPage int, pageParam, hint string) *AppError {
+	offset := (page - 1) * perPage
+	if config.MaxOffset == 0 || offset <= config.MaxOffset {
+		return nil
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"page":       page,
+		"per_page":   perPage,
+		"offset":     offset,
+		"max_offset": config.MaxOffset,
+		"ip":         c.ClientIP(),
+		"path":       c.Request.URL.Path,
+	}).Warn("Pagination offset beyond the configured ceiling")
+
+	offsetErr := NewValidationError("OFFSET_TOO_LARGE", fmt.Sprintf(
+		"%s %d skips %d results, more than the %d that can be paged through. %s",
+		pageParam, page, offset, config.MaxOffset, hint), pageParam)
+	return &offsetErr
+}
+
+// parseOpenEndedPagination is parsePaginationParams without the page ceiling, for listings clients iterate to the end of
+// Callers answer pages past maxPage with no results instead of querying them
+func parseOpenEndedPagination(c *gin.Context, config PaginationConfig, pageParam, perPageParam string) (page, perPage int, appErr *AppError) {
+	page, err := strconv.Atoi(c.DefaultQuery(pageParam, "1"))
+	if err != nil || page < 1 {
+		paginationErr := NewValidationError("INVALID_INPUT", fmt.Sprintf("invalid %s parameter. Must be a positive integer", pageParam), pageParam)
+		return 0, 0, &paginationErr
+	}
+
+	perPage, err = strconv.Atoi(c.DefaultQuery(perPageParam, strconv.Itoa(config.DefaultPerPage)))
+	if err != nil || perPage < 1 || perPage > config.MaxPerPage {
+		paginationErr := NewValidationError("INVALID_INPUT", fmt.Sprintf("invalid %s parameter. Must be between 1 and %d", perPageParam, config.MaxPerPage), perPageParam)
+		return 0, 0, &paginationErr
+	}
+
+	return page, perPage, nil
+}
+
+// paginateSlice returns the page of items and its metadata; pages past the end are empty
+func paginateSlice[T any](items []T, page, perPage int) ([]T, *Pagination) {
+	total := len(items)
+	pagination := &Pagination{
+		Page:       page,
+		PerPage:    perPage,
+		Total:      total,
+		TotalPages: (total + perPage - 1) / perPage,
+	}
+
+	start := (page - 1) * perPage
+	if start >= total {
+		return []T{}, pagination
+	}
+	end := start + perPage
+	if end > total {
+		end = total
+	}
+	return items[start:end], pagination
+}
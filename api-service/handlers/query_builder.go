@@ -2,7 +2,11 @@ package handlers
 
 import (
 	"fmt"
+	"slices"
 	"strings"
+	"time"
+
+	"digital-recipes/api-service/models"
 )
 
 // QueryBuilder helps build safe SQL queries with parameterized values
@@ -10,6 +14,8 @@ type QueryBuilder struct {
 	baseQuery string
 	args      []interface{}
 	argIndex  int
+	// hasWhere tracks conditions added by the builder; WHERE inside subqueries of the base query does not count
+	hasWhere bool
 }
 
 // NewQueryBuilder creates a new query builder
@@ -21,12 +27,37 @@ func NewQueryBuilder(baseQuery string) *QueryBuilder {
 	}
 }
 
+// comparisonOperators lists the operators AddComparison accepts
+var comparisonOperators = map[string]bool{
+	"=":  true,
+	"<":  true,
+	"<=": true,
+	">":  true,
+	">=": true,
+}
+
 // AddWhereCondition adds a WHERE condition to the query
 func (qb *QueryBuilder) AddWhereCondition(field string, value interface{}) {
-	if !strings.Contains(qb.baseQuery, "WHERE") {
-		qb.baseQuery += fmt.Sprintf(" WHERE %s = $%d", field, qb.argIndex)
+	qb.AddComparison(field, "=", value)
+}
+
+// AddComparison adds a WHERE condition comparing field to a parameterized value
+// Unknown operators are ignored rather than interpolated into the query
+func (qb *QueryBuilder) AddComparison(field, operator string, value interface{}) {
+	if !comparisonOperators[operator] {
+		return
+	}
+
+	qb.addCondition(fmt.Sprintf("%s %s $%%d", field, operator), value)
+}
+
+// addCondition adds a WHERE condition written with %d where its single parameter goes
+func (qb *QueryBuilder) addCondition(condition string, value interface{}) {
+	if !qb.hasWhere {
+		qb.baseQuery += " WHERE " + fmt.Sprintf(condition, qb.argIndex)
+		qb.hasWhere = true
 	} else {
-		qb.baseQuery += fmt.Sprintf(" AND %s = $%d", field, qb.argIndex)
+		qb.baseQuery += " AND " + fmt.Sprintf(condition, qb.argIndex)
 	}
 	qb.args = append(qb.args, value)
 	qb.argIndex++
@@ -96,7 +127,9 @@ type RecipesQueryBuilder struct {
 func NewRecipesQueryBuilder() *RecipesQueryBuilder {
 	baseQuery := `
 		SELECT 
-			id, title, servings, instructions, tips, status, user_id, created_at, updated_at,
+			` + recipeColumns + `,
+			(SELECT COUNT(*) FROM recipe_ingredients ri WHERE ri.recipe_id = recipes.id) as ingredient_count,
+			EXISTS (SELECT 1 FROM recipe_images img WHERE img.recipe_id = recipes.id) as has_images,
 			COUNT(*) OVER() as total_count
 		FROM recipes`
 	
@@ -105,6 +138,18 @@ func NewRecipesQueryBuilder() *RecipesQueryBuilder {
 	}
 }
 
+// NewRecipesCountQueryBuilder creates a recipes query builder that only counts matching rows
+// Filters are shared with NewRecipesQueryBuilder so counts always match the list endpoint
+func NewRecipesCountQueryBuilder() *RecipesQueryBuilder {
+	baseQuery := `
+		SELECT COUNT(*)
+		FROM recipes`
+
+	return &RecipesQueryBuilder{
+		QueryBuilder: NewQueryBuilder(baseQuery),
+	}
+}
+
 // WithStatus adds status filter
 func (rqb *RecipesQueryBuilder) WithStatus(status string) *RecipesQueryBuilder {
 	// Validate status against known valid values
@@ -120,6 +165,52 @@ func (rqb *RecipesQueryBuilder) WithStatus(status string) *RecipesQueryBuilder {
 	return rqb
 }
 
+// WithCreatedRange limits results to recipes created within an inclusive range; nil bounds are open
+func (rqb *RecipesQueryBuilder) WithCreatedRange(after, before *time.Time) *RecipesQueryBuilder {
+	if after != nil {
+		rqb.AddComparison("created_at", ">=", *after)
+	}
+	if before != nil {
+		rqb.AddComparison("created_at", "<=", *before)
+	}
+	return rqb
+}
+
+// totalTimeExpression is a recipe's prep plus cook time, treating a missing half as zero
+// It is NULL when neither time is known so such recipes never match a time filter
+const totalTimeExpression = "COALESCE(prep_time_minutes + cook_time_minutes, prep_time_minutes, cook_time_minutes)"
+
+// WithMaxTotalTime limits results to recipes that take at most the given minutes to prepare and cook
+func (rqb *RecipesQueryBuilder) WithMaxTotalTime(minutes int) *RecipesQueryBuilder {
+	rqb.AddComparison(totalTimeExpression, "<=", minutes)
+	return rqb
+}
+
+// WithDifficulty adds difficulty filter
+func (rqb *RecipesQueryBuilder) WithDifficulty(difficulty string) *RecipesQueryBuilder {
+	if slices.Contains(models.RecipeDifficulties, difficulty) {
+		rqb.AddWhereCondition("difficulty", difficulty)
+	}
+	return rqb
+}
+
+// WithVisibleTo limits results to public recipes plus, for a signed-in user, every recipe they own
+// Anonymous callers pass a userID of 0 and only see public recipes
+func (rqb *RecipesQueryBuilder) WithVisibleTo(userID int) *RecipesQueryBuilder {
+	if userID == 0 {
+		rqb.AddWhereCondition("visibility", models.VisibilityPublic)
+		return rqb
+	}
+	rqb.addCondition("(visibility = '"+models.VisibilityPublic+"' OR user_id = $%d)", userID)
+	return rqb
+}
+
+// WithFeatured limits results to recipes admins have featured
+func (rqb *RecipesQueryBuilder) WithFeatured() *RecipesQueryBuilder {
+	rqb.AddWhereCondition("featured", true)
+	return rqb
+}
+
 // WithPagination adds pagination
 func (rqb *RecipesQueryBuilder) WithPagination(limit, offset int) *RecipesQueryBuilder {
 	rqb.AddOrderBy("created_at", "DESC")
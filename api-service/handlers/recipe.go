@@ -3,15 +3,25 @@ package handlers
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
+	"reflect"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
 
 	"digital-recipes/api-service/db"
+	"digital-recipes/api-service/ingredients"
+	"digital-recipes/api-service/logctx"
 	"digital-recipes/api-service/middleware"
 	"digital-recipes/api-service/models"
+	"digital-recipes/api-service/webhooks"
 	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/lib/pq"
 	"github.com/sirupsen/logrus"
 )
 
@@ -19,6 +29,10 @@ import (
 type RecipeHandler struct {
 	db             *db.Database
 	storageService *StorageService
+	webhooks       *webhooks.Dispatcher
+	statusBroker   *StatusBroker
+	pagination     PaginationConfig
+	maxIngredients int
 }
 
 // NewRecipeHandler creates a new recipe handler
@@ -26,22 +40,28 @@ func NewRecipeHandler(database *db.Database, storageService *StorageService) *Re
 	return &RecipeHandler{
 		db:             database,
 		storageService: storageService,
+		statusBroker:   NewStatusBroker(),
+		pagination:     DefaultPaginationConfig(),
+		maxIngredients: DefaultMaxIngredientsPerRecipe,
 	}
 }
 
-// Constants for pagination limits
-const (
-	maxPerPage     = 100
-	defaultPerPage = 10
-	maxPage        = 10000 // Prevent excessive offset calculations
-)
+// SetPagination replaces the page size bounds used by the handler's list endpoints
+func (h *RecipeHandler) SetPagination(config PaginationConfig) {
+	h.pagination = config
+}
+
+// SetMaxIngredients replaces the number of ingredients a recipe may have
+func (h *RecipeHandler) SetMaxIngredients(max int) {
+	h.maxIngredients = max
+}
 
 // GetRecipes handles GET /recipes requests
 func (h *RecipeHandler) GetRecipes(c *gin.Context) {
 	// Parse query parameters
 	status := c.Query("status")
 	pageStr := c.DefaultQuery("page", "1")
-	perPageStr := c.DefaultQuery("per_page", strconv.Itoa(defaultPerPage))
+	perPageStr := c.DefaultQuery("per_page", strconv.Itoa(h.pagination.DefaultPerPage))
 	
 	// Log request parameters
 	logrus.WithFields(logrus.Fields{
@@ -51,16 +71,10 @@ func (h *RecipeHandler) GetRecipes(c *gin.Context) {
 		"ip":       c.ClientIP(),
 	}).Debug("GetRecipes request")
 
-	// Validate pagination parameters with proper bounds
-	page, err := strconv.Atoi(pageStr)
-	if err != nil || page < 1 || page > maxPage {
-		BadRequestError(c, fmt.Sprintf("invalid page parameter. Must be between 1 and %d", maxPage))
-		return
-	}
-	
-	perPage, err := strconv.Atoi(perPageStr)
-	if err != nil || perPage < 1 || perPage > maxPerPage {
-		BadRequestError(c, fmt.Sprintf("invalid per_page parameter. Must be between 1 and %d", maxPerPage))
+	// Validate pagination parameters; pages past the end are answered empty rather than rejected
+	page, perPage, paginationErr := parseOpenEndedPagination(c, h.pagination, "page", "per_page")
+	if paginationErr != nil {
+		SafeErrorResponse(c, *paginationErr, http.StatusBadRequest)
 		return
 	}
 
@@ -85,13 +99,72 @@ func (h *RecipeHandler) GetRecipes(c *gin.Context) {
 		}
 	}
 
+	createdAfter, createdBefore, ok := parseCreatedRange(c)
+	if !ok {
+		return
+	}
+	maxTotalTime, difficulty, ok := parseRecipeAttributeFilters(c)
+	if !ok {
+		return
+	}
+	// Listings show public recipes, and each caller's own; admins reviewing recipes see them all
+	filters := recipeListFilters{
+		viewerID:      middleware.GetUserID(c),
+		allVisible:    middleware.IsAdmin(c),
+		status:        status,
+		createdAfter:  createdAfter,
+		createdBefore: createdBefore,
+		maxTotalTime:  maxTotalTime,
+		difficulty:    difficulty,
+	}
+
+	includeIngredients, ok := parseRecipeIncludes(c)
+	if !ok {
+		return
+	}
+
+	// Count-only requests skip fetching recipe rows entirely
+	if c.Query("count_only") == "true" {
+		h.getRecipesCount(c, filters, page, perPage, nil)
+		return
+	}
+
+	// Pages must stay within the offset ceiling whether or not they would be queried, so deeper
+	// pages are never answered where shallower ones are refused
+	if offsetErr := checkPageOffset(c, h.pagination, page, perPage, "page", recipesOffsetHint); offsetErr != nil {
+		SafeErrorResponse(c, *offsetErr, http.StatusBadRequest)
+		return
+	}
+
+	// Pages beyond the ceiling are not queried, so their offset cost stays bounded
+	// They are only answered empty when the count shows they really are past the end
+	if page > maxPage {
+		total, err := h.countRecipes(c, filters)
+		if err != nil {
+			logrus.WithError(err).Error("GetRecipes count query error")
+			InternalServerError(c, "failed to count recipes")
+			return
+		}
+		if page <= (total+perPage-1)/perPage {
+			logrus.WithFields(logrus.Fields{
+				"page":     page,
+				"max_page": maxPage,
+				"ip":       c.ClientIP(),
+			}).Warn("GetRecipes page beyond the queried range")
+			pageErr := NewValidationError("OFFSET_TOO_LARGE", fmt.Sprintf(
+				"page %d is beyond the %d pages that can be listed. %s", page, maxPage, recipesOffsetHint), "page")
+			SafeErrorResponse(c, pageErr, http.StatusBadRequest)
+			return
+		}
+		respondWithRecipesCount(c, total, page, perPage, []models.RecipeListItem{})
+		return
+	}
+
 	// Build secure query using query builder
 	queryBuilder := NewRecipesQueryBuilder()
 	
-	// Add status filter if provided
-	if status != "" {
-		queryBuilder.WithStatus(status)
-	}
+	// Add filters shared with the count query
+	filters.apply(queryBuilder)
 	
 	// Add pagination
 	queryBuilder.WithPagination(limit, offset)
@@ -100,7 +173,7 @@ func (h *RecipeHandler) GetRecipes(c *gin.Context) {
 	query, args := queryBuilder.Build()
 
 	// Execute single query for both data and count
-	rows, err := h.db.DB.Query(query, args...)
+	rows, err := h.db.ReadQueryContextLogged(c.Request.Context(), query, args...)
 	if err != nil {
 		logrus.WithError(err).Error("GetRecipes query error")
 		InternalServerError(c, "failed to retrieve recipes")
@@ -109,22 +182,15 @@ func (h *RecipeHandler) GetRecipes(c *gin.Context) {
 	defer rows.Close()
 
 	// Parse results and get total count from first row
-	var recipes []models.Recipe
+	var recipes []models.RecipeListItem
 	var total int
 	for rows.Next() {
-		var recipe models.Recipe
-		err := rows.Scan(
-			&recipe.ID,
-			&recipe.Title,
-			&recipe.Servings,
-			&recipe.Instructions,
-			&recipe.Tips,
-			&recipe.Status,
-			&recipe.UserID,
-			&recipe.CreatedAt,
-			&recipe.UpdatedAt,
+		var recipe models.RecipeListItem
+		err := rows.Scan(append(recipeScanTargets(&recipe.Recipe),
+			&recipe.IngredientCount,
+			&recipe.HasImages,
 			&total, // Total count from window function
-		)
+		)...)
 		if err != nil {
 			logrus.WithError(err).Error("GetRecipes scan error")
 			InternalServerError(c, "failed to parse recipe data")
@@ -139,9 +205,13 @@ func (h *RecipeHandler) GetRecipes(c *gin.Context) {
 		return
 	}
 
-	// Return empty array if no recipes found
+	// The total comes from the rows, so an empty page past the end needs its own count
 	if recipes == nil {
-		recipes = []models.Recipe{}
+		if page > 1 {
+			h.getRecipesCount(c, filters, page, perPage, []models.RecipeListItem{})
+			return
+		}
+		recipes = []models.RecipeListItem{}
 		total = 0
 	}
 
@@ -156,12 +226,205 @@ func (h *RecipeHandler) GetRecipes(c *gin.Context) {
 		TotalPages: totalPages,
 	}
 
+	if includeIngredients {
+		if err := h.attachIngredients(c.Request.Context(), recipes); err != nil {
+			DatabaseError(c, err, "load recipe ingredients")
+			return
+		}
+	}
+
+	// Thumbnail URLs can only be signed when storage is configured
+	if h.storageService != nil {
+		if err := h.attachThumbnailURLs(c.Request.Context(), recipes); err != nil {
+			DatabaseError(c, err, "load recipe thumbnails")
+			return
+		}
+	}
+
 	// Return standardized paginated response
 	SuccessResponseWithPagination(c, recipes, pagination)
 }
 
+// recipeListFilters holds the validated GetRecipes filters shared by the list and count queries
+type recipeListFilters struct {
+	viewerID      int
+	allVisible    bool
+	status        string
+	createdAfter  *time.Time
+	createdBefore *time.Time
+	maxTotalTime  *int
+	difficulty    string
+}
+
+// apply adds the filters to a recipes query
+func (f recipeListFilters) apply(queryBuilder *RecipesQueryBuilder) {
+	if !f.allVisible {
+		queryBuilder.WithVisibleTo(f.viewerID)
+	}
+	if f.status != "" {
+		queryBuilder.WithStatus(f.status)
+	}
+	queryBuilder.WithCreatedRange(f.createdAfter, f.createdBefore)
+	if f.maxTotalTime != nil {
+		queryBuilder.WithMaxTotalTime(*f.maxTotalTime)
+	}
+	if f.difficulty != "" {
+		queryBuilder.WithDifficulty(f.difficulty)
+	}
+}
+
+// parseRecipeAttributeFilters reads the max_total_time (minutes) and difficulty parameters of GetRecipes
+func parseRecipeAttributeFilters(c *gin.Context) (maxTotalTime *int, difficulty string, ok bool) {
+	if value := c.Query("max_total_time"); value != "" {
+		minutes, err := strconv.Atoi(value)
+		if err != nil || minutes < 0 {
+			ValidationError(c, fmt.Sprintf("invalid max_total_time: %s. Use a non-negative number of minutes", value), "max_total_time")
+			return nil, "", false
+		}
+		maxTotalTime = &minutes
+	}
+
+	difficulty = c.Query("difficulty")
+	if difficulty != "" && !slices.Contains(models.RecipeDifficulties, difficulty) {
+		ValidationError(c, fmt.Sprintf("invalid difficulty: %s. Valid difficulties are: %s",
+			difficulty, strings.Join(models.RecipeDifficulties, ", ")), "difficulty")
+		return nil, "", false
+	}
+	return maxTotalTime, difficulty, true
+}
+
+// parseCreatedRange reads the RFC3339 created_after and created_before parameters of GetRecipes
+func parseCreatedRange(c *gin.Context) (after, before *time.Time, ok bool) {
+	for _, param := range []struct {
+		name   string
+		target **time.Time
+	}{
+		{"created_after", &after},
+		{"created_before", &before},
+	} {
+		value := c.Query(param.name)
+		if value == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			ValidationError(c, fmt.Sprintf("invalid %s: %s. Use an RFC3339 timestamp such as 2024-01-31T00:00:00Z", param.name, value), param.name)
+			return nil, nil, false
+		}
+		*param.target = &parsed
+	}
+
+	if after != nil && before != nil && after.After(*before) {
+		ValidationError(c, "created_after must not be later than created_before", "created_after")
+		return nil, nil, false
+	}
+	return after, before, true
+}
+
+// parseRecipeIncludes reads the comma-separated include parameter of GetRecipes
+// Only "ingredients" is supported; anything else is rejected so typos are not silently ignored
+func parseRecipeIncludes(c *gin.Context) (includeIngredients bool, ok bool) {
+	include := c.Query("include")
+	if include == "" {
+		return false, true
+	}
+
+	for _, value := range strings.Split(include, ",") {
+		switch strings.TrimSpace(value) {
+		case "ingredients":
+			includeIngredients = true
+		default:
+			ValidationError(c, fmt.Sprintf("invalid include: %s. Supported values are: ingredients", value), "include")
+			return false, false
+		}
+	}
+	return includeIngredients, true
+}
+
+// attachIngredients loads the ingredients of a page of recipes with a single query
+func (h *RecipeHandler) attachIngredients(ctx context.Context, recipes []models.RecipeListItem) error {
+	if len(recipes) == 0 {
+		return nil
+	}
+
+	recipeIDs := make([]int, len(recipes))
+	for i, recipe := range recipes {
+		recipeIDs[i] = recipe.ID
+	}
+
+	rows, err := h.db.ReadQueryContextLogged(ctx, recipeIngredientsBatchQuery, pq.Array(recipeIDs))
+	if err != nil {
+		return err
+	}
+	ingredients, err := scanRecipeIngredients(rows)
+	if err != nil {
+		return err
+	}
+
+	byRecipe := make(map[int][]models.RecipeIngredient, len(recipes))
+	for _, ingredient := range ingredients {
+		byRecipe[ingredient.RecipeID] = append(byRecipe[ingredient.RecipeID], ingredient)
+	}
+	for i := range recipes {
+		recipes[i].Ingredients = byRecipe[recipes[i].ID]
+	}
+	return nil
+}
+
+// getRecipesCount responds with data and pagination metadata from a separate count of the filtered recipes
+// Count-only requests pass nil data; pages with no rows pass an empty list
+func (h *RecipeHandler) getRecipesCount(c *gin.Context, filters recipeListFilters, page, perPage int, data interface{}) {
+	total, err := h.countRecipes(c, filters)
+	if err != nil {
+		logrus.WithError(err).Error("GetRecipes count query error")
+		InternalServerError(c, "failed to count recipes")
+		return
+	}
+	respondWithRecipesCount(c, total, page, perPage, data)
+}
+
+// countRecipes counts the recipes matching the GetRecipes filters
+func (h *RecipeHandler) countRecipes(c *gin.Context, filters recipeListFilters) (int, error) {
+	queryBuilder := NewRecipesCountQueryBuilder()
+	filters.apply(queryBuilder)
+	query, args := queryBuilder.Build()
+
+	var total int
+	err := h.db.ReadQueryRowContextLogged(c.Request.Context(), query, args...).Scan(&total)
+	return total, err
+}
+
+// respondWithRecipesCount responds with data and the pagination metadata for total recipes
+func respondWithRecipesCount(c *gin.Context, total, page, perPage int, data interface{}) {
+	pagination := &Pagination{
+		Page:       page,
+		PerPage:    perPage,
+		Total:      total,
+		TotalPages: (total + perPage - 1) / perPage,
+	}
+
+	SuccessResponseWithPagination(c, data, pagination)
+}
+
 // GetRecipe handles GET /recipes/:id requests
+// The Accept header selects JSON (the default), JSON-LD or Markdown. The last two are rendered like
+// GET /recipes/:id/jsonld and /export, so unpublished recipes stay visible only to their owner
 func (h *RecipeHandler) GetRecipe(c *gin.Context) {
+	c.Writer.Header().Add("Vary", "Accept")
+	mediaType, ok := negotiateMediaType(c.GetHeader("Accept"), mediaTypeJSON, mediaTypeJSONLD, mediaTypeMarkdown)
+	if !ok {
+		NotAcceptableError(c, fmt.Sprintf("recipes are available as %s, %s or %s", mediaTypeJSON, mediaTypeJSONLD, mediaTypeMarkdown))
+		return
+	}
+	switch mediaType {
+	case mediaTypeJSONLD:
+		h.GetRecipeJSONLD(c)
+		return
+	case mediaTypeMarkdown:
+		h.getRecipeMarkdown(c)
+		return
+	}
+
 	// Parse recipe ID from URL parameter
 	idStr := c.Param("id")
 	recipeID, err := strconv.Atoi(idStr)
@@ -171,29 +434,24 @@ func (h *RecipeHandler) GetRecipe(c *gin.Context) {
 		return
 	}
 	
+	// Ingredients are only paginated when asked for, so existing clients keep getting the full list
+	_, hasPage := c.GetQuery("ingredients_page")
+	_, hasPerPage := c.GetQuery("ingredients_per_page")
+	paginateIngredients := hasPage || hasPerPage
+	var ingredientsPage, ingredientsPerPage int
+	if paginateIngredients {
+		var paginationErr *AppError
+		ingredientsPage, ingredientsPerPage, paginationErr = parsePaginationParams(c, h.pagination, "ingredients_page", "ingredients_per_page")
+		if paginationErr != nil {
+			SafeErrorResponse(c, *paginationErr, http.StatusBadRequest)
+			return
+		}
+	}
+
 	// Log request
 	logrus.WithFields(logrus.Fields{"recipe_id": recipeID, "ip": c.ClientIP()}).Debug("GetRecipe request")
 
-	// Query for the specific recipe
-	query := `
-		SELECT id, title, servings, instructions, tips, status, user_id, created_at, updated_at
-		FROM recipes
-		WHERE id = $1
-	`
-
-	var recipe models.Recipe
-	err = h.db.DB.QueryRow(query, recipeID).Scan(
-		&recipe.ID,
-		&recipe.Title,
-		&recipe.Servings,
-		&recipe.Instructions,
-		&recipe.Tips,
-		&recipe.Status,
-		&recipe.UserID,
-		&recipe.CreatedAt,
-		&recipe.UpdatedAt,
-	)
-
+	recipe, err := h.readRecipe(c.Request.Context(), recipeID)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			NotFoundError(c, "recipe not found")
@@ -203,9 +461,82 @@ func (h *RecipeHandler) GetRecipe(c *gin.Context) {
 		InternalServerError(c, "failed to retrieve recipe")
 		return
 	}
+	if !canViewRecipe(c, recipe.UserID, recipe.Visibility) {
+		NotFoundError(c, "recipe not found")
+		return
+	}
+
+	ingredients, err := h.readRecipeIngredients(c.Request.Context(), recipeID)
+	if err != nil {
+		logrus.WithError(err).Error("GetRecipe ingredients query error")
+		InternalServerError(c, "failed to retrieve ingredients")
+		return
+	}
+
+	primaryImageURL, err := h.primaryImageURL(c.Request.Context(), recipeID)
+	if err != nil {
+		logrus.WithError(err).Error("GetRecipe primary image query error")
+		InternalServerError(c, "failed to retrieve recipe images")
+		return
+	}
+
+	// Create response with ingredients using RecipeWithIngredients model
+	recipeWithIngredients := models.RecipeWithIngredients{
+		Recipe:          recipe,
+		Ingredients:     ingredients,
+		PrimaryImageURL: primaryImageURL,
+	}
+
+	setLastModified(c, recipe)
+	if paginateIngredients {
+		page, pagination := paginateSlice(ingredients, ingredientsPage, ingredientsPerPage)
+		recipeWithIngredients.Ingredients = page
+		SuccessResponse(c, recipeWithIngredientPage{
+			RecipeWithIngredients: recipeWithIngredients,
+			IngredientsPagination: pagination,
+		})
+		return
+	}
+
+	// Return standardized response
+	SuccessResponse(c, recipeWithIngredients)
+}
+
+// recipeWithIngredientPage is a recipe response carrying one page of its ingredients
+type recipeWithIngredientPage struct {
+	models.RecipeWithIngredients
+	IngredientsPagination *Pagination `json:"ingredients_pagination"`
+}
+
+// Names of the statements prepared for the hot recipe-by-id and ingredients queries
+const (
+	recipeByIDStatement        = "recipe_by_id"
+	recipeIngredientsStatement = "recipe_ingredients"
+)
+
+// recipeColumns are the recipes columns scanned by recipeScanTargets
+const recipeColumns = `id, title, servings, instructions, tips, status, user_id, created_at, updated_at,
+		prep_time_minutes, cook_time_minutes, difficulty, source_name, source_url, last_modified_by, visibility,
+		featured, featured_at`
+
+// qualifiedRecipeColumns is recipeColumns with every column prefixed by a table alias, for queries joining recipes
+func qualifiedRecipeColumns(alias string) string {
+	columns := strings.Split(recipeColumns, ",")
+	for i, column := range columns {
+		columns[i] = alias + "." + strings.TrimSpace(column)
+	}
+	return strings.Join(columns, ", ")
+}
+
+// recipeByIDQuery selects a single recipe row
+const recipeByIDQuery = `
+		SELECT ` + recipeColumns + `
+		FROM recipes
+		WHERE id = $1
+	`
 
-	// Query for ingredients
-	ingredientsQuery := `
+// recipeIngredientsQuery selects a recipe's ingredients in insertion order with canonical names
+const recipeIngredientsQuery = `
 		SELECT 
 			ri.id,
 			ri.recipe_id,
@@ -222,19 +553,140 @@ func (h *RecipeHandler) GetRecipe(c *gin.Context) {
 		ORDER BY ri.id
 	`
 
-	ingredientRows, err := h.db.DB.Query(ingredientsQuery, recipeID)
+// recipeIngredientsBatchQuery selects the ingredients of several recipes, grouped by recipe in insertion order
+const recipeIngredientsBatchQuery = `
+		SELECT 
+			ri.id,
+			ri.recipe_id,
+			ri.canonical_ingredient_id,
+			ri.original_text,
+			ri.quantity,
+			ri.unit,
+			ri.created_at,
+			ri.updated_at,
+			ci.name as canonical_name
+		FROM recipe_ingredients ri
+		LEFT JOIN canonical_ingredients ci ON ri.canonical_ingredient_id = ci.id
+		WHERE ri.recipe_id = ANY($1)
+		ORDER BY ri.recipe_id, ri.id
+	`
+
+// PrepareRecipeStatements prepares the queries behind GetRecipe so requests reuse them
+// Statements not prepared here are still prepared on first use
+func PrepareRecipeStatements(ctx context.Context, database *db.Database) error {
+	if _, err := database.PrepareCached(ctx, recipeByIDStatement, recipeByIDQuery); err != nil {
+		return err
+	}
+	_, err := database.PrepareCached(ctx, recipeIngredientsStatement, recipeIngredientsQuery)
+	return err
+}
+
+// loadRecipe fetches a single recipe by ID, returning sql.ErrNoRows when it does not exist
+func (h *RecipeHandler) loadRecipe(ctx context.Context, recipeID int) (models.Recipe, error) {
+	return scanRecipe(h.db.QueryRowPrepared(ctx, recipeByIDStatement, recipeByIDQuery, recipeID))
+}
+
+// loadRecipeIngredients fetches a recipe's ingredients in insertion order with canonical names
+func (h *RecipeHandler) loadRecipeIngredients(ctx context.Context, recipeID int) ([]models.RecipeIngredient, error) {
+	rows, err := h.db.QueryPrepared(ctx, recipeIngredientsStatement, recipeIngredientsQuery, recipeID)
 	if err != nil {
-		logrus.WithError(err).Error("GetRecipe ingredients query error")
-		InternalServerError(c, "failed to retrieve ingredients")
-		return
+		return nil, err
+	}
+	return scanRecipeIngredients(rows)
+}
+
+// readRecipe is loadRecipe on the read replica, for requests that do not follow their own writes
+func (h *RecipeHandler) readRecipe(ctx context.Context, recipeID int) (models.Recipe, error) {
+	return scanRecipe(h.db.ReadQueryRowPrepared(ctx, recipeByIDStatement, recipeByIDQuery, recipeID))
+}
+
+// readRecipeIngredients is loadRecipeIngredients on the read replica
+func (h *RecipeHandler) readRecipeIngredients(ctx context.Context, recipeID int) ([]models.RecipeIngredient, error) {
+	rows, err := h.db.ReadQueryPrepared(ctx, recipeIngredientsStatement, recipeIngredientsQuery, recipeID)
+	if err != nil {
+		return nil, err
+	}
+	return scanRecipeIngredients(rows)
+}
+
+// queryRecipe fetches a recipe through a connection or transaction
+// forUpdate locks the row until the surrounding transaction ends
+func queryRecipe(q queryRower, recipeID int, forUpdate bool) (models.Recipe, error) {
+	query := recipeByIDQuery
+	if forUpdate {
+		query += " FOR UPDATE"
+	}
+	return scanRecipe(q.QueryRow(query, recipeID))
+}
+
+// scanRecipe reads a row selected with recipeColumns
+func scanRecipe(row *sql.Row) (models.Recipe, error) {
+	var recipe models.Recipe
+	err := row.Scan(recipeScanTargets(&recipe)...)
+	return recipe, err
+}
+
+// recipeScanTargets returns the destinations for recipeColumns, for queries selecting further columns after them
+func recipeScanTargets(recipe *models.Recipe) []interface{} {
+	return []interface{}{
+		&recipe.ID,
+		&recipe.Title,
+		servingsScanner{recipe},
+		&recipe.Instructions,
+		&recipe.Tips,
+		&recipe.Status,
+		&recipe.UserID,
+		&recipe.CreatedAt,
+		&recipe.UpdatedAt,
+		&recipe.PrepTimeMinutes,
+		&recipe.CookTimeMinutes,
+		&recipe.Difficulty,
+		&recipe.SourceName,
+		&recipe.SourceURL,
+		&recipe.LastModifiedBy,
+		&recipe.Visibility,
+		&recipe.Featured,
+		&recipe.FeaturedAt,
+	}
+}
+
+// servingsScanner reads the servings column into a recipe along with the count parsed from it
+type servingsScanner struct {
+	recipe *models.Recipe
+}
+
+// Scan implements sql.Scanner
+func (s servingsScanner) Scan(value interface{}) error {
+	var servings sql.NullString
+	if err := servings.Scan(value); err != nil {
+		return err
+	}
+	s.recipe.Servings, s.recipe.ServingsCount = nil, nil
+	if servings.Valid {
+		s.recipe.Servings = &servings.String
+		s.recipe.ServingsCount = ingredients.ParseServings(servings.String)
+	}
+	return nil
+}
+
+// queryRecipeIngredients fetches a recipe's ingredients through a connection or transaction
+func queryRecipeIngredients(q dbQuerier, recipeID int) ([]models.RecipeIngredient, error) {
+	ingredientRows, err := q.Query(recipeIngredientsQuery, recipeID)
+	if err != nil {
+		return nil, err
 	}
+	return scanRecipeIngredients(ingredientRows)
+}
+
+// scanRecipeIngredients reads and closes rows selected by recipeIngredientsQuery
+func scanRecipeIngredients(ingredientRows *sql.Rows) ([]models.RecipeIngredient, error) {
 	defer ingredientRows.Close()
 
 	var ingredients []models.RecipeIngredient
 	for ingredientRows.Next() {
 		var ingredient models.RecipeIngredient
 		var canonicalName sql.NullString
-		
+
 		err := ingredientRows.Scan(
 			&ingredient.ID,
 			&ingredient.RecipeID,
@@ -247,30 +699,75 @@ func (h *RecipeHandler) GetRecipe(c *gin.Context) {
 			&canonicalName,
 		)
 		if err != nil {
-			logrus.WithError(err).Error("GetRecipe ingredient scan error")
-			InternalServerError(c, "failed to parse ingredient data")
-			return
+			return nil, err
 		}
-		
+
 		// Set canonical name if available
 		if canonicalName.Valid {
 			ingredient.CanonicalName = &canonicalName.String
 		}
-		
+
 		ingredients = append(ingredients, ingredient)
 	}
 
-	// Create response with ingredients using RecipeWithIngredients model
-	recipeWithIngredients := models.RecipeWithIngredients{
-		Recipe:      recipe,
-		Ingredients: ingredients,
+	return ingredients, ingredientRows.Err()
+}
+
+// respondWithRecipe reloads a recipe with its ingredients and writes it as the success response
+// It answers writes, so ingredients still needing review are listed as warnings
+func (h *RecipeHandler) respondWithRecipe(c *gin.Context, recipeID int) {
+	recipe, err := h.loadRecipe(c.Request.Context(), recipeID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			NotFoundError(c, "recipe not found")
+			return
+		}
+		DatabaseError(c, err, "load recipe")
+		return
 	}
 
-	// Return standardized response
-	SuccessResponse(c, recipeWithIngredients)
+	ingredients, err := h.loadRecipeIngredients(c.Request.Context(), recipeID)
+	if err != nil {
+		DatabaseError(c, err, "load recipe ingredients")
+		return
+	}
+
+	primaryImageURL, err := h.primaryImageURL(c.Request.Context(), recipeID)
+	if err != nil {
+		DatabaseError(c, err, "load primary image")
+		return
+	}
+
+	setLastModified(c, recipe)
+	SuccessResponseWithWarnings(c, models.RecipeWithIngredients{
+		Recipe:          recipe,
+		Ingredients:     ingredients,
+		PrimaryImageURL: primaryImageURL,
+	}, ingredientWarnings(ingredients))
+}
+
+// uploadDuplicateWindow is how long a repeat of an upload request is answered with the recipe the first one created
+const uploadDuplicateWindow = 10 * time.Second
+
+// findDuplicateUpload looks for a recipe the user created from the same upload request since the given time
+// Only recipes still processing with no confirmed images qualify, so nothing the user already started on is reused
+func findDuplicateUpload(q queryRower, userID int, fingerprint string, since time.Time) (int, string, error) {
+	var recipeID int
+	var storagePrefix string
+	err := q.QueryRow(`
+		SELECT id, `+recipeStoragePrefixColumn+`
+		FROM recipes
+		WHERE user_id = $1 AND upload_fingerprint = $2 AND status = 'processing' AND created_at > $3
+			AND NOT EXISTS (SELECT 1 FROM recipe_images WHERE recipe_images.recipe_id = recipes.id)
+		ORDER BY created_at DESC
+		LIMIT 1
+	`, userID, fingerprint, since).Scan(&recipeID, &storagePrefix)
+	return recipeID, storagePrefix, err
 }
 
 // PostUploadRequest handles POST /recipes/upload-request requests with enhanced security
+// A repeat of the same request within uploadDuplicateWindow, such as a double-click, gets fresh upload URLs
+// for the recipe the first request created instead of a second recipe
 func (h *RecipeHandler) PostUploadRequest(c *gin.Context) {
 	logger := middleware.LogWithContext(c)
 	
@@ -278,6 +775,28 @@ func (h *RecipeHandler) PostUploadRequest(c *gin.Context) {
 	var uploadRequest models.UploadRequest
 	if err := c.ShouldBindJSON(&uploadRequest); err != nil {
 		logger.WithError(err).Warn("Upload request binding failed")
+		if isBodyTooLarge(err) {
+			RequestTooLargeError(c, "Request body too large")
+			return
+		}
+		var bindingErrs validator.ValidationErrors
+		if errors.As(err, &bindingErrs) {
+			// Report binding rule failures with the same field-level messages as Validate
+			if validateErr := uploadRequest.Validate(); validateErr != nil {
+				FieldValidationError(c, validateErr)
+				return
+			}
+			field := jsonFieldName(uploadRequest, bindingErrs[0].StructField())
+			ValidationError(c, fmt.Sprintf("invalid value for %s", field), field)
+			return
+		}
+		// A field of the wrong shape, such as allowed_types sent as a string or nested arrays, is reported against that field
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) && typeErr.Field != "" {
+			field, _, _ := strings.Cut(typeErr.Field, ".")
+			ValidationError(c, fmt.Sprintf("invalid value for %s", field), field)
+			return
+		}
 		ValidationError(c, "Invalid request format. Check image_count field.")
 		return
 	}
@@ -285,7 +804,7 @@ func (h *RecipeHandler) PostUploadRequest(c *gin.Context) {
 	// Perform additional business logic validation
 	if err := uploadRequest.Validate(); err != nil {
 		logger.WithError(err).Warn("Upload request validation failed")
-		ValidationError(c, err.Error())
+		FieldValidationError(c, err)
 		return
 	}
 
@@ -293,13 +812,15 @@ func (h *RecipeHandler) PostUploadRequest(c *gin.Context) {
 		"image_count":       uploadRequest.ImageCount,
 		"max_file_size_mb":  uploadRequest.GetMaxFileSizeMB(),
 		"allowed_types":     uploadRequest.GetAllowedTypes(),
+		"content_types":     uploadRequest.ContentTypes,
 		"expiration_hours":  uploadRequest.GetExpirationHours(),
 	}).Info("Processing upload request")
 
-	// Validate storage service is available
+	// Without storage there is nothing to upload to, so no recipe is created
+	// and clients are told to retry rather than left with an orphaned processing recipe
 	if h.storageService == nil {
 		logger.Error("Storage service not available")
-		InternalServerError(c, "File upload service is temporarily unavailable")
+		ServiceUnavailableError(c, "File upload service is temporarily unavailable")
 		return
 	}
 
@@ -311,60 +832,103 @@ func (h *RecipeHandler) PostUploadRequest(c *gin.Context) {
 		return
 	}
 
-	// Begin transaction for recipe creation
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	// Create the recipe and its upload URLs in one transaction
+	// The context carries the request logger so storage logs share the request ID
+	ctx, cancel := context.WithTimeout(logctx.WithLogger(context.Background(), logger), 30*time.Second)
 	defer cancel()
 
-	tx, err := h.db.DB.BeginTx(ctx, nil)
-	if err != nil {
-		logger.WithError(err).Error("Failed to begin database transaction")
-		InternalServerError(c, "Failed to process upload request")
-		return
-	}
-	defer tx.Rollback()
-
-	// Insert new recipe with processing status
 	var recipeID int
-	query := `
-		INSERT INTO recipes (title, status, user_id, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5)
-		RETURNING id
-	`
-	
-	now := time.Now().UTC()
-	err = tx.QueryRow(query, "Processing Recipe", "processing", userID, now, now).Scan(&recipeID)
+	var uploadURLs []models.ImageUploadURL
+	var reused bool
+	fingerprint := uploadRequest.Fingerprint()
+	err := h.db.WithTx(ctx, func(tx *sql.Tx) error {
+		// Requests from one user are handled one at a time so two clicks cannot both miss the duplicate check
+		if _, err := tx.Exec(`SELECT pg_advisory_xact_lock(hashtext('recipe_upload_request'), $1)`, userID); err != nil {
+			return err
+		}
+
+		now := time.Now().UTC()
+		duplicateID, storagePrefix, err := findDuplicateUpload(tx, userID, fingerprint, now.Add(-uploadDuplicateWindow))
+		switch {
+		case err == nil:
+			recipeID = duplicateID
+			reused = true
+		case err == sql.ErrNoRows:
+			// Insert new recipe with processing status; the database assigns its random storage prefix
+			query := `
+				INSERT INTO recipes (title, status, user_id, upload_fingerprint, created_at, updated_at)
+				VALUES ($1, $2, $3, $4, $5, $6)
+				RETURNING id, ` + recipeStoragePrefixColumn
+
+			if err := tx.QueryRow(query, "Processing Recipe", "processing", userID, fingerprint, now, now).Scan(&recipeID, &storagePrefix); err != nil {
+				return err
+			}
+		default:
+			return err
+		}
+
+		// Generate pre-signed upload URLs with enhanced security
+		urls, err := h.storageService.GenerateUploadURLs(ctx, recipeID, storagePrefix, &uploadRequest, c.ClientIP())
+		if err != nil {
+			return &storageFailure{err: err}
+		}
+		uploadURLs = urls
+		return nil
+	})
 	if err != nil {
+		var storageErr *storageFailure
+		if errors.As(err, &storageErr) {
+			if errors.Is(storageErr.err, context.DeadlineExceeded) || errors.Is(storageErr.err, context.Canceled) {
+				// Nothing was created, so the client can simply try again
+				logger.WithError(storageErr.err).Warn("Upload URL generation did not finish in time")
+				ServiceUnavailableError(c, "File upload service is temporarily unavailable")
+				return
+			}
+			logger.WithError(storageErr.err).Error("Failed to generate upload URLs")
+			StorageError(c, storageErr.err, "generate upload URLs")
+			return
+		}
 		logger.WithError(err).Error("Failed to create recipe record")
 		DatabaseError(c, err, "create recipe")
 		return
 	}
 
-	// Generate pre-signed upload URLs with enhanced security
-	uploadURLs, err := h.storageService.GenerateUploadURLs(ctx, recipeID, &uploadRequest, c.ClientIP())
-	if err != nil {
-		logger.WithError(err).Error("Failed to generate upload URLs")
-		StorageError(c, err, "generate upload URLs")
-		return
-	}
-
-	// Commit transaction
-	if err = tx.Commit(); err != nil {
-		logger.WithError(err).Error("Failed to commit transaction")
-		DatabaseError(c, err, "commit recipe creation")
-		return
-	}
-
 	// Create response
 	response := models.UploadResponse{
 		RecipeID:   recipeID,
 		UploadURLs: uploadURLs,
+		Reused:     reused,
 	}
 
 	logger.WithFields(logrus.Fields{
 		"recipe_id":    recipeID,
 		"upload_count": len(uploadURLs),
+		"reused":       reused,
 	}).Info("Upload request processed successfully")
 
 	// Return standardized response
 	SuccessResponse(c, response)
-}
\ No newline at end of file
+}
+
+// storageFailure marks an error from object storage raised inside a database transaction
+// so the handler can tell it apart from database errors once the transaction has rolled back
+type storageFailure struct {
+	err error
+}
+
+func (e *storageFailure) Error() string { return e.err.Error() }
+
+func (e *storageFailure) Unwrap() error { return e.err }
+
+// jsonFieldName resolves the JSON name of a struct field for field-level error reporting
+func jsonFieldName(obj interface{}, structField string) string {
+	field, ok := reflect.TypeOf(obj).FieldByName(structField)
+	if !ok {
+		return structField
+	}
+	name := strings.Split(field.Tag.Get("json"), ",")[0]
+	if name == "" || name == "-" {
+		return structField
+	}
+	return name
+}
@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"digital-recipes/api-service/logctx"
+	"digital-recipes/api-service/middleware"
+	"digital-recipes/api-service/models"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/sirupsen/logrus"
+)
+
+// batchStatusBindingMessage explains why a batch status request failed its binding rules
+func batchStatusBindingMessage(req models.BatchStatusRequest, bindingErr validator.FieldError) (string, string) {
+	if bindingErr.StructField() == "Status" {
+		return "status must be one of processing, review_required, published", "status"
+	}
+	switch {
+	case len(req.IDs) == 0:
+		return "ids must list at least one recipe", "ids"
+	case len(req.IDs) > models.MaxBatchStatusRecipes:
+		return fmt.Sprintf("at most %d recipes can be updated at once", models.MaxBatchStatusRecipes), "ids"
+	default:
+		return "ids must be unique positive recipe IDs", "ids"
+	}
+}
+
+// batchStatusFailure turns a recipe change error into the batch result for that recipe
+func batchStatusFailure(recipeID int, changeErr *recipeChangeError) models.BatchStatusResult {
+	result := models.BatchStatusResult{ID: recipeID, Result: models.BatchResultFailed, Reason: changeErr.message}
+	switch changeErr.status {
+	case 404:
+		result.Result = models.BatchResultNotFound
+	case 403:
+		result.Result = models.BatchResultForbidden
+	}
+	return result
+}
+
+// BatchUpdateRecipeStatus handles POST /recipes/batch/status requests
+// Every recipe is checked like a PUT status change and the allowed ones are applied in one transaction.
+// Recipes that are missing, not the caller's or cannot make the transition are reported rather than
+// aborting the batch; only database errors roll the whole batch back.
+func (h *RecipeHandler) BatchUpdateRecipeStatus(c *gin.Context) {
+	logger := middleware.LogWithContext(c)
+
+	var req models.BatchStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.WithError(err).Warn("Batch status binding failed")
+		if isBodyTooLarge(err) {
+			RequestTooLargeError(c, "Request body too large")
+			return
+		}
+		var bindingErrs validator.ValidationErrors
+		if errors.As(err, &bindingErrs) {
+			message, field := batchStatusBindingMessage(req, bindingErrs[0])
+			ValidationError(c, message, field)
+			return
+		}
+		ValidationError(c, "Invalid request format. Check ids and status fields.")
+		return
+	}
+
+	if middleware.GetUserID(c) == 0 {
+		AuthenticationError(c, "Authentication required to edit recipes")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(logctx.WithLogger(context.Background(), logger), 30*time.Second)
+	defer cancel()
+
+	tx, err := h.db.DB.BeginTx(ctx, nil)
+	if err != nil {
+		DatabaseError(c, err, "batch update recipe status")
+		return
+	}
+	defer tx.Rollback()
+
+	type statusChange struct {
+		previousStatus string
+		recipe         *models.Recipe
+	}
+
+	status := req.Status
+	patch := models.RecipePatch{Status: &status}
+	results := make(map[int]models.BatchStatusResult, len(req.IDs))
+	var changes []statusChange
+
+	// Rows are locked in ID order so concurrent batches cannot deadlock on each other.
+	// If-Unmodified-Since names a single recipe's state, so it does not apply to batches
+	for _, recipeID := range slices.Sorted(slices.Values(req.IDs)) {
+		previousStatus, updated, changeErr := changeRecipeInTx(c, tx, recipeID, nil, patch.Apply, nil)
+		if changeErr != nil {
+			if changeErr.dbErr != nil {
+				DatabaseError(c, changeErr.dbErr, "batch update recipe status")
+				return
+			}
+			results[recipeID] = batchStatusFailure(recipeID, changeErr)
+			continue
+		}
+		results[recipeID] = models.BatchStatusResult{ID: recipeID, Result: models.BatchResultUpdated, Status: updated.Status}
+		changes = append(changes, statusChange{previousStatus: previousStatus, recipe: updated})
+	}
+
+	if err := tx.Commit(); err != nil {
+		DatabaseError(c, err, "commit batch status update")
+		return
+	}
+
+	for _, change := range changes {
+		h.notifyStatusChange(ctx, change.previousStatus, change.recipe)
+	}
+
+	response := models.BatchStatusResponse{
+		Updated: len(changes),
+		Failed:  len(req.IDs) - len(changes),
+		Results: make([]models.BatchStatusResult, 0, len(req.IDs)),
+	}
+	for _, recipeID := range req.IDs {
+		response.Results = append(response.Results, results[recipeID])
+	}
+
+	logger.WithFields(logrus.Fields{
+		"status":  req.Status,
+		"updated": response.Updated,
+		"failed":  response.Failed,
+	}).Info("Batch recipe status update processed")
+
+	SuccessResponse(c, response)
+}
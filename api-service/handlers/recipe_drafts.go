@@ -0,0 +1,199 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"strconv"
+
+	"digital-recipes/api-service/middleware"
+	"digital-recipes/api-service/models"
+	"github.com/gin-gonic/gin"
+)
+
+// scanRecipeDraft scans a recipe_drafts row and decodes its content
+func scanRecipeDraft(scan func(dest ...interface{}) error) (models.RecipeDraft, error) {
+	var draft models.RecipeDraft
+	var content []byte
+	if err := scan(&draft.RecipeID, &content, &draft.CreatedAt, &draft.UpdatedAt); err != nil {
+		return draft, err
+	}
+	err := json.Unmarshal(content, &draft.Content)
+	return draft, err
+}
+
+// authorizeDraftAccess loads the recipe owner and checks the caller is that owner
+// Drafts are unpublished work, so unlike edits to the live recipe admins cannot reach them either
+// It writes the error response itself and returns false when the caller should stop
+func (h *RecipeHandler) authorizeDraftAccess(c *gin.Context, recipeID int) bool {
+	userID := middleware.GetUserID(c)
+	if userID == 0 {
+		AuthenticationError(c, "Authentication required to use recipe drafts")
+		return false
+	}
+
+	var ownerID int
+	err := h.db.DB.QueryRow(`SELECT user_id FROM recipes WHERE id = $1`, recipeID).Scan(&ownerID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			NotFoundError(c, "recipe not found")
+			return false
+		}
+		DatabaseError(c, err, "load recipe owner")
+		return false
+	}
+
+	if userID != ownerID {
+		AuthorizationError(c, "Only the recipe owner can use its draft")
+		return false
+	}
+	return true
+}
+
+// loadRecipeDraft reads the draft saved for a recipe, returning sql.ErrNoRows when there is none
+func (h *RecipeHandler) loadRecipeDraft(recipeID int) (models.RecipeDraft, error) {
+	return scanRecipeDraft(h.db.DB.QueryRow(`
+		SELECT recipe_id, content, created_at, updated_at
+		FROM recipe_drafts
+		WHERE recipe_id = $1
+	`, recipeID).Scan)
+}
+
+// SaveRecipeDraft handles PUT /recipes/:id/draft requests
+// The body is a partial recipe like a PATCH body; it replaces any earlier draft and leaves the live recipe alone.
+// Drafts are validated like a PATCH so promoting one later cannot fail on its content.
+func (h *RecipeHandler) SaveRecipeDraft(c *gin.Context) {
+	logger := middleware.LogWithContext(c)
+
+	recipeID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		BadRequestError(c, "invalid recipe ID")
+		return
+	}
+
+	var content models.RecipePatch
+	if err := c.ShouldBindJSON(&content); err != nil {
+		logger.WithError(err).Warn("Save recipe draft binding failed")
+		if isBodyTooLarge(err) {
+			RequestTooLargeError(c, "Request body too large")
+			return
+		}
+		ValidationError(c, "Invalid request format. Check title, ingredients, time, difficulty and source fields.")
+		return
+	}
+
+	if content.IsEmpty() {
+		ValidationError(c, "at least one field must be provided")
+		return
+	}
+
+	// Status changes go through the review workflow, not through drafts
+	if content.Status != nil {
+		ValidationError(c, "status cannot be saved in a draft", "status")
+		return
+	}
+
+	if errs := append(content.Validate(), h.inputIngredientLimitErrors(content.Ingredients)...); len(errs) > 0 {
+		FieldValidationErrors(c, errs)
+		return
+	}
+
+	if !h.authorizeDraftAccess(c, recipeID) {
+		return
+	}
+
+	if !h.checkCanonicalLinks(c, "ingredients", content.Ingredients) {
+		return
+	}
+
+	encoded, err := json.Marshal(content)
+	if err != nil {
+		DatabaseError(c, err, "encode recipe draft")
+		return
+	}
+
+	draft, err := scanRecipeDraft(h.db.DB.QueryRow(`
+		INSERT INTO recipe_drafts (recipe_id, content)
+		VALUES ($1, $2)
+		ON CONFLICT (recipe_id) DO UPDATE SET content = EXCLUDED.content
+		RETURNING recipe_id, content, created_at, updated_at
+	`, recipeID, encoded).Scan)
+	if err != nil {
+		DatabaseError(c, err, "save recipe draft")
+		return
+	}
+
+	logger.WithField("recipe_id", recipeID).Debug("Recipe draft saved")
+
+	SuccessResponse(c, draft)
+}
+
+// GetRecipeDraft handles GET /recipes/:id/draft requests
+func (h *RecipeHandler) GetRecipeDraft(c *gin.Context) {
+	recipeID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		BadRequestError(c, "invalid recipe ID")
+		return
+	}
+
+	if !h.authorizeDraftAccess(c, recipeID) {
+		return
+	}
+
+	draft, err := h.loadRecipeDraft(recipeID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			NotFoundError(c, "draft not found")
+			return
+		}
+		DatabaseError(c, err, "load recipe draft")
+		return
+	}
+
+	SuccessResponse(c, draft)
+}
+
+// PromoteRecipeDraft handles POST /recipes/:id/draft/promote requests
+// The draft is applied like a PATCH, so the replaced state is kept as a revision and the status is unchanged.
+// The draft is then removed unless it was autosaved again while being promoted.
+func (h *RecipeHandler) PromoteRecipeDraft(c *gin.Context) {
+	logger := middleware.LogWithContext(c)
+
+	recipeID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		BadRequestError(c, "invalid recipe ID")
+		return
+	}
+
+	if !h.authorizeDraftAccess(c, recipeID) {
+		return
+	}
+
+	draft, err := h.loadRecipeDraft(recipeID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			NotFoundError(c, "draft not found")
+			return
+		}
+		DatabaseError(c, err, "load recipe draft")
+		return
+	}
+
+	// Links are checked again, since a canonical ingredient may have been removed since the draft was saved
+	if !h.checkCanonicalLinks(c, "ingredients", draft.Content.Ingredients) {
+		return
+	}
+
+	if _, changeErr := h.applyRecipeChangeFrom(c, recipeID, draft.Content.Apply); changeErr != nil {
+		changeErr.respond(c, "promote recipe draft")
+		return
+	}
+
+	// The recipe is already updated, so a draft left behind is only logged
+	if _, err := h.db.DB.Exec(`DELETE FROM recipe_drafts WHERE recipe_id = $1 AND updated_at = $2`, recipeID, draft.UpdatedAt); err != nil {
+		logger.WithError(err).WithField("recipe_id", recipeID).Error("Failed to remove promoted recipe draft")
+	}
+
+	logger.WithField("recipe_id", recipeID).Info("Recipe draft promoted")
+
+	h.respondWithRecipe(c, recipeID)
+}
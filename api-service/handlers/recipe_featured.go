@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"digital-recipes/api-service/middleware"
+	"digital-recipes/api-service/models"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// FeatureRecipe handles PATCH /recipes/:id/feature requests from admins
+// Featuring a recipe that is already featured keeps its featured_at, so it does not jump the queue;
+// any recipe can be featured, but only published public ones are listed by GetFeaturedRecipes
+func (h *RecipeHandler) FeatureRecipe(c *gin.Context) {
+	logger := middleware.LogWithContext(c)
+
+	recipeID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		BadRequestError(c, "invalid recipe ID")
+		return
+	}
+
+	var input models.RecipeFeatureInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		logger.WithError(err).Warn("Feature recipe binding failed")
+		if isBodyTooLarge(err) {
+			RequestTooLargeError(c, "Request body too large")
+			return
+		}
+		ValidationError(c, "Invalid request format. featured must be true or false.", "featured")
+		return
+	}
+
+	result, err := h.db.DB.ExecContext(c.Request.Context(), `
+		UPDATE recipes
+		SET featured = $1, featured_at = CASE WHEN $1 THEN COALESCE(featured_at, NOW()) ELSE NULL END
+		WHERE id = $2
+	`, *input.Featured, recipeID)
+	if err != nil {
+		DatabaseError(c, err, "feature recipe")
+		return
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		NotFoundError(c, "recipe not found")
+		return
+	}
+
+	logger.WithFields(logrus.Fields{
+		"recipe_id": recipeID,
+		"featured":  *input.Featured,
+	}).Info("Recipe feature flag updated")
+
+	h.respondWithRecipe(c, recipeID)
+}
+
+// GetFeaturedRecipes handles GET /recipes/featured requests for the curated homepage
+// Only published, public recipes are listed, most recently featured first, whoever is asking
+func (h *RecipeHandler) GetFeaturedRecipes(c *gin.Context) {
+	page, perPage, paginationErr := parsePagination(c, h.pagination)
+	if paginationErr != nil {
+		SafeErrorResponse(c, *paginationErr, http.StatusBadRequest)
+		return
+	}
+
+	queryBuilder := NewRecipesQueryBuilder()
+	queryBuilder.WithFeatured().WithStatus("published").WithVisibleTo(0)
+	queryBuilder.AddOrderBy("featured_at", "DESC")
+	queryBuilder.AddLimitOffset(perPage, (page-1)*perPage)
+	query, args := queryBuilder.Build()
+
+	rows, err := h.db.ReadQueryContextLogged(c.Request.Context(), query, args...)
+	if err != nil {
+		DatabaseError(c, err, "load featured recipes")
+		return
+	}
+	defer rows.Close()
+
+	recipes := []models.RecipeListItem{}
+	total := 0
+	for rows.Next() {
+		var recipe models.RecipeListItem
+		if err := rows.Scan(append(recipeScanTargets(&recipe.Recipe),
+			&recipe.IngredientCount,
+			&recipe.HasImages,
+			&total,
+		)...); err != nil {
+			DatabaseError(c, err, "read featured recipes")
+			return
+		}
+		recipes = append(recipes, recipe)
+	}
+	if err := rows.Err(); err != nil {
+		DatabaseError(c, err, "read featured recipes")
+		return
+	}
+
+	// Thumbnail URLs can only be signed when storage is configured
+	if h.storageService != nil {
+		if err := h.attachThumbnailURLs(c.Request.Context(), recipes); err != nil {
+			DatabaseError(c, err, "load recipe thumbnails")
+			return
+		}
+	}
+
+	SuccessResponseWithPagination(c, recipes, &Pagination{
+		Page:       page,
+		PerPage:    perPage,
+		Total:      total,
+		TotalPages: (total + perPage - 1) / perPage,
+	})
+}
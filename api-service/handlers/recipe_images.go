@@ -0,0 +1,565 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+
+	"cloud.google.com/go/storage"
+	"digital-recipes/api-service/logctx"
+	"digital-recipes/api-service/middleware"
+	"digital-recipes/api-service/models"
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+	"github.com/sirupsen/logrus"
+)
+
+// primaryImageURLExpiry is how long download URLs in recipe responses stay valid
+const primaryImageURLExpiry = time.Hour
+
+// validImageID matches the image IDs GenerateUploadURLs issues, which are safe to embed in object names
+var validImageID = regexp.MustCompile(`^[a-zA-Z0-9_-]{10,100}$`)
+
+// recipeImageColumns are the recipe_images columns scanned by scanRecipeImage
+const recipeImageColumns = `id, recipe_id, object_name, content_type, size_bytes, position, is_primary, content_hash, created_at,
+	thumbnail_object_name, thumbnail_failed`
+
+// recipeImagesQuery selects a recipe's images in display order
+const recipeImagesQuery = `
+		SELECT ` + recipeImageColumns + `
+		FROM recipe_images
+		WHERE recipe_id = $1
+		ORDER BY position, id
+	`
+
+// queryRecipeImages fetches a recipe's images in display order
+func queryRecipeImages(q dbQuerier, recipeID int) ([]models.RecipeImage, error) {
+	rows, err := q.Query(recipeImagesQuery, recipeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	images := []models.RecipeImage{}
+	for rows.Next() {
+		image, err := scanRecipeImage(rows.Scan)
+		if err != nil {
+			return nil, err
+		}
+		images = append(images, image)
+	}
+	return images, rows.Err()
+}
+
+// scanRecipeImage scans one row selected with recipeImageColumns
+func scanRecipeImage(scan func(dest ...interface{}) error) (models.RecipeImage, error) {
+	var image models.RecipeImage
+	err := scan(
+		&image.ID,
+		&image.RecipeID,
+		&image.ObjectName,
+		&image.ContentType,
+		&image.SizeBytes,
+		&image.Position,
+		&image.IsPrimary,
+		&image.ContentHash,
+		&image.CreatedAt,
+		&image.ThumbnailObjectName,
+		&image.ThumbnailFailed,
+	)
+	return image, err
+}
+
+// primaryImageURL returns a download URL for a recipe's primary image
+// Recipes without images, or a service without storage, get nil; signing failures are logged and also give nil
+// so a storage outage never hides the recipe itself
+func (h *RecipeHandler) primaryImageURL(ctx context.Context, recipeID int) (*string, error) {
+	if h.storageService == nil {
+		return nil, nil
+	}
+
+	var objectName string
+	err := h.db.DB.QueryRowContext(ctx, `
+		SELECT object_name FROM recipe_images WHERE recipe_id = $1 AND is_primary
+	`, recipeID).Scan(&objectName)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	url, err := h.storageService.GenerateDownloadURL(ctx, objectName, primaryImageURLExpiry)
+	if err != nil {
+		return nil, nil
+	}
+	return &url, nil
+}
+
+// attachThumbnail generates and records the thumbnail of a confirmed image
+// It never fails the confirmation: undecodable content marks the image so it is not retried,
+// while storage failures are only logged so confirming the upload again retries them
+func (h *RecipeHandler) attachThumbnail(ctx context.Context, image *models.RecipeImage) {
+	logger := logctx.FromContext(ctx).WithField("image_id", image.ID)
+
+	thumbnailName, err := h.storageService.StoreThumbnail(ctx, image.ObjectName)
+	switch {
+	case errors.Is(err, ErrThumbnailDecode):
+		if _, err := h.db.DB.ExecContext(ctx, `UPDATE recipe_images SET thumbnail_failed = true WHERE id = $1`, image.ID); err != nil {
+			logger.WithError(err).Error("Failed to mark image thumbnail as failed")
+			return
+		}
+		image.ThumbnailFailed = true
+	case err != nil:
+		logger.WithError(err).Warn("Image thumbnail not generated")
+	default:
+		if _, err := h.db.DB.ExecContext(ctx, `UPDATE recipe_images SET thumbnail_object_name = $2 WHERE id = $1`, image.ID, thumbnailName); err != nil {
+			logger.WithError(err).Error("Failed to record image thumbnail")
+			return
+		}
+		image.ThumbnailObjectName = &thumbnailName
+	}
+}
+
+// recipeThumbnailsQuery selects the thumbnail of each listed recipe's primary image
+const recipeThumbnailsQuery = `
+		SELECT recipe_id, thumbnail_object_name
+		FROM recipe_images
+		WHERE recipe_id = ANY($1) AND is_primary AND thumbnail_object_name IS NOT NULL
+	`
+
+// attachThumbnailURLs sets download URLs for the primary image thumbnails of a page of recipes
+// Like primaryImageURL, signing failures leave the URL unset rather than failing the list
+func (h *RecipeHandler) attachThumbnailURLs(ctx context.Context, recipes []models.RecipeListItem) error {
+	if len(recipes) == 0 {
+		return nil
+	}
+
+	recipeIDs := make([]int, len(recipes))
+	for i, recipe := range recipes {
+		recipeIDs[i] = recipe.ID
+	}
+
+	rows, err := h.db.QueryContextLogged(ctx, recipeThumbnailsQuery, pq.Array(recipeIDs))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	thumbnails := make(map[int]string, len(recipes))
+	for rows.Next() {
+		var recipeID int
+		var objectName string
+		if err := rows.Scan(&recipeID, &objectName); err != nil {
+			return err
+		}
+		thumbnails[recipeID] = objectName
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for i := range recipes {
+		objectName, ok := thumbnails[recipes[i].ID]
+		if !ok {
+			continue
+		}
+		if url, err := h.storageService.GenerateDownloadURL(ctx, objectName, primaryImageURLExpiry); err == nil {
+			recipes[i].ThumbnailURL = &url
+		}
+	}
+	return nil
+}
+
+// lockRecipeForImageChange locks a recipe row and checks the current user may change its images
+func lockRecipeForImageChange(c *gin.Context, tx *sql.Tx, recipeID int) *recipeChangeError {
+	recipe, err := queryRecipe(tx, recipeID, true)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return &recipeChangeError{status: 404, message: "recipe not found"}
+		}
+		return &recipeChangeError{dbErr: err}
+	}
+	if !canModifyRecipe(c, recipe.UserID) {
+		return &recipeChangeError{status: 403, message: "You do not have permission to modify this recipe"}
+	}
+	return nil
+}
+
+// GetRecipeImages handles GET /recipes/:id/images requests
+func (h *RecipeHandler) GetRecipeImages(c *gin.Context) {
+	recipeID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		BadRequestError(c, "invalid recipe ID")
+		return
+	}
+
+	visible, err := h.recipeVisible(c, recipeID)
+	if err != nil {
+		DatabaseError(c, err, "load recipe")
+		return
+	}
+	if !visible {
+		NotFoundError(c, "recipe not found")
+		return
+	}
+
+	images, err := queryRecipeImages(h.db.DB, recipeID)
+	if err != nil {
+		DatabaseError(c, err, "load recipe images")
+		return
+	}
+
+	SuccessResponse(c, images)
+}
+
+// ReorderRecipeImages handles PUT /recipes/:id/images/order requests
+// The request must list every image of the recipe exactly once; positions follow the list order
+func (h *RecipeHandler) ReorderRecipeImages(c *gin.Context) {
+	logger := middleware.LogWithContext(c)
+
+	recipeID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		BadRequestError(c, "invalid recipe ID")
+		return
+	}
+
+	var input models.ReorderImagesRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		logger.WithError(err).Warn("Reorder images binding failed")
+		if isBodyTooLarge(err) {
+			RequestTooLargeError(c, "Request body too large")
+			return
+		}
+		ValidationError(c, "Invalid request format. Check image_ids field.", "image_ids")
+		return
+	}
+
+	if middleware.GetUserID(c) == 0 {
+		AuthenticationError(c, "Authentication required to reorder images")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(logctx.WithLogger(context.Background(), logger), 30*time.Second)
+	defer cancel()
+
+	var images []models.RecipeImage
+	err = h.db.WithTx(ctx, func(tx *sql.Tx) error {
+		if changeErr := lockRecipeForImageChange(c, tx, recipeID); changeErr != nil {
+			return changeErr
+		}
+
+		current, err := queryRecipeImages(tx, recipeID)
+		if err != nil {
+			return err
+		}
+		if msg := validateImageOrder(current, input.ImageIDs); msg != "" {
+			return &recipeChangeError{status: 400, field: "image_ids", message: msg}
+		}
+
+		for position, imageID := range input.ImageIDs {
+			if _, err := tx.Exec(`UPDATE recipe_images SET position = $1 WHERE id = $2`, position, imageID); err != nil {
+				return err
+			}
+		}
+
+		images, err = queryRecipeImages(tx, recipeID)
+		return err
+	})
+	var changeErr *recipeChangeError
+	if errors.As(err, &changeErr) {
+		changeErr.respond(c, "reorder recipe images")
+		return
+	}
+	if err != nil {
+		DatabaseError(c, err, "reorder recipe images")
+		return
+	}
+
+	logger.WithFields(logrus.Fields{
+		"recipe_id":   recipeID,
+		"image_count": len(images),
+	}).Info("Recipe images reordered")
+
+	SuccessResponse(c, images)
+}
+
+// validateImageOrder checks that ids names each current image exactly once
+// and returns a message describing the first problem, or "" when the order is valid
+func validateImageOrder(current []models.RecipeImage, ids []int) string {
+	known := make(map[int]bool, len(current))
+	for _, image := range current {
+		known[image.ID] = true
+	}
+
+	seen := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		if !known[id] {
+			return fmt.Sprintf("image %d does not belong to this recipe", id)
+		}
+		if seen[id] {
+			return fmt.Sprintf("image %d is listed more than once", id)
+		}
+		seen[id] = true
+	}
+
+	if len(seen) != len(known) {
+		return "image_ids must list every image of the recipe"
+	}
+	return ""
+}
+
+// SetPrimaryRecipeImage handles PUT /recipes/:id/images/:imageId/primary requests
+// The previous primary image loses the flag in the same transaction, so a recipe never has two
+func (h *RecipeHandler) SetPrimaryRecipeImage(c *gin.Context) {
+	logger := middleware.LogWithContext(c)
+
+	recipeID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		BadRequestError(c, "invalid recipe ID")
+		return
+	}
+
+	imageID, err := strconv.Atoi(c.Param("imageId"))
+	if err != nil {
+		BadRequestError(c, "invalid image ID")
+		return
+	}
+
+	if middleware.GetUserID(c) == 0 {
+		AuthenticationError(c, "Authentication required to change the primary image")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(logctx.WithLogger(context.Background(), logger), 30*time.Second)
+	defer cancel()
+
+	var images []models.RecipeImage
+	err = h.db.WithTx(ctx, func(tx *sql.Tx) error {
+		if changeErr := lockRecipeForImageChange(c, tx, recipeID); changeErr != nil {
+			return changeErr
+		}
+
+		var belongs bool
+		err := tx.QueryRow(`
+			SELECT EXISTS(SELECT 1 FROM recipe_images WHERE id = $1 AND recipe_id = $2)
+		`, imageID, recipeID).Scan(&belongs)
+		if err != nil {
+			return err
+		}
+		if !belongs {
+			return &recipeChangeError{status: 404, message: "image not found"}
+		}
+
+		// Clear the old flag first; the partial unique index rejects two primaries even mid-transaction
+		if _, err := tx.Exec(`
+			UPDATE recipe_images SET is_primary = FALSE
+			WHERE recipe_id = $1 AND is_primary AND id <> $2
+		`, recipeID, imageID); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(`UPDATE recipe_images SET is_primary = TRUE WHERE id = $1`, imageID); err != nil {
+			return err
+		}
+
+		images, err = queryRecipeImages(tx, recipeID)
+		return err
+	})
+	var changeErr *recipeChangeError
+	if errors.As(err, &changeErr) {
+		changeErr.respond(c, "set primary image")
+		return
+	}
+	if err != nil {
+		DatabaseError(c, err, "set primary image")
+		return
+	}
+
+	logger.WithFields(logrus.Fields{
+		"recipe_id": recipeID,
+		"image_id":  imageID,
+	}).Info("Primary recipe image changed")
+
+	SuccessResponse(c, images)
+}
+
+// ConfirmRecipeImage handles POST /recipes/:id/images/confirm requests
+// Clients call it after uploading to a URL from an upload request. The image is recorded with its content hash;
+// when the recipe already has identical content the new object is deleted and the existing image is returned
+func (h *RecipeHandler) ConfirmRecipeImage(c *gin.Context) {
+	logger := middleware.LogWithContext(c)
+
+	recipeID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		BadRequestError(c, "invalid recipe ID")
+		return
+	}
+
+	var input models.ConfirmImageRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		logger.WithError(err).Warn("Confirm image binding failed")
+		if isBodyTooLarge(err) {
+			RequestTooLargeError(c, "Request body too large")
+			return
+		}
+		ValidationError(c, "Invalid request format. Check image_id field.", "image_id")
+		return
+	}
+
+	if !validImageID.MatchString(input.ImageID) {
+		ValidationError(c, "image_id must be an image ID returned by an upload request", "image_id")
+		return
+	}
+
+	if middleware.GetUserID(c) == 0 {
+		AuthenticationError(c, "Authentication required to confirm uploads")
+		return
+	}
+
+	if h.storageService == nil {
+		logger.Error("Storage service not available")
+		ServiceUnavailableError(c, "File upload service is temporarily unavailable")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(logctx.WithLogger(context.Background(), logger), 30*time.Second)
+	defer cancel()
+
+	// Ownership is checked before storage is read so other users cannot probe for uploads
+	var ownerID int
+	var storagePrefix string
+	err = h.db.DB.QueryRowContext(ctx, `SELECT user_id, `+recipeStoragePrefixColumn+` FROM recipes WHERE id = $1`, recipeID).Scan(&ownerID, &storagePrefix)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			NotFoundError(c, "recipe not found")
+			return
+		}
+		DatabaseError(c, err, "load recipe owner")
+		return
+	}
+	if !canModifyRecipe(c, ownerID) {
+		AuthorizationError(c, "You do not have permission to modify this recipe")
+		return
+	}
+
+	objectName, err := h.storageService.FindUploadedImage(ctx, storagePrefix, input.ImageID)
+	var stored StoredObject
+	if err == nil {
+		stored, err = h.storageService.GetObjectHash(ctx, objectName)
+	}
+	if err != nil {
+		if errors.Is(err, storage.ErrObjectNotExist) {
+			NotFoundError(c, "uploaded image not found")
+			return
+		}
+		StorageError(c, err, "read uploaded image")
+		return
+	}
+
+	var contentType *string
+	if stored.ContentType != "" {
+		canonical := models.CanonicalizeContentType(stored.ContentType)
+		contentType = &canonical
+	}
+
+	var confirmation models.ImageConfirmation
+	overStorageCap := false
+	err = h.db.WithTx(ctx, func(tx *sql.Tx) error {
+		if changeErr := lockRecipeForImageChange(c, tx, recipeID); changeErr != nil {
+			return changeErr
+		}
+
+		// Confirming the same upload again returns the image already recorded for it
+		image, err := scanRecipeImage(tx.QueryRow(`
+			SELECT `+recipeImageColumns+` FROM recipe_images WHERE object_name = $1
+		`, objectName).Scan)
+		if err == nil {
+			confirmation.Image = image
+			return nil
+		}
+		if err != sql.ErrNoRows {
+			return err
+		}
+
+		image, err = scanRecipeImage(tx.QueryRow(`
+			SELECT `+recipeImageColumns+` FROM recipe_images WHERE recipe_id = $1 AND content_hash = $2
+		`, recipeID, stored.Hash).Scan)
+		if err == nil {
+			confirmation = models.ImageConfirmation{Image: image, Deduplicated: true}
+			return nil
+		}
+		if err != sql.ErrNoRows {
+			return err
+		}
+
+		// The recipe lock keeps concurrent confirmations from both fitting under the cap
+		var storedBytes int64
+		if err := tx.QueryRow(`
+			SELECT COALESCE(SUM(size_bytes), 0) FROM recipe_images WHERE recipe_id = $1
+		`, recipeID).Scan(&storedBytes); err != nil {
+			return err
+		}
+		if storedBytes+stored.Size > models.MaxRecipeImageBytes {
+			overStorageCap = true
+			msg := fmt.Sprintf("recipe images cannot exceed %dMB in total", models.MaxTotalUploadMB)
+			return &recipeChangeError{status: 400, field: "image_id", message: msg}
+		}
+
+		// New images go last; the first image of a recipe becomes its primary image
+		image, err = scanRecipeImage(tx.QueryRow(`
+			INSERT INTO recipe_images (recipe_id, object_name, content_type, size_bytes, content_hash, position, is_primary)
+			VALUES (
+				$1, $2, $3, $4, $5,
+				(SELECT COALESCE(MAX(position) + 1, 0) FROM recipe_images WHERE recipe_id = $1),
+				NOT EXISTS (SELECT 1 FROM recipe_images WHERE recipe_id = $1 AND is_primary)
+			)
+			RETURNING `+recipeImageColumns+`
+		`, recipeID, objectName, contentType, stored.Size, stored.Hash).Scan)
+		confirmation.Image = image
+		return err
+	})
+	var changeErr *recipeChangeError
+	if errors.As(err, &changeErr) {
+		// An upload that would take the recipe over its cap is not kept in storage either
+		if overStorageCap {
+			logger.WithFields(logrus.Fields{
+				"recipe_id":  recipeID,
+				"object":     objectName,
+				"size_bytes": stored.Size,
+			}).Warn("Recipe image storage cap exceeded")
+			if err := h.storageService.DeleteObject(ctx, objectName); err != nil {
+				logger.WithError(err).WithField("object", objectName).Warn("Failed to delete upload over the storage cap")
+			}
+		}
+		changeErr.respond(c, "confirm recipe image")
+		return
+	}
+	if err != nil {
+		DatabaseError(c, err, "confirm recipe image")
+		return
+	}
+
+	// The duplicate is only removed once the existing image is known to be kept
+	if confirmation.Deduplicated {
+		if err := h.storageService.DeleteObject(ctx, objectName); err != nil {
+			logger.WithError(err).WithField("object", objectName).Warn("Failed to delete duplicate upload")
+		}
+	}
+
+	// Images confirmed before thumbnails existed, or whose thumbnail could not be stored, get one now
+	if confirmation.Image.ThumbnailObjectName == nil && !confirmation.Image.ThumbnailFailed {
+		h.attachThumbnail(ctx, &confirmation.Image)
+	}
+
+	logger.WithFields(logrus.Fields{
+		"recipe_id":    recipeID,
+		"image_id":     confirmation.Image.ID,
+		"deduplicated": confirmation.Deduplicated,
+	}).Info("Recipe image confirmed")
+
+	SuccessResponse(c, confirmation)
+}
@@ -0,0 +1,512 @@
+package handlers
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"digital-recipes/api-service/ingredients"
+	"digital-recipes/api-service/middleware"
+	"digital-recipes/api-service/models"
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultMaxIngredientsPerRecipe is how many ingredients a recipe may have when MAX_INGREDIENTS_PER_RECIPE is not set
+const DefaultMaxIngredientsPerRecipe = 200
+
+// GetMaxIngredientsPerRecipe returns the most ingredients a recipe may have, from MAX_INGREDIENTS_PER_RECIPE
+// The cap bounds the ingredient joins and single-recipe responses
+func GetMaxIngredientsPerRecipe() int {
+	value := os.Getenv("MAX_INGREDIENTS_PER_RECIPE")
+	if value == "" {
+		return DefaultMaxIngredientsPerRecipe
+	}
+
+	max, err := strconv.Atoi(value)
+	if err != nil || max <= 0 {
+		logrus.WithField("MAX_INGREDIENTS_PER_RECIPE", value).Warn("Invalid MAX_INGREDIENTS_PER_RECIPE, using default")
+		return DefaultMaxIngredientsPerRecipe
+	}
+	return max
+}
+
+// ingredientLimitErrors reports field when a recipe would end up with more ingredients than the handler allows
+func (h *RecipeHandler) ingredientLimitErrors(field string, count int) models.FieldErrors {
+	if count <= h.maxIngredients {
+		return nil
+	}
+	return models.FieldErrors{{Field: field, Message: fmt.Sprintf("a recipe can have at most %d ingredients", h.maxIngredients)}}
+}
+
+// inputIngredientLimitErrors checks the ingredient list of a write that replaces the list only when it sends one
+func (h *RecipeHandler) inputIngredientLimitErrors(ingredients *[]models.IngredientInput) models.FieldErrors {
+	if ingredients == nil {
+		return nil
+	}
+	return h.ingredientLimitErrors("ingredients", len(*ingredients))
+}
+
+// canModifyRecipe reports whether the current user owns the recipe or is an admin
+func canModifyRecipe(c *gin.Context, ownerID int) bool {
+	userID := middleware.GetUserID(c)
+	return userID != 0 && (userID == ownerID || middleware.IsAdmin(c))
+}
+
+// canViewRecipe reports whether the caller may open a recipe with the given owner and visibility by its ID
+// Private recipes are reported as missing to everyone but their owner and admins
+func canViewRecipe(c *gin.Context, ownerID int, visibility string) bool {
+	return visibility != models.VisibilityPrivate || canModifyRecipe(c, ownerID)
+}
+
+// recipeVisible reports whether a recipe exists and the caller may view it
+func (h *RecipeHandler) recipeVisible(c *gin.Context, recipeID int) (bool, error) {
+	var ownerID int
+	var visibility string
+	err := h.db.DB.QueryRowContext(c.Request.Context(), `SELECT user_id, visibility FROM recipes WHERE id = $1`, recipeID).Scan(&ownerID, &visibility)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return canViewRecipe(c, ownerID, visibility), nil
+}
+
+// insertRecipeIngredient stores an ingredient line for a recipe
+// Quantity and unit the caller left out are filled in by parsing original_text
+func insertRecipeIngredient(q queryRower, recipeID int, input models.IngredientInput) (models.RecipeIngredient, error) {
+	quantity, unit := input.Quantity, input.Unit
+	if quantity == nil || unit == nil {
+		parsedQuantity, parsedUnit, _ := ingredients.ParseIngredientLine(input.OriginalText)
+		if quantity == nil {
+			quantity = parsedQuantity
+		}
+		if unit == nil {
+			unit = parsedUnit
+		}
+	}
+
+	// Links to canonical ingredients deleted since a snapshot was taken become NULL,
+	// matching the ON DELETE SET NULL behaviour of the foreign key
+	query := `
+		INSERT INTO recipe_ingredients (recipe_id, original_text, quantity, unit, canonical_ingredient_id)
+		VALUES ($1, $2, $3, $4, (SELECT id FROM canonical_ingredients WHERE id = $5))
+		RETURNING id, recipe_id, canonical_ingredient_id, original_text, quantity, unit, created_at, updated_at
+	`
+
+	var ingredient models.RecipeIngredient
+	err := q.QueryRow(query, recipeID, input.OriginalText, quantity, unit, input.CanonicalIngredientID).Scan(
+		&ingredient.ID,
+		&ingredient.RecipeID,
+		&ingredient.CanonicalIngredientID,
+		&ingredient.OriginalText,
+		&ingredient.Quantity,
+		&ingredient.Unit,
+		&ingredient.CreatedAt,
+		&ingredient.UpdatedAt,
+	)
+	return ingredient, err
+}
+
+// canonicalLinkErrors checks each line sent with a canonical_ingredient_id links an existing, approved canonical
+// ingredient, as LinkRecipeIngredient requires; field names the list the lines came from, or is empty for a single line
+func canonicalLinkErrors(q dbQuerier, field string, inputs []models.IngredientInput) (models.FieldErrors, error) {
+	var ids []int64
+	for _, input := range inputs {
+		if input.CanonicalIngredientID != nil {
+			ids = append(ids, int64(*input.CanonicalIngredientID))
+		}
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	rows, err := q.Query(`SELECT id, is_approved FROM canonical_ingredients WHERE id = ANY($1)`, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	approved := make(map[int]bool, len(ids))
+	for rows.Next() {
+		var id int
+		var isApproved bool
+		if err := rows.Scan(&id, &isApproved); err != nil {
+			return nil, err
+		}
+		approved[id] = isApproved
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	var errs models.FieldErrors
+	for i, input := range inputs {
+		if input.CanonicalIngredientID == nil {
+			continue
+		}
+		name := "canonical_ingredient_id"
+		if field != "" {
+			name = fmt.Sprintf("%s[%d].canonical_ingredient_id", field, i)
+		}
+		isApproved, found := approved[*input.CanonicalIngredientID]
+		switch {
+		case !found:
+			errs = append(errs, models.FieldError{Field: name, Message: "canonical ingredient not found"})
+		case !isApproved:
+			errs = append(errs, models.FieldError{Field: name, Message: "canonical ingredient is not approved"})
+		}
+	}
+	return errs, nil
+}
+
+// checkCanonicalLinks rejects a write with 422 when one of its lines links a missing or unapproved canonical
+// ingredient, reporting whether the write may go ahead; a nil list links nothing
+func (h *RecipeHandler) checkCanonicalLinks(c *gin.Context, field string, inputs *[]models.IngredientInput) bool {
+	if inputs == nil {
+		return true
+	}
+	errs, err := canonicalLinkErrors(h.db.DB, field, *inputs)
+	if err != nil {
+		DatabaseError(c, err, "check canonical ingredients")
+		return false
+	}
+	if len(errs) > 0 {
+		FieldValidationErrors(c, errs)
+		return false
+	}
+	return true
+}
+
+// insertRecipeIngredients stores several ingredient lines for a recipe in order
+func insertRecipeIngredients(q queryRower, recipeID int, inputs []models.IngredientInput) ([]models.RecipeIngredient, error) {
+	created := make([]models.RecipeIngredient, 0, len(inputs))
+	for _, input := range inputs {
+		ingredient, err := insertRecipeIngredient(q, recipeID, input)
+		if err != nil {
+			return nil, err
+		}
+		created = append(created, ingredient)
+	}
+	return created, nil
+}
+
+// ingredientWarnings describes ingredient lines that were saved but still need a reviewer's attention
+// Lines without a canonical ingredient are left out of shopping lists and similar recipes, and lines without
+// a quantity cannot be scaled
+func ingredientWarnings(recipeIngredients []models.RecipeIngredient) []string {
+	unmatched, unparsed := 0, 0
+	for _, ingredient := range recipeIngredients {
+		if ingredient.CanonicalIngredientID == nil {
+			unmatched++
+		}
+		if ingredient.Quantity == nil {
+			unparsed++
+		}
+	}
+
+	var warnings []string
+	if unmatched > 0 {
+		warnings = append(warnings, fmt.Sprintf("%s could not be matched to known ingredients", countIngredients(unmatched)))
+	}
+	if unparsed > 0 {
+		warnings = append(warnings, fmt.Sprintf("%s had no quantity that could be parsed", countIngredients(unparsed)))
+	}
+	return warnings
+}
+
+// countIngredients phrases an ingredient count, such as "1 ingredient" or "3 ingredients"
+func countIngredients(count int) string {
+	if count == 1 {
+		return "1 ingredient"
+	}
+	return fmt.Sprintf("%d ingredients", count)
+}
+
+// GetRecipeIngredients handles GET /recipes/:id/ingredients requests
+// It pages through a recipe's ingredients in insertion order, with canonical names, without the recipe itself.
+// unmatched=true narrows the list to lines not yet linked to a canonical ingredient so reviewers can
+// work through them, and unmatched=false to the linked ones
+func (h *RecipeHandler) GetRecipeIngredients(c *gin.Context) {
+	recipeID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		BadRequestError(c, "invalid recipe ID")
+		return
+	}
+
+	page, perPage, paginationErr := parsePagination(c, h.pagination)
+	if paginationErr != nil {
+		SafeErrorResponse(c, *paginationErr, http.StatusBadRequest)
+		return
+	}
+
+	var unmatched *bool
+	if value, set := c.GetQuery("unmatched"); set {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			ValidationError(c, "invalid unmatched parameter. Must be true or false", "unmatched")
+			return
+		}
+		unmatched = &parsed
+	}
+
+	visible, err := h.recipeVisible(c, recipeID)
+	if err != nil {
+		DatabaseError(c, err, "load recipe")
+		return
+	}
+	if !visible {
+		NotFoundError(c, "recipe not found")
+		return
+	}
+
+	ingredients, err := h.loadRecipeIngredients(c.Request.Context(), recipeID)
+	if err != nil {
+		DatabaseError(c, err, "load recipe ingredients")
+		return
+	}
+	if unmatched != nil {
+		ingredients = slices.DeleteFunc(ingredients, func(ingredient models.RecipeIngredient) bool {
+			return (ingredient.CanonicalIngredientID == nil) != *unmatched
+		})
+	}
+
+	ingredientPage, pagination := paginateSlice(ingredients, page, perPage)
+	SuccessResponseWithPagination(c, ingredientPage, pagination)
+}
+
+// AddRecipeIngredient handles POST /recipes/:id/ingredients requests
+// Reviewers use it to add ingredient lines the OCR pipeline missed
+func (h *RecipeHandler) AddRecipeIngredient(c *gin.Context) {
+	logger := middleware.LogWithContext(c)
+
+	recipeID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		BadRequestError(c, "invalid recipe ID")
+		return
+	}
+
+	var input models.IngredientInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		logger.WithError(err).Warn("Add ingredient binding failed")
+		if isBodyTooLarge(err) {
+			RequestTooLargeError(c, "Request body too large")
+			return
+		}
+		ValidationError(c, "Invalid request format. Check original_text field.", "original_text")
+		return
+	}
+
+	if strings.TrimSpace(input.OriginalText) == "" {
+		ValidationError(c, "original_text cannot be empty", "original_text")
+		return
+	}
+
+	if middleware.GetUserID(c) == 0 {
+		AuthenticationError(c, "Authentication required to review ingredients")
+		return
+	}
+
+	if !h.checkCanonicalLinks(c, "", &[]models.IngredientInput{input}) {
+		return
+	}
+
+	// The change locks the recipe row, so concurrent additions cannot both fit under the ingredient cap
+	var ingredient models.RecipeIngredient
+	_, changeErr := h.applyRecipeIngredientChange(c, recipeID, func(tx *sql.Tx, current []models.RecipeIngredient) *recipeChangeError {
+		if errs := h.ingredientLimitErrors("ingredients", len(current)+1); len(errs) > 0 {
+			return &recipeChangeError{status: 422, fieldErrs: errs}
+		}
+
+		added, err := insertRecipeIngredient(tx, recipeID, input)
+		if err != nil {
+			return &recipeChangeError{dbErr: err}
+		}
+		ingredient = added
+		return nil
+	})
+	if changeErr != nil {
+		changeErr.respond(c, "add recipe ingredient")
+		return
+	}
+
+	logger.WithFields(logrus.Fields{
+		"recipe_id":     recipeID,
+		"ingredient_id": ingredient.ID,
+	}).Info("Recipe ingredient added")
+
+	SuccessResponseWithWarnings(c, ingredient, ingredientWarnings([]models.RecipeIngredient{ingredient}))
+}
+
+// LinkRecipeIngredient handles PATCH /recipes/:id/ingredients/:ingredientId requests
+// Reviewers use it to link an OCR'd ingredient to an approved canonical ingredient
+func (h *RecipeHandler) LinkRecipeIngredient(c *gin.Context) {
+	logger := middleware.LogWithContext(c)
+
+	recipeID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		BadRequestError(c, "invalid recipe ID")
+		return
+	}
+
+	ingredientID, err := strconv.Atoi(c.Param("ingredientId"))
+	if err != nil {
+		BadRequestError(c, "invalid ingredient ID")
+		return
+	}
+
+	var linkRequest models.LinkIngredientRequest
+	if err := c.ShouldBindJSON(&linkRequest); err != nil {
+		logger.WithError(err).Warn("Link ingredient binding failed")
+		if isBodyTooLarge(err) {
+			RequestTooLargeError(c, "Request body too large")
+			return
+		}
+		ValidationError(c, "Invalid request format. Check canonical_ingredient_id field.", "canonical_ingredient_id")
+		return
+	}
+
+	if middleware.GetUserID(c) == 0 {
+		AuthenticationError(c, "Authentication required to review ingredients")
+		return
+	}
+
+	// Link the ingredient, keeping existing quantity and unit unless corrections were sent
+	query := `
+		UPDATE recipe_ingredients
+		SET canonical_ingredient_id = $1,
+			quantity = COALESCE($2, quantity),
+			unit = COALESCE($3, unit)
+		WHERE id = $4 AND recipe_id = $5
+		RETURNING id, recipe_id, canonical_ingredient_id, original_text, quantity, unit, created_at, updated_at
+	`
+
+	// The recipe change checks the recipe exists and the caller may edit it before anything is looked up
+	var ingredient models.RecipeIngredient
+	var canonicalName string
+	_, changeErr := h.applyRecipeIngredientChange(c, recipeID, func(tx *sql.Tx, _ []models.RecipeIngredient) *recipeChangeError {
+		// Only approved canonical ingredients can be linked
+		var isApproved bool
+		err := tx.QueryRow(`
+			SELECT name, is_approved FROM canonical_ingredients WHERE id = $1
+		`, linkRequest.CanonicalIngredientID).Scan(&canonicalName, &isApproved)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return &recipeChangeError{status: 404, message: "canonical ingredient not found"}
+			}
+			return &recipeChangeError{dbErr: err}
+		}
+
+		if !isApproved {
+			return &recipeChangeError{status: 400, field: "canonical_ingredient_id", message: "canonical ingredient is not approved"}
+		}
+
+		err = tx.QueryRow(query,
+			linkRequest.CanonicalIngredientID,
+			linkRequest.Quantity,
+			linkRequest.Unit,
+			ingredientID,
+			recipeID,
+		).Scan(
+			&ingredient.ID,
+			&ingredient.RecipeID,
+			&ingredient.CanonicalIngredientID,
+			&ingredient.OriginalText,
+			&ingredient.Quantity,
+			&ingredient.Unit,
+			&ingredient.CreatedAt,
+			&ingredient.UpdatedAt,
+		)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return &recipeChangeError{status: 404, message: "recipe ingredient not found"}
+			}
+			return &recipeChangeError{dbErr: err}
+		}
+		return nil
+	})
+	if changeErr != nil {
+		changeErr.respond(c, "link recipe ingredient")
+		return
+	}
+	ingredient.CanonicalName = &canonicalName
+
+	logger.WithFields(logrus.Fields{
+		"recipe_id":               recipeID,
+		"ingredient_id":           ingredientID,
+		"canonical_ingredient_id": linkRequest.CanonicalIngredientID,
+	}).Info("Recipe ingredient linked")
+
+	SuccessResponse(c, ingredient)
+}
+
+// ReplaceRecipeIngredients handles PUT /recipes/:id/ingredients requests
+// Reviewers use it to correct the whole ingredient list at once; the old rows are deleted and the new
+// ones inserted in one transaction, with a revision recorded first so the change can be undone
+func (h *RecipeHandler) ReplaceRecipeIngredients(c *gin.Context) {
+	logger := middleware.LogWithContext(c)
+
+	recipeID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		BadRequestError(c, "invalid recipe ID")
+		return
+	}
+
+	var request models.ReplaceIngredientsRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		logger.WithError(err).Warn("Replace ingredients binding failed")
+		if isBodyTooLarge(err) {
+			RequestTooLargeError(c, "Request body too large")
+			return
+		}
+		ValidationError(c, "Invalid request format. Each ingredient needs original_text and a non-negative quantity.", "ingredients")
+		return
+	}
+
+	for i, input := range request.Ingredients {
+		if strings.TrimSpace(input.OriginalText) == "" {
+			ValidationError(c, "original_text cannot be empty", fmt.Sprintf("ingredients[%d].original_text", i))
+			return
+		}
+	}
+
+	if errs := h.ingredientLimitErrors("ingredients", len(request.Ingredients)); len(errs) > 0 {
+		FieldValidationErrors(c, errs)
+		return
+	}
+
+	if middleware.GetUserID(c) == 0 {
+		AuthenticationError(c, "Authentication required to review ingredients")
+		return
+	}
+
+	if !h.checkCanonicalLinks(c, "ingredients", &request.Ingredients) {
+		return
+	}
+
+	// Everything but the ingredients keeps its current value
+	patch := models.RecipePatch{Ingredients: &request.Ingredients}
+	if _, changeErr := h.applyRecipeChangeFrom(c, recipeID, patch.Apply); changeErr != nil {
+		changeErr.respond(c, "replace recipe ingredients")
+		return
+	}
+
+	replaced, err := h.loadRecipeIngredients(c.Request.Context(), recipeID)
+	if err != nil {
+		DatabaseError(c, err, "load recipe ingredients")
+		return
+	}
+
+	logger.WithFields(logrus.Fields{
+		"recipe_id":        recipeID,
+		"ingredient_count": len(replaced),
+	}).Info("Recipe ingredients replaced")
+
+	SuccessResponseWithWarnings(c, replaced, ingredientWarnings(replaced))
+}
@@ -0,0 +1,273 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"io"
+	"strconv"
+	"time"
+
+	"digital-recipes/api-service/middleware"
+	"digital-recipes/api-service/models"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// shareTokenBytes is how much randomness each share token carries; tokens are its hex encoding
+const shareTokenBytes = 32
+
+// shareColumns are the share_tokens columns scanned by scanRecipeShare
+const shareColumns = `id, recipe_id, created_by, expires_at, revoked_at, created_at`
+
+// generateShareToken returns a random hex token for a share link
+func generateShareToken() (string, error) {
+	token := make([]byte, shareTokenBytes)
+	if _, err := rand.Read(token); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(token), nil
+}
+
+// hashShareToken returns the hash share tokens are stored and looked up by
+func hashShareToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// validShareToken reports whether token has the shape generateShareToken produces
+// Anything else cannot match a link, so it is refused without a query
+func validShareToken(token string) bool {
+	if len(token) != 2*shareTokenBytes {
+		return false
+	}
+	_, err := hex.DecodeString(token)
+	return err == nil
+}
+
+// scanRecipeShare scans a row selected with shareColumns
+func scanRecipeShare(scan func(dest ...interface{}) error) (models.RecipeShare, error) {
+	var share models.RecipeShare
+	err := scan(&share.ID, &share.RecipeID, &share.CreatedBy, &share.ExpiresAt, &share.RevokedAt, &share.CreatedAt)
+	return share, err
+}
+
+// authorizeShareAccess loads the recipe owner and checks the caller may manage its share links
+// It writes the error response itself and returns false when the caller should stop
+func (h *RecipeHandler) authorizeShareAccess(c *gin.Context, recipeID int) bool {
+	if middleware.GetUserID(c) == 0 {
+		AuthenticationError(c, "Authentication required to share recipes")
+		return false
+	}
+
+	var ownerID int
+	err := h.db.DB.QueryRow(`SELECT user_id FROM recipes WHERE id = $1`, recipeID).Scan(&ownerID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			NotFoundError(c, "recipe not found")
+			return false
+		}
+		DatabaseError(c, err, "load recipe owner")
+		return false
+	}
+
+	if !canModifyRecipe(c, ownerID) {
+		AuthorizationError(c, "You do not have permission to share this recipe")
+		return false
+	}
+	return true
+}
+
+// CreateRecipeShare handles POST /recipes/:id/share requests
+// The body is optional; expires_at limits how long the link works, otherwise it lasts until revoked
+func (h *RecipeHandler) CreateRecipeShare(c *gin.Context) {
+	logger := middleware.LogWithContext(c)
+
+	recipeID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		BadRequestError(c, "invalid recipe ID")
+		return
+	}
+
+	var input models.RecipeShareInput
+	if err := c.ShouldBindJSON(&input); err != nil && !errors.Is(err, io.EOF) {
+		logger.WithError(err).Warn("Create recipe share binding failed")
+		if isBodyTooLarge(err) {
+			RequestTooLargeError(c, "Request body too large")
+			return
+		}
+		ValidationError(c, "Invalid request format. expires_at must be an RFC 3339 date-time.", "expires_at")
+		return
+	}
+	if input.ExpiresAt != nil && !input.ExpiresAt.After(time.Now()) {
+		ValidationError(c, "expires_at must be in the future", "expires_at")
+		return
+	}
+
+	if !h.authorizeShareAccess(c, recipeID) {
+		return
+	}
+
+	token, err := generateShareToken()
+	if err != nil {
+		InternalServerError(c, "Failed to generate share token")
+		return
+	}
+
+	link := models.RecipeShareLink{Token: token, Path: "/api/v1/shared/" + token}
+	link.RecipeShare, err = scanRecipeShare(h.db.DB.QueryRow(`
+		INSERT INTO share_tokens (recipe_id, token_hash, created_by, expires_at)
+		VALUES ($1, $2, (SELECT id FROM users WHERE id = $3), $4)
+		RETURNING `+shareColumns,
+		recipeID, hashShareToken(token), middleware.GetUserID(c), input.ExpiresAt,
+	).Scan)
+	if err != nil {
+		DatabaseError(c, err, "create share link")
+		return
+	}
+
+	logger.WithFields(logrus.Fields{
+		"recipe_id": recipeID,
+		"share_id":  link.ID,
+	}).Info("Recipe share link created")
+
+	SuccessResponse(c, link)
+}
+
+// GetRecipeShares handles GET /recipes/:id/share requests, newest first; tokens are never listed
+func (h *RecipeHandler) GetRecipeShares(c *gin.Context) {
+	recipeID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		BadRequestError(c, "invalid recipe ID")
+		return
+	}
+
+	if !h.authorizeShareAccess(c, recipeID) {
+		return
+	}
+
+	rows, err := h.db.DB.Query(`
+		SELECT `+shareColumns+` FROM share_tokens WHERE recipe_id = $1 ORDER BY id DESC
+	`, recipeID)
+	if err != nil {
+		DatabaseError(c, err, "list share links")
+		return
+	}
+	defer rows.Close()
+
+	shares := []models.RecipeShare{}
+	for rows.Next() {
+		share, err := scanRecipeShare(rows.Scan)
+		if err != nil {
+			DatabaseError(c, err, "read share link")
+			return
+		}
+		shares = append(shares, share)
+	}
+	if err := rows.Err(); err != nil {
+		DatabaseError(c, err, "read share links")
+		return
+	}
+
+	SuccessResponse(c, shares)
+}
+
+// RevokeRecipeShare handles DELETE /recipes/:id/share/:shareId requests
+// Revoking a link that is already revoked keeps its original revocation time
+func (h *RecipeHandler) RevokeRecipeShare(c *gin.Context) {
+	logger := middleware.LogWithContext(c)
+
+	recipeID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		BadRequestError(c, "invalid recipe ID")
+		return
+	}
+	shareID, err := strconv.Atoi(c.Param("shareId"))
+	if err != nil {
+		BadRequestError(c, "invalid share ID")
+		return
+	}
+
+	if !h.authorizeShareAccess(c, recipeID) {
+		return
+	}
+
+	share, err := scanRecipeShare(h.db.DB.QueryRow(`
+		UPDATE share_tokens SET revoked_at = COALESCE(revoked_at, NOW())
+		WHERE id = $1 AND recipe_id = $2
+		RETURNING `+shareColumns,
+		shareID, recipeID,
+	).Scan)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			NotFoundError(c, "share link not found")
+			return
+		}
+		DatabaseError(c, err, "revoke share link")
+		return
+	}
+
+	logger.WithFields(logrus.Fields{
+		"recipe_id": recipeID,
+		"share_id":  share.ID,
+	}).Info("Recipe share link revoked")
+
+	SuccessResponse(c, share)
+}
+
+// GetSharedRecipe handles GET /shared/:token requests
+// A valid link returns the recipe whatever its status or visibility; unknown, revoked and expired links all get 404
+func (h *RecipeHandler) GetSharedRecipe(c *gin.Context) {
+	// Revoked links must stop working straight away, so nothing along the way may keep a copy
+	c.Header("Cache-Control", "no-store")
+
+	token := c.Param("token")
+	if !validShareToken(token) {
+		NotFoundError(c, "shared recipe not found")
+		return
+	}
+
+	var recipeID int
+	err := h.db.DB.QueryRowContext(c.Request.Context(), `
+		SELECT recipe_id FROM share_tokens
+		WHERE token_hash = $1 AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > NOW())
+	`, hashShareToken(token)).Scan(&recipeID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			NotFoundError(c, "shared recipe not found")
+			return
+		}
+		DatabaseError(c, err, "load share link")
+		return
+	}
+
+	recipe, err := h.loadRecipe(c.Request.Context(), recipeID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			NotFoundError(c, "shared recipe not found")
+			return
+		}
+		DatabaseError(c, err, "load shared recipe")
+		return
+	}
+
+	ingredients, err := h.loadRecipeIngredients(c.Request.Context(), recipeID)
+	if err != nil {
+		DatabaseError(c, err, "load shared recipe ingredients")
+		return
+	}
+
+	primaryImageURL, err := h.primaryImageURL(c.Request.Context(), recipeID)
+	if err != nil {
+		DatabaseError(c, err, "load primary image")
+		return
+	}
+
+	SuccessResponse(c, models.RecipeWithIngredients{
+		Recipe:          recipe,
+		Ingredients:     ingredients,
+		PrimaryImageURL: primaryImageURL,
+	})
+}
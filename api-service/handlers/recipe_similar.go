@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"digital-recipes/api-service/models"
+	"github.com/gin-gonic/gin"
+)
+
+// similarRecipesQuery ranks published, public recipes by how many canonical ingredients they share with $1
+// The target's rows are found through idx_recipe_ingredients_recipe_id and candidates through
+// idx_recipe_ingredients_canonical_id, so only recipes sharing at least one ingredient are touched
+// Ties are broken by recipe ID to keep pages stable
+var similarRecipesQuery = `
+		SELECT
+			` + qualifiedRecipeColumns("r") + `,
+			COUNT(DISTINCT candidate.canonical_ingredient_id) AS shared_ingredients,
+			COUNT(*) OVER() AS total_count
+		FROM recipe_ingredients target
+		JOIN recipe_ingredients candidate
+			ON candidate.canonical_ingredient_id = target.canonical_ingredient_id
+			AND candidate.recipe_id <> target.recipe_id
+		JOIN recipes r ON r.id = candidate.recipe_id
+		WHERE target.recipe_id = $1
+			AND target.canonical_ingredient_id IS NOT NULL
+			AND r.status = 'published'
+			AND r.visibility = 'public'
+		GROUP BY r.id
+		ORDER BY shared_ingredients DESC, r.id ASC
+		LIMIT $2 OFFSET $3
+	`
+
+// GetSimilarRecipes handles GET /recipes/:id/similar requests
+// Recipes with no linked ingredients in common with the target are not listed
+func (h *RecipeHandler) GetSimilarRecipes(c *gin.Context) {
+	recipeID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		BadRequestError(c, "invalid recipe ID")
+		return
+	}
+
+	page, perPage, paginationErr := parsePagination(c, h.pagination)
+	if paginationErr != nil {
+		SafeErrorResponse(c, *paginationErr, http.StatusBadRequest)
+		return
+	}
+
+	visible, err := h.recipeVisible(c, recipeID)
+	if err != nil {
+		DatabaseError(c, err, "load recipe")
+		return
+	}
+	if !visible {
+		NotFoundError(c, "recipe not found")
+		return
+	}
+
+	rows, err := h.db.QueryContextLogged(c.Request.Context(), similarRecipesQuery, recipeID, perPage, (page-1)*perPage)
+	if err != nil {
+		DatabaseError(c, err, "load similar recipes")
+		return
+	}
+	defer rows.Close()
+
+	recipes := []models.SimilarRecipe{}
+	total := 0
+	for rows.Next() {
+		var recipe models.SimilarRecipe
+		if err := rows.Scan(append(recipeScanTargets(&recipe.Recipe),
+			&recipe.SharedIngredients,
+			&total,
+		)...); err != nil {
+			DatabaseError(c, err, "read similar recipes")
+			return
+		}
+		recipes = append(recipes, recipe)
+	}
+	if err := rows.Err(); err != nil {
+		DatabaseError(c, err, "read similar recipes")
+		return
+	}
+
+	SuccessResponseWithPagination(c, recipes, &Pagination{
+		Page:       page,
+		PerPage:    perPage,
+		Total:      total,
+		TotalPages: (total + perPage - 1) / perPage,
+	})
+}
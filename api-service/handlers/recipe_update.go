@@ -0,0 +1,409 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"digital-recipes/api-service/logctx"
+	"digital-recipes/api-service/middleware"
+	"digital-recipes/api-service/models"
+	"digital-recipes/api-service/webhooks"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// allowedStatusTransitions lists the statuses each status may move to
+// Published recipes can be sent back for review but never back to processing
+var allowedStatusTransitions = map[string][]string{
+	"processing":      {"review_required"},
+	"review_required": {"published"},
+	"published":       {"review_required"},
+}
+
+// isValidStatusTransition reports whether a recipe may move from one status to another
+func isValidStatusTransition(from, to string) bool {
+	if from == to {
+		return true
+	}
+	for _, allowed := range allowedStatusTransitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// recipeChangeError describes why a recipe change failed so the handler can respond
+// fieldErrs is set instead of field and message when the change is rejected with a list of field errors
+type recipeChangeError struct {
+	status    int
+	field     string
+	message   string
+	fieldErrs models.FieldErrors
+	dbErr     error
+}
+
+// Error implements the error interface so the change can abort a transaction
+func (e *recipeChangeError) Error() string {
+	if e.dbErr != nil {
+		return e.dbErr.Error()
+	}
+	if len(e.fieldErrs) > 0 {
+		return e.fieldErrs[0].Message
+	}
+	return e.message
+}
+
+// Unwrap exposes the database error so callers can tell transient failures apart
+func (e *recipeChangeError) Unwrap() error {
+	return e.dbErr
+}
+
+// respond writes the error using the matching error helper
+func (e *recipeChangeError) respond(c *gin.Context, operation string) {
+	switch {
+	case e.dbErr != nil:
+		DatabaseError(c, e.dbErr, operation)
+	case len(e.fieldErrs) > 0:
+		FieldValidationErrors(c, e.fieldErrs)
+	case e.status == 404:
+		NotFoundError(c, e.message)
+	case e.status == 403:
+		AuthorizationError(c, e.message)
+	case e.status == 412:
+		PreconditionFailedError(c, e.message)
+	default:
+		ValidationError(c, e.message, e.field)
+	}
+}
+
+// writeRecipeRevision snapshots the recipe's current state before it is changed
+// The caller must hold a row lock on the recipe so revision numbers cannot collide
+func writeRecipeRevision(tx *sql.Tx, recipe models.RecipeWithIngredients, changedBy int) error {
+	snapshot, err := json.Marshal(recipe)
+	if err != nil {
+		return err
+	}
+
+	var changedByRef *int
+	if changedBy != 0 {
+		changedByRef = &changedBy
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO recipe_revisions (recipe_id, revision_number, snapshot, changed_by)
+		VALUES (
+			$1,
+			COALESCE((SELECT MAX(revision_number) FROM recipe_revisions WHERE recipe_id = $1), 0) + 1,
+			$2,
+			(SELECT id FROM users WHERE id = $3)
+		)
+	`, recipe.ID, snapshot, changedByRef)
+	return err
+}
+
+// notifyStatusChange tells status stream subscribers and webhooks a recipe moved into a new status
+func (h *RecipeHandler) notifyStatusChange(ctx context.Context, previousStatus string, recipe *models.Recipe) {
+	if previousStatus == recipe.Status {
+		return
+	}
+
+	event := models.RecipeStatusEvent{
+		RecipeID:       recipe.ID,
+		Title:          recipe.Title,
+		PreviousStatus: previousStatus,
+		Status:         recipe.Status,
+		UserID:         recipe.UserID,
+	}
+
+	h.statusBroker.Publish(event)
+
+	if h.webhooks != nil {
+		if name, ok := webhooks.EventForStatus(recipe.Status); ok {
+			h.webhooks.Dispatch(ctx, name, event)
+		}
+	}
+}
+
+// applyRecipeChange updates a recipe inside one transaction, recording a revision first
+// An empty input status keeps the current status
+func (h *RecipeHandler) applyRecipeChange(c *gin.Context, recipeID int, input models.RecipeInput) (*models.Recipe, *recipeChangeError) {
+	return h.applyRecipeChangeFrom(c, recipeID, func(models.Recipe) models.RecipeInput { return input })
+}
+
+// ifUnmodifiedSince returns the time in a request's If-Unmodified-Since header
+// A missing or unparseable date is ignored, as RFC 9110 requires, and nil is returned
+func ifUnmodifiedSince(c *gin.Context) *time.Time {
+	value := c.GetHeader("If-Unmodified-Since")
+	if value == "" {
+		return nil
+	}
+	since, err := http.ParseTime(value)
+	if err != nil {
+		return nil
+	}
+	return &since
+}
+
+// setLastModified sends a recipe's updated_at so clients can make their next change conditional on it
+func setLastModified(c *gin.Context, recipe models.Recipe) {
+	c.Header("Last-Modified", recipe.UpdatedAt.UTC().Format(http.TimeFormat))
+}
+
+// recipeIngredientChange edits individual ingredient rows as part of a recipe change, once the revision is recorded
+// It is given the ingredients the recipe had before the change; an error rolls the whole change back
+type recipeIngredientChange func(tx *sql.Tx, current []models.RecipeIngredient) *recipeChangeError
+
+// applyRecipeChangeFrom is applyRecipeChange with the input built from the locked current recipe,
+// so partial updates merge with the state they replace rather than a copy read earlier.
+// A request with If-Unmodified-Since only changes a recipe nobody has updated since then
+func (h *RecipeHandler) applyRecipeChangeFrom(c *gin.Context, recipeID int, buildInput func(current models.Recipe) models.RecipeInput) (*models.Recipe, *recipeChangeError) {
+	return h.applyRecipeChangeWith(c, recipeID, buildInput, nil)
+}
+
+// applyRecipeIngredientChange edits some of a recipe's ingredient rows and leaves the rest of the recipe as it is
+// It goes through the same change as a recipe update, so the edit is attributed, recorded as a revision,
+// bumps updated_at and honours If-Unmodified-Since
+func (h *RecipeHandler) applyRecipeIngredientChange(c *gin.Context, recipeID int, change recipeIngredientChange) (*models.Recipe, *recipeChangeError) {
+	return h.applyRecipeChangeWith(c, recipeID, models.RecipePatch{}.Apply, change)
+}
+
+// applyRecipeChangeWith runs a recipe change in a transaction, with an optional ingredient change applied after the update
+func (h *RecipeHandler) applyRecipeChangeWith(c *gin.Context, recipeID int, buildInput func(current models.Recipe) models.RecipeInput, changeIngredients recipeIngredientChange) (*models.Recipe, *recipeChangeError) {
+	ctx, cancel := context.WithTimeout(logctx.WithLogger(context.Background(), middleware.LogWithContext(c)), 30*time.Second)
+	defer cancel()
+
+	// Serialization failures and deadlocks with a concurrent change are retried from the locking read
+	var previousStatus string
+	var updated *models.Recipe
+	err := h.db.WithRetryTx(ctx, func(tx *sql.Tx) error {
+		status, recipe, changeErr := changeRecipeInTx(c, tx, recipeID, ifUnmodifiedSince(c), buildInput, changeIngredients)
+		if changeErr != nil {
+			return changeErr
+		}
+		previousStatus, updated = status, recipe
+		return nil
+	})
+	var changeErr *recipeChangeError
+	if errors.As(err, &changeErr) {
+		return nil, changeErr
+	}
+	if err != nil {
+		return nil, &recipeChangeError{dbErr: err}
+	}
+
+	h.notifyStatusChange(ctx, previousStatus, updated)
+
+	return updated, nil
+}
+
+// changeRecipeInTx locks a recipe, checks the caller may change it and applies the input after recording a revision
+// It returns the status the recipe had before the change so the caller can notify subscribers once committed.
+// Ownership, precondition, status and not-found failures happen before anything is written.
+// A non-nil unmodifiedSince rejects the change with 412 when the recipe was updated after it; HTTP dates
+// have whole-second precision, so updated_at is compared truncated to the second.
+// A non-nil changeIngredients runs after the recipe and any replacement ingredient list are written
+func changeRecipeInTx(c *gin.Context, tx *sql.Tx, recipeID int, unmodifiedSince *time.Time, buildInput func(current models.Recipe) models.RecipeInput, changeIngredients recipeIngredientChange) (string, *models.Recipe, *recipeChangeError) {
+	current, err := queryRecipe(tx, recipeID, true)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", nil, &recipeChangeError{status: 404, message: "recipe not found"}
+		}
+		return "", nil, &recipeChangeError{dbErr: err}
+	}
+
+	if !canModifyRecipe(c, current.UserID) {
+		return "", nil, &recipeChangeError{status: 403, message: "You do not have permission to modify this recipe"}
+	}
+
+	if unmodifiedSince != nil && current.UpdatedAt.Truncate(time.Second).After(*unmodifiedSince) {
+		return "", nil, &recipeChangeError{status: 412, message: "recipe was modified after If-Unmodified-Since; reload it and try again"}
+	}
+
+	input := buildInput(current)
+	status := input.Status
+	if status == "" {
+		status = current.Status
+	}
+	if !isValidStatusTransition(current.Status, status) {
+		return "", nil, &recipeChangeError{
+			status:  400,
+			field:   "status",
+			message: fmt.Sprintf("cannot change status from %s to %s", current.Status, status),
+		}
+	}
+
+	currentIngredients, err := queryRecipeIngredients(tx, recipeID)
+	if err != nil {
+		return "", nil, &recipeChangeError{dbErr: err}
+	}
+
+	snapshot := models.RecipeWithIngredients{Recipe: current, Ingredients: currentIngredients}
+	if err := writeRecipeRevision(tx, snapshot, middleware.GetUserID(c)); err != nil {
+		return "", nil, &recipeChangeError{dbErr: err}
+	}
+
+	// The editor is recorded rather than the owner, so reviewers' and admins' changes are attributed to them
+	var modifiedBy *int
+	if userID := middleware.GetUserID(c); userID != 0 {
+		modifiedBy = &userID
+	}
+
+	visibility := input.Visibility
+	if visibility == "" {
+		visibility = current.Visibility
+	}
+
+	var updated models.Recipe
+	err = tx.QueryRow(`
+		UPDATE recipes
+		SET title = $1, servings = $2, instructions = $3, tips = $4, status = $5,
+			prep_time_minutes = $6, cook_time_minutes = $7, difficulty = $8,
+			source_name = NULLIF(TRIM($9), ''), source_url = NULLIF(TRIM($10), ''),
+			last_modified_by = (SELECT id FROM users WHERE id = $12), visibility = $13
+		WHERE id = $11
+		RETURNING `+recipeColumns+`
+	`, strings.TrimSpace(input.Title), input.Servings, input.Instructions, input.Tips, status,
+		input.PrepTimeMinutes, input.CookTimeMinutes, input.Difficulty,
+		input.SourceName, input.SourceURL, recipeID, modifiedBy, visibility).Scan(recipeScanTargets(&updated)...)
+	if err != nil {
+		return "", nil, &recipeChangeError{dbErr: err}
+	}
+
+	if input.Ingredients != nil {
+		if _, err := tx.Exec(`DELETE FROM recipe_ingredients WHERE recipe_id = $1`, recipeID); err != nil {
+			return "", nil, &recipeChangeError{dbErr: err}
+		}
+		if _, err := insertRecipeIngredients(tx, recipeID, *input.Ingredients); err != nil {
+			return "", nil, &recipeChangeError{dbErr: err}
+		}
+	}
+
+	if changeIngredients != nil {
+		if changeErr := changeIngredients(tx, currentIngredients); changeErr != nil {
+			return "", nil, changeErr
+		}
+	}
+
+	return current.Status, &updated, nil
+}
+
+// UpdateRecipe handles PUT /recipes/:id requests
+func (h *RecipeHandler) UpdateRecipe(c *gin.Context) {
+	logger := middleware.LogWithContext(c)
+
+	recipeID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		BadRequestError(c, "invalid recipe ID")
+		return
+	}
+
+	var input models.RecipeInput
+	bindErr := c.ShouldBindJSON(&input)
+	if bindErr != nil {
+		logger.WithError(bindErr).Warn("Update recipe binding failed")
+		if isBodyTooLarge(bindErr) {
+			RequestTooLargeError(c, "Request body too large")
+			return
+		}
+	}
+
+	// Every invalid field is reported together so clients can show all problems at once
+	fieldErrs, ok := recipeFieldErrors(input, bindErr, append(input.Validate(), h.inputIngredientLimitErrors(input.Ingredients)...))
+	if !ok {
+		ValidationError(c, "Invalid request format. Check title, status, ingredients, time, difficulty and source fields.")
+		return
+	}
+	if len(fieldErrs) > 0 {
+		FieldValidationErrors(c, fieldErrs)
+		return
+	}
+
+	if middleware.GetUserID(c) == 0 {
+		AuthenticationError(c, "Authentication required to edit recipes")
+		return
+	}
+
+	if !h.checkCanonicalLinks(c, "ingredients", input.Ingredients) {
+		return
+	}
+
+	updated, changeErr := h.applyRecipeChange(c, recipeID, input)
+	if changeErr != nil {
+		changeErr.respond(c, "update recipe")
+		return
+	}
+
+	logger.WithFields(logrus.Fields{
+		"recipe_id": recipeID,
+		"status":    updated.Status,
+	}).Info("Recipe updated")
+
+	h.respondWithRecipe(c, recipeID)
+}
+
+// PatchRecipe handles PATCH /recipes/:id requests
+// Only fields present in the body change; ingredients are replaced only when an ingredients array is sent
+func (h *RecipeHandler) PatchRecipe(c *gin.Context) {
+	logger := middleware.LogWithContext(c)
+
+	recipeID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		BadRequestError(c, "invalid recipe ID")
+		return
+	}
+
+	var patch models.RecipePatch
+	bindErr := c.ShouldBindJSON(&patch)
+	if bindErr != nil {
+		logger.WithError(bindErr).Warn("Patch recipe binding failed")
+		if isBodyTooLarge(bindErr) {
+			RequestTooLargeError(c, "Request body too large")
+			return
+		}
+	}
+
+	fieldErrs, ok := recipeFieldErrors(patch, bindErr, append(patch.Validate(), h.inputIngredientLimitErrors(patch.Ingredients)...))
+	if !ok {
+		ValidationError(c, "Invalid request format. Check title, status, ingredients, time, difficulty and source fields.")
+		return
+	}
+	if len(fieldErrs) > 0 {
+		FieldValidationErrors(c, fieldErrs)
+		return
+	}
+
+	if patch.IsEmpty() {
+		ValidationError(c, "at least one field must be provided")
+		return
+	}
+
+	if middleware.GetUserID(c) == 0 {
+		AuthenticationError(c, "Authentication required to edit recipes")
+		return
+	}
+
+	if !h.checkCanonicalLinks(c, "ingredients", patch.Ingredients) {
+		return
+	}
+
+	updated, changeErr := h.applyRecipeChangeFrom(c, recipeID, patch.Apply)
+	if changeErr != nil {
+		changeErr.respond(c, "patch recipe")
+		return
+	}
+
+	logger.WithFields(logrus.Fields{
+		"recipe_id": recipeID,
+		"status":    updated.Status,
+	}).Info("Recipe patched")
+
+	h.respondWithRecipe(c, recipeID)
+}
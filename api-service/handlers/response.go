@@ -1,8 +1,14 @@
 package handlers
 
 import (
+	"fmt"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 
+	"digital-recipes/api-service/middleware"
 	"github.com/gin-gonic/gin"
 )
 
@@ -23,32 +29,97 @@ type Pagination struct {
 }
 
 // Meta contains additional response metadata
+// Warnings lists non-fatal issues with what was saved, such as ingredients that need review
 type Meta struct {
-	RequestID string `json:"request_id,omitempty"`
-	Timestamp string `json:"timestamp,omitempty"`
+	RequestID string   `json:"request_id,omitempty"`
+	Timestamp string   `json:"timestamp,omitempty"`
+	Warnings  []string `json:"warnings,omitempty"`
+}
+
+// newMeta builds response metadata from the request context set by RequestIDMiddleware
+func newMeta(c *gin.Context) *Meta {
+	return &Meta{
+		RequestID: middleware.GetRequestID(c),
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+	}
 }
 
 // SuccessResponse sends a standardized success response
 func SuccessResponse(c *gin.Context, data interface{}) {
 	response := StandardResponse{
 		Data: data,
+		Meta: newMeta(c),
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// SuccessResponseWithWarnings sends a standardized success response listing non-fatal issues in the metadata
+func SuccessResponseWithWarnings(c *gin.Context, data interface{}, warnings []string) {
+	meta := newMeta(c)
+	meta.Warnings = warnings
+	response := StandardResponse{
+		Data: data,
+		Meta: meta,
 	}
 	c.JSON(http.StatusOK, response)
 }
 
 // SuccessResponseWithPagination sends a standardized success response with pagination
+// The total is repeated in an X-Total-Count header for clients that read it from there
 func SuccessResponseWithPagination(c *gin.Context, data interface{}, pagination *Pagination) {
+	setPaginationLinks(c, pagination)
+	if pagination != nil {
+		c.Header("X-Total-Count", strconv.Itoa(pagination.Total))
+	}
+
 	response := StandardResponse{
 		Data:       data,
 		Pagination: pagination,
+		Meta:       newMeta(c),
 	}
 	c.JSON(http.StatusOK, response)
 }
 
+// setPaginationLinks adds RFC 5988 Link headers (first, prev, next, last) built from the request URL
+// prev and next are omitted at the boundaries so clients can stop iterating on their absence
+func setPaginationLinks(c *gin.Context, pagination *Pagination) {
+	if pagination == nil || c.Request == nil || c.Request.URL == nil {
+		return
+	}
+
+	lastPage := pagination.TotalPages
+	if lastPage < 1 {
+		lastPage = 1
+	}
+
+	pageURL := func(page int) string {
+		query := c.Request.URL.Query()
+		query.Set("page", strconv.Itoa(page))
+		query.Set("per_page", strconv.Itoa(pagination.PerPage))
+		return (&url.URL{Path: c.Request.URL.Path, RawQuery: query.Encode()}).String()
+	}
+
+	links := []string{fmt.Sprintf(`<%s>; rel="first"`, pageURL(1))}
+	if pagination.Page > 1 {
+		prevPage := pagination.Page - 1
+		if prevPage > lastPage {
+			prevPage = lastPage
+		}
+		links = append(links, fmt.Sprintf(`<%s>; rel="prev"`, pageURL(prevPage)))
+	}
+	if pagination.Page < lastPage {
+		links = append(links, fmt.Sprintf(`<%s>; rel="next"`, pageURL(pagination.Page+1)))
+	}
+	links = append(links, fmt.Sprintf(`<%s>; rel="last"`, pageURL(lastPage)))
+
+	c.Header("Link", strings.Join(links, ", "))
+}
+
 // ErrorResponse sends a standardized error response
 func ErrorResponse(c *gin.Context, statusCode int, message string) {
 	response := StandardResponse{
 		Error: &message,
+		Meta:  newMeta(c),
 	}
 	c.JSON(statusCode, response)
 }
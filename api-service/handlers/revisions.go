@@ -0,0 +1,194 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"digital-recipes/api-service/middleware"
+	"digital-recipes/api-service/models"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// scanRecipeRevision scans a recipe_revisions row and decodes its snapshot
+func scanRecipeRevision(scan func(dest ...interface{}) error) (models.RecipeRevision, error) {
+	var revision models.RecipeRevision
+	var snapshot []byte
+	if err := scan(
+		&revision.ID,
+		&revision.RecipeID,
+		&revision.RevisionNumber,
+		&snapshot,
+		&revision.ChangedBy,
+		&revision.CreatedAt,
+	); err != nil {
+		return revision, err
+	}
+	err := json.Unmarshal(snapshot, &revision.Snapshot)
+	return revision, err
+}
+
+// authorizeRevisionAccess loads the recipe owner and checks the caller may see its history
+// It writes the error response itself and returns false when the caller should stop
+func (h *RecipeHandler) authorizeRevisionAccess(c *gin.Context, recipeID int) bool {
+	if middleware.GetUserID(c) == 0 {
+		AuthenticationError(c, "Authentication required to view recipe history")
+		return false
+	}
+
+	var ownerID int
+	err := h.db.DB.QueryRow(`SELECT user_id FROM recipes WHERE id = $1`, recipeID).Scan(&ownerID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			NotFoundError(c, "recipe not found")
+			return false
+		}
+		DatabaseError(c, err, "load recipe owner")
+		return false
+	}
+
+	if !canModifyRecipe(c, ownerID) {
+		AuthorizationError(c, "You do not have permission to view this recipe's history")
+		return false
+	}
+	return true
+}
+
+// GetRecipeRevisions handles GET /recipes/:id/revisions requests, newest first
+func (h *RecipeHandler) GetRecipeRevisions(c *gin.Context) {
+	recipeID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		BadRequestError(c, "invalid recipe ID")
+		return
+	}
+
+	page, perPage, paginationErr := parsePagination(c, h.pagination)
+	if paginationErr != nil {
+		SafeErrorResponse(c, *paginationErr, http.StatusBadRequest)
+		return
+	}
+
+	if !h.authorizeRevisionAccess(c, recipeID) {
+		return
+	}
+
+	rows, err := h.db.DB.Query(`
+		SELECT id, recipe_id, revision_number, snapshot, changed_by, created_at,
+			COUNT(*) OVER() as total_count
+		FROM recipe_revisions
+		WHERE recipe_id = $1
+		ORDER BY revision_number DESC
+		LIMIT $2 OFFSET $3
+	`, recipeID, perPage, (page-1)*perPage)
+	if err != nil {
+		DatabaseError(c, err, "list recipe revisions")
+		return
+	}
+	defer rows.Close()
+
+	revisions := []models.RecipeRevision{}
+	var total int
+	for rows.Next() {
+		revision, err := scanRecipeRevision(func(dest ...interface{}) error {
+			return rows.Scan(append(dest, &total)...)
+		})
+		if err != nil {
+			DatabaseError(c, err, "read recipe revision")
+			return
+		}
+		revisions = append(revisions, revision)
+	}
+	if err := rows.Err(); err != nil {
+		DatabaseError(c, err, "read recipe revisions")
+		return
+	}
+
+	SuccessResponseWithPagination(c, revisions, &Pagination{
+		Page:       page,
+		PerPage:    perPage,
+		Total:      total,
+		TotalPages: (total + perPage - 1) / perPage,
+	})
+}
+
+// RestoreRecipeRevision handles POST /recipes/:id/revisions/:revisionId/restore requests
+// Restoring is itself an update, so the state it replaces is kept as a new revision.
+// The current status is kept because restoring content must not bypass the review workflow.
+func (h *RecipeHandler) RestoreRecipeRevision(c *gin.Context) {
+	logger := middleware.LogWithContext(c)
+
+	recipeID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		BadRequestError(c, "invalid recipe ID")
+		return
+	}
+
+	revisionID, err := strconv.Atoi(c.Param("revisionId"))
+	if err != nil {
+		BadRequestError(c, "invalid revision ID")
+		return
+	}
+
+	if !h.authorizeRevisionAccess(c, recipeID) {
+		return
+	}
+
+	revision, err := scanRecipeRevision(h.db.DB.QueryRow(`
+		SELECT id, recipe_id, revision_number, snapshot, changed_by, created_at
+		FROM recipe_revisions
+		WHERE id = $1 AND recipe_id = $2
+	`, revisionID, recipeID).Scan)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			NotFoundError(c, "revision not found")
+			return
+		}
+		DatabaseError(c, err, "load recipe revision")
+		return
+	}
+
+	snapshot := revision.Snapshot
+	ingredients := make([]models.IngredientInput, 0, len(snapshot.Ingredients))
+	for _, ingredient := range snapshot.Ingredients {
+		ingredients = append(ingredients, models.IngredientInput{
+			OriginalText:          ingredient.OriginalText,
+			Quantity:              ingredient.Quantity,
+			Unit:                  ingredient.Unit,
+			CanonicalIngredientID: ingredient.CanonicalIngredientID,
+		})
+	}
+	// A revision saved under a higher cap must not bring back more ingredients than the recipe may now have
+	if errs := h.ingredientLimitErrors("ingredients", len(ingredients)); len(errs) > 0 {
+		FieldValidationErrors(c, errs)
+		return
+	}
+
+	_, changeErr := h.applyRecipeChange(c, recipeID, models.RecipeInput{
+		Title:        snapshot.Title,
+		Servings:     snapshot.Servings,
+		Instructions: snapshot.Instructions,
+		Tips:         snapshot.Tips,
+		Ingredients:  &ingredients,
+
+		PrepTimeMinutes: snapshot.PrepTimeMinutes,
+		CookTimeMinutes: snapshot.CookTimeMinutes,
+		Difficulty:      snapshot.Difficulty,
+
+		SourceName: snapshot.SourceName,
+		SourceURL:  snapshot.SourceURL,
+	})
+	if changeErr != nil {
+		changeErr.respond(c, "restore recipe revision")
+		return
+	}
+
+	logger.WithFields(logrus.Fields{
+		"recipe_id":       recipeID,
+		"revision_id":     revisionID,
+		"revision_number": revision.RevisionNumber,
+	}).Info("Recipe revision restored")
+
+	h.respondWithRecipe(c, recipeID)
+}
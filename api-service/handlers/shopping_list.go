@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"digital-recipes/api-service/ingredients"
+	"digital-recipes/api-service/middleware"
+	"digital-recipes/api-service/models"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// shoppingListBuilder merges recipe ingredients into shopping list items in first-seen order
+type shoppingListBuilder struct {
+	items []models.ShoppingListItem
+	index map[string]int
+}
+
+// newShoppingListBuilder creates an empty shopping list builder
+func newShoppingListBuilder() *shoppingListBuilder {
+	return &shoppingListBuilder{index: make(map[string]int)}
+}
+
+// add merges one ingredient, scaled by the recipe's servings factor
+// Ingredients are grouped by canonical ingredient, falling back to their normalized name,
+// and quantities are summed only when units are identical or convertible
+func (b *shoppingListBuilder) add(recipeID int, ingredient models.RecipeIngredient, scale float64) {
+	var groupKey, name string
+	if ingredient.CanonicalIngredientID != nil && ingredient.CanonicalName != nil {
+		groupKey = "canonical:" + strconv.Itoa(*ingredient.CanonicalIngredientID)
+		name = *ingredient.CanonicalName
+	} else {
+		name = ingredients.NormalizeName(ingredient.OriginalText)
+		if name == "" {
+			name = strings.TrimSpace(ingredient.OriginalText)
+		}
+		groupKey = "name:" + name
+	}
+
+	unit := ""
+	if ingredient.Unit != nil {
+		unit = *ingredient.Unit
+	}
+
+	// Compatible units share a bucket so "1 cup" and "4 tbsp" combine into one line
+	bucket := "unit:" + unit
+	if dimension, ok := ingredients.UnitDimension(unit); ok {
+		bucket = "dimension:" + string(dimension)
+	}
+	if ingredient.Quantity == nil {
+		bucket = "unquantified:" + unit
+	}
+	key := groupKey + "|" + bucket
+
+	i, exists := b.index[key]
+	if !exists {
+		item := models.ShoppingListItem{
+			Name:                  name,
+			CanonicalIngredientID: ingredient.CanonicalIngredientID,
+			RecipeIDs:             []int{},
+			OriginalText:          []string{},
+		}
+		if unit != "" {
+			item.Unit = &unit
+		}
+		if ingredient.Quantity != nil {
+			zero := 0.0
+			item.Quantity = &zero
+		}
+		b.items = append(b.items, item)
+		i = len(b.items) - 1
+		b.index[key] = i
+	}
+
+	item := &b.items[i]
+	if ingredient.Quantity != nil {
+		amount := *ingredient.Quantity * scale
+		if item.Unit != nil {
+			// Convert into the unit the item was first listed with
+			amount, _ = ingredients.ConvertQuantity(amount, unit, *item.Unit)
+		}
+		total := math.Round((*item.Quantity+amount)*1000) / 1000
+		item.Quantity = &total
+	}
+
+	item.OriginalText = append(item.OriginalText, ingredient.OriginalText)
+	if len(item.RecipeIDs) == 0 || item.RecipeIDs[len(item.RecipeIDs)-1] != recipeID {
+		item.RecipeIDs = append(item.RecipeIDs, recipeID)
+	}
+}
+
+// servingsScale returns the factor that scales a recipe to the requested servings
+func servingsScale(recipe models.Recipe, override *float64) (float64, error) {
+	if override == nil {
+		return 1, nil
+	}
+	if recipe.ServingsCount != nil {
+		return *override / *recipe.ServingsCount, nil
+	}
+	return 0, fmt.Errorf("recipe %d has no numeric servings to scale from", recipe.ID)
+}
+
+// CreateShoppingList handles POST /shopping-list requests
+func (h *RecipeHandler) CreateShoppingList(c *gin.Context) {
+	logger := middleware.LogWithContext(c)
+
+	var request models.ShoppingListRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		logger.WithError(err).Warn("Shopping list binding failed")
+		if isBodyTooLarge(err) {
+			RequestTooLargeError(c, "Request body too large")
+			return
+		}
+		ValidationError(c, fmt.Sprintf("Invalid request format. Provide between 1 and %d recipes.", models.MaxShoppingListRecipes), "recipes")
+		return
+	}
+
+	builder := newShoppingListBuilder()
+	recipeIDs := make([]int, 0, len(request.Recipes))
+
+	for _, selection := range request.Recipes {
+		recipe, err := h.loadRecipe(c.Request.Context(), selection.RecipeID)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				NotFoundError(c, fmt.Sprintf("recipe %d not found", selection.RecipeID))
+				return
+			}
+			DatabaseError(c, err, "load recipe for shopping list")
+			return
+		}
+
+		// Drafts and private recipes are only usable by their owner, and are reported as missing to everyone else
+		if (recipe.Status != "published" || recipe.Visibility == models.VisibilityPrivate) && !canModifyRecipe(c, recipe.UserID) {
+			NotFoundError(c, fmt.Sprintf("recipe %d not found", selection.RecipeID))
+			return
+		}
+
+		scale, err := servingsScale(recipe, selection.Servings)
+		if err != nil {
+			ValidationError(c, err.Error(), "servings")
+			return
+		}
+
+		recipeIngredients, err := h.loadRecipeIngredients(c.Request.Context(), recipe.ID)
+		if err != nil {
+			DatabaseError(c, err, "load ingredients for shopping list")
+			return
+		}
+
+		for _, ingredient := range recipeIngredients {
+			builder.add(recipe.ID, ingredient, scale)
+		}
+		recipeIDs = append(recipeIDs, recipe.ID)
+	}
+
+	items := builder.items
+	if items == nil {
+		items = []models.ShoppingListItem{}
+	}
+
+	logger.WithFields(logrus.Fields{
+		"recipe_count": len(recipeIDs),
+		"item_count":   len(items),
+	}).Info("Shopping list generated")
+
+	SuccessResponse(c, models.ShoppingList{
+		RecipeIDs: recipeIDs,
+		Items:     items,
+	})
+}
@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"digital-recipes/api-service/db"
+	"digital-recipes/api-service/logctx"
+	"digital-recipes/api-service/middleware"
+	"digital-recipes/api-service/models"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultStatsCacheSeconds is how long dashboard figures are reused when STATS_CACHE_SECONDS is not set
+const DefaultStatsCacheSeconds = 60
+
+// GetStatsCacheTTL returns how long /stats reuses computed figures, from STATS_CACHE_SECONDS
+// Zero is allowed and recomputes the figures on every request
+func GetStatsCacheTTL() time.Duration {
+	value := os.Getenv("STATS_CACHE_SECONDS")
+	if value == "" {
+		return DefaultStatsCacheSeconds * time.Second
+	}
+
+	seconds, err := strconv.Atoi(value)
+	if err != nil || seconds < 0 {
+		logrus.WithField("STATS_CACHE_SECONDS", value).Warn("Invalid STATS_CACHE_SECONDS, using default")
+		return DefaultStatsCacheSeconds * time.Second
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// StatsHandler reports aggregate figures for the admin dashboard
+type StatsHandler struct {
+	db       *db.Database
+	cacheTTL time.Duration
+
+	mu     sync.Mutex
+	cached *models.Stats
+}
+
+// NewStatsHandler creates a new stats handler
+func NewStatsHandler(database *db.Database) *StatsHandler {
+	return &StatsHandler{
+		db:       database,
+		cacheTTL: DefaultStatsCacheSeconds * time.Second,
+	}
+}
+
+// SetCacheTTL sets how long computed figures are reused; zero recomputes them on every request
+func (h *StatsHandler) SetCacheTTL(ttl time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.cacheTTL = ttl
+	h.cached = nil
+}
+
+// stats returns the cached figures, computing them again once they are older than the cache TTL
+// Concurrent requests after expiry wait for a single computation; failures are not cached
+func (h *StatsHandler) stats(logger *logrus.Entry) (models.Stats, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.cached != nil && time.Since(h.cached.GeneratedAt) < h.cacheTTL {
+		return *h.cached, nil
+	}
+
+	// The request context is not used because the result is shared with other requests
+	ctx, cancel := context.WithTimeout(logctx.WithLogger(context.Background(), logger), 10*time.Second)
+	defer cancel()
+
+	stats, err := h.computeStats(ctx)
+	if err != nil {
+		return models.Stats{}, err
+	}
+	h.cached = &stats
+	return stats, nil
+}
+
+// computeStats counts recipes grouped by status, then users and canonical ingredients
+func (h *StatsHandler) computeStats(ctx context.Context) (models.Stats, error) {
+	var stats models.Stats
+
+	rows, err := h.db.QueryContextLogged(ctx, `
+		SELECT status, COUNT(*),
+			COUNT(*) FILTER (WHERE created_at >= NOW() - INTERVAL '7 days'),
+			COUNT(*) FILTER (WHERE created_at >= NOW() - INTERVAL '30 days')
+		FROM recipes
+		GROUP BY status
+	`)
+	if err != nil {
+		return stats, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var status *string
+		var count, last7Days, last30Days int
+		if err := rows.Scan(&status, &count, &last7Days, &last30Days); err != nil {
+			return stats, err
+		}
+		stats.Recipes.Total += count
+		stats.Recipes.CreatedLast7Days += last7Days
+		stats.Recipes.CreatedLast30Days += last30Days
+		if status == nil {
+			continue
+		}
+		switch *status {
+		case "processing":
+			stats.Recipes.ByStatus.Processing = count
+		case "review_required":
+			stats.Recipes.ByStatus.ReviewRequired = count
+		case "published":
+			stats.Recipes.ByStatus.Published = count
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return stats, err
+	}
+
+	err = h.db.QueryRowContextLogged(ctx, `
+		SELECT (SELECT COUNT(*) FROM users),
+			COUNT(*),
+			COUNT(*) FILTER (WHERE is_approved)
+		FROM canonical_ingredients
+	`).Scan(&stats.Users, &stats.CanonicalIngredients.Total, &stats.CanonicalIngredients.Approved)
+	if err != nil {
+		return stats, err
+	}
+	stats.CanonicalIngredients.Pending = stats.CanonicalIngredients.Total - stats.CanonicalIngredients.Approved
+
+	stats.GeneratedAt = time.Now().UTC()
+	return stats, nil
+}
+
+// GetStats handles GET /stats requests from admins
+// The figures come from grouped counts over whole tables, so they are cached for STATS_CACHE_SECONDS
+func (h *StatsHandler) GetStats(c *gin.Context) {
+	stats, err := h.stats(middleware.LogWithContext(c))
+	if err != nil {
+		DatabaseError(c, err, "compute stats")
+		return
+	}
+	SuccessResponse(c, stats)
+}
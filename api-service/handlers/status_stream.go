@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"digital-recipes/api-service/middleware"
+	"digital-recipes/api-service/models"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// statusSubscriberBuffer is how many events a slow subscriber may fall behind before events are dropped
+const statusSubscriberBuffer = 8
+
+// statusStreamHeartbeat keeps idle connections open through proxies
+const statusStreamHeartbeat = 15 * time.Second
+
+// StatusBroker is an in-process pub/sub of recipe status changes keyed by recipe ID
+type StatusBroker struct {
+	mu          sync.Mutex
+	subscribers map[int]map[chan models.RecipeStatusEvent]struct{}
+}
+
+// NewStatusBroker creates an empty status broker
+func NewStatusBroker() *StatusBroker {
+	return &StatusBroker{subscribers: make(map[int]map[chan models.RecipeStatusEvent]struct{})}
+}
+
+// Subscribe registers for status changes of one recipe
+// The returned function must be called to release the subscription
+func (b *StatusBroker) Subscribe(recipeID int) (<-chan models.RecipeStatusEvent, func()) {
+	ch := make(chan models.RecipeStatusEvent, statusSubscriberBuffer)
+
+	b.mu.Lock()
+	if b.subscribers[recipeID] == nil {
+		b.subscribers[recipeID] = make(map[chan models.RecipeStatusEvent]struct{})
+	}
+	b.subscribers[recipeID][ch] = struct{}{}
+	b.mu.Unlock()
+
+	return ch, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		delete(b.subscribers[recipeID], ch)
+		if len(b.subscribers[recipeID]) == 0 {
+			delete(b.subscribers, recipeID)
+		}
+	}
+}
+
+// Publish sends an event to every subscriber of its recipe without blocking
+func (b *StatusBroker) Publish(event models.RecipeStatusEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for ch := range b.subscribers[event.RecipeID] {
+		select {
+		case ch <- event:
+		default:
+			logrus.WithField("recipe_id", event.RecipeID).Warn("Dropping status event for slow subscriber")
+		}
+	}
+}
+
+// SubscriberCount returns the number of open subscriptions for a recipe
+func (b *StatusBroker) SubscriberCount(recipeID int) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.subscribers[recipeID])
+}
+
+// StatusBroker returns the broker status changes are published to
+func (h *RecipeHandler) StatusBroker() *StatusBroker {
+	return h.statusBroker
+}
+
+// writeStatusEvent writes one SSE "status" event and flushes it to the client
+func writeStatusEvent(c *gin.Context, event models.RecipeStatusEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(c.Writer, "event: status\ndata: %s\n\n", data); err != nil {
+		return err
+	}
+	c.Writer.Flush()
+	return nil
+}
+
+// StreamRecipeStatus handles GET /recipes/:id/status/stream requests
+// The current status is sent immediately, followed by an event for every change until the client disconnects
+func (h *RecipeHandler) StreamRecipeStatus(c *gin.Context) {
+	logger := middleware.LogWithContext(c)
+
+	recipeID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		BadRequestError(c, "invalid recipe ID")
+		return
+	}
+
+	userID := middleware.GetUserID(c)
+	if userID == 0 {
+		AuthenticationError(c, "Authentication required to follow recipe status")
+		return
+	}
+
+	// Subscribe before reading the current status so no change can slip in between
+	events, unsubscribe := h.statusBroker.Subscribe(recipeID)
+	defer unsubscribe()
+
+	recipe, err := h.loadRecipe(c.Request.Context(), recipeID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			NotFoundError(c, "recipe not found")
+			return
+		}
+		DatabaseError(c, err, "load recipe")
+		return
+	}
+
+	if recipe.UserID != userID {
+		AuthorizationError(c, "Only the recipe owner can follow its status")
+		return
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(200)
+
+	current := models.RecipeStatusEvent{
+		RecipeID:       recipe.ID,
+		Title:          recipe.Title,
+		PreviousStatus: recipe.Status,
+		Status:         recipe.Status,
+		UserID:         recipe.UserID,
+	}
+	if err := writeStatusEvent(c, current); err != nil {
+		return
+	}
+
+	logger.WithField("recipe_id", recipeID).Debug("Status stream opened")
+
+	heartbeat := time.NewTicker(statusStreamHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			logger.WithField("recipe_id", recipeID).Debug("Status stream closed by client")
+			return
+		case event := <-events:
+			if err := writeStatusEvent(c, event); err != nil {
+				return
+			}
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(c.Writer, ": keep-alive\n\n"); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		}
+	}
+}
@@ -2,17 +2,26 @@ package handlers
 
 import (
 	"context"
+	"database/sql"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"io"
 	"net"
 	"os"
 	"regexp"
+	"slices"
+	"strings"
+	"sync"
 	"time"
 
 	"cloud.google.com/go/storage"
 	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
+	"google.golang.org/api/iterator"
 	"google.golang.org/api/option"
 
+	"digital-recipes/api-service/logctx"
 	"digital-recipes/api-service/models"
 )
 
@@ -42,41 +51,128 @@ func sanitizeImageID(imageID string) string {
 	return sanitized
 }
 
-func validateContentType(contentType string) bool {
-	allowedTypes := []string{
-		"image/jpeg",
-		"image/png", 
-		"image/webp",
-	}
-	
-	for _, allowed := range allowedTypes {
-		if contentType == allowed {
-			return true
-		}
-	}
-	return false
+// imageFileType is what the storage service knows about one supported image content type
+// extensions[0] names new objects of the type; every listed extension is accepted by filename validation
+type imageFileType struct {
+	extensions []string
+	signatures []string
 }
 
-// validateFileSignature validates file content based on magic numbers/file signatures
-// This prevents file upload attacks where malicious files have image extensions
-func validateFileSignature(filename, contentType string) error {
-	// Define expected file signatures (magic numbers) for image types
-	validSignatures := map[string][]string{
-		"image/jpeg": {
+// imageFileTypes is the single table of supported upload content types, keyed by canonical content type
+// Upload URL object keys and filename validation both read it, so the two cannot disagree
+var imageFileTypes = map[string]*imageFileType{
+	"image/jpeg": {
+		extensions: []string{".jpg", ".jpeg"},
+		signatures: []string{
 			"\xFF\xD8\xFF",        // JPEG/JFIF
 			"\xFF\xD8\xFF\xE0",    // JPEG/JFIF
 			"\xFF\xD8\xFF\xE1",    // JPEG/EXIF
 		},
-		"image/png": {
-			"\x89PNG\r\n\x1a\n",   // PNG signature
-		},
-		"image/webp": {
-			"RIFF",                // WebP starts with RIFF
-		},
+	},
+	"image/png": {
+		extensions: []string{".png"},
+		signatures: []string{"\x89PNG\r\n\x1a\n"}, // PNG signature
+	},
+	"image/webp": {
+		extensions: []string{".webp"},
+		signatures: []string{"RIFF"}, // WebP starts with RIFF
+	},
+}
+
+// SupportedImageTypes returns the canonical content types uploads may use, sorted
+func SupportedImageTypes() []string {
+	types := make([]string, 0, len(imageFileTypes))
+	for contentType := range imageFileTypes {
+		types = append(types, contentType)
 	}
-	
+	slices.Sort(types)
+	return types
+}
+
+// extraImageExtensions are file extensions accepted on top of each content type's built-in ones, keyed by
+// canonical content type; imageExtensionsMu guards it so it can be configured while uploads are validated
+var (
+	imageExtensionsMu    sync.RWMutex
+	extraImageExtensions map[string][]string
+)
+
+// ImageExtensions returns the file extensions accepted for a content type, the one used for new objects first
+func ImageExtensions(contentType string) []string {
+	contentType = models.CanonicalizeContentType(contentType)
+	fileType, ok := imageFileTypes[contentType]
+	if !ok {
+		return nil
+	}
+
+	imageExtensionsMu.RLock()
+	defer imageExtensionsMu.RUnlock()
+	return append(slices.Clone(fileType.extensions), extraImageExtensions[contentType]...)
+}
+
+// SetExtraImageExtensions replaces the file extensions accepted on top of each content type's built-in ones,
+// such as ".jpe" for image/jpeg; nil leaves only the built-in extensions.
+// The extension used for new objects is unchanged, and nothing changes when an entry is invalid
+func SetExtraImageExtensions(extra map[string][]string) error {
+	normalized := make(map[string][]string, len(extra))
+	for contentType, extensions := range extra {
+		canonical := models.CanonicalizeContentType(contentType)
+		fileType, ok := imageFileTypes[canonical]
+		if !ok {
+			return fmt.Errorf("unsupported content type: %s", contentType)
+		}
+
+		for _, extension := range extensions {
+			extension = strings.ToLower(strings.TrimSpace(extension))
+			if !strings.HasPrefix(extension, ".") {
+				extension = "." + extension
+			}
+			if len(extension) < 2 || strings.ContainsAny(extension[1:], "./\\") {
+				return fmt.Errorf("invalid file extension for %s: %q", contentType, extension)
+			}
+			if !slices.Contains(fileType.extensions, extension) && !slices.Contains(normalized[canonical], extension) {
+				normalized[canonical] = append(normalized[canonical], extension)
+			}
+		}
+	}
+
+	imageExtensionsMu.Lock()
+	defer imageExtensionsMu.Unlock()
+	extraImageExtensions = normalized
+	return nil
+}
+
+// GetExtraImageExtensions reads EXTRA_IMAGE_EXTENSIONS, a comma-separated list of content type and extension
+// pairs such as "image/jpeg:.jpe,image/jpeg:.jfif", for SetExtraImageExtensions
+func GetExtraImageExtensions() (map[string][]string, error) {
+	extra := map[string][]string{}
+	for _, entry := range strings.Split(os.Getenv("EXTRA_IMAGE_EXTENSIONS"), ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		contentType, extension, found := strings.Cut(entry, ":")
+		if !found {
+			return nil, fmt.Errorf("EXTRA_IMAGE_EXTENSIONS entry %q must be content-type:extension", entry)
+		}
+		contentType = strings.TrimSpace(contentType)
+		extra[contentType] = append(extra[contentType], extension)
+	}
+	return extra, nil
+}
+
+func validateContentType(contentType string) bool {
+	_, ok := imageFileTypes[contentType]
+	return ok
+}
+
+// ValidateFileSignature validates an uploaded filename against the content type it was sent as
+// This prevents file upload attacks where malicious files have image extensions
+func ValidateFileSignature(filename, contentType string) error {
+	contentType = models.CanonicalizeContentType(contentType)
+
 	// Validate content type is supported
-	if !validateContentType(contentType) {
+	extensions := ImageExtensions(contentType)
+	if extensions == nil {
 		return fmt.Errorf("unsupported content type: %s", contentType)
 	}
 	
@@ -86,13 +182,6 @@ func validateFileSignature(filename, contentType string) error {
 	}
 	
 	// Validate filename extension matches content type
-	expectedExtensions := map[string][]string{
-		"image/jpeg": {".jpg", ".jpeg"},
-		"image/png":  {".png"},
-		"image/webp": {".webp"},
-	}
-	
-	extensions := expectedExtensions[contentType]
 	validExtension := false
 	filename = strings.ToLower(filename)
 	
@@ -110,11 +199,126 @@ func validateFileSignature(filename, contentType string) error {
 	return nil
 }
 
+// StorageBackend is the bucket operations the storage service relies on
+// gcsBackend implements it for Google Cloud Storage; tests substitute their own implementation
+type StorageBackend interface {
+	SignedURL(object string, opts *storage.SignedURLOptions) (string, error)
+	Attrs(ctx context.Context) (*storage.BucketAttrs, error)
+	ListObjects(ctx context.Context, prefix string) ([]string, error)
+	DeleteObject(ctx context.Context, name string) error
+	ObjectAttrs(ctx context.Context, name string) (*storage.ObjectAttrs, error)
+	ReadObject(ctx context.Context, name string, limit int64) ([]byte, error)
+	WriteObject(ctx context.Context, name, contentType string, data []byte) error
+}
+
+// gcsBackend adapts a GCS bucket handle to StorageBackend
+type gcsBackend struct {
+	*storage.BucketHandle
+	client *storage.Client
+}
+
+// Close releases the client the bucket handle was created from
+func (b gcsBackend) Close() error {
+	return b.client.Close()
+}
+
+// ListObjects returns the names of all objects under prefix
+func (b gcsBackend) ListObjects(ctx context.Context, prefix string) ([]string, error) {
+	var names []string
+	it := b.Objects(ctx, &storage.Query{Prefix: prefix})
+	for {
+		attrs, err := it.Next()
+		if err == iterator.Done {
+			return names, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, attrs.Name)
+	}
+}
+
+// DeleteObject removes one object; objects that are already gone are not an error
+func (b gcsBackend) DeleteObject(ctx context.Context, name string) error {
+	err := b.Object(name).Delete(ctx)
+	if errors.Is(err, storage.ErrObjectNotExist) {
+		return nil
+	}
+	return err
+}
+
+// ObjectAttrs returns the attributes of one object, including its checksums
+func (b gcsBackend) ObjectAttrs(ctx context.Context, name string) (*storage.ObjectAttrs, error) {
+	return b.Object(name).Attrs(ctx)
+}
+
+// ReadObject returns the content of one object, reading at most limit bytes
+func (b gcsBackend) ReadObject(ctx context.Context, name string, limit int64) ([]byte, error) {
+	reader, err := b.Object(name).NewReader(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return io.ReadAll(io.LimitReader(reader, limit))
+}
+
+// WriteObject stores data as one object, replacing any existing object with the same name
+func (b gcsBackend) WriteObject(ctx context.Context, name, contentType string, data []byte) error {
+	writer := b.Object(name).NewWriter(ctx)
+	writer.ContentType = contentType
+	if _, err := writer.Write(data); err != nil {
+		writer.Close()
+		return err
+	}
+	return writer.Close()
+}
+
 // StorageService handles file storage operations
 type StorageService struct {
-	gcsClient  *storage.Client
-	bucketName string
-	projectID  string
+	bucketName   string
+	projectID    string
+	uploadExpiry UploadURLExpiryConfig
+
+	// mu guards bucket, which Reconnect replaces
+	mu     sync.RWMutex
+	bucket StorageBackend
+
+	reconnect reconnectState
+}
+
+// NewStorageServiceWithBackend creates a storage service on top of an existing bucket backend
+// Without a connector the service cannot reconnect, so Reconnect always fails
+func NewStorageServiceWithBackend(bucketName string, backend StorageBackend) *StorageService {
+	return &StorageService{
+		bucket:       backend,
+		bucketName:   bucketName,
+		uploadExpiry: DefaultUploadURLExpiryConfig(),
+		reconnect:    reconnectState{cooldown: DefaultStorageReconnectCooldown},
+	}
+}
+
+// NewStorageServiceWithConnector creates a storage service whose backend comes from connect
+// connect is called once now and again whenever the service reconnects
+func NewStorageServiceWithConnector(ctx context.Context, bucketName string, connect StorageConnector) (*StorageService, error) {
+	backend, err := connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	service := NewStorageServiceWithBackend(bucketName, backend)
+	service.reconnect.connect = connect
+	return service, nil
+}
+
+// backend returns the current bucket backend
+func (s *StorageService) backend() StorageBackend {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.bucket
+}
+
+// SetUploadURLExpiry replaces the default and maximum lifetime of upload URLs
+func (s *StorageService) SetUploadURLExpiry(config UploadURLExpiryConfig) {
+	s.uploadExpiry = config
 }
 
 // NewStorageService creates a new storage service
@@ -129,70 +333,105 @@ func NewStorageService() (*StorageService, error) {
 		projectID = "digital-recipes-dev" // Default project for development
 	}
 
-	ctx := context.Background()
-	var gcsClient *storage.Client
-	var err error
-
-	// Check if we have a service account key file
-	credentialsFile := os.Getenv("GOOGLE_APPLICATION_CREDENTIALS")
-	if credentialsFile != "" {
-		// Use service account key file
-		gcsClient, err = storage.NewClient(ctx, option.WithCredentialsFile(credentialsFile))
-	} else {
-		// Use default credential chain (Application Default Credentials)
-		// This includes: service account on GCE/GKE, gcloud credentials, etc.
-		gcsClient, err = storage.NewClient(ctx)
-	}
-
+	service, err := NewStorageServiceWithConnector(context.Background(), bucketName, gcsConnector(bucketName))
 	if err != nil {
-		return nil, fmt.Errorf("failed to create GCS client: %w", err)
+		return nil, err
 	}
+	service.projectID = projectID
+	return service, nil
+}
 
-	return &StorageService{
-		gcsClient:  gcsClient,
-		bucketName: bucketName,
-		projectID:  projectID,
-	}, nil
+// gcsConnector creates GCS clients for a bucket, reading credentials again on every connection
+// so a reconnect picks up rotated service account keys
+func gcsConnector(bucketName string) StorageConnector {
+	return func(ctx context.Context) (StorageBackend, error) {
+		var gcsClient *storage.Client
+		var err error
+
+		// Check if we have a service account key file
+		credentialsFile := os.Getenv("GOOGLE_APPLICATION_CREDENTIALS")
+		if credentialsFile != "" {
+			// Use service account key file
+			gcsClient, err = storage.NewClient(ctx, option.WithCredentialsFile(credentialsFile))
+		} else {
+			// Use default credential chain (Application Default Credentials)
+			// This includes: service account on GCE/GKE, gcloud credentials, etc.
+			gcsClient, err = storage.NewClient(ctx)
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to create GCS client: %w", err)
+		}
+		return gcsBackend{BucketHandle: gcsClient.Bucket(bucketName), client: gcsClient}, nil
+	}
 }
 
 // GenerateUploadURLs creates pre-signed URLs for image uploads with enhanced security
-func (s *StorageService) GenerateUploadURLs(ctx context.Context, recipeID int, uploadReq *models.UploadRequest, clientIP string) ([]models.ImageUploadURL, error) {
+// Objects are keyed under the recipe's storage prefix; the recipe ID is only recorded in their metadata
+// Log lines carry the request fields of the logger attached to ctx, ending with one summary line per call
+// so the upload funnel can be monitored; no URLs are returned unless every requested one was generated
+func (s *StorageService) GenerateUploadURLs(ctx context.Context, recipeID int, storagePrefix string, uploadReq *models.UploadRequest, clientIP string) (urls []models.ImageUploadURL, err error) {
+	logger := logctx.FromContext(ctx)
 	var uploadURLs []models.ImageUploadURL
+
+	start := time.Now()
+	defer func() {
+		summary := logger.WithFields(logrus.Fields{
+			"recipe_id":       recipeID,
+			"requested_count": uploadReq.ImageCount,
+			"generated_count": len(uploadURLs),
+			"failed_count":    uploadReq.ImageCount - len(uploadURLs),
+			"duration_ms":     time.Since(start).Milliseconds(),
+		})
+		if err != nil {
+			summary.WithError(err).Warn("Upload URL generation incomplete")
+			return
+		}
+		summary.Info("Upload URLs generated")
+	}()
 	
 	// Get validated parameters from request
 	maxFileSizeBytes := int64(uploadReq.GetMaxFileSizeMB()) * 1024 * 1024
-	allowedTypes := uploadReq.GetAllowedTypes()
-	expirationDuration := time.Duration(uploadReq.GetExpirationHours()) * time.Hour
+	expirationHours, clamped := s.uploadExpiry.Hours(uploadReq.ExpirationHours)
+	if clamped {
+		logger.WithFields(logrus.Fields{
+			"recipe_id":        recipeID,
+			"requested_hours":  uploadReq.ExpirationHours,
+			"expiration_hours": expirationHours,
+		}).Info("Upload URL expiration clamped to the configured maximum")
+	}
+	expirationDuration := time.Duration(expirationHours) * time.Hour
 
 	for i := 0; i < uploadReq.ImageCount; i++ {
+		// Stop once the caller has given up or its deadline has passed rather than signing URLs nobody will receive
+		if err := ctx.Err(); err != nil {
+			logger.WithError(err).WithFields(logrus.Fields{
+				"recipe_id":   recipeID,
+				"image_index": i,
+			}).Warn("Upload URL generation stopped")
+			return nil, fmt.Errorf("upload URL generation stopped: %w", err)
+		}
+
 		// Generate unique image ID with timestamp for uniqueness
 		timestamp := time.Now().Unix()
 		rawImageID := fmt.Sprintf("recipe-%d-%d-%s", recipeID, timestamp, uuid.New().String())
 		imageID := sanitizeImageID(rawImageID)
 		
-		// Determine file extension based on allowed types (default to jpg)
-		extension := "jpg"
-		contentType := "image/jpeg"
-		if len(allowedTypes) > 0 {
-			contentType = allowedTypes[0]
-			// Validate content type for security
-			if !validateContentType(contentType) {
-				logrus.WithFields(logrus.Fields{
-					"content_type": contentType,
-					"recipe_id":   recipeID,
-				}).Warn("Invalid content type provided, using default")
-				contentType = "image/jpeg"
-			}
-			switch contentType {
-			case "image/png":
-				extension = "png"
-			case "image/webp":
-				extension = "webp"
-			}
+		// The signed Content-Type is this image's requested type, or the first allowed type when none was given
+		// The extension follows it so the stored object matches what the URL accepts
+		contentType := models.CanonicalizeContentType(uploadReq.ContentTypeFor(i))
+		// Validate content type for security
+		if !validateContentType(contentType) {
+			logger.WithFields(logrus.Fields{
+				"content_type": contentType,
+				"recipe_id":   recipeID,
+			}).Warn("Invalid content type provided, using default")
+			contentType = "image/jpeg"
 		}
+		extension := imageFileTypes[contentType].extensions[0]
 		
 		// Create object key with proper prefix and extension
-		objectKey := fmt.Sprintf("recipes/%d/images/%s.%s", recipeID, imageID, extension)
+		objectKey := fmt.Sprintf("%simages/%s%s", recipeObjectPrefix(storagePrefix), imageID, extension)
 
 		// Set object metadata with sanitized inputs
 		sanitizedClientIP := sanitizeClientIP(clientIP)
@@ -220,15 +459,23 @@ func (s *StorageService) GenerateUploadURLs(ctx context.Context, recipeID int, u
 			opts.Headers = append(opts.Headers, fmt.Sprintf("x-goog-meta-%s:%s", key, value))
 		}
 
-		signedURL, err := s.gcsClient.Bucket(s.bucketName).SignedURL(objectKey, opts)
+		signedURL, err := s.backend().SignedURL(objectKey, opts)
+		if err != nil && s.Reconnect(ctx) == nil {
+			// A stale client is replaced once so the upload can go ahead on the new one
+			signedURL, err = s.backend().SignedURL(objectKey, opts)
+		}
 		if err != nil {
-			logrus.WithError(err).WithField("image_index", i).Error("Failed to create pre-signed URL")
+			logger.WithError(err).WithFields(logrus.Fields{
+				"recipe_id":   recipeID,
+				"image_index": i,
+			}).Error("Failed to create pre-signed URL")
 			return nil, fmt.Errorf("failed to create upload URL: %w", err)
 		}
 
 		uploadURL := models.ImageUploadURL{
 			ImageID:   imageID,
 			UploadURL: signedURL,
+			ObjectKey: objectKey,
 			Fields:    make(map[string]string),
 		}
 
@@ -248,16 +495,156 @@ func (s *StorageService) GenerateUploadURLs(ctx context.Context, recipeID int, u
 	return uploadURLs, nil
 }
 
+// GenerateDownloadURL creates a pre-signed URL for reading one stored object
+func (s *StorageService) GenerateDownloadURL(ctx context.Context, objectName string, expiry time.Duration) (string, error) {
+	signedURL, err := s.backend().SignedURL(objectName, &storage.SignedURLOptions{
+		Scheme:  storage.SigningSchemeV4,
+		Method:  "GET",
+		Expires: time.Now().Add(expiry),
+	})
+	if err != nil {
+		logctx.FromContext(ctx).WithError(err).WithField("object", objectName).Error("Failed to create download URL")
+		return "", fmt.Errorf("failed to create download URL: %w", err)
+	}
+	return signedURL, nil
+}
+
+// StoredObject describes an uploaded object as reported by the bucket
+// Hash identifies the content: "md5:<hex>" when the bucket has an MD5, otherwise "crc32c:<hex>:<size>"
+// for composite objects, which GCS stores without one
+type StoredObject struct {
+	Name        string
+	ContentType string
+	Size        int64
+	Hash        string
+}
+
+// GetObjectHash reads an object's attributes and derives its content hash from the checksums GCS keeps
+func (s *StorageService) GetObjectHash(ctx context.Context, objectName string) (StoredObject, error) {
+	attrs, err := s.backend().ObjectAttrs(ctx, objectName)
+	if err != nil {
+		if !errors.Is(err, storage.ErrObjectNotExist) {
+			logctx.FromContext(ctx).WithError(err).WithField("object", objectName).Error("Failed to read object attributes")
+		}
+		return StoredObject{}, fmt.Errorf("failed to read object attributes: %w", err)
+	}
+
+	// CRC32C alone collides too easily to dedupe on, so the size is part of the fallback hash
+	hash := fmt.Sprintf("crc32c:%08x:%d", attrs.CRC32C, attrs.Size)
+	if len(attrs.MD5) > 0 {
+		hash = "md5:" + hex.EncodeToString(attrs.MD5)
+	}
+
+	return StoredObject{
+		Name:        objectName,
+		ContentType: attrs.ContentType,
+		Size:        attrs.Size,
+		Hash:        hash,
+	}, nil
+}
+
+// FindUploadedImage returns the name of the object uploaded for an image ID issued by GenerateUploadURLs
+// It returns storage.ErrObjectNotExist when nothing was uploaded under that ID
+func (s *StorageService) FindUploadedImage(ctx context.Context, storagePrefix string, imageID string) (string, error) {
+	names, err := s.backend().ListObjects(ctx, fmt.Sprintf("%simages/%s.", recipeObjectPrefix(storagePrefix), imageID))
+	if err != nil {
+		logctx.FromContext(ctx).WithError(err).WithField("storage_prefix", storagePrefix).Error("Failed to list uploaded images")
+		return "", fmt.Errorf("failed to list uploaded images: %w", err)
+	}
+	if len(names) == 0 {
+		return "", storage.ErrObjectNotExist
+	}
+	return names[0], nil
+}
+
+// DeleteObject removes one stored object
+func (s *StorageService) DeleteObject(ctx context.Context, objectName string) error {
+	if err := s.backend().DeleteObject(ctx, objectName); err != nil {
+		logctx.FromContext(ctx).WithError(err).WithField("object", objectName).Error("Failed to delete object")
+		return fmt.Errorf("failed to delete %s: %w", objectName, err)
+	}
+	return nil
+}
+
+// recipeStoragePrefixColumn selects the key segment of a recipe's stored files
+// Recipes created before migration 014 have no random prefix and keep their files under their ID
+const recipeStoragePrefixColumn = `COALESCE(storage_prefix, id::text)`
+
+// recipeObjectPrefix is the prefix under which all of a recipe's stored files live
+func recipeObjectPrefix(storagePrefix string) string {
+	return "recipes/" + storagePrefix + "/"
+}
+
+// StoragePrefixFromObject extracts the recipe storage prefix from an object name such as
+// "recipes/3f2a.../images/abc.jpg"; ok is false for names outside the recipes/ layout
+func StoragePrefixFromObject(objectName string) (prefix string, ok bool) {
+	rest, found := strings.CutPrefix(objectName, "recipes/")
+	if !found {
+		return "", false
+	}
+	prefix, _, found = strings.Cut(rest, "/")
+	if !found || prefix == "" {
+		return "", false
+	}
+	return prefix, true
+}
+
+// ResolveObjectRecipe returns the ID of the recipe a stored object belongs to
+// It returns sql.ErrNoRows when the object's prefix matches no recipe
+func ResolveObjectRecipe(q queryRower, objectName string) (int, error) {
+	prefix, ok := StoragePrefixFromObject(objectName)
+	if !ok {
+		return 0, sql.ErrNoRows
+	}
+	var recipeID int
+	err := q.QueryRow(`
+		SELECT id FROM recipes
+		WHERE storage_prefix = $1 OR (storage_prefix IS NULL AND id::text = $1)
+	`, prefix).Scan(&recipeID)
+	return recipeID, err
+}
+
+// DeleteRecipeObjects removes every stored file under a recipe's storage prefix and returns how many were deleted
+// Deletion continues past individual failures; the first error is returned
+func (s *StorageService) DeleteRecipeObjects(ctx context.Context, storagePrefix string) (int, error) {
+	logger := logctx.FromContext(ctx).WithField("storage_prefix", storagePrefix)
+
+	names, err := s.backend().ListObjects(ctx, recipeObjectPrefix(storagePrefix))
+	if err != nil {
+		logger.WithError(err).Error("Failed to list recipe objects")
+		return 0, fmt.Errorf("failed to list recipe objects: %w", err)
+	}
+
+	deleted := 0
+	var firstErr error
+	for _, name := range names {
+		if err := s.backend().DeleteObject(ctx, name); err != nil {
+			logger.WithError(err).WithField("object", name).Error("Failed to delete recipe object")
+			if firstErr == nil {
+				firstErr = fmt.Errorf("failed to delete %s: %w", name, err)
+			}
+			continue
+		}
+		deleted++
+	}
+	return deleted, firstErr
+}
+
 // HealthCheck verifies GCS connectivity
 func (s *StorageService) HealthCheck(ctx context.Context) error {
 	// Simple operation to test connectivity
-	bucket := s.gcsClient.Bucket(s.bucketName)
-	_, err := bucket.Attrs(ctx)
-	
+	_, err := s.backend().Attrs(ctx)
+
+	// Repeated failures suggest a stale client, so it is replaced and checked again
+	if err != nil && s.reconnect.recordFailure() && s.Reconnect(ctx) == nil {
+		_, err = s.backend().Attrs(ctx)
+	}
+
 	if err != nil {
-		logrus.WithError(err).Error("GCS health check failed")
+		logctx.FromContext(ctx).WithError(err).Error("GCS health check failed")
 		return fmt.Errorf("GCS connectivity check failed: %w", err)
 	}
 
+	s.reconnect.recordSuccess()
 	return nil
 }
\ No newline at end of file
@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"digital-recipes/api-service/logctx"
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultStorageReconnectCooldown is the minimum time between two storage reconnection attempts
+const DefaultStorageReconnectCooldown = 30 * time.Second
+
+// storageReconnectAfterFailures is how many health checks in a row must fail before the client is replaced
+const storageReconnectAfterFailures = 3
+
+var (
+	// ErrStorageReconnectUnsupported is returned by Reconnect when the service was built without a connector
+	ErrStorageReconnectUnsupported = errors.New("storage reconnection is not supported")
+	// ErrStorageReconnectCooldown is returned by Reconnect when the previous attempt was too recent
+	ErrStorageReconnectCooldown = errors.New("storage reconnection attempted too recently")
+)
+
+// StorageConnector creates a new bucket backend, for example by building a new GCS client
+type StorageConnector func(ctx context.Context) (StorageBackend, error)
+
+// reconnectState tracks health check failures and reconnection attempts for a storage service
+type reconnectState struct {
+	connect  StorageConnector
+	cooldown time.Duration
+
+	mu             sync.Mutex
+	healthFailures int
+	lastAttempt    time.Time
+}
+
+// recordFailure counts a failed health check and reports whether enough have failed in a row to reconnect
+func (r *reconnectState) recordFailure() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.healthFailures++
+	return r.healthFailures >= storageReconnectAfterFailures
+}
+
+// recordSuccess resets the failed health check count
+func (r *reconnectState) recordSuccess() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.healthFailures = 0
+}
+
+// SetReconnectCooldown sets the minimum time between two reconnection attempts
+func (s *StorageService) SetReconnectCooldown(cooldown time.Duration) {
+	s.reconnect.mu.Lock()
+	defer s.reconnect.mu.Unlock()
+	s.reconnect.cooldown = cooldown
+}
+
+// Reconnect replaces the bucket backend with a newly connected one and closes the old one
+// Attempts within the cooldown of the previous attempt, successful or not, return ErrStorageReconnectCooldown
+// so a storage outage does not turn every failing request into a new connection
+func (s *StorageService) Reconnect(ctx context.Context) error {
+	if s.reconnect.connect == nil {
+		return ErrStorageReconnectUnsupported
+	}
+
+	s.reconnect.mu.Lock()
+	defer s.reconnect.mu.Unlock()
+
+	if !s.reconnect.lastAttempt.IsZero() && time.Since(s.reconnect.lastAttempt) < s.reconnect.cooldown {
+		return ErrStorageReconnectCooldown
+	}
+	s.reconnect.lastAttempt = time.Now()
+
+	logger := logctx.FromContext(ctx).WithFields(logrus.Fields{
+		"bucket":          s.bucketName,
+		"health_failures": s.reconnect.healthFailures,
+	})
+	logger.Warn("Reconnecting storage client")
+
+	backend, err := s.reconnect.connect(ctx)
+	if err != nil {
+		logger.WithError(err).Error("Storage reconnection failed")
+		return fmt.Errorf("failed to reconnect storage: %w", err)
+	}
+
+	s.mu.Lock()
+	previous := s.bucket
+	s.bucket = backend
+	s.mu.Unlock()
+	s.reconnect.healthFailures = 0
+
+	// Requests still using the old client fail, as they would have on a stale client anyway
+	if closer, ok := previous.(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			logger.WithError(err).Warn("Failed to close previous storage client")
+		}
+	}
+
+	logger.Info("Storage client reconnected")
+	return nil
+}
@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	_ "image/png" // register PNG decoding for image.Decode
+	"path"
+	"strings"
+
+	"digital-recipes/api-service/logctx"
+	"digital-recipes/api-service/models"
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp" // register WebP decoding for image.Decode
+)
+
+// Thumbnail bounds: the longer side is scaled down to maxThumbnailDimension, smaller images keep their size
+const (
+	maxThumbnailDimension = 320
+	thumbnailJPEGQuality  = 80
+	thumbnailContentType  = "image/jpeg"
+)
+
+// maxThumbnailSourceBytes caps how much of an uploaded image is read to build its thumbnail
+const maxThumbnailSourceBytes = int64(models.MaxFileSizeMBLimit) << 20
+
+// maxThumbnailSourcePixels caps the size of an image that is decoded, since a small compressed file can describe
+// a huge image; 50 megapixels leaves room for full-resolution photos from current phone cameras
+const maxThumbnailSourcePixels = 50_000_000
+
+// ErrThumbnailDecode is returned when an uploaded image is not a JPEG, PNG or WebP that can be decoded,
+// or is too large to decode
+var ErrThumbnailDecode = errors.New("image could not be decoded")
+
+// thumbnailObjectName is where the thumbnail of an uploaded image is stored, next to its images/ directory
+// "recipes/3f2a/images/abc.webp" becomes "recipes/3f2a/thumbnails/abc.jpg"
+func thumbnailObjectName(objectName string) string {
+	base := path.Base(objectName)
+	base = strings.TrimSuffix(base, path.Ext(base))
+	return path.Join(path.Dir(path.Dir(objectName)), "thumbnails", base+".jpg")
+}
+
+// generateThumbnail decodes a JPEG, PNG or WebP image and re-encodes it as a JPEG no larger than
+// maxThumbnailDimension on either side; transparent areas are flattened onto white
+func generateThumbnail(data []byte) ([]byte, error) {
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrThumbnailDecode, err)
+	}
+	if int64(config.Width)*int64(config.Height) > maxThumbnailSourcePixels {
+		return nil, fmt.Errorf("%w: %dx%d image is over the %d pixel limit", ErrThumbnailDecode, config.Width, config.Height, maxThumbnailSourcePixels)
+	}
+
+	source, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrThumbnailDecode, err)
+	}
+
+	bounds := source.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width == 0 || height == 0 {
+		return nil, fmt.Errorf("%w: image has no pixels", ErrThumbnailDecode)
+	}
+	if width > maxThumbnailDimension || height > maxThumbnailDimension {
+		if width >= height {
+			height = max(1, height*maxThumbnailDimension/width)
+			width = maxThumbnailDimension
+		} else {
+			width = max(1, width*maxThumbnailDimension/height)
+			height = maxThumbnailDimension
+		}
+	}
+
+	thumbnail := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(thumbnail, thumbnail.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.CatmullRom.Scale(thumbnail, thumbnail.Bounds(), source, bounds, draw.Over, nil)
+
+	var encoded bytes.Buffer
+	if err := jpeg.Encode(&encoded, thumbnail, &jpeg.Options{Quality: thumbnailJPEGQuality}); err != nil {
+		return nil, fmt.Errorf("failed to encode thumbnail: %w", err)
+	}
+	return encoded.Bytes(), nil
+}
+
+// StoreThumbnail downloads an uploaded image, stores a JPEG thumbnail of it under the recipe's thumbnails/
+// directory and returns the thumbnail's object name
+// Content that cannot be decoded is reported with ErrThumbnailDecode; other errors come from storage
+func (s *StorageService) StoreThumbnail(ctx context.Context, objectName string) (string, error) {
+	logger := logctx.FromContext(ctx).WithField("object", objectName)
+
+	data, err := s.backend().ReadObject(ctx, objectName, maxThumbnailSourceBytes)
+	if err != nil {
+		logger.WithError(err).Error("Failed to read image for thumbnail")
+		return "", fmt.Errorf("failed to read %s: %w", objectName, err)
+	}
+
+	thumbnail, err := generateThumbnail(data)
+	if err != nil {
+		logger.WithError(err).Warn("Failed to generate thumbnail")
+		return "", err
+	}
+
+	thumbnailName := thumbnailObjectName(objectName)
+	if err := s.backend().WriteObject(ctx, thumbnailName, thumbnailContentType, thumbnail); err != nil {
+		logger.WithError(err).WithField("thumbnail", thumbnailName).Error("Failed to store thumbnail")
+		return "", fmt.Errorf("failed to store %s: %w", thumbnailName, err)
+	}
+	return thumbnailName, nil
+}
@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"fmt"
+
+	"digital-recipes/api-service/models"
+)
+
+// UploadURLExpiryConfig bounds how long pre-signed upload URLs stay valid
+// Operators can lower the ceiling below the 24 hours clients may ask for, but never raise it
+type UploadURLExpiryConfig struct {
+	DefaultHours int
+	MaxHours     int
+}
+
+// DefaultUploadURLExpiryConfig returns the built-in expiration bounds
+func DefaultUploadURLExpiryConfig() UploadURLExpiryConfig {
+	return UploadURLExpiryConfig{
+		DefaultHours: models.DefaultExpirationHrs,
+		MaxHours:     models.MaxExpirationHours,
+	}
+}
+
+// Validate checks that both bounds are positive, the ceiling is within the request limit and the default fits under it
+func (u UploadURLExpiryConfig) Validate() error {
+	if u.DefaultHours < 1 || u.MaxHours < 1 {
+		return fmt.Errorf("upload URL expiration hours must be positive (default %d, max %d)", u.DefaultHours, u.MaxHours)
+	}
+	if u.MaxHours > models.MaxExpirationHours {
+		return fmt.Errorf("UPLOAD_URL_MAX_HOURS (%d) must not exceed %d", u.MaxHours, models.MaxExpirationHours)
+	}
+	if u.DefaultHours > u.MaxHours {
+		return fmt.Errorf("UPLOAD_URL_DEFAULT_HOURS (%d) must not exceed UPLOAD_URL_MAX_HOURS (%d)", u.DefaultHours, u.MaxHours)
+	}
+	return nil
+}
+
+// GetUploadURLExpiryConfig reads UPLOAD_URL_DEFAULT_HOURS and UPLOAD_URL_MAX_HOURS
+// Unparseable values fall back to the built-in bounds; a combination that fails Validate is returned as an error
+func GetUploadURLExpiryConfig() (UploadURLExpiryConfig, error) {
+	config := DefaultUploadURLExpiryConfig()
+	config.DefaultHours = positiveIntEnv("UPLOAD_URL_DEFAULT_HOURS", models.DefaultExpirationHrs)
+	config.MaxHours = positiveIntEnv("UPLOAD_URL_MAX_HOURS", models.MaxExpirationHours)
+	return config, config.Validate()
+}
+
+// Hours returns the expiration for a requested number of hours
+// Unset requests get the default; requests above the ceiling are clamped to it and reported as clamped
+func (u UploadURLExpiryConfig) Hours(requested int) (hours int, clamped bool) {
+	if requested <= 0 {
+		return u.DefaultHours, false
+	}
+	if requested > u.MaxHours {
+		return u.MaxHours, true
+	}
+	return requested, false
+}
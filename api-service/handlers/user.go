@@ -0,0 +1,429 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"strings"
+	"time"
+
+	"digital-recipes/api-service/db"
+	"digital-recipes/api-service/logctx"
+	"digital-recipes/api-service/middleware"
+	"digital-recipes/api-service/models"
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// maxEmailFilterLength matches the users.email column size
+const maxEmailFilterLength = models.MaxEmailLength
+
+// likePatternEscaper escapes LIKE wildcards so filters match literally
+var likePatternEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// UserHandler handles user profile and administration HTTP requests
+type UserHandler struct {
+	db             *db.Database
+	storageService *StorageService
+	pagination     PaginationConfig
+}
+
+// NewUserHandler creates a new user handler; storageService may be nil when storage is not configured
+func NewUserHandler(database *db.Database, storageService *StorageService) *UserHandler {
+	return &UserHandler{
+		db:             database,
+		storageService: storageService,
+		pagination:     DefaultPaginationConfig(),
+	}
+}
+
+// SetPagination replaces the page size bounds used by the user listing
+func (h *UserHandler) SetPagination(config PaginationConfig) {
+	h.pagination = config
+}
+
+// GetUsers handles GET /users requests with pagination and an optional email substring filter
+// Access is restricted to admins by the route's RequireRole middleware
+func (h *UserHandler) GetUsers(c *gin.Context) {
+	logger := middleware.LogWithContext(c)
+
+	page, perPage, paginationErr := parsePagination(c, h.pagination)
+	if paginationErr != nil {
+		SafeErrorResponse(c, *paginationErr, http.StatusBadRequest)
+		return
+	}
+
+	email := strings.TrimSpace(c.Query("email"))
+	if len(email) > maxEmailFilterLength {
+		ValidationError(c, "email filter is too long", "email")
+		return
+	}
+
+	rows, err := h.db.QueryContextLogged(c.Request.Context(), `
+		SELECT id, email, name, created_at, COUNT(*) OVER() as total_count
+		FROM users
+		WHERE $1 = '' OR email ILIKE '%' || $1 || '%' ESCAPE '\'
+		ORDER BY id ASC
+		LIMIT $2 OFFSET $3
+	`, likePatternEscaper.Replace(email), perPage, (page-1)*perPage)
+	if err != nil {
+		DatabaseError(c, err, "list users")
+		return
+	}
+	defer rows.Close()
+
+	users := []models.User{}
+	var total int
+	for rows.Next() {
+		var user models.User
+		if err := rows.Scan(&user.ID, &user.Email, &user.Name, &user.CreatedAt, &total); err != nil {
+			DatabaseError(c, err, "read user")
+			return
+		}
+		users = append(users, user)
+	}
+	if err := rows.Err(); err != nil {
+		DatabaseError(c, err, "read users")
+		return
+	}
+
+	logger.WithField("result_count", len(users)).Debug("Listed users")
+
+	SuccessResponseWithPagination(c, users, &Pagination{
+		Page:       page,
+		PerPage:    perPage,
+		Total:      total,
+		TotalPages: (total + perPage - 1) / perPage,
+	})
+}
+
+// uniqueViolation is the PostgreSQL error code for unique constraint violations
+const uniqueViolation = "23505"
+
+// scanUser scans a users row selected as id, email, name, created_at
+func scanUser(row *sql.Row, user *models.User) error {
+	return row.Scan(&user.ID, &user.Email, &user.Name, &user.CreatedAt)
+}
+
+// GetCurrentUser handles GET /users/me requests
+func (h *UserHandler) GetCurrentUser(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	if userID == 0 {
+		AuthenticationError(c, "Authentication required to view your profile")
+		return
+	}
+
+	var user models.User
+	err := scanUser(h.db.QueryRowContextLogged(c.Request.Context(), `
+		SELECT id, email, name, created_at FROM users WHERE id = $1
+	`, userID), &user)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			NotFoundError(c, "user not found")
+			return
+		}
+		DatabaseError(c, err, "load user")
+		return
+	}
+
+	SuccessResponse(c, user)
+}
+
+// UpdateCurrentUser handles PATCH /users/me requests
+// Emails are stored lowercased and must not belong to another account, compared case-insensitively
+func (h *UserHandler) UpdateCurrentUser(c *gin.Context) {
+	logger := middleware.LogWithContext(c)
+
+	userID := middleware.GetUserID(c)
+	if userID == 0 {
+		AuthenticationError(c, "Authentication required to update your profile")
+		return
+	}
+
+	var input models.UpdateProfileRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		logger.WithError(err).Warn("Update profile binding failed")
+		if isBodyTooLarge(err) {
+			RequestTooLargeError(c, "Request body too large")
+			return
+		}
+		ValidationError(c, "Invalid request format. name and email must be at most 255 characters.")
+		return
+	}
+
+	if input.Name == nil && input.Email == nil {
+		ValidationError(c, "name or email is required")
+		return
+	}
+
+	var name, email *string
+	if input.Name != nil {
+		trimmed := strings.TrimSpace(*input.Name)
+		if trimmed == "" {
+			ValidationError(c, "name must not be blank", "name")
+			return
+		}
+		name = &trimmed
+	}
+	if input.Email != nil {
+		normalized := models.NormalizeEmail(*input.Email)
+		if err := models.ValidateEmail(normalized); err != nil {
+			FieldValidationError(c, err)
+			return
+		}
+		email = &normalized
+
+		var taken bool
+		err := h.db.QueryRowContextLogged(c.Request.Context(), `
+			SELECT EXISTS (SELECT 1 FROM users WHERE LOWER(email) = $1 AND id <> $2)
+		`, normalized, userID).Scan(&taken)
+		if err != nil {
+			DatabaseError(c, err, "check email availability")
+			return
+		}
+		if taken {
+			ConflictError(c, "email is already in use")
+			return
+		}
+	}
+
+	var user models.User
+	err := scanUser(h.db.QueryRowContextLogged(c.Request.Context(), `
+		UPDATE users
+		SET name = COALESCE($1, name), email = COALESCE($2, email)
+		WHERE id = $3
+		RETURNING id, email, name, created_at
+	`, name, email, userID), &user)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			NotFoundError(c, "user not found")
+			return
+		}
+		// A concurrent update may still claim the email between the check and the update
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == uniqueViolation {
+			ConflictError(c, "email is already in use")
+			return
+		}
+		DatabaseError(c, err, "update user")
+		return
+	}
+
+	logger.WithFields(logrus.Fields{
+		"name_changed":  name != nil,
+		"email_changed": email != nil,
+	}).Info("User profile updated")
+
+	SuccessResponse(c, user)
+}
+
+// hashPassword returns the bcrypt hash stored for a password
+func hashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	return string(hash), err
+}
+
+// passwordMatches reports whether password matches a stored hash; accounts without one never match
+func passwordMatches(hash sql.NullString, password string) bool {
+	return hash.Valid && bcrypt.CompareHashAndPassword([]byte(hash.String), []byte(password)) == nil
+}
+
+// ChangePassword handles POST /users/me/password requests
+func (h *UserHandler) ChangePassword(c *gin.Context) {
+	logger := middleware.LogWithContext(c)
+
+	userID := middleware.GetUserID(c)
+	if userID == 0 {
+		AuthenticationError(c, "Authentication required to change your password")
+		return
+	}
+
+	var input models.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		logger.WithError(err).Warn("Change password binding failed")
+		if isBodyTooLarge(err) {
+			RequestTooLargeError(c, "Request body too large")
+			return
+		}
+		ValidationError(c, "Invalid request format. current_password and new_password are required.")
+		return
+	}
+
+	if err := input.Validate(); err != nil {
+		FieldValidationError(c, err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(logctx.WithLogger(context.Background(), logger), 30*time.Second)
+	defer cancel()
+
+	tx, err := h.db.DB.BeginTx(ctx, nil)
+	if err != nil {
+		DatabaseError(c, err, "begin password change")
+		return
+	}
+	defer tx.Rollback()
+
+	var currentHash sql.NullString
+	err = tx.QueryRow(`SELECT password_hash FROM users WHERE id = $1 FOR UPDATE`, userID).Scan(&currentHash)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			NotFoundError(c, "user not found")
+			return
+		}
+		DatabaseError(c, err, "load password")
+		return
+	}
+
+	if !passwordMatches(currentHash, input.CurrentPassword) {
+		logger.Warn("Password change rejected: current password incorrect")
+		AuthenticationError(c, "current password is incorrect")
+		return
+	}
+
+	newHash, err := hashPassword(input.NewPassword)
+	if err != nil {
+		logger.WithError(err).Error("Failed to hash password")
+		InternalServerError(c, "Failed to change password")
+		return
+	}
+
+	if _, err := tx.Exec(`UPDATE users SET password_hash = $1 WHERE id = $2`, newHash, userID); err != nil {
+		DatabaseError(c, err, "update password")
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		DatabaseError(c, err, "commit password change")
+		return
+	}
+
+	logger.Info("User password changed")
+
+	SuccessResponse(c, gin.H{"message": "password changed"})
+}
+
+// DeleteCurrentUser handles DELETE /users/me requests
+// The user's recipes and their ingredients and revisions go with the account through
+// cascading foreign keys; stored recipe images are removed afterwards on a best-effort basis
+func (h *UserHandler) DeleteCurrentUser(c *gin.Context) {
+	logger := middleware.LogWithContext(c)
+
+	userID := middleware.GetUserID(c)
+	if userID == 0 {
+		AuthenticationError(c, "Authentication required to delete your account")
+		return
+	}
+
+	var input models.DeleteAccountRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		logger.WithError(err).Warn("Delete account binding failed")
+		if isBodyTooLarge(err) {
+			RequestTooLargeError(c, "Request body too large")
+			return
+		}
+		ValidationError(c, "Invalid request format. password is required to confirm account deletion.", "password")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(logctx.WithLogger(context.Background(), logger), 30*time.Second)
+	defer cancel()
+
+	tx, err := h.db.DB.BeginTx(ctx, nil)
+	if err != nil {
+		DatabaseError(c, err, "begin account deletion")
+		return
+	}
+	defer tx.Rollback()
+
+	var passwordHash sql.NullString
+	err = tx.QueryRow(`SELECT password_hash FROM users WHERE id = $1 FOR UPDATE`, userID).Scan(&passwordHash)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			NotFoundError(c, "user not found")
+			return
+		}
+		DatabaseError(c, err, "load password")
+		return
+	}
+
+	if !passwordMatches(passwordHash, input.Password) {
+		logger.Warn("Account deletion rejected: password incorrect")
+		AuthenticationError(c, "password is incorrect")
+		return
+	}
+
+	// Storage prefixes are collected before the cascade removes the recipes so their images can be found
+	rows, err := tx.Query(`SELECT id, `+recipeStoragePrefixColumn+` FROM recipes WHERE user_id = $1 ORDER BY id`, userID)
+	if err != nil {
+		DatabaseError(c, err, "list user recipes")
+		return
+	}
+	var recipes []recipeStorage
+	for rows.Next() {
+		var recipe recipeStorage
+		if err := rows.Scan(&recipe.id, &recipe.prefix); err != nil {
+			rows.Close()
+			DatabaseError(c, err, "read user recipe")
+			return
+		}
+		recipes = append(recipes, recipe)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		DatabaseError(c, err, "read user recipes")
+		return
+	}
+
+	if _, err := tx.Exec(`DELETE FROM users WHERE id = $1`, userID); err != nil {
+		DatabaseError(c, err, "delete user")
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		DatabaseError(c, err, "commit account deletion")
+		return
+	}
+
+	deletedObjects := h.deleteRecipeObjects(ctx, recipes)
+
+	logger.WithFields(logrus.Fields{
+		"deleted_recipes": len(recipes),
+		"deleted_objects": deletedObjects,
+	}).Info("User account deleted")
+
+	c.Status(http.StatusNoContent)
+}
+
+// recipeStorage pairs a recipe ID with the storage prefix its files live under
+type recipeStorage struct {
+	id     int
+	prefix string
+}
+
+// deleteRecipeObjects removes the stored images of deleted recipes, logging failures
+// The account is already gone at this point, so leftover objects are only reported
+func (h *UserHandler) deleteRecipeObjects(ctx context.Context, recipes []recipeStorage) int {
+	if len(recipes) == 0 {
+		return 0
+	}
+	if h.storageService == nil {
+		recipeIDs := make([]int, len(recipes))
+		for i, recipe := range recipes {
+			recipeIDs[i] = recipe.id
+		}
+		logctx.FromContext(ctx).WithField("recipe_ids", recipeIDs).Warn("Storage not configured, recipe images were not deleted")
+		return 0
+	}
+
+	total := 0
+	for _, recipe := range recipes {
+		deleted, err := h.storageService.DeleteRecipeObjects(ctx, recipe.prefix)
+		total += deleted
+		if err != nil {
+			logctx.FromContext(ctx).WithError(err).WithField("recipe_id", recipe.id).Error("Recipe images left behind after account deletion")
+		}
+	}
+	return total
+}
@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"digital-recipes/api-service/models"
+	"github.com/go-playground/validator/v10"
+)
+
+// recipeFieldErrors combines the binding rule failures of a recipe write with the model's own checks
+// obj is the bound request, which is fully decoded even when its binding rules fail. ok is false when
+// bindErr is not a rule failure, such as malformed JSON, and the caller should report it on its own
+func recipeFieldErrors(obj interface{}, bindErr error, modelErrs models.FieldErrors) (errs models.FieldErrors, ok bool) {
+	if bindErr != nil {
+		var bindingErrs validator.ValidationErrors
+		if !errors.As(bindErr, &bindingErrs) {
+			return nil, false
+		}
+		errs = bindingFieldErrors(obj, bindingErrs)
+	}
+	return errs.Merge(modelErrs), true
+}
+
+// bindingFieldErrors describes each failed binding rule against the JSON path of its field
+func bindingFieldErrors(obj interface{}, bindingErrs validator.ValidationErrors) models.FieldErrors {
+	errs := make(models.FieldErrors, 0, len(bindingErrs))
+	for _, bindingErr := range bindingErrs {
+		field := bindingFieldPath(reflect.TypeOf(obj), bindingErr.Namespace())
+		errs = append(errs, models.FieldError{Field: field, Message: bindingRuleMessage(field, bindingErr)})
+	}
+	return errs
+}
+
+// bindingFieldPath turns a validator namespace such as RecipeInput.Ingredients[1].Quantity into the
+// JSON path ingredients[1].quantity, following the json tags of the bound types
+func bindingFieldPath(t reflect.Type, namespace string) string {
+	segments := strings.Split(namespace, ".")[1:]
+	path := make([]string, 0, len(segments))
+	for _, segment := range segments {
+		name, index, indexed := strings.Cut(segment, "[")
+		for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice {
+			t = t.Elem()
+		}
+		if t.Kind() == reflect.Struct {
+			if field, ok := t.FieldByName(name); ok {
+				t = field.Type
+				if jsonName := strings.Split(field.Tag.Get("json"), ",")[0]; jsonName != "" && jsonName != "-" {
+					name = jsonName
+				}
+			}
+		}
+		if indexed {
+			name += "[" + index
+		}
+		path = append(path, name)
+	}
+	return strings.Join(path, ".")
+}
+
+// bindingRuleMessage explains a failed binding rule in the terms a client sent the field in
+func bindingRuleMessage(field string, bindingErr validator.FieldError) string {
+	switch bindingErr.Tag() {
+	case "required":
+		return fmt.Sprintf("%s is required", field)
+	case "oneof":
+		return fmt.Sprintf("%s must be one of %s", field, strings.ReplaceAll(bindingErr.Param(), " ", ", "))
+	case "min":
+		if bindingErr.Param() == "0" {
+			return fmt.Sprintf("%s cannot be negative", field)
+		}
+		return fmt.Sprintf("%s must be at least %s", field, bindingErr.Param())
+	case "max":
+		switch bindingErr.Kind() {
+		case reflect.String:
+			return fmt.Sprintf("%s must be at most %s characters", field, bindingErr.Param())
+		case reflect.Slice:
+			return fmt.Sprintf("%s must have at most %s entries", field, bindingErr.Param())
+		}
+		return fmt.Sprintf("%s must be at most %s", field, bindingErr.Param())
+	default:
+		return fmt.Sprintf("invalid value for %s", field)
+	}
+}
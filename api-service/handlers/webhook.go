@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"net/url"
+	"strconv"
+
+	"digital-recipes/api-service/db"
+	"digital-recipes/api-service/middleware"
+	"digital-recipes/api-service/models"
+	"digital-recipes/api-service/webhooks"
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+	"github.com/sirupsen/logrus"
+)
+
+// WebhookHandler handles webhook registration HTTP requests
+type WebhookHandler struct {
+	db *db.Database
+}
+
+// NewWebhookHandler creates a new webhook handler
+func NewWebhookHandler(database *db.Database) *WebhookHandler {
+	return &WebhookHandler{db: database}
+}
+
+// SetWebhookDispatcher enables webhook notifications for recipe status changes
+func (h *RecipeHandler) SetWebhookDispatcher(dispatcher *webhooks.Dispatcher) {
+	h.webhooks = dispatcher
+}
+
+// requireWebhookAdmin checks the caller may manage webhooks, which receive events for all recipes
+// It writes the error response itself and returns false when the caller should stop
+func requireWebhookAdmin(c *gin.Context) bool {
+	if middleware.GetUserID(c) == 0 {
+		AuthenticationError(c, "Authentication required to manage webhooks")
+		return false
+	}
+	if !middleware.IsAdmin(c) {
+		AuthorizationError(c, "Only administrators can manage webhooks")
+		return false
+	}
+	return true
+}
+
+// generateWebhookSecret returns a random hex secret for signing deliveries
+func generateWebhookSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(secret), nil
+}
+
+// uniqueEvents removes repeated event names while keeping their order
+func uniqueEvents(events []string) []string {
+	seen := make(map[string]bool, len(events))
+	unique := make([]string, 0, len(events))
+	for _, event := range events {
+		if !seen[event] {
+			seen[event] = true
+			unique = append(unique, event)
+		}
+	}
+	return unique
+}
+
+// CreateWebhook handles POST /webhooks requests
+func (h *WebhookHandler) CreateWebhook(c *gin.Context) {
+	logger := middleware.LogWithContext(c)
+
+	if !requireWebhookAdmin(c) {
+		return
+	}
+
+	var input models.WebhookInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		logger.WithError(err).Warn("Create webhook binding failed")
+		if isBodyTooLarge(err) {
+			RequestTooLargeError(c, "Request body too large")
+			return
+		}
+		ValidationError(c, "Invalid request format. url and events are required; events may be recipe.review_required or recipe.published.")
+		return
+	}
+
+	target, err := url.Parse(input.URL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		ValidationError(c, "url must be an absolute http or https URL", "url")
+		return
+	}
+
+	secret := input.Secret
+	if secret == "" {
+		if secret, err = generateWebhookSecret(); err != nil {
+			InternalServerError(c, "Failed to generate webhook secret")
+			return
+		}
+	}
+
+	createdBy := middleware.GetUserID(c)
+	registration := models.WebhookRegistration{Secret: secret}
+	err = h.db.DB.QueryRow(`
+		INSERT INTO webhooks (url, secret, events, created_by)
+		VALUES ($1, $2, $3, (SELECT id FROM users WHERE id = $4))
+		RETURNING id, url, events, created_by, created_at
+	`, target.String(), secret, pq.Array(uniqueEvents(input.Events)), createdBy).Scan(
+		&registration.ID,
+		&registration.URL,
+		pq.Array(&registration.Events),
+		&registration.CreatedBy,
+		&registration.CreatedAt,
+	)
+	if err != nil {
+		DatabaseError(c, err, "create webhook")
+		return
+	}
+
+	logger.WithFields(logrus.Fields{
+		"webhook_id": registration.ID,
+		"events":     registration.Events,
+	}).Info("Webhook registered")
+
+	SuccessResponse(c, registration)
+}
+
+// GetWebhooks handles GET /webhooks requests; secrets are never listed
+func (h *WebhookHandler) GetWebhooks(c *gin.Context) {
+	if !requireWebhookAdmin(c) {
+		return
+	}
+
+	rows, err := h.db.DB.Query(`
+		SELECT id, url, events, created_by, created_at FROM webhooks ORDER BY id
+	`)
+	if err != nil {
+		DatabaseError(c, err, "list webhooks")
+		return
+	}
+	defer rows.Close()
+
+	hooks := []models.Webhook{}
+	for rows.Next() {
+		var hook models.Webhook
+		if err := rows.Scan(&hook.ID, &hook.URL, pq.Array(&hook.Events), &hook.CreatedBy, &hook.CreatedAt); err != nil {
+			DatabaseError(c, err, "read webhook")
+			return
+		}
+		hooks = append(hooks, hook)
+	}
+	if err := rows.Err(); err != nil {
+		DatabaseError(c, err, "read webhooks")
+		return
+	}
+
+	SuccessResponse(c, hooks)
+}
+
+// DeleteWebhook handles DELETE /webhooks/:id requests
+func (h *WebhookHandler) DeleteWebhook(c *gin.Context) {
+	logger := middleware.LogWithContext(c)
+
+	webhookID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		BadRequestError(c, "invalid webhook ID")
+		return
+	}
+
+	if !requireWebhookAdmin(c) {
+		return
+	}
+
+	var deletedID int
+	err = h.db.DB.QueryRow(`DELETE FROM webhooks WHERE id = $1 RETURNING id`, webhookID).Scan(&deletedID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			NotFoundError(c, "webhook not found")
+			return
+		}
+		DatabaseError(c, err, "delete webhook")
+		return
+	}
+
+	logger.WithField("webhook_id", deletedID).Info("Webhook unregistered")
+
+	SuccessResponse(c, gin.H{"id": deletedID, "deleted": true})
+}
@@ -0,0 +1,55 @@
+package ingredients
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Patterns used to strip preparation notes from ingredient text
+var (
+	parentheticalPattern = regexp.MustCompile(`\([^)]*\)`)
+	whitespacePattern    = regexp.MustCompile(`\s+`)
+)
+
+// descriptorWords are size and form words that do not distinguish ingredients
+var descriptorWords = map[string]bool{
+	"whole": true, "large": true, "medium": true, "small": true, "of": true,
+}
+
+// NormalizeName reduces free-form ingredient text to a comparable ingredient name
+// "2 cups All-Purpose Flour, sifted" becomes "all-purpose flour"
+func NormalizeName(text string) string {
+	name := strings.ToLower(text)
+
+	// Preparation notes follow a comma ("eggs, beaten") and parentheses hold asides
+	if idx := strings.Index(name, ","); idx >= 0 {
+		name = name[:idx]
+	}
+	name = parentheticalPattern.ReplaceAllString(name, " ")
+
+	// Drop quantity and unit, then any leading descriptors
+	_, _, name = ParseIngredientLine(name)
+	words := strings.Fields(name)
+	start := 0
+	for start < len(words) && descriptorWords[words[start]] {
+		start++
+	}
+
+	name = strings.Join(words[start:], " ")
+	return strings.TrimSpace(whitespacePattern.ReplaceAllString(name, " "))
+}
+
+// NameCandidates returns the normalized name plus a naive singular form for matching
+// Matching "eggs" against an existing "Egg" avoids creating plural duplicates
+func NameCandidates(name string) []string {
+	candidates := []string{name}
+	switch {
+	case strings.HasSuffix(name, "ies") && len(name) > 3:
+		candidates = append(candidates, strings.TrimSuffix(name, "ies")+"y")
+	case strings.HasSuffix(name, "oes") && len(name) > 3:
+		candidates = append(candidates, strings.TrimSuffix(name, "es"))
+	case strings.HasSuffix(name, "s") && !strings.HasSuffix(name, "ss") && len(name) > 1:
+		candidates = append(candidates, strings.TrimSuffix(name, "s"))
+	}
+	return candidates
+}
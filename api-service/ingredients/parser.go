@@ -0,0 +1,200 @@
+package ingredients
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// unicodeFractions maps vulgar fraction characters to their values
+var unicodeFractions = map[rune]float64{
+	'½': 1.0 / 2,
+	'⅓': 1.0 / 3,
+	'⅔': 2.0 / 3,
+	'¼': 1.0 / 4,
+	'¾': 3.0 / 4,
+	'⅕': 1.0 / 5,
+	'⅖': 2.0 / 5,
+	'⅗': 3.0 / 5,
+	'⅘': 4.0 / 5,
+	'⅙': 1.0 / 6,
+	'⅚': 5.0 / 6,
+	'⅛': 1.0 / 8,
+	'⅜': 3.0 / 8,
+	'⅝': 5.0 / 8,
+	'⅞': 7.0 / 8,
+}
+
+// unitAliases maps the spellings found in recipes to the canonical unit stored in recipe_ingredients
+var unitAliases = map[string]string{
+	"cup": "cup", "cups": "cup", "c": "cup",
+	"tablespoon": "tbsp", "tablespoons": "tbsp", "tbsp": "tbsp", "tbs": "tbsp", "tbl": "tbsp",
+	"teaspoon": "tsp", "teaspoons": "tsp", "tsp": "tsp",
+	"gram": "g", "grams": "g", "g": "g",
+	"kilogram": "kg", "kilograms": "kg", "kg": "kg",
+	"milliliter": "ml", "milliliters": "ml", "millilitre": "ml", "millilitres": "ml", "ml": "ml",
+	"liter": "l", "liters": "l", "litre": "l", "litres": "l", "l": "l",
+	"ounce": "oz", "ounces": "oz", "oz": "oz",
+	"pound": "lb", "pounds": "lb", "lb": "lb", "lbs": "lb",
+	"pint": "pint", "pints": "pint", "pt": "pint",
+	"quart": "quart", "quarts": "quart", "qt": "quart",
+	"gallon": "gallon", "gallons": "gallon", "gal": "gallon",
+	"pinch": "pinch", "pinches": "pinch",
+	"dash": "dash", "dashes": "dash",
+	"clove": "clove", "cloves": "clove",
+	"can": "can", "cans": "can",
+	"stick": "stick", "sticks": "stick",
+	"slice": "slice", "slices": "slice",
+	"piece": "piece", "pieces": "piece",
+}
+
+// ParseIngredientLine splits ingredient text such as "2 cups all-purpose flour" into
+// quantity, canonical unit and the remaining name. Quantity and unit are nil when the
+// text does not start with them. Ranges like "2-3" resolve to their upper bound so
+// shopping lists never under-buy.
+func ParseIngredientLine(text string) (quantity *float64, unit *string, name string) {
+	words := strings.Fields(text)
+	i := 0
+
+	if len(words) > 0 {
+		amountText, attachedUnit := splitAttachedUnit(words[0])
+		if amount, ok := parseAmount(amountText); ok {
+			i = 1
+
+			if attachedUnit == "" && i < len(words) {
+				if fraction, ok := parseFraction(words[i]); ok && isWholeNumber(amountText) {
+					// Mixed number: "1 1/2" or "1 ½"
+					amount += fraction
+					i++
+				} else if isRangeSeparator(words[i]) && i+1 < len(words) {
+					// Spaced range: "2 - 3" or "2 to 3"
+					if upper, ok := parseAmount(words[i+1]); ok && upper >= amount {
+						amount = upper
+						i += 2
+					}
+				}
+			}
+
+			quantity = &amount
+			if attachedUnit != "" {
+				unit = &attachedUnit
+			}
+		}
+	}
+
+	if unit == nil && i < len(words) {
+		if canonical, ok := lookupUnit(words[i]); ok {
+			// Without a quantity only "<unit> of" is trusted, e.g. "pinch of salt"
+			if quantity != nil || (i+1 < len(words) && strings.EqualFold(words[i+1], "of")) {
+				unit = &canonical
+				i++
+			}
+		}
+	}
+
+	if unit != nil && i < len(words) && strings.EqualFold(words[i], "of") {
+		i++
+	}
+
+	return quantity, unit, strings.Join(words[i:], " ")
+}
+
+// lookupUnit returns the canonical unit for a word, ignoring case and a trailing period
+func lookupUnit(word string) (string, bool) {
+	canonical, ok := unitAliases[strings.TrimSuffix(strings.ToLower(word), ".")]
+	return canonical, ok
+}
+
+// splitAttachedUnit separates a unit written directly after the number, as in "200g"
+func splitAttachedUnit(word string) (string, string) {
+	idx := strings.IndexFunc(word, func(r rune) bool {
+		return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z'
+	})
+	if idx <= 0 {
+		return word, ""
+	}
+	if canonical, ok := lookupUnit(word[idx:]); ok {
+		return word[:idx], canonical
+	}
+	return word, ""
+}
+
+// isRangeSeparator reports whether a word joins the two ends of a spaced range
+func isRangeSeparator(word string) bool {
+	return word == "-" || word == "–" || strings.EqualFold(word, "to")
+}
+
+// parseAmount parses a single quantity or a range such as "2-3", returning the upper bound
+func parseAmount(text string) (float64, bool) {
+	text = strings.ReplaceAll(text, "–", "-")
+	if lowerText, upperText, found := strings.Cut(text, "-"); found {
+		lower, ok := parseNumber(lowerText)
+		if !ok {
+			return 0, false
+		}
+		upper, ok := parseNumber(upperText)
+		if !ok || upper < lower {
+			return 0, false
+		}
+		return upper, true
+	}
+	return parseNumber(text)
+}
+
+// parseNumber parses whole numbers, decimals, "1/2" fractions and unicode fractions like "1½"
+func parseNumber(text string) (float64, bool) {
+	if text == "" {
+		return 0, false
+	}
+
+	if last, size := utf8.DecodeLastRuneInString(text); last != utf8.RuneError {
+		if fraction, ok := unicodeFractions[last]; ok {
+			whole := text[:len(text)-size]
+			if whole == "" {
+				return fraction, true
+			}
+			if !isWholeNumber(whole) {
+				return 0, false
+			}
+			value, _ := strconv.ParseFloat(whole, 64)
+			return value + fraction, true
+		}
+	}
+
+	if numerator, denominator, found := strings.Cut(text, "/"); found {
+		if !isWholeNumber(numerator) || !isWholeNumber(denominator) {
+			return 0, false
+		}
+		num, _ := strconv.ParseFloat(numerator, 64)
+		den, _ := strconv.ParseFloat(denominator, 64)
+		if den == 0 {
+			return 0, false
+		}
+		return num / den, true
+	}
+
+	// Restrict to digits and a decimal point so ParseFloat never accepts "inf" or "1e5"
+	if strings.Trim(text, "0123456789.") != "" || strings.Count(text, ".") > 1 || text == "." {
+		return 0, false
+	}
+	value, err := strconv.ParseFloat(text, 64)
+	return value, err == nil
+}
+
+// parseFraction parses a word that is only a fraction, as used in mixed numbers
+func parseFraction(word string) (float64, bool) {
+	if r, size := utf8.DecodeRuneInString(word); size == len(word) {
+		fraction, ok := unicodeFractions[r]
+		return fraction, ok
+	}
+	if !strings.Contains(word, "/") {
+		return 0, false
+	}
+	value, ok := parseNumber(word)
+	return value, ok && value < 1
+}
+
+// isWholeNumber reports whether text consists only of ASCII digits
+func isWholeNumber(text string) bool {
+	return text != "" && strings.Trim(text, "0123456789") == ""
+}
@@ -0,0 +1,27 @@
+package ingredients
+
+import "strings"
+
+// ParseServings finds the number of servings in free text such as "4", "4-6", "serves 4" or
+// "Makes 12 cookies", so recipes can be scaled. Like ingredient quantities, ranges resolve to
+// their upper bound. It returns nil when the text holds no positive amount.
+func ParseServings(text string) *float64 {
+	words := strings.Fields(text)
+	for i, word := range words {
+		amount, ok := parseAmount(strings.Trim(word, "(),:;"))
+		if !ok {
+			continue
+		}
+		// Spaced range: "4 - 6" or "4 to 6"
+		if i+2 < len(words) && isRangeSeparator(words[i+1]) {
+			if upper, ok := parseAmount(strings.Trim(words[i+2], "(),:;")); ok && upper >= amount {
+				amount = upper
+			}
+		}
+		if amount <= 0 {
+			return nil
+		}
+		return &amount
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package ingredients
+
+// Dimension groups units that can be converted into each other
+type Dimension string
+
+// Supported dimensions; counted units such as "clove" or "can" have none
+const (
+	Volume Dimension = "volume"
+	Mass   Dimension = "mass"
+)
+
+// unitFactor describes a canonical unit relative to its dimension's base unit (ml or g)
+type unitFactor struct {
+	dimension Dimension
+	toBase    float64
+}
+
+// unitFactors covers the canonical units produced by ParseIngredientLine, using US customary measures
+var unitFactors = map[string]unitFactor{
+	"ml":     {Volume, 1},
+	"l":      {Volume, 1000},
+	"tsp":    {Volume, 4.92892},
+	"tbsp":   {Volume, 14.7868},
+	"cup":    {Volume, 236.588},
+	"pint":   {Volume, 473.176},
+	"quart":  {Volume, 946.353},
+	"gallon": {Volume, 3785.41},
+	"g":      {Mass, 1},
+	"kg":     {Mass, 1000},
+	"oz":     {Mass, 28.3495},
+	"lb":     {Mass, 453.592},
+}
+
+// UnitDimension returns the dimension of a canonical unit
+func UnitDimension(unit string) (Dimension, bool) {
+	factor, ok := unitFactors[unit]
+	return factor.dimension, ok
+}
+
+// ConvertQuantity converts a quantity between canonical units
+// Identical units always convert; otherwise both units must share a dimension
+func ConvertQuantity(quantity float64, from, to string) (float64, bool) {
+	if from == to {
+		return quantity, true
+	}
+
+	fromFactor, ok := unitFactors[from]
+	if !ok {
+		return 0, false
+	}
+	toFactor, ok := unitFactors[to]
+	if !ok || fromFactor.dimension != toFactor.dimension {
+		return 0, false
+	}
+
+	return quantity * fromFactor.toBase / toFactor.toBase, true
+}
@@ -0,0 +1,27 @@
+// Package logctx carries a request-scoped logger through a context.Context, so storage, database and
+// webhook code logs with the same request fields as the handler that called it without depending on HTTP code
+package logctx
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+)
+
+// loggerKey is the context key under which a request-scoped logger is stored
+type loggerKey struct{}
+
+// WithLogger returns a copy of ctx carrying logger
+func WithLogger(ctx context.Context, logger *logrus.Entry) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// FromContext returns the logger carried by ctx, or the standard logger when there is none
+func FromContext(ctx context.Context) *logrus.Entry {
+	if ctx != nil {
+		if logger, ok := ctx.Value(loggerKey{}).(*logrus.Entry); ok && logger != nil {
+			return logger
+		}
+	}
+	return logrus.NewEntry(logrus.StandardLogger())
+}
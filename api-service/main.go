@@ -2,66 +2,20 @@ package main
 
 import (
 	"context"
-	"net/http"
 	"net/url"
 	"os"
 	"path/filepath"
-	"regexp"
 	"strings"
-	"time"
 
+	"digital-recipes/api-service/buildinfo"
 	"digital-recipes/api-service/db"
 	"digital-recipes/api-service/handlers"
 	"digital-recipes/api-service/middleware"
+	"digital-recipes/api-service/webhooks"
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
 )
 
-// isOriginAllowed performs secure origin validation with proper URL parsing
-func isOriginAllowed(origin, allowedOrigins string) bool {
-	// Parse the origin URL to validate it's well-formed
-	originURL, err := url.Parse(origin)
-	if err != nil {
-		// Malformed URL is not allowed
-		return false
-	}
-	
-	// Only allow http and https schemes
-	if originURL.Scheme != "http" && originURL.Scheme != "https" {
-		return false
-	}
-	
-	// Prevent subdomain bypasses by ensuring no wildcards in host
-	if strings.Contains(originURL.Host, "*") {
-		return false
-	}
-	
-	// Split allowed origins and validate each one
-	for _, allowedOrigin := range strings.Split(allowedOrigins, ",") {
-		allowedOrigin = strings.TrimSpace(allowedOrigin)
-		
-		// Skip empty origins
-		if allowedOrigin == "" {
-			continue
-		}
-		
-		// Parse allowed origin to ensure it's valid
-		allowedURL, err := url.Parse(allowedOrigin)
-		if err != nil {
-			continue // Skip malformed allowed origins
-		}
-		
-		// Exact match required (scheme, host, and port must match exactly)
-		if originURL.Scheme == allowedURL.Scheme &&
-		   originURL.Host == allowedURL.Host &&
-		   originURL.Port() == allowedURL.Port() {
-			return true
-		}
-	}
-	
-	return false
-}
-
 // validateEnvironment checks for required environment variables and security settings
 func validateEnvironment() {
 	// Validate JWT secret is set
@@ -87,11 +41,16 @@ func validateEnvironment() {
 	}
 }
 
-func main() {
-	// Validate environment before starting
-	validateEnvironment()
-	// Configure structured logging
-	logrus.SetFormatter(&logrus.JSONFormatter{})
+// setupLogging configures the log format, level and destination from the environment
+// LOG_FORMAT selects json (default) or text; LOG_FILE appends to a file instead of stdout.
+// The returned function closes the log file, if any.
+func setupLogging() func() {
+	formatter, formatErr := middleware.NewLogFormatter(os.Getenv("LOG_FORMAT"))
+	logrus.SetFormatter(formatter)
+	if formatErr != nil {
+		logrus.WithError(formatErr).Warn("Invalid log format, defaulting to json")
+	}
+
 	logLevel := os.Getenv("LOG_LEVEL")
 	if logLevel == "" {
 		logLevel = "info"
@@ -103,6 +62,26 @@ func main() {
 	}
 	logrus.SetLevel(level)
 
+	logFile := os.Getenv("LOG_FILE")
+	if logFile == "" {
+		return func() {}
+	}
+	file, err := middleware.OpenLogFile(logFile)
+	if err != nil {
+		logrus.WithError(err).Fatal("Failed to open log file")
+	}
+	logrus.SetOutput(file)
+	return func() { file.Close() }
+}
+
+func main() {
+	// Configure structured logging
+	closeLog := setupLogging()
+	defer closeLog()
+
+	// Validate environment before starting
+	validateEnvironment()
+
 	// Set Gin mode based on environment
 	if os.Getenv("GIN_MODE") != "debug" {
 		gin.SetMode(gin.ReleaseMode)
@@ -115,10 +94,23 @@ func main() {
 	}
 	defer database.Close()
 
-	// Run migrations
+	// Run migrations unless a separate migration job owns the schema
 	migrationsDir := filepath.Join("db", "migrations")
-	if err := database.RunMigrations(migrationsDir); err != nil {
-		logrus.WithError(err).Fatal("Failed to run migrations")
+	skipped, applied, err := database.StartupMigrations(migrationsDir)
+	switch {
+	case err != nil:
+		logrus.WithError(err).WithField("applied", applied).Fatal("Failed to run migrations")
+	case skipped:
+		logrus.Info("MIGRATE_ON_START=false, skipping migrations; the schema must be migrated out of band")
+	case applied == 0:
+		logrus.Info("Database schema is up to date")
+	default:
+		logrus.WithField("applied", applied).Info("Database migrations applied")
+	}
+
+	// Prepare hot queries once so requests reuse the parsed statements
+	if err := handlers.PrepareRecipeStatements(context.Background(), database); err != nil {
+		logrus.WithError(err).Warn("Failed to prepare recipe statements, they will be prepared on first use")
 	}
 
 	// Initialize authentication configuration
@@ -129,65 +121,31 @@ func main() {
 	}).Info("Authentication configured")
 
 	r := gin.New()
+
+	// Only listed proxies may set the client IP used for rate limiting and logs; see ConfigureTrustedProxies
+	trustedProxies := middleware.GetTrustedProxies()
+	if err := middleware.ConfigureTrustedProxies(r, trustedProxies); err != nil {
+		logrus.WithError(err).Fatal("Invalid TRUSTED_PROXIES")
+	}
+	logrus.WithField("trusted_proxies", trustedProxies).Info("Trusted proxies configured")
 	
 	// Add core middleware (order matters!)
 	r.Use(middleware.RequestIDMiddleware())
 	r.Use(middleware.StructuredLoggingMiddleware())
+	r.Use(middleware.RecoveryMiddleware()) // Inside request logging so recovered panics are logged as 500s
 	r.Use(middleware.SecurityLoggingMiddleware())
 	r.Use(middleware.CreateGeneralRateLimit())
-	r.Use(gin.Recovery())
+	r.Use(middleware.RequireJSONMiddleware()) // Before the body limit wraps empty bodies
+	r.Use(middleware.BodyLimitMiddleware(middleware.GetMaxBodyBytes()))
+	r.Use(middleware.GzipMiddleware(middleware.GetGzipMinBytes()))
+	r.Use(middleware.DebugBodyLoggingMiddleware(middleware.GetDebugLogBodies())) // Inside gzip so bodies are logged uncompressed
 	
 	// Add secure CORS middleware with strict origin validation
 	allowedOrigins := os.Getenv("ALLOWED_ORIGINS")
 	if allowedOrigins == "" {
 		allowedOrigins = "http://localhost:3000" // Development default
 	}
-	
-	r.Use(func(c *gin.Context) {
-		origin := c.Request.Header.Get("Origin")
-		allowed := false
-		
-		if origin != "" {
-			allowed = isOriginAllowed(origin, allowedOrigins)
-			
-			// Log CORS violations for security monitoring
-			if !allowed {
-				logrus.WithFields(logrus.Fields{
-					"origin":      origin,
-					"client_ip":   c.ClientIP(),
-					"request_id":  c.GetHeader("X-Request-ID"),
-					"user_agent":  c.GetHeader("User-Agent"),
-					"method":      c.Request.Method,
-					"path":        c.Request.URL.Path,
-				}).Warn("CORS violation: Origin not allowed")
-			}
-		}
-		
-		if allowed {
-			c.Header("Access-Control-Allow-Origin", origin)
-			c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-			c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Authorization, X-Request-ID")
-			c.Header("Access-Control-Allow-Credentials", "true")
-			c.Header("Access-Control-Max-Age", "86400") // 24 hours
-		}
-		
-		if c.Request.Method == "OPTIONS" {
-			if allowed {
-				c.AbortWithStatus(204)
-			} else {
-				c.AbortWithStatus(403)
-			}
-			return
-		}
-		
-		if !allowed && origin != "" {
-			c.JSON(http.StatusForbidden, gin.H{"error": "Origin not allowed"})
-			c.Abort()
-			return
-		}
-		
-		c.Next()
-	})
+	r.Use(middleware.CORSMiddleware(allowedOrigins, middleware.GetCORSMaxAge()))
 	
 	// Initialize storage service
 	storageService, err := handlers.NewStorageService()
@@ -205,56 +163,163 @@ func main() {
 		}).Info("Google Cloud Storage service initialized successfully")
 	}
 
+	// Lifetime bounds for pre-signed upload URLs
+	uploadExpiryConfig, err := handlers.GetUploadURLExpiryConfig()
+	if err != nil {
+		logrus.WithError(err).Fatal("Invalid upload URL expiration configuration")
+	}
+	logrus.WithFields(logrus.Fields{
+		"default_hours": uploadExpiryConfig.DefaultHours,
+		"max_hours":     uploadExpiryConfig.MaxHours,
+	}).Info("Upload URL expiration configured")
+	if storageService != nil {
+		storageService.SetUploadURLExpiry(uploadExpiryConfig)
+	}
+
+	// File extensions accepted for uploads on top of each image type's own
+	extraImageExtensions, err := handlers.GetExtraImageExtensions()
+	if err == nil {
+		err = handlers.SetExtraImageExtensions(extraImageExtensions)
+	}
+	if err != nil {
+		logrus.WithError(err).Fatal("Invalid EXTRA_IMAGE_EXTENSIONS")
+	}
+	if len(extraImageExtensions) > 0 {
+		logrus.WithField("extra_extensions", extraImageExtensions).Info("Extra image file extensions configured")
+	}
+
+	// Page size bounds for list endpoints
+	paginationConfig, err := handlers.GetPaginationConfig()
+	if err != nil {
+		logrus.WithError(err).Fatal("Invalid pagination configuration")
+	}
+	logrus.WithFields(logrus.Fields{
+		"default_per_page": paginationConfig.DefaultPerPage,
+		"max_per_page":     paginationConfig.MaxPerPage,
+		"max_offset":       paginationConfig.MaxOffset,
+	}).Info("Pagination configured")
+
 	// Initialize handlers
 	recipeHandler := handlers.NewRecipeHandler(database, storageService)
+	ingredientHandler := handlers.NewIngredientHandler(database)
+	webhookHandler := handlers.NewWebhookHandler(database)
+	userHandler := handlers.NewUserHandler(database, storageService)
+	recipeHandler.SetWebhookDispatcher(webhooks.NewDispatcher(database))
+	recipeHandler.SetPagination(paginationConfig)
+	recipeHandler.SetMaxIngredients(handlers.GetMaxIngredientsPerRecipe())
+	userHandler.SetPagination(paginationConfig)
+	ingredientHandler.SetPagination(paginationConfig)
 	
-	r.GET("/health", func(c *gin.Context) {
-		// Check database health
-		dbStatus := "healthy"
-		if err := database.HealthCheck(); err != nil {
-			dbStatus = "unhealthy"
-			logrus.WithError(err).Error("Database health check failed")
-		}
+	healthHandler := handlers.NewHealthHandler(database, storageService)
+	healthHandler.SetCacheTTL(handlers.GetHealthCacheTTL())
 
-		// Check storage health if available
-		storageStatus := "not_configured"
-		if storageService != nil {
-			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-			defer cancel()
-			if err := storageService.HealthCheck(ctx); err != nil {
-				storageStatus = "unhealthy"
-				logrus.WithError(err).Warn("Storage health check failed")
-			} else {
-				storageStatus = "healthy"
-			}
-		}
+	statsHandler := handlers.NewStatsHandler(database)
+	statsHandler.SetCacheTTL(handlers.GetStatsCacheTTL())
+
+	r.GET("/health", healthHandler.GetHealth)
 
-		c.JSON(http.StatusOK, gin.H{
-			"status":   "healthy",
-			"service":  "digital-recipes-api",
-			"database": dbStatus,
-			"storage":  storageStatus,
-			"version":  "1.0.0",
-		})
-	})
+	// Machine-readable API contract
+	r.GET("/openapi.json", handlers.GetOpenAPISpec)
 
 	// Public API routes (no authentication required)
 	public := r.Group("/api/v1")
+	public.Use(middleware.IdentifyUserMiddleware(authConfig))
 	{
 		public.GET("/recipes", recipeHandler.GetRecipes)
+		public.GET("/recipes/featured", recipeHandler.GetFeaturedRecipes)
 		public.GET("/recipes/:id", recipeHandler.GetRecipe)
+		public.GET("/recipes/:id/ingredients", recipeHandler.GetRecipeIngredients)
+		public.GET("/recipes/:id/images", recipeHandler.GetRecipeImages)
+		public.GET("/recipes/:id/similar", recipeHandler.GetSimilarRecipes)
+
+		// Export endpoints
+		public.GET("/recipes/:id/jsonld", recipeHandler.GetRecipeJSONLD)
+		public.GET("/recipes/:id/export", recipeHandler.ExportRecipe)
+
+		// Meal planning endpoints
+		public.POST("/shopping-list", recipeHandler.CreateShoppingList)
+
+		// Share links open a recipe whatever its visibility
+		public.GET("/shared/:token", recipeHandler.GetSharedRecipe)
 	}
 
+	// Recipe writes share one per-user budget so a single account cannot flood the recipes table
+	recipeRateLimit := middleware.GetRecipeRateLimit()
+	recipeWriteLimit := middleware.CreateRecipeRateLimit(recipeRateLimit)
+	logrus.WithField("per_minute", recipeRateLimit).Info("Recipe write rate limit configured")
+
 	// Protected API routes (authentication required)
 	protected := r.Group("/api/v1")
 	protected.Use(middleware.OptionalAuthMiddleware(authConfig)) // Optional for backwards compatibility
 	{
-		// Upload endpoints with additional rate limiting
+		// Upload endpoints with additional rate and body size limits
 		uploadGroup := protected.Group("/recipes")
 		uploadGroup.Use(middleware.CreateUploadRateLimit())
+		uploadGroup.Use(middleware.BodyLimitMiddleware(middleware.GetMaxUploadRequestBodyBytes()))
 		{
 			uploadGroup.POST("/upload-request", recipeHandler.PostUploadRequest)
 		}
+
+		// Import endpoints
+		protected.POST("/recipes/import", recipeWriteLimit, recipeHandler.ImportRecipe)
+		protected.POST("/recipes/:id/duplicate", recipeWriteLimit, recipeHandler.DuplicateRecipe)
+
+		// Editing and revision history endpoints
+		protected.PUT("/recipes/:id", recipeWriteLimit, recipeHandler.UpdateRecipe)
+		protected.PATCH("/recipes/:id", recipeWriteLimit, recipeHandler.PatchRecipe)
+		protected.GET("/recipes/:id/revisions", recipeHandler.GetRecipeRevisions)
+		protected.POST("/recipes/:id/revisions/:revisionId/restore", recipeWriteLimit, recipeHandler.RestoreRecipeRevision)
+
+		// Draft endpoints; autosaves only touch the drafts table, so they are not held to the recipe write limit
+		protected.PUT("/recipes/:id/draft", recipeHandler.SaveRecipeDraft)
+		protected.GET("/recipes/:id/draft", recipeHandler.GetRecipeDraft)
+		protected.POST("/recipes/:id/draft/promote", recipeWriteLimit, recipeHandler.PromoteRecipeDraft)
+
+		// Share link endpoints
+		protected.POST("/recipes/:id/share", recipeWriteLimit, recipeHandler.CreateRecipeShare)
+		protected.GET("/recipes/:id/share", recipeHandler.GetRecipeShares)
+		protected.DELETE("/recipes/:id/share/:shareId", recipeHandler.RevokeRecipeShare)
+
+		// Status streaming endpoints
+		protected.GET("/recipes/:id/status/stream", recipeHandler.StreamRecipeStatus)
+
+		// Batch review endpoints
+		protected.POST("/recipes/batch/status", recipeWriteLimit, recipeHandler.BatchUpdateRecipeStatus)
+
+		// Review workflow endpoints
+		protected.POST("/recipes/:id/ingredients", recipeWriteLimit, recipeHandler.AddRecipeIngredient)
+		protected.PUT("/recipes/:id/ingredients", recipeWriteLimit, recipeHandler.ReplaceRecipeIngredients)
+		protected.PATCH("/recipes/:id/ingredients/:ingredientId", recipeWriteLimit, recipeHandler.LinkRecipeIngredient)
+		protected.POST("/ingredients/link-or-create", ingredientHandler.LinkOrCreate)
+		protected.GET("/ingredients", middleware.RequireRole(middleware.RoleAdmin), ingredientHandler.GetIngredients)
+		protected.POST("/ingredients", middleware.RequireRole(middleware.RoleAdmin), ingredientHandler.CreateIngredient)
+		protected.PATCH("/ingredients/:id", middleware.RequireRole(middleware.RoleAdmin), ingredientHandler.RenameIngredient)
+		protected.POST("/ingredients/merge", middleware.RequireRole(middleware.RoleAdmin), ingredientHandler.MergeIngredients)
+
+		// Homepage curation endpoints
+		protected.PATCH("/recipes/:id/feature", middleware.RequireRole(middleware.RoleAdmin), recipeHandler.FeatureRecipe)
+
+		// Image confirmation and ordering endpoints
+		protected.POST("/recipes/:id/images/confirm", recipeWriteLimit, recipeHandler.ConfirmRecipeImage)
+		protected.PUT("/recipes/:id/images/order", recipeWriteLimit, recipeHandler.ReorderRecipeImages)
+		protected.PUT("/recipes/:id/images/:imageId/primary", recipeWriteLimit, recipeHandler.SetPrimaryRecipeImage)
+
+		// Webhook endpoints
+		protected.POST("/webhooks", webhookHandler.CreateWebhook)
+		protected.GET("/webhooks", webhookHandler.GetWebhooks)
+		protected.DELETE("/webhooks/:id", webhookHandler.DeleteWebhook)
+
+		// User administration endpoints
+		protected.GET("/users", middleware.RequireRole(middleware.RoleAdmin), userHandler.GetUsers)
+
+		// Dashboard endpoints
+		protected.GET("/stats", middleware.RequireRole(middleware.RoleAdmin), statsHandler.GetStats)
+
+		// Profile endpoints
+		protected.GET("/users/me", userHandler.GetCurrentUser)
+		protected.PATCH("/users/me", userHandler.UpdateCurrentUser)
+		protected.POST("/users/me/password", userHandler.ChangePassword)
+		protected.DELETE("/users/me", userHandler.DeleteCurrentUser)
 	}
 
 	port := os.Getenv("PORT")
@@ -262,8 +327,12 @@ func main() {
 		port = "8080"
 	}
 
+	build := buildinfo.Get()
 	logrus.WithFields(logrus.Fields{
 		"port":            port,
+		"version":         build.Version,
+		"commit":          build.Commit,
+		"build_time":      build.BuildTime,
 		"database_status": "connected",
 		"storage_status":  func() string {
 			if storageService != nil {
@@ -17,9 +17,16 @@ type Claims struct {
 	UserID   int    `json:"user_id"`
 	Email    string `json:"email"`
 	Name     string `json:"name"`
+	Role     string `json:"role,omitempty"`
 	jwt.RegisteredClaims
 }
 
+// User roles carried in the JWT role claim
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
 // AuthConfig holds authentication configuration
 type AuthConfig struct {
 	JWTSecret     string
@@ -59,6 +66,17 @@ func NewAuthConfig() *AuthConfig {
 	}
 }
 
+// bearerToken extracts the token from an Authorization header of the form "Bearer <token>"
+// The scheme is matched case-insensitively and surrounding or repeated whitespace is ignored;
+// headers with another scheme, no token or anything after the token are rejected
+func bearerToken(authHeader string) (string, bool) {
+	fields := strings.Fields(authHeader)
+	if len(fields) != 2 || !strings.EqualFold(fields[0], "Bearer") {
+		return "", false
+	}
+	return fields[1], true
+}
+
 // AuthMiddleware creates JWT authentication middleware
 func AuthMiddleware(config *AuthConfig) gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -80,8 +98,8 @@ func AuthMiddleware(config *AuthConfig) gin.HandlerFunc {
 		}
 
 		// Extract Bearer token
-		parts := strings.SplitN(authHeader, " ", 2)
-		if len(parts) != 2 || parts[0] != "Bearer" {
+		tokenString, ok := bearerToken(authHeader)
+		if !ok {
 			logrus.WithFields(logrus.Fields{
 				"ip":         c.ClientIP(),
 				"request_id": c.GetHeader("X-Request-ID"),
@@ -94,8 +112,6 @@ func AuthMiddleware(config *AuthConfig) gin.HandlerFunc {
 			return
 		}
 
-		tokenString := parts[1]
-
 		// Parse and validate token
 		token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
 			// Validate signing method
@@ -135,9 +151,7 @@ func AuthMiddleware(config *AuthConfig) gin.HandlerFunc {
 		}
 
 		// Store user information in context
-		c.Set("user_id", claims.UserID)
-		c.Set("user_email", claims.Email)
-		c.Set("user_name", claims.Name)
+		setUserContext(c, claims)
 
 		logrus.WithFields(logrus.Fields{
 			"user_id":    claims.UserID,
@@ -190,8 +204,8 @@ func OptionalAuthMiddleware(config *AuthConfig) gin.HandlerFunc {
 		}
 
 		// Run auth middleware logic
-		parts := strings.SplitN(authHeader, " ", 2)
-		if len(parts) != 2 || parts[0] != "Bearer" {
+		tokenString, ok := bearerToken(authHeader)
+		if !ok {
 			if isDevelopment {
 				// Development: fallback to default user
 				c.Set("user_id", 1)
@@ -209,7 +223,6 @@ func OptionalAuthMiddleware(config *AuthConfig) gin.HandlerFunc {
 			}
 		}
 
-		tokenString := parts[1]
 		token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
 			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
@@ -219,9 +232,7 @@ func OptionalAuthMiddleware(config *AuthConfig) gin.HandlerFunc {
 
 		if err == nil && token.Valid {
 			if claims, ok := token.Claims.(*Claims); ok {
-				c.Set("user_id", claims.UserID)
-				c.Set("user_email", claims.Email)
-				c.Set("user_name", claims.Name)
+				setUserContext(c, claims)
 			}
 		} else {
 			if isDevelopment {
@@ -255,12 +266,63 @@ func OptionalAuthMiddleware(config *AuthConfig) gin.HandlerFunc {
 	}
 }
 
-// GenerateToken creates a new JWT token for a user
+// IdentifyUserMiddleware sets the user context when a valid bearer token is present
+// Unlike AuthMiddleware it never rejects the request, so public routes can tailor
+// responses to the caller (for example letting owners see their unpublished recipes)
+func IdentifyUserMiddleware(config *AuthConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tokenString, ok := bearerToken(c.GetHeader("Authorization"))
+		if !ok {
+			c.Next()
+			return
+		}
+
+		token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
+			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+			}
+			return []byte(config.JWTSecret), nil
+		})
+
+		if err == nil && token.Valid {
+			if claims, ok := token.Claims.(*Claims); ok {
+				setUserContext(c, claims)
+			}
+		} else {
+			logrus.WithFields(logrus.Fields{
+				"ip":         c.ClientIP(),
+				"request_id": c.GetHeader("X-Request-ID"),
+			}).Debug("Ignoring invalid token on public route")
+		}
+
+		c.Next()
+	}
+}
+
+// setUserContext stores the authenticated user's claims in the gin context
+func setUserContext(c *gin.Context, claims *Claims) {
+	role := claims.Role
+	if role == "" {
+		role = RoleUser
+	}
+	c.Set("user_id", claims.UserID)
+	c.Set("user_email", claims.Email)
+	c.Set("user_name", claims.Name)
+	c.Set("user_role", role)
+}
+
+// GenerateToken creates a new JWT token for a user with the default role
 func GenerateToken(config *AuthConfig, userID int, email, name string) (string, error) {
+	return GenerateTokenWithRole(config, userID, email, name, RoleUser)
+}
+
+// GenerateTokenWithRole creates a new JWT token for a user with the given role
+func GenerateTokenWithRole(config *AuthConfig, userID int, email, name, role string) (string, error) {
 	claims := Claims{
 		UserID: userID,
 		Email:  email,
 		Name:   name,
+		Role:   role,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(config.TokenDuration)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
@@ -301,4 +363,51 @@ func GetUserName(c *gin.Context) string {
 		}
 	}
 	return ""
-}
\ No newline at end of file
+}
+
+// GetUserRole extracts the user role from gin context, defaulting to the regular user role
+func GetUserRole(c *gin.Context) string {
+	if role, exists := c.Get("user_role"); exists {
+		if roleStr, ok := role.(string); ok && roleStr != "" {
+			return roleStr
+		}
+	}
+	return RoleUser
+}
+
+// IsAdmin reports whether the authenticated user has the admin role
+func IsAdmin(c *gin.Context) bool {
+	return GetUserRole(c) == RoleAdmin
+}
+
+// RequireRole rejects requests whose authenticated user does not have the given role
+// It must run after a middleware that sets the user context, such as AuthMiddleware
+func RequireRole(role string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if GetUserID(c) == 0 {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "Authentication required",
+			})
+			c.Abort()
+			return
+		}
+
+		if GetUserRole(c) != role {
+			logrus.WithFields(logrus.Fields{
+				"user_id":       GetUserID(c),
+				"role":          GetUserRole(c),
+				"required_role": role,
+				"path":          c.Request.URL.Path,
+				"request_id":    GetRequestID(c),
+			}).Warn("Insufficient role for endpoint")
+
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": "Insufficient permissions",
+			})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultMaxBodyBytes is the request body cap used when MAX_BODY_BYTES is not set
+const DefaultMaxBodyBytes int64 = 1 << 20 // 1MB
+
+// DefaultMaxUploadRequestBodyBytes is the upload request body cap used when MAX_UPLOAD_REQUEST_BODY_BYTES is not set
+// An upload request is a few numbers and at most a handful of short type lists, so it needs far less than other writes
+const DefaultMaxUploadRequestBodyBytes int64 = 4 << 10 // 4KB
+
+// GetMaxBodyBytes returns the configured request body limit from MAX_BODY_BYTES
+func GetMaxBodyBytes() int64 {
+	return getBodyLimit("MAX_BODY_BYTES", DefaultMaxBodyBytes)
+}
+
+// GetMaxUploadRequestBodyBytes returns the configured upload request body limit from MAX_UPLOAD_REQUEST_BODY_BYTES
+// It applies on top of MAX_BODY_BYTES, so the smaller of the two wins
+func GetMaxUploadRequestBodyBytes() int64 {
+	return getBodyLimit("MAX_UPLOAD_REQUEST_BODY_BYTES", DefaultMaxUploadRequestBodyBytes)
+}
+
+// getBodyLimit reads a positive byte count from the named environment variable
+func getBodyLimit(name string, defaultBytes int64) int64 {
+	value := os.Getenv(name)
+	if value == "" {
+		return defaultBytes
+	}
+
+	maxBytes, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || maxBytes <= 0 {
+		logrus.WithField(strings.ToLower(name), value).Warn("Invalid " + name + ", using default")
+		return defaultBytes
+	}
+	return maxBytes
+}
+
+// BodyLimitMiddleware caps the size of request bodies on write requests, DELETE included
+// Requests declaring a larger Content-Length are rejected with 413 up front; bodies of
+// unknown length are wrapped with http.MaxBytesReader so reads fail once the cap is hit
+func BodyLimitMiddleware(maxBytes int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Only methods that carry a body need to be limited
+		switch c.Request.Method {
+		case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		default:
+			c.Next()
+			return
+		}
+
+		if c.Request.ContentLength > maxBytes {
+			logrus.WithFields(logrus.Fields{
+				"content_length": c.Request.ContentLength,
+				"max_bytes":      maxBytes,
+				"ip":             c.ClientIP(),
+				"path":           c.Request.URL.Path,
+				"request_id":     c.GetHeader("X-Request-ID"),
+			}).Warn("Request body too large")
+
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{
+				"error": "Request body too large",
+			})
+			c.Abort()
+			return
+		}
+
+		if c.Request.Body != nil {
+			c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes)
+		}
+
+		c.Next()
+	}
+}
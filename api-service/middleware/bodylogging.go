@@ -0,0 +1,171 @@
+package middleware
+
+import (
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// DebugBodyLogMaxBytes is how much of each request and response body DebugBodyLoggingMiddleware logs
+const DebugBodyLogMaxBytes = 4 << 10 // 4KB
+
+// redacted replaces sensitive header and field values in body logs
+const redacted = "[REDACTED]"
+
+// sensitiveHeaders are request headers whose values never appear in body logs
+var sensitiveHeaders = map[string]bool{
+	"Authorization":       true,
+	"Proxy-Authorization": true,
+	"Cookie":              true,
+	"X-Api-Key":           true,
+}
+
+// sensitiveFieldPattern matches JSON string fields whose name mentions a password, secret or token
+// A value cut off by the size cap runs to the end of the text, so it is matched and hidden too
+var sensitiveFieldPattern = regexp.MustCompile(`"([^"\\]*(?i:password|secret|token)[^"\\]*)"\s*:\s*"(?:[^"\\]|\\.)*(?:"|\\?$)`)
+
+// GetDebugLogBodies reports whether DEBUG_LOG_BODIES turns on request and response body logging
+// It is only honoured with GIN_MODE=debug, so a setting left over from debugging cannot log bodies in production
+func GetDebugLogBodies() bool {
+	value := os.Getenv("DEBUG_LOG_BODIES")
+	if value == "" {
+		return false
+	}
+
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		logrus.WithField("debug_log_bodies", value).Warn("Invalid DEBUG_LOG_BODIES, body logging stays off")
+		return false
+	}
+	if enabled && os.Getenv("GIN_MODE") != "debug" {
+		logrus.Warn("DEBUG_LOG_BODIES is ignored unless GIN_MODE=debug")
+		return false
+	}
+	if enabled {
+		logrus.WithField("max_bytes", DebugBodyLogMaxBytes).Warn("Request and response bodies are being logged")
+	}
+	return enabled
+}
+
+// DebugBodyLoggingMiddleware logs each request's headers and body along with the response body, for debugging
+// client integrations. It does nothing unless enabled. Bodies are captured as they are read and written, so a
+// request body the handler never reads is not logged. Only JSON and text bodies are logged, up to
+// DebugBodyLogMaxBytes each; other content such as images is reported by size alone. Credentials in
+// headers and password, secret and token fields are redacted
+func DebugBodyLoggingMiddleware(enabled bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !enabled {
+			c.Next()
+			return
+		}
+
+		request := &bodyCapture{}
+		if c.Request.Body != nil {
+			c.Request.Body = &capturingReadCloser{ReadCloser: c.Request.Body, capture: request}
+		}
+		writer := &capturingResponseWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		defer func() { c.Writer = writer.ResponseWriter }()
+
+		c.Next()
+
+		fields := logrus.Fields{
+			"status":          writer.Status(),
+			"request_headers": redactHeaders(c.Request.Header),
+		}
+		addBodyFields(fields, "request", c.Request.Header.Get("Content-Type"), request)
+		addBodyFields(fields, "response", writer.Header().Get("Content-Type"), &writer.capture)
+		LogWithContext(c).WithFields(fields).Info("Request and response bodies")
+	}
+}
+
+// bodyCapture keeps the start of a body and counts its full length
+type bodyCapture struct {
+	data  []byte
+	total int
+}
+
+func (b *bodyCapture) record(data []byte) {
+	b.total += len(data)
+	if room := DebugBodyLogMaxBytes - len(b.data); room > 0 {
+		b.data = append(b.data, data[:min(room, len(data))]...)
+	}
+}
+
+// capturingReadCloser records a request body as the handler reads it
+type capturingReadCloser struct {
+	io.ReadCloser
+	capture *bodyCapture
+}
+
+func (r *capturingReadCloser) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.capture.record(p[:n])
+	return n, err
+}
+
+// capturingResponseWriter records a response body as it is written
+type capturingResponseWriter struct {
+	gin.ResponseWriter
+	capture bodyCapture
+}
+
+func (w *capturingResponseWriter) Write(data []byte) (int, error) {
+	w.capture.record(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *capturingResponseWriter) WriteString(s string) (int, error) {
+	w.capture.record([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+// redactHeaders flattens headers for logging with credentials replaced
+func redactHeaders(header http.Header) map[string]string {
+	flattened := make(map[string]string, len(header))
+	for name, values := range header {
+		if sensitiveHeaders[http.CanonicalHeaderKey(name)] {
+			flattened[name] = redacted
+			continue
+		}
+		flattened[name] = strings.Join(values, ", ")
+	}
+	return flattened
+}
+
+// addBodyFields adds a captured body to fields under the given prefix
+func addBodyFields(fields logrus.Fields, prefix, contentType string, capture *bodyCapture) {
+	fields[prefix+"_body_bytes"] = capture.total
+	if capture.total == 0 {
+		return
+	}
+	if !loggableContentType(contentType) {
+		fields[prefix+"_body"] = "[" + strconv.Itoa(capture.total) + " bytes of " + contentType + " omitted]"
+		return
+	}
+	fields[prefix+"_body"] = redactBody(string(capture.data))
+	if capture.total > len(capture.data) {
+		fields[prefix+"_body_truncated"] = true
+	}
+}
+
+// loggableContentType reports whether a body of this type is text worth logging; binary bodies never are
+func loggableContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return strings.HasPrefix(mediaType, "text/") || mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// redactBody hides the values of sensitive JSON fields
+func redactBody(body string) string {
+	return sensitiveFieldPattern.ReplaceAllString(body, `"$1":"`+redacted+`"`)
+}
@@ -0,0 +1,178 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultGzipMinBytes is the smallest response compressed when GZIP_MIN_BYTES is not set
+const DefaultGzipMinBytes = 1024
+
+// GetGzipMinBytes returns the response size from which bodies are compressed, from GZIP_MIN_BYTES
+func GetGzipMinBytes() int {
+	value := os.Getenv("GZIP_MIN_BYTES")
+	if value == "" {
+		return DefaultGzipMinBytes
+	}
+
+	minBytes, err := strconv.Atoi(value)
+	if err != nil || minBytes <= 0 {
+		logrus.WithField("gzip_min_bytes", value).Warn("Invalid GZIP_MIN_BYTES, using default")
+		return DefaultGzipMinBytes
+	}
+	return minBytes
+}
+
+// GzipMiddleware compresses response bodies of at least minBytes for clients that accept gzip
+// The start of the body is held back until minBytes have been written, so smaller responses are sent as they are.
+// Responses that flush before reaching minBytes, such as event streams, are never compressed so every event
+// reaches the client when it is written
+func GzipMiddleware(minBytes int) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Caches must keep compressed and uncompressed variants apart
+		c.Writer.Header().Add("Vary", "Accept-Encoding")
+
+		if c.Request.Method == http.MethodHead || !acceptsGzip(c.GetHeader("Accept-Encoding")) {
+			c.Next()
+			return
+		}
+
+		writer := &gzipResponseWriter{ResponseWriter: c.Writer, minBytes: minBytes}
+		c.Writer = writer
+		defer func() { c.Writer = writer.ResponseWriter }()
+
+		c.Next()
+
+		if err := writer.finish(); err != nil {
+			LogWithContext(c).WithError(err).Warn("Failed to finish compressed response")
+		}
+	}
+}
+
+// acceptsGzip reports whether an Accept-Encoding header allows gzip; a q value of 0 refuses it
+func acceptsGzip(acceptEncoding string) bool {
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		coding, params, _ := strings.Cut(part, ";")
+		if !strings.EqualFold(strings.TrimSpace(coding), "gzip") {
+			continue
+		}
+		name, value, found := strings.Cut(strings.TrimSpace(params), "=")
+		if !found || !strings.EqualFold(strings.TrimSpace(name), "q") {
+			return true
+		}
+		q, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		return err == nil && q > 0
+	}
+	return false
+}
+
+// gzipResponseWriter buffers the start of a response until it knows whether to compress it
+type gzipResponseWriter struct {
+	gin.ResponseWriter
+	minBytes int
+	buffer   []byte
+	decided  bool
+	gzip     *gzip.Writer
+}
+
+func (w *gzipResponseWriter) Write(data []byte) (int, error) {
+	if !w.decided {
+		w.buffer = append(w.buffer, data...)
+		if len(w.buffer) < w.minBytes {
+			return len(data), nil
+		}
+		if err := w.decide(true); err != nil {
+			return 0, err
+		}
+		return len(data), nil
+	}
+	if w.gzip != nil {
+		return w.gzip.Write(data)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *gzipResponseWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// WriteHeaderNow sends the headers straight away, so the response can no longer be compressed
+func (w *gzipResponseWriter) WriteHeaderNow() {
+	if !w.decided {
+		if err := w.decide(false); err != nil {
+			return
+		}
+	}
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+// Flush sends what has been written so far; a response flushed before reaching minBytes stays uncompressed
+func (w *gzipResponseWriter) Flush() {
+	if !w.decided {
+		if err := w.decide(false); err != nil {
+			return
+		}
+	}
+	if w.gzip != nil {
+		if err := w.gzip.Flush(); err != nil {
+			return
+		}
+	}
+	w.ResponseWriter.Flush()
+}
+
+// decide starts the response, compressed when compress is true and the response allows it,
+// and writes out the buffered start of the body
+func (w *gzipResponseWriter) decide(compress bool) error {
+	w.decided = true
+	buffered := w.buffer
+	w.buffer = nil
+
+	if compress && w.compressible() {
+		header := w.Header()
+		header.Set("Content-Encoding", "gzip")
+		header.Del("Content-Length")
+		w.gzip = gzip.NewWriter(w.ResponseWriter)
+		_, err := w.gzip.Write(buffered)
+		return err
+	}
+	if len(buffered) == 0 {
+		return nil
+	}
+	_, err := w.ResponseWriter.Write(buffered)
+	return err
+}
+
+// compressible reports whether the response may be gzip encoded
+// Already encoded bodies, event streams and images are sent as they are
+func (w *gzipResponseWriter) compressible() bool {
+	status := w.Status()
+	if status < http.StatusOK || status == http.StatusNoContent || status == http.StatusNotModified {
+		return false
+	}
+	header := w.Header()
+	if header.Get("Content-Encoding") != "" {
+		return false
+	}
+	contentType := header.Get("Content-Type")
+	return !strings.HasPrefix(contentType, "text/event-stream") && !strings.HasPrefix(contentType, "image/")
+}
+
+// finish sends a response that never reached minBytes and completes a compressed one
+func (w *gzipResponseWriter) finish() error {
+	if !w.decided {
+		if err := w.decide(false); err != nil {
+			return err
+		}
+	}
+	if w.gzip != nil {
+		return w.gzip.Close()
+	}
+	return nil
+}
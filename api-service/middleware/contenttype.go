@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// RequireJSONMiddleware rejects write requests, DELETE included, whose body is not declared as JSON with 415
+// application/json and structured suffixes such as application/ld+json are accepted. Requests without
+// a body, like POST /recipes/:id/duplicate, need no Content-Type
+func RequireJSONMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		default:
+			c.Next()
+			return
+		}
+
+		if !hasBody(c.Request) || isJSONContentType(c.GetHeader("Content-Type")) {
+			c.Next()
+			return
+		}
+
+		logrus.WithFields(logrus.Fields{
+			"content_type": c.GetHeader("Content-Type"),
+			"ip":           c.ClientIP(),
+			"path":         c.Request.URL.Path,
+			"request_id":   c.GetHeader("X-Request-ID"),
+		}).Warn("Unsupported request content type")
+
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{
+			"error": "Content-Type must be application/json",
+		})
+		c.Abort()
+	}
+}
+
+// hasBody reports whether a request carries a body; requests of unknown length are assumed to
+func hasBody(r *http.Request) bool {
+	if r.ContentLength != 0 {
+		return true
+	}
+	return r.Body != nil && r.Body != http.NoBody
+}
+
+// isJSONContentType reports whether a Content-Type header names JSON, ignoring parameters like charset
+func isJSONContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mediaType == "application/json" || (strings.HasPrefix(mediaType, "application/") && strings.HasSuffix(mediaType, "+json"))
+}
@@ -0,0 +1,135 @@
+package middleware
+
+import (
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultCORSMaxAge is how long, in seconds, browsers may cache a preflight response when CORS_MAX_AGE is not set
+const DefaultCORSMaxAge = 600
+
+// corsExposedHeaders lists the response headers browsers may read from cross-origin responses
+// The pagination headers have to be listed because they are not CORS-safelisted
+const corsExposedHeaders = "Link, X-Total-Count"
+
+// GetCORSMaxAge returns the preflight cache lifetime in seconds from CORS_MAX_AGE
+// Zero is allowed and asks browsers not to cache preflight responses
+func GetCORSMaxAge() int {
+	value := os.Getenv("CORS_MAX_AGE")
+	if value == "" {
+		return DefaultCORSMaxAge
+	}
+
+	seconds, err := strconv.Atoi(value)
+	if err != nil || seconds < 0 {
+		logrus.WithField("cors_max_age", value).Warn("Invalid CORS_MAX_AGE, using default")
+		return DefaultCORSMaxAge
+	}
+	return seconds
+}
+
+// isOriginAllowed performs secure origin validation with proper URL parsing
+func isOriginAllowed(origin, allowedOrigins string) bool {
+	// Parse the origin URL to validate it's well-formed
+	originURL, err := url.Parse(origin)
+	if err != nil {
+		// Malformed URL is not allowed
+		return false
+	}
+	
+	// Only allow http and https schemes
+	if originURL.Scheme != "http" && originURL.Scheme != "https" {
+		return false
+	}
+	
+	// Prevent subdomain bypasses by ensuring no wildcards in host
+	if strings.Contains(originURL.Host, "*") {
+		return false
+	}
+	
+	// Split allowed origins and validate each one
+	for _, allowedOrigin := range strings.Split(allowedOrigins, ",") {
+		allowedOrigin = strings.TrimSpace(allowedOrigin)
+		
+		// Skip empty origins
+		if allowedOrigin == "" {
+			continue
+		}
+		
+		// Parse allowed origin to ensure it's valid
+		allowedURL, err := url.Parse(allowedOrigin)
+		if err != nil {
+			continue // Skip malformed allowed origins
+		}
+		
+		// Exact match required (scheme, host, and port must match exactly)
+		if originURL.Scheme == allowedURL.Scheme &&
+		   originURL.Host == allowedURL.Host &&
+		   originURL.Port() == allowedURL.Port() {
+			return true
+		}
+	}
+	
+	return false
+}
+
+// CORSMiddleware allows cross-origin requests from the comma-separated allowedOrigins
+// Preflight requests from allowed origins are answered with 204 and may be cached for maxAgeSeconds;
+// requests from other origins are rejected with 403
+func CORSMiddleware(allowedOrigins string, maxAgeSeconds int) gin.HandlerFunc {
+	maxAge := strconv.Itoa(maxAgeSeconds)
+
+	return func(c *gin.Context) {
+		origin := c.Request.Header.Get("Origin")
+		allowed := false
+		
+		if origin != "" {
+			allowed = isOriginAllowed(origin, allowedOrigins)
+			
+			// Log CORS violations for security monitoring
+			if !allowed {
+				logrus.WithFields(logrus.Fields{
+					"origin":      origin,
+					"client_ip":   c.ClientIP(),
+					"request_id":  c.GetHeader("X-Request-ID"),
+					"user_agent":  c.GetHeader("User-Agent"),
+					"method":      c.Request.Method,
+					"path":        c.Request.URL.Path,
+				}).Warn("CORS violation: Origin not allowed")
+			}
+		}
+		
+		if allowed {
+			c.Header("Access-Control-Allow-Origin", origin)
+			c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
+			c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Authorization, X-Request-ID, If-Unmodified-Since")
+			c.Header("Access-Control-Allow-Credentials", "true")
+			c.Header("Access-Control-Expose-Headers", corsExposedHeaders)
+		}
+		
+		if c.Request.Method == "OPTIONS" {
+			if allowed {
+				// Lets browsers reuse the preflight result instead of repeating it before every request
+				c.Header("Access-Control-Max-Age", maxAge)
+				c.AbortWithStatus(204)
+			} else {
+				c.AbortWithStatus(403)
+			}
+			return
+		}
+		
+		if !allowed && origin != "" {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Origin not allowed"})
+			c.Abort()
+			return
+		}
+		
+		c.Next()
+	}
+}
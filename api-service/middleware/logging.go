@@ -1,27 +1,86 @@
 package middleware
 
 import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
 	"time"
 
+	"digital-recipes/api-service/logctx"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 )
 
+// Supported LOG_FORMAT values
+const (
+	LogFormatJSON = "json"
+	LogFormatText = "text"
+)
+
+// NewLogFormatter returns the logrus formatter for a LOG_FORMAT value
+// An empty format selects JSON; an unknown one returns JSON along with an error
+func NewLogFormatter(format string) (logrus.Formatter, error) {
+	switch strings.ToLower(strings.TrimSpace(format)) {
+	case "", LogFormatJSON:
+		return &logrus.JSONFormatter{}, nil
+	case LogFormatText:
+		return &logrus.TextFormatter{FullTimestamp: true}, nil
+	default:
+		return &logrus.JSONFormatter{}, fmt.Errorf("unsupported log format %q, expected %s or %s", format, LogFormatJSON, LogFormatText)
+	}
+}
+
+// OpenLogFile opens a log file for appending, creating it if it does not exist
+func OpenLogFile(path string) (*os.File, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0640)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open log file %s: %w", path, err)
+	}
+	return file, nil
+}
+
+// maxRequestIDLength caps client-supplied request IDs; UUIDs and load balancer trace IDs fit well within it
+const maxRequestIDLength = 128
+
+// requestIDPattern is the shape of request IDs accepted from clients: letters, digits and . _ : -
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._:-]+$`)
+
+// validRequestID reports whether a client-supplied request ID is safe to write to logs and echo back
+func validRequestID(requestID string) bool {
+	return len(requestID) <= maxRequestIDLength && requestIDPattern.MatchString(requestID)
+}
+
 // RequestIDMiddleware adds a unique request ID to each request
+// IDs sent by clients or load balancers are kept when they look like an ID; anything else, such as
+// a value carrying newlines to forge log lines, is replaced by a generated UUID
 func RequestIDMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Check if request ID already exists (from client or load balancer)
-		requestID := c.GetHeader("X-Request-ID")
-		if requestID == "" {
+		requestID := strings.TrimSpace(c.GetHeader("X-Request-ID"))
+		rejected := requestID != "" && !validRequestID(requestID)
+		if requestID == "" || rejected {
 			// Generate new UUID if not provided
 			requestID = uuid.New().String()
 		}
+		if rejected {
+			logrus.WithFields(logrus.Fields{
+				"ip":         c.ClientIP(),
+				"length":     len(c.GetHeader("X-Request-ID")),
+				"request_id": requestID,
+			}).Warn("Replaced invalid X-Request-ID")
+		}
 
-		// Set request ID in headers and context
+		// Set request ID in headers and context; the request header is rewritten too because
+		// some logs read it from there
+		c.Request.Header.Set("X-Request-ID", requestID)
 		c.Header("X-Request-ID", requestID)
 		c.Set("request_id", requestID)
 
+		// Carry the request ID into the request context for layers without the gin context
+		c.Request = c.Request.WithContext(logctx.WithLogger(c.Request.Context(), logrus.WithField("request_id", requestID)))
+
 		c.Next()
 	}
 }
@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetTrustedProxies returns the comma-separated proxy IPs or CIDR ranges in TRUSTED_PROXIES
+// An unset variable returns nil, which trusts no proxy at all
+func GetTrustedProxies() []string {
+	return splitCommaList(os.Getenv("TRUSTED_PROXIES"))
+}
+
+// ConfigureTrustedProxies sets which direct peers may report the client address to c.ClientIP()
+//
+// Security: X-Forwarded-For and X-Real-IP are client-controlled headers. They are only honoured when the
+// request comes straight from a listed proxy, so list just the load balancer's addresses. Trusting a range
+// clients can reach directly lets them pick their own IP, which defeats per-IP rate limiting and poisons
+// the client_ip in logs. With no proxies the TCP peer address is always used, so behind a load balancer
+// every client shares the proxy's IP and its rate limit.
+func ConfigureTrustedProxies(engine *gin.Engine, proxies []string) error {
+	if err := engine.SetTrustedProxies(proxies); err != nil {
+		return fmt.Errorf("invalid trusted proxy list: %w", err)
+	}
+	return nil
+}
+
+// splitCommaList splits a comma-separated setting, dropping blank entries
+func splitCommaList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -3,7 +3,10 @@ package middleware
 import (
 	"fmt"
 	"net/http"
+	"os"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
@@ -12,10 +15,39 @@ import (
 )
 
 // RateLimitConfig holds rate limiting configuration
+// SkipPaths lists request paths that bypass the limiter; a trailing * matches any path with that prefix
 type RateLimitConfig struct {
-	Rate   limiter.Rate
-	Store  limiter.Store
-	KeyGen func(c *gin.Context) string
+	Rate      limiter.Rate
+	Store     limiter.Store
+	KeyGen    func(c *gin.Context) string
+	SkipPaths []string
+}
+
+// DefaultRateLimitSkipPaths keeps load balancer probes and metrics scrapes from using up a client's limit
+var DefaultRateLimitSkipPaths = []string{"/health*", "/metrics"}
+
+// GetRateLimitSkipPaths returns the comma-separated paths in RATE_LIMIT_SKIP_PATHS, or the defaults when unset
+func GetRateLimitSkipPaths() []string {
+	value := os.Getenv("RATE_LIMIT_SKIP_PATHS")
+	if strings.TrimSpace(value) == "" {
+		return append([]string(nil), DefaultRateLimitSkipPaths...)
+	}
+
+	return splitCommaList(value)
+}
+
+// skipsRateLimit reports whether path matches one of the skip patterns
+func skipsRateLimit(path string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+			if strings.HasPrefix(path, prefix) {
+				return true
+			}
+		} else if path == pattern {
+			return true
+		}
+	}
+	return false
 }
 
 // NewMemoryRateLimit creates an in-memory rate limiter
@@ -28,8 +60,9 @@ func NewMemoryRateLimit(rate string) (*RateLimitConfig, error) {
 	store := memory.NewStore()
 
 	return &RateLimitConfig{
-		Rate:  parsedRate,
-		Store: store,
+		Rate:      parsedRate,
+		Store:     store,
+		SkipPaths: append([]string(nil), DefaultRateLimitSkipPaths...),
 		KeyGen: func(c *gin.Context) string {
 			// Rate limit by IP address
 			return c.ClientIP()
@@ -47,8 +80,9 @@ func NewUserRateLimit(rate string) (*RateLimitConfig, error) {
 	store := memory.NewStore()
 
 	return &RateLimitConfig{
-		Rate:  parsedRate,
-		Store: store,
+		Rate:      parsedRate,
+		Store:     store,
+		SkipPaths: append([]string(nil), DefaultRateLimitSkipPaths...),
 		KeyGen: func(c *gin.Context) string {
 			// Rate limit by user ID if authenticated, otherwise by IP
 			if userID := GetUserID(c); userID != 0 {
@@ -64,6 +98,11 @@ func RateLimitMiddleware(config *RateLimitConfig) gin.HandlerFunc {
 	instance := limiter.New(config.Store, config.Rate)
 
 	return func(c *gin.Context) {
+		if skipsRateLimit(c.Request.URL.Path, config.SkipPaths) {
+			c.Next()
+			return
+		}
+
 		key := config.KeyGen(c)
 		
 		context, err := instance.Get(c, key)
@@ -72,7 +111,7 @@ func RateLimitMiddleware(config *RateLimitConfig) gin.HandlerFunc {
 				"error":      err.Error(),
 				"key":        key,
 				"ip":         c.ClientIP(),
-				"request_id": c.GetHeader("X-Request-ID"),
+				"request_id": GetRequestID(c),
 			}).Error("Rate limiter error")
 			
 			// Continue on error to avoid blocking legitimate requests
@@ -86,6 +125,19 @@ func RateLimitMiddleware(config *RateLimitConfig) gin.HandlerFunc {
 		c.Header("X-RateLimit-Reset", strconv.FormatInt(context.Reset, 10))
 
 		if context.Reached {
+			// Reset is a Unix timestamp; clients are told how many whole seconds to wait
+			retryAfter := context.Reset - time.Now().Unix()
+			if retryAfter < 1 {
+				retryAfter = 1
+			}
+			c.Header("Retry-After", strconv.FormatInt(retryAfter, 10))
+
+			// Support can match a throttling complaint to this log line by the request ID the client received
+			requestID := GetRequestID(c)
+			if requestID != "" {
+				c.Header("X-Request-ID", requestID)
+			}
+
 			logrus.WithFields(logrus.Fields{
 				"key":        key,
 				"limit":      context.Limit,
@@ -93,26 +145,28 @@ func RateLimitMiddleware(config *RateLimitConfig) gin.HandlerFunc {
 				"path":       c.Request.URL.Path,
 				"method":     c.Request.Method,
 				"user_id":    GetUserID(c),
-				"request_id": c.GetHeader("X-Request-ID"),
+				"request_id": requestID,
 			}).Warn("Rate limit exceeded")
 
-			c.JSON(http.StatusTooManyRequests, gin.H{
-				"error":   "Rate limit exceeded",
-				"message": fmt.Sprintf("Too many requests. Limit: %d requests per %s", context.Limit, config.Rate.Period),
-				"retry_after": context.Reset,
+			// Same envelope as handler errors; the wait is carried by Retry-After
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+				"error":      fmt.Sprintf("Too many requests. Limit: %d requests per %s", context.Limit, config.Rate.Period),
+				"type":       "rate_limit",
+				"code":       "RATE_LIMIT_EXCEEDED",
+				"request_id": requestID,
 			})
-			c.Abort()
 			return
 		}
 
 		// Log rate limit usage for monitoring
 		if context.Remaining < context.Limit/10 { // Warn when < 10% remaining
 			logrus.WithFields(logrus.Fields{
-				"key":       key,
-				"remaining": context.Remaining,
-				"limit":     context.Limit,
-				"user_id":   GetUserID(c),
-				"ip":        c.ClientIP(),
+				"key":        key,
+				"remaining":  context.Remaining,
+				"limit":      context.Limit,
+				"user_id":    GetUserID(c),
+				"ip":         c.ClientIP(),
+				"request_id": GetRequestID(c),
 			}).Debug("Rate limit approaching")
 		}
 
@@ -138,6 +192,7 @@ func CreateGeneralRateLimit() gin.HandlerFunc {
 	if err != nil {
 		logrus.WithError(err).Fatal("Failed to create general rate limiter")
 	}
+	config.SkipPaths = GetRateLimitSkipPaths()
 
 	return RateLimitMiddleware(config)
 }
@@ -151,4 +206,33 @@ func CreateAuthRateLimit() gin.HandlerFunc {
 	}
 
 	return RateLimitMiddleware(config)
-}
\ No newline at end of file
+}
+
+// DefaultRecipeRateLimit is the recipe write requests per minute allowed when RECIPE_RATE_LIMIT is not set
+const DefaultRecipeRateLimit = 20
+
+// GetRecipeRateLimit returns the configured recipe write requests per minute from RECIPE_RATE_LIMIT
+func GetRecipeRateLimit() int {
+	value := os.Getenv("RECIPE_RATE_LIMIT")
+	if value == "" {
+		return DefaultRecipeRateLimit
+	}
+
+	perMinute, err := strconv.Atoi(value)
+	if err != nil || perMinute <= 0 {
+		logrus.WithField("recipe_rate_limit", value).Warn("Invalid RECIPE_RATE_LIMIT, using default")
+		return DefaultRecipeRateLimit
+	}
+	return perMinute
+}
+
+// CreateRecipeRateLimit creates a rate limiter for endpoints that create or change recipes
+// The limit is per user and shared by every route the returned middleware is applied to
+func CreateRecipeRateLimit(perMinute int) gin.HandlerFunc {
+	config, err := NewUserRateLimit(fmt.Sprintf("%d-M", perMinute))
+	if err != nil {
+		logrus.WithError(err).Fatal("Failed to create recipe rate limiter")
+	}
+
+	return RateLimitMiddleware(config)
+}
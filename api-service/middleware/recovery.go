@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+	"syscall"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// RecoveryMiddleware recovers from panics in later handlers, logs them with the request
+// context and stack trace, and responds with a 500 error carrying the request ID
+func RecoveryMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+
+			fields := logrus.Fields{
+				"request_id": GetRequestID(c),
+				"user_id":    GetUserID(c),
+				"ip":         c.ClientIP(),
+				"method":     c.Request.Method,
+				"path":       c.Request.URL.Path,
+				"panic":      fmt.Sprint(recovered),
+				"stack":      string(debug.Stack()),
+			}
+
+			// A client that went away cannot be sent a response
+			if isConnectionReset(recovered) {
+				logrus.WithFields(fields).Warn("Client connection lost while writing response")
+				c.Abort()
+				return
+			}
+
+			logrus.WithFields(fields).Error("Recovered from panic")
+
+			if c.Writer.Written() {
+				c.Abort()
+				return
+			}
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+				"error":      "Internal server error",
+				"type":       "internal",
+				"code":       "INTERNAL_ERROR",
+				"request_id": GetRequestID(c),
+			})
+		}()
+
+		c.Next()
+	}
+}
+
+// isConnectionReset reports whether a panic was caused by the client closing the connection
+func isConnectionReset(recovered interface{}) bool {
+	err, ok := recovered.(error)
+	if !ok {
+		return false
+	}
+	return errors.Is(err, syscall.EPIPE) || errors.Is(err, syscall.ECONNRESET)
+}
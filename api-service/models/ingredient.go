@@ -0,0 +1,53 @@
+package models
+
+import "time"
+
+// CanonicalIngredient represents a normalized ingredient shared across recipes
+type CanonicalIngredient struct {
+	ID         int       `json:"id" db:"id"`
+	Name       string    `json:"name" db:"name"`
+	IsApproved bool      `json:"is_approved" db:"is_approved"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// IngredientUsage is a canonical ingredient with the number of recipe ingredients linked to it
+type IngredientUsage struct {
+	CanonicalIngredient
+	UsageCount int `json:"usage_count"`
+}
+
+// LinkOrCreateRequest represents free-form ingredient text to resolve to a canonical ingredient
+type LinkOrCreateRequest struct {
+	OriginalText string `json:"original_text" binding:"required,max=500"`
+}
+
+// LinkOrCreateResponse reports the resolved canonical ingredient and whether it was newly created
+type LinkOrCreateResponse struct {
+	Ingredient     CanonicalIngredient `json:"ingredient"`
+	NormalizedName string              `json:"normalized_name"`
+	Created        bool                `json:"created"`
+}
+
+// CreateIngredientRequest represents an admin request to add a canonical ingredient by name
+type CreateIngredientRequest struct {
+	Name       string `json:"name" binding:"required,max=255"`
+	IsApproved *bool  `json:"is_approved,omitempty"`
+}
+
+// RenameIngredientRequest represents an admin request to correct a canonical ingredient's name
+type RenameIngredientRequest struct {
+	Name string `json:"name" binding:"required,max=255"`
+}
+
+// MergeIngredientsRequest represents an admin request to fold a duplicate canonical ingredient into another
+type MergeIngredientsRequest struct {
+	SourceID int `json:"source_id" binding:"required,min=1"`
+	TargetID int `json:"target_id" binding:"required,min=1"`
+}
+
+// MergeIngredientsResponse reports the surviving ingredient and how many recipe ingredients now link to it instead of the source
+type MergeIngredientsResponse struct {
+	Target                     CanonicalIngredient `json:"target"`
+	RecipeIngredientsRepointed int                 `json:"recipe_ingredients_repointed"`
+}
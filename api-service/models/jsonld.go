@@ -0,0 +1,348 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// SchemaOrgContext is the JSON-LD context for schema.org vocabulary
+const SchemaOrgContext = "https://schema.org"
+
+// JSONLDPerson represents a schema.org Person
+type JSONLDPerson struct {
+	Type string `json:"@type"`
+	Name string `json:"name"`
+}
+
+// JSONLDRecipe represents a recipe serialized as schema.org Recipe JSON-LD
+type JSONLDRecipe struct {
+	Context            string        `json:"@context"`
+	Type               string        `json:"@type"`
+	Identifier         string        `json:"identifier"`
+	Name               string        `json:"name"`
+	Author             *JSONLDPerson `json:"author,omitempty"`
+	URL                *string       `json:"url,omitempty"`
+	RecipeYield        *string       `json:"recipeYield,omitempty"`
+	RecipeIngredient   []string      `json:"recipeIngredient"`
+	RecipeInstructions *string       `json:"recipeInstructions,omitempty"`
+	PrepTime           *string       `json:"prepTime,omitempty"`
+	CookTime           *string       `json:"cookTime,omitempty"`
+	DateCreated        string        `json:"dateCreated"`
+	DateModified       string        `json:"dateModified"`
+}
+
+// MaxServingsLength is the import limit matching the recipes servings column
+// Titles are bounded by MaxTitleLength, and the number of ingredients by the import handler,
+// which knows the configured limit
+const MaxServingsLength = 50
+
+// JSONLDRecipeDocument is an incoming schema.org Recipe as published by recipe sites
+// Fields that sites serialize in several shapes are kept raw and decoded by the accessors
+type JSONLDRecipeDocument struct {
+	Type               json.RawMessage   `json:"@type"`
+	Graph              []json.RawMessage `json:"@graph,omitempty"`
+	Name               string            `json:"name"`
+	Author             json.RawMessage   `json:"author,omitempty"`
+	URL                string            `json:"url,omitempty"`
+	RecipeYield        json.RawMessage   `json:"recipeYield,omitempty"`
+	RecipeIngredient   []string          `json:"recipeIngredient,omitempty"`
+	RecipeInstructions json.RawMessage   `json:"recipeInstructions,omitempty"`
+	PrepTime           string            `json:"prepTime,omitempty"`
+	CookTime           string            `json:"cookTime,omitempty"`
+}
+
+// jsonLDInstruction covers HowToStep and HowToSection entries in recipeInstructions
+type jsonLDInstruction struct {
+	Type            json.RawMessage `json:"@type"`
+	Name            string          `json:"name"`
+	Text            string          `json:"text"`
+	ItemListElement json.RawMessage `json:"itemListElement"`
+}
+
+// ParseJSONLDRecipe decodes a JSON-LD document and returns its Recipe node
+// Documents wrapping the recipe in an @graph, as many CMS plugins do, are supported
+func ParseJSONLDRecipe(data []byte) (*JSONLDRecipeDocument, error) {
+	var document JSONLDRecipeDocument
+	if err := json.Unmarshal(data, &document); err != nil {
+		return nil, &FieldError{Field: "@type", Message: "document must be a JSON-LD object"}
+	}
+
+	if !document.isRecipe() {
+		for _, node := range document.Graph {
+			var candidate JSONLDRecipeDocument
+			if err := json.Unmarshal(node, &candidate); err == nil && candidate.isRecipe() {
+				document = candidate
+				break
+			}
+		}
+	}
+
+	if !document.isRecipe() {
+		return nil, &FieldError{Field: "@type", Message: "@type must be Recipe"}
+	}
+
+	return &document, document.Validate()
+}
+
+// isRecipe reports whether @type is "Recipe" or an array containing it
+func (d *JSONLDRecipeDocument) isRecipe() bool {
+	return typeIncludes(d.Type, "Recipe")
+}
+
+// Validate checks the document fits the recipe schema
+func (d *JSONLDRecipeDocument) Validate() error {
+	name := strings.TrimSpace(d.Name)
+	if name == "" {
+		return &FieldError{Field: "name", Message: "name is required"}
+	}
+	if utf8.RuneCountInString(name) > MaxTitleLength {
+		return &FieldError{Field: "name", Message: fmt.Sprintf("name cannot exceed %d characters", MaxTitleLength)}
+	}
+	if yield := d.Yield(); yield != nil && utf8.RuneCountInString(*yield) > MaxServingsLength {
+		return &FieldError{Field: "recipeYield", Message: fmt.Sprintf("recipeYield cannot exceed %d characters", MaxServingsLength)}
+	}
+	return nil
+}
+
+// Yield returns recipeYield, which sites publish as a string, a number or an array
+func (d *JSONLDRecipeDocument) Yield() *string {
+	if len(d.RecipeYield) == 0 {
+		return nil
+	}
+
+	var text string
+	if err := json.Unmarshal(d.RecipeYield, &text); err == nil {
+		return nonEmpty(text)
+	}
+
+	var number json.Number
+	if err := json.Unmarshal(d.RecipeYield, &number); err == nil {
+		return nonEmpty(number.String())
+	}
+
+	// Arrays usually hold "4" and "4 servings"; the first entry is the plain count
+	var values []json.RawMessage
+	if err := json.Unmarshal(d.RecipeYield, &values); err == nil && len(values) > 0 {
+		first := JSONLDRecipeDocument{RecipeYield: values[0]}
+		return first.Yield()
+	}
+
+	return nil
+}
+
+// Ingredients returns the non-empty recipeIngredient lines
+func (d *JSONLDRecipeDocument) Ingredients() []string {
+	lines := make([]string, 0, len(d.RecipeIngredient))
+	for _, line := range d.RecipeIngredient {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// Instructions flattens recipeInstructions into newline separated text
+// It accepts a plain string, an array of strings, HowToStep objects and HowToSection groups
+func (d *JSONLDRecipeDocument) Instructions() *string {
+	steps := flattenInstructions(d.RecipeInstructions)
+	if len(steps) == 0 {
+		return nil
+	}
+	return nonEmpty(strings.Join(steps, "\n"))
+}
+
+// PrepTimeMinutes returns prepTime in minutes, or nil when it is missing or not an ISO 8601 duration
+func (d *JSONLDRecipeDocument) PrepTimeMinutes() *int {
+	return durationMinutes(d.PrepTime)
+}
+
+// CookTimeMinutes returns cookTime in minutes, or nil when it is missing or not an ISO 8601 duration
+func (d *JSONLDRecipeDocument) CookTimeMinutes() *int {
+	return durationMinutes(d.CookTime)
+}
+
+// SourceName returns the recipe's author for attribution, joining several authors with commas
+// Authors may be plain names or Person and Organization objects; names are cut to MaxSourceNameLength characters
+func (d *JSONLDRecipeDocument) SourceName() *string {
+	names := authorNames(d.Author)
+	if len(names) == 0 {
+		return nil
+	}
+	name := strings.Join(names, ", ")
+	if utf8.RuneCountInString(name) > MaxSourceNameLength {
+		name = strings.TrimSpace(string([]rune(name)[:MaxSourceNameLength]))
+	}
+	return &name
+}
+
+// SourceURL returns the page the recipe was published on, or nil when it is missing or not an http or https URL
+func (d *JSONLDRecipeDocument) SourceURL() *string {
+	sourceURL := strings.TrimSpace(d.URL)
+	if sourceURL == "" || len(sourceURL) > MaxSourceURLLength || ValidateSourceURL(&sourceURL) != nil {
+		return nil
+	}
+	return &sourceURL
+}
+
+// authorNames decodes an author value, which sites publish as a string, an object with a name or a list of either
+func authorNames(raw json.RawMessage) []string {
+	if len(raw) == 0 {
+		return nil
+	}
+
+	var name string
+	if err := json.Unmarshal(raw, &name); err == nil {
+		if name = strings.TrimSpace(name); name != "" {
+			return []string{name}
+		}
+		return nil
+	}
+
+	var items []json.RawMessage
+	if err := json.Unmarshal(raw, &items); err == nil {
+		var names []string
+		for _, item := range items {
+			names = append(names, authorNames(item)...)
+		}
+		return names
+	}
+
+	var author struct {
+		Name json.RawMessage `json:"name"`
+	}
+	if err := json.Unmarshal(raw, &author); err != nil {
+		return nil
+	}
+	if err := json.Unmarshal(author.Name, &name); err != nil {
+		return nil
+	}
+	if name = strings.TrimSpace(name); name != "" {
+		return []string{name}
+	}
+	return nil
+}
+
+// isoDurationPattern matches the day and time parts of ISO 8601 durations such as "PT1H30M" or "P1DT2H"
+var isoDurationPattern = regexp.MustCompile(`^P(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:\d+(?:\.\d+)?S)?)?$`)
+
+// durationMinutes converts an ISO 8601 duration to whole minutes, dropping seconds
+// Durations longer than a week are treated as unknown; sites publish those by mistake
+func durationMinutes(value string) *int {
+	value = strings.ToUpper(strings.TrimSpace(value))
+	match := isoDurationPattern.FindStringSubmatch(value)
+	if match == nil || value == "P" || value == "PT" {
+		return nil
+	}
+
+	minutes := 0
+	for i, perUnit := range []int{24 * 60, 60, 1} {
+		if match[i+1] == "" {
+			continue
+		}
+		amount, err := strconv.Atoi(match[i+1])
+		if err != nil {
+			return nil
+		}
+		minutes += amount * perUnit
+	}
+	if minutes > MaxRecipeTimeMinutes {
+		return nil
+	}
+	return &minutes
+}
+
+// FormatDuration writes minutes as an ISO 8601 duration such as "PT1H30M"; nil stays nil
+func FormatDuration(minutes *int) *string {
+	if minutes == nil {
+		return nil
+	}
+	hours, rest := *minutes/60, *minutes%60
+	var formatted string
+	switch {
+	case hours == 0:
+		formatted = fmt.Sprintf("PT%dM", rest)
+	case rest == 0:
+		formatted = fmt.Sprintf("PT%dH", hours)
+	default:
+		formatted = fmt.Sprintf("PT%dH%dM", hours, rest)
+	}
+	return &formatted
+}
+
+// flattenInstructions decodes one recipeInstructions value into its text steps
+func flattenInstructions(raw json.RawMessage) []string {
+	if len(raw) == 0 {
+		return nil
+	}
+
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		if text = strings.TrimSpace(text); text != "" {
+			return []string{text}
+		}
+		return nil
+	}
+
+	var items []json.RawMessage
+	if err := json.Unmarshal(raw, &items); err == nil {
+		var steps []string
+		for _, item := range items {
+			steps = append(steps, flattenInstructions(item)...)
+		}
+		return steps
+	}
+
+	var instruction jsonLDInstruction
+	if err := json.Unmarshal(raw, &instruction); err != nil {
+		return nil
+	}
+
+	if typeIncludes(instruction.Type, "HowToSection") {
+		steps := flattenInstructions(instruction.ItemListElement)
+		if name := strings.TrimSpace(instruction.Name); name != "" && len(steps) > 0 {
+			steps = append([]string{name + ":"}, steps...)
+		}
+		return steps
+	}
+
+	// HowToStep sometimes omits text and only carries a name
+	text = strings.TrimSpace(instruction.Text)
+	if text == "" {
+		text = strings.TrimSpace(instruction.Name)
+	}
+	if text == "" {
+		return nil
+	}
+	return []string{text}
+}
+
+// typeIncludes reports whether a JSON-LD @type value, string or array, contains want
+func typeIncludes(raw json.RawMessage, want string) bool {
+	var single string
+	if err := json.Unmarshal(raw, &single); err == nil {
+		return single == want
+	}
+
+	var multiple []string
+	if err := json.Unmarshal(raw, &multiple); err == nil {
+		for _, value := range multiple {
+			if value == want {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// nonEmpty returns a pointer to the trimmed text, or nil when it is empty
+func nonEmpty(text string) *string {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil
+	}
+	return &text
+}
@@ -1,8 +1,15 @@
 package models
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
+	"unicode/utf8"
 )
 
 // Recipe represents a recipe in the system
@@ -16,12 +23,106 @@ type Recipe struct {
 	UserID       int       `json:"user_id" db:"user_id"`
 	CreatedAt    time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
+
+	PrepTimeMinutes *int    `json:"prep_time_minutes,omitempty" db:"prep_time_minutes"`
+	CookTimeMinutes *int    `json:"cook_time_minutes,omitempty" db:"cook_time_minutes"`
+	Difficulty      *string `json:"difficulty,omitempty" db:"difficulty"`
+
+	SourceName *string `json:"source_name,omitempty" db:"source_name"`
+	SourceURL  *string `json:"source_url,omitempty" db:"source_url"`
+
+	// ServingsCount is the number read from Servings for scaling, such as 6 for "serves 4-6"; nil when it holds none
+	ServingsCount *float64 `json:"servings_count,omitempty" db:"-"`
+
+	// LastModifiedBy is the user who last updated the recipe; nil until it is first edited
+	LastModifiedBy *int `json:"last_modified_by,omitempty" db:"last_modified_by"`
+
+	Visibility string `json:"visibility" db:"visibility"`
+
+	// Featured recipes are picked by admins for the homepage; FeaturedAt is when the current feature began
+	Featured   bool       `json:"featured" db:"featured"`
+	FeaturedAt *time.Time `json:"featured_at,omitempty" db:"featured_at"`
+}
+
+// Recipe difficulty levels, from least to most demanding
+const (
+	DifficultyEasy   = "easy"
+	DifficultyMedium = "medium"
+	DifficultyHard   = "hard"
+)
+
+// Recipe visibility levels, which are independent of the review status
+// Public recipes are listed for everyone, unlisted ones are only opened by ID and private ones only
+// by their owner and admins. Owners always see their own recipes in listings
+const (
+	VisibilityPublic   = "public"
+	VisibilityUnlisted = "unlisted"
+	VisibilityPrivate  = "private"
+)
+
+// RecipeDifficulties lists the accepted difficulty values
+var RecipeDifficulties = []string{DifficultyEasy, DifficultyMedium, DifficultyHard}
+
+// MaxRecipeTimeMinutes bounds prep and cook times at one week
+const MaxRecipeTimeMinutes = 7 * 24 * 60
+
+// MaxTitleLength bounds recipe titles, counted in characters after trimming surrounding whitespace
+// The recipes_title_length check enforces the same limit in the database
+const MaxTitleLength = 200
+
+// ValidateTitle checks a title is not blank and fits MaxTitleLength once trimmed
+func ValidateTitle(title string) error {
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return &FieldError{Field: "title", Message: "title cannot be empty"}
+	}
+	if utf8.RuneCountInString(title) > MaxTitleLength {
+		return &FieldError{Field: "title", Message: fmt.Sprintf("title must be at most %d characters", MaxTitleLength)}
+	}
+	return nil
+}
+
+// Source attribution limits; the binding tags on RecipeInput and RecipePatch must match these values
+const (
+	MaxSourceNameLength = 255
+	MaxSourceURLLength  = 2048
+)
+
+// ValidateSourceURL checks that a recipe's source URL is an absolute http or https URL
+// An empty URL is valid and clears the source URL
+func ValidateSourceURL(sourceURL *string) error {
+	if sourceURL == nil || strings.TrimSpace(*sourceURL) == "" {
+		return nil
+	}
+	parsed, err := url.Parse(strings.TrimSpace(*sourceURL))
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return &FieldError{Field: "source_url", Message: "source_url must be an absolute http or https URL"}
+	}
+	return nil
 }
 
 // RecipeWithIngredients represents a recipe with its ingredients
+// PrimaryImageURL is a short-lived download URL for the cover image, set only on single-recipe responses
 type RecipeWithIngredients struct {
 	Recipe
-	Ingredients []RecipeIngredient `json:"ingredients,omitempty"`
+	Ingredients     []RecipeIngredient `json:"ingredients,omitempty"`
+	PrimaryImageURL *string            `json:"primary_image_url,omitempty"`
+}
+
+// RecipeListItem is a recipe as listed by GET /recipes, with hints about how complete it is
+// ThumbnailURL is a short-lived download URL for the primary image's thumbnail, when one was generated
+type RecipeListItem struct {
+	RecipeWithIngredients
+	IngredientCount int     `json:"ingredient_count"`
+	HasImages       bool    `json:"has_images"`
+	ThumbnailURL    *string `json:"thumbnail_url,omitempty"`
+}
+
+// SimilarRecipe is a published recipe listed by GET /recipes/:id/similar
+// SharedIngredients counts the distinct canonical ingredients it has in common with the target recipe
+type SimilarRecipe struct {
+	Recipe
+	SharedIngredients int `json:"shared_ingredients"`
 }
 
 // RecipeIngredient represents an ingredient in a recipe
@@ -37,18 +138,298 @@ type RecipeIngredient struct {
 	UpdatedAt              time.Time `json:"updated_at" db:"updated_at"`
 }
 
+// LinkIngredientRequest represents a reviewer linking a recipe ingredient to a canonical ingredient
+type LinkIngredientRequest struct {
+	CanonicalIngredientID int      `json:"canonical_ingredient_id" binding:"required,min=1"`
+	Quantity              *float64 `json:"quantity,omitempty" binding:"omitempty,min=0"`
+	Unit                  *string  `json:"unit,omitempty" binding:"omitempty,max=50"`
+}
+
+// IngredientInput represents an ingredient line being added to a recipe
+// Quantity and unit are parsed from original_text when not provided
+// Every write replaces the lines it sends, so clients send canonical_ingredient_id back to keep a line linked;
+// handlers check it names an approved canonical ingredient, while revision restores keep the snapshot's links
+type IngredientInput struct {
+	OriginalText          string   `json:"original_text" binding:"required,max=1000"`
+	Quantity              *float64 `json:"quantity,omitempty" binding:"omitempty,min=0"`
+	Unit                  *string  `json:"unit,omitempty" binding:"omitempty,max=50"`
+	CanonicalIngredientID *int     `json:"canonical_ingredient_id,omitempty" binding:"omitempty,min=1"`
+}
+
+// ReplaceIngredientsRequest is the complete ingredient list of a recipe, replacing the current one
+// An empty list removes every ingredient; the handler caps the list at MAX_INGREDIENTS_PER_RECIPE
+type ReplaceIngredientsRequest struct {
+	Ingredients []IngredientInput `json:"ingredients" binding:"required,dive"`
+}
+
+// RecipeInput represents the editable fields of a recipe
+// Ingredients replace the existing list when present and are left untouched when omitted
+// Times are in minutes, capped at one week; an empty source name or URL clears it.
+// An empty status or visibility keeps the current value. The handler caps the ingredient list at MAX_INGREDIENTS_PER_RECIPE
+type RecipeInput struct {
+	Title        string             `json:"title" binding:"required"`
+	Servings     *string            `json:"servings,omitempty" binding:"omitempty,max=50"`
+	Instructions *string            `json:"instructions,omitempty"`
+	Tips         *string            `json:"tips,omitempty"`
+	Status       string             `json:"status,omitempty" binding:"omitempty,oneof=processing review_required published"`
+	Visibility   string             `json:"visibility,omitempty" binding:"omitempty,oneof=public unlisted private"`
+	Ingredients  *[]IngredientInput `json:"ingredients,omitempty" binding:"omitempty,dive"`
+
+	PrepTimeMinutes *int    `json:"prep_time_minutes,omitempty" binding:"omitempty,min=0,max=10080"`
+	CookTimeMinutes *int    `json:"cook_time_minutes,omitempty" binding:"omitempty,min=0,max=10080"`
+	Difficulty      *string `json:"difficulty,omitempty" binding:"omitempty,oneof=easy medium hard"`
+
+	SourceName *string `json:"source_name,omitempty" binding:"omitempty,max=255"`
+	SourceURL  *string `json:"source_url,omitempty" binding:"omitempty,max=2048"`
+}
+
+// RecipeFeatureInput represents an admin request to feature a recipe on the homepage or stop featuring it
+// Featured is a pointer so that false can be told apart from a missing field
+type RecipeFeatureInput struct {
+	Featured *bool `json:"featured" binding:"required"`
+}
+
+// RecipePatch represents a partial recipe update
+// Omitted (or null) fields keep their current values; a field sent as "" is set to empty
+type RecipePatch struct {
+	Title        *string            `json:"title,omitempty"`
+	Servings     *string            `json:"servings,omitempty" binding:"omitempty,max=50"`
+	Instructions *string            `json:"instructions,omitempty"`
+	Tips         *string            `json:"tips,omitempty"`
+	Status       *string            `json:"status,omitempty" binding:"omitempty,oneof=processing review_required published"`
+	Visibility   *string            `json:"visibility,omitempty" binding:"omitempty,oneof=public unlisted private"`
+	Ingredients  *[]IngredientInput `json:"ingredients,omitempty" binding:"omitempty,dive"`
+
+	PrepTimeMinutes *int    `json:"prep_time_minutes,omitempty" binding:"omitempty,min=0,max=10080"`
+	CookTimeMinutes *int    `json:"cook_time_minutes,omitempty" binding:"omitempty,min=0,max=10080"`
+	Difficulty      *string `json:"difficulty,omitempty" binding:"omitempty,oneof=easy medium hard"`
+
+	SourceName *string `json:"source_name,omitempty" binding:"omitempty,max=255"`
+	SourceURL  *string `json:"source_url,omitempty" binding:"omitempty,max=2048"`
+}
+
+// Validate reports what the binding rules cannot express: a blank or overlong title, blank ingredient
+// lines and a source URL that is not http or https
+func (in RecipeInput) Validate() FieldErrors {
+	var errs FieldErrors
+	if strings.TrimSpace(in.Title) == "" {
+		errs = append(errs, FieldError{Field: "title", Message: "title is required"})
+	} else if err := ValidateTitle(in.Title); err != nil {
+		errs = append(errs, FieldError{Field: "title", Message: err.Error()})
+	}
+	errs = append(errs, validateIngredientLines(in.Ingredients)...)
+	if err := ValidateSourceURL(in.SourceURL); err != nil {
+		errs = append(errs, FieldError{Field: "source_url", Message: err.Error()})
+	}
+	return errs
+}
+
+// validateIngredientLines reports each ingredient sent with blank original_text, by its position in the list
+func validateIngredientLines(ingredients *[]IngredientInput) FieldErrors {
+	if ingredients == nil {
+		return nil
+	}
+	var errs FieldErrors
+	for i, ingredient := range *ingredients {
+		if strings.TrimSpace(ingredient.OriginalText) == "" {
+			errs = append(errs, FieldError{Field: fmt.Sprintf("ingredients[%d].original_text", i), Message: "original_text cannot be empty"})
+		}
+	}
+	return errs
+}
+
+// IsEmpty reports whether the patch changes nothing
+func (p RecipePatch) IsEmpty() bool {
+	return p.Title == nil && p.Servings == nil && p.Instructions == nil && p.Tips == nil &&
+		p.Status == nil && p.Visibility == nil && p.Ingredients == nil &&
+		p.PrepTimeMinutes == nil && p.CookTimeMinutes == nil && p.Difficulty == nil &&
+		p.SourceName == nil && p.SourceURL == nil
+}
+
+// Validate reports the same checks as RecipeInput.Validate for the fields the patch sends
+func (p RecipePatch) Validate() FieldErrors {
+	var errs FieldErrors
+	if p.Title != nil {
+		if err := ValidateTitle(*p.Title); err != nil {
+			errs = append(errs, FieldError{Field: "title", Message: err.Error()})
+		}
+	}
+	errs = append(errs, validateIngredientLines(p.Ingredients)...)
+	if err := ValidateSourceURL(p.SourceURL); err != nil {
+		errs = append(errs, FieldError{Field: "source_url", Message: err.Error()})
+	}
+	return errs
+}
+
+// Apply merges the patch over the current recipe into a full update
+func (p RecipePatch) Apply(current Recipe) RecipeInput {
+	input := RecipeInput{
+		Title:        current.Title,
+		Servings:     current.Servings,
+		Instructions: current.Instructions,
+		Tips:         current.Tips,
+		Status:       current.Status,
+		Visibility:   current.Visibility,
+		Ingredients:  p.Ingredients,
+
+		PrepTimeMinutes: current.PrepTimeMinutes,
+		CookTimeMinutes: current.CookTimeMinutes,
+		Difficulty:      current.Difficulty,
+
+		SourceName: current.SourceName,
+		SourceURL:  current.SourceURL,
+	}
+	if p.Title != nil {
+		input.Title = *p.Title
+	}
+	if p.Servings != nil {
+		input.Servings = p.Servings
+	}
+	if p.Instructions != nil {
+		input.Instructions = p.Instructions
+	}
+	if p.Tips != nil {
+		input.Tips = p.Tips
+	}
+	if p.Status != nil {
+		input.Status = *p.Status
+	}
+	if p.Visibility != nil {
+		input.Visibility = *p.Visibility
+	}
+	if p.PrepTimeMinutes != nil {
+		input.PrepTimeMinutes = p.PrepTimeMinutes
+	}
+	if p.CookTimeMinutes != nil {
+		input.CookTimeMinutes = p.CookTimeMinutes
+	}
+	if p.Difficulty != nil {
+		input.Difficulty = p.Difficulty
+	}
+	if p.SourceName != nil {
+		input.SourceName = p.SourceName
+	}
+	if p.SourceURL != nil {
+		input.SourceURL = p.SourceURL
+	}
+	return input
+}
+
+// RecipeRevision is a snapshot of a recipe taken before an update
+// ChangedBy and CreatedAt describe the update that replaced the snapshot
+type RecipeRevision struct {
+	ID             int                   `json:"id" db:"id"`
+	RecipeID       int                   `json:"recipe_id" db:"recipe_id"`
+	RevisionNumber int                   `json:"revision_number" db:"revision_number"`
+	Snapshot       RecipeWithIngredients `json:"snapshot" db:"snapshot"`
+	ChangedBy      *int                  `json:"changed_by,omitempty" db:"changed_by"`
+	CreatedAt      time.Time             `json:"created_at" db:"created_at"`
+}
+
+// MaxBatchStatusRecipes caps how many recipes one batch status update may change
+// The binding tag on BatchStatusRequest.IDs must match this value
+const MaxBatchStatusRecipes = 50
+
+// BatchStatusRequest moves several recipes to the same status
+type BatchStatusRequest struct {
+	IDs    []int  `json:"ids" binding:"required,min=1,max=50,unique,dive,min=1"`
+	Status string `json:"status" binding:"required,oneof=processing review_required published"`
+}
+
+// Outcomes reported for each recipe of a batch status update
+const (
+	BatchResultUpdated   = "updated"
+	BatchResultFailed    = "failed"
+	BatchResultForbidden = "forbidden"
+	BatchResultNotFound  = "not_found"
+)
+
+// BatchStatusResult is the outcome of one recipe in a batch status update
+// Status is the recipe's status after the batch; Reason explains outcomes other than updated
+type BatchStatusResult struct {
+	ID     int    `json:"id"`
+	Result string `json:"result"`
+	Status string `json:"status,omitempty"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// BatchStatusResponse lists the outcome for every requested recipe, in request order
+type BatchStatusResponse struct {
+	Updated int                 `json:"updated"`
+	Failed  int                 `json:"failed"`
+	Results []BatchStatusResult `json:"results"`
+}
+
+// RecipeDraft is autosaved content for a recipe that does not affect the live recipe until promoted
+type RecipeDraft struct {
+	RecipeID  int         `json:"recipe_id" db:"recipe_id"`
+	Content   RecipePatch `json:"content" db:"content"`
+	CreatedAt time.Time   `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at" db:"updated_at"`
+}
+
+// Upload request limits shared by the binding tags and Validate
+// The numeric bounds in UploadRequest's binding tags must match these values
+const (
+	MaxImagesPerUpload   = 5
+	MaxFileSizeMBLimit   = 25
+	MaxTotalUploadMB     = 100
+	MaxExpirationHours   = 24
+	DefaultFileSizeMB    = 10
+	DefaultExpirationHrs = 1
+	MaxAllowedTypes      = 4
+)
+
+// MaxRecipeImageBytes caps the combined size of a recipe's confirmed images
+// It holds real uploads to the same budget that MaxTotalUploadMB sets for an upload request
+const MaxRecipeImageBytes int64 = MaxTotalUploadMB << 20
+
+// FieldError describes a validation failure tied to a specific request field
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Error implements the error interface
+func (e *FieldError) Error() string {
+	return e.Message
+}
+
+// FieldErrors lists every field that failed validation so a client can show all problems at once
+type FieldErrors []FieldError
+
+// Error implements the error interface
+func (e FieldErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, fieldErr := range e {
+		messages[i] = fieldErr.Message
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Merge appends the failures of other for fields that have none yet, keeping one message per field
+func (e FieldErrors) Merge(other FieldErrors) FieldErrors {
+	for _, fieldErr := range other {
+		if !slices.ContainsFunc(e, func(existing FieldError) bool { return existing.Field == fieldErr.Field }) {
+			e = append(e, fieldErr)
+		}
+	}
+	return e
+}
+
 // UploadRequest represents a request to upload recipe images
 type UploadRequest struct {
-	ImageCount      int      `json:"image_count" binding:"required,min=1,max=10"`
-	MaxFileSizeMB   int      `json:"max_file_size_mb,omitempty" binding:"omitempty,min=1,max=50"`
-	AllowedTypes    []string `json:"allowed_types,omitempty" binding:"omitempty,dive,oneof=image/jpeg image/jpg image/png image/webp"`
+	ImageCount      int      `json:"image_count" binding:"required,min=1,max=5"`
+	MaxFileSizeMB   int      `json:"max_file_size_mb,omitempty" binding:"omitempty,min=1,max=25"`
+	AllowedTypes    []string `json:"allowed_types,omitempty" binding:"omitempty,max=4,dive,oneof=image/jpeg image/jpg image/png image/webp"`
+	ContentTypes    []string `json:"content_types,omitempty" binding:"omitempty,max=5,dive,oneof=image/jpeg image/jpg image/png image/webp"`
 	ExpirationHours int      `json:"expiration_hours,omitempty" binding:"omitempty,min=1,max=24"`
 }
 
 // GetMaxFileSizeMB returns the max file size with a default
 func (ur *UploadRequest) GetMaxFileSizeMB() int {
 	if ur.MaxFileSizeMB <= 0 {
-		return 10 // Default 10MB
+		return DefaultFileSizeMB
 	}
 	return ur.MaxFileSizeMB
 }
@@ -61,63 +442,146 @@ func (ur *UploadRequest) GetAllowedTypes() []string {
 	return ur.AllowedTypes
 }
 
+// ContentTypeFor returns the content type the upload URL of image i is signed for
+// Without per-image content types every image uses the first allowed type
+func (ur *UploadRequest) ContentTypeFor(i int) string {
+	if i < len(ur.ContentTypes) {
+		return ur.ContentTypes[i]
+	}
+	return ur.GetAllowedTypes()[0]
+}
+
+// CanonicalizeContentType maps content type aliases to the single spelling used for signing and metadata
+// "image/jpg" is accepted from clients but is not a registered media type, so it becomes "image/jpeg"
+func CanonicalizeContentType(contentType string) string {
+	contentType = strings.ToLower(strings.TrimSpace(contentType))
+	if contentType == "image/jpg" {
+		return "image/jpeg"
+	}
+	return contentType
+}
+
+// Fingerprint identifies the images an upload request asks for so a repeat of the same request can be recognised
+// It covers the image count, sizes and types; the URL expiration is left out as it does not change the recipe
+func (ur *UploadRequest) Fingerprint() string {
+	allowed := make([]string, 0, len(ur.GetAllowedTypes()))
+	for _, allowedType := range ur.GetAllowedTypes() {
+		allowed = append(allowed, CanonicalizeContentType(allowedType))
+	}
+	slices.Sort(allowed)
+	allowed = slices.Compact(allowed)
+
+	contentTypes := make([]string, 0, ur.ImageCount)
+	for i := 0; i < ur.ImageCount; i++ {
+		contentTypes = append(contentTypes, CanonicalizeContentType(ur.ContentTypeFor(i)))
+	}
+
+	sum := sha256.Sum256([]byte(strings.Join([]string{
+		strconv.Itoa(ur.ImageCount),
+		strconv.Itoa(ur.GetMaxFileSizeMB()),
+		strings.Join(allowed, ","),
+		strings.Join(contentTypes, ","),
+	}, "|")))
+	return hex.EncodeToString(sum[:])
+}
+
 // GetExpirationHours returns expiration hours with default
 func (ur *UploadRequest) GetExpirationHours() int {
 	if ur.ExpirationHours <= 0 {
-		return 1 // Default 1 hour
+		return DefaultExpirationHrs
 	}
 	return ur.ExpirationHours
 }
 
-// Validate performs additional business logic validation
+// Validate performs business logic validation and is the source of truth for error messages
+// It covers every rule in the binding tags so both layers report the same field-level errors
+// Repeated entries in AllowedTypes are removed, keeping the first occurrence
 func (ur *UploadRequest) Validate() error {
-	// Enhanced security validation
-	
 	// 1. Strict size and count limits to prevent resource abuse
-	if ur.ImageCount > 5 {
-		return fmt.Errorf("maximum 5 images allowed per upload request")
+	if ur.ImageCount <= 0 {
+		return &FieldError{Field: "image_count", Message: "image count must be positive"}
+	}
+
+	if ur.ImageCount > MaxImagesPerUpload {
+		return &FieldError{Field: "image_count", Message: fmt.Sprintf("maximum %d images allowed per upload request", MaxImagesPerUpload)}
 	}
-	
-	if ur.GetMaxFileSizeMB() > 25 {
-		return fmt.Errorf("maximum file size is 25MB per image")
+
+	if ur.MaxFileSizeMB < 0 {
+		return &FieldError{Field: "max_file_size_mb", Message: "max file size must be positive"}
 	}
-	
+
+	if ur.GetMaxFileSizeMB() > MaxFileSizeMBLimit {
+		return &FieldError{Field: "max_file_size_mb", Message: fmt.Sprintf("maximum file size is %dMB per image", MaxFileSizeMBLimit)}
+	}
+
 	// 2. Prevent resource exhaustion attacks
 	totalSizeLimit := ur.ImageCount * ur.GetMaxFileSizeMB()
-	if totalSizeLimit > 100 { // Maximum 100MB total per request
-		return fmt.Errorf("total upload size cannot exceed 100MB (currently %dMB)", totalSizeLimit)
+	if totalSizeLimit > MaxTotalUploadMB {
+		return &FieldError{Field: "max_file_size_mb", Message: fmt.Sprintf("total upload size cannot exceed %dMB (currently %dMB)", MaxTotalUploadMB, totalSizeLimit)}
 	}
-	
+
 	// 3. Validate file types with strict whitelist
 	validTypes := map[string]bool{
 		"image/jpeg": true,
-		"image/jpg":  true,  // Allow both JPEG variants
+		"image/jpg":  true, // Allow both JPEG variants
 		"image/png":  true,
 		"image/webp": true,
 	}
-	
+
+	if len(ur.AllowedTypes) > MaxAllowedTypes {
+		return &FieldError{Field: "allowed_types", Message: fmt.Sprintf("at most %d allowed file types may be specified", MaxAllowedTypes)}
+	}
+
 	allowedTypes := ur.GetAllowedTypes()
 	if len(allowedTypes) == 0 {
-		return fmt.Errorf("at least one allowed file type must be specified")
+		return &FieldError{Field: "allowed_types", Message: "at least one allowed file type must be specified"}
 	}
-	
+
+	seen := make(map[string]bool, len(allowedTypes))
+	unique := make([]string, 0, len(allowedTypes))
 	for _, fileType := range allowedTypes {
 		if !validTypes[fileType] {
-			return fmt.Errorf("unsupported file type: %s. Allowed types: image/jpeg, image/png, image/webp", fileType)
+			return &FieldError{Field: "allowed_types", Message: fmt.Sprintf("unsupported file type: %s. Allowed types: image/jpeg, image/png, image/webp", fileType)}
 		}
+		if !seen[fileType] {
+			seen[fileType] = true
+			unique = append(unique, fileType)
+		}
+	}
+
+	// Both JPEG spellings map to the same extension, so listing both is ambiguous
+	if seen["image/jpeg"] && seen["image/jpg"] {
+		return &FieldError{Field: "allowed_types", Message: "image/jpeg and image/jpg are the same type; specify only one"}
+	}
+
+	if len(ur.AllowedTypes) > 0 {
+		ur.AllowedTypes = unique
 	}
-	
+
+	// Signed URLs pin the Content-Type header, so a per-image type is needed for each image
+	if len(ur.ContentTypes) > 0 {
+		if len(ur.ContentTypes) != ur.ImageCount {
+			return &FieldError{Field: "content_types", Message: fmt.Sprintf("content_types must list one type per image (got %d for %d images)", len(ur.ContentTypes), ur.ImageCount)}
+		}
+		for _, contentType := range ur.ContentTypes {
+			if !validTypes[contentType] {
+				return &FieldError{Field: "content_types", Message: fmt.Sprintf("unsupported file type: %s. Allowed types: image/jpeg, image/png, image/webp", contentType)}
+			}
+			if !slices.ContainsFunc(unique, func(allowed string) bool { return CanonicalizeContentType(allowed) == CanonicalizeContentType(contentType) }) {
+				return &FieldError{Field: "content_types", Message: fmt.Sprintf("content type %s is not in allowed_types", contentType)}
+			}
+		}
+	}
+
 	// 4. Validate expiration time is reasonable
-	expirationHours := ur.GetExpirationHours()
-	if expirationHours > 24 {
-		return fmt.Errorf("expiration time cannot exceed 24 hours")
+	if ur.ExpirationHours < 0 {
+		return &FieldError{Field: "expiration_hours", Message: "expiration time must be positive"}
 	}
-	
-	// 5. Additional security checks for suspicious patterns
-	if ur.ImageCount <= 0 {
-		return fmt.Errorf("image count must be positive")
+
+	if ur.GetExpirationHours() > MaxExpirationHours {
+		return &FieldError{Field: "expiration_hours", Message: fmt.Sprintf("expiration time cannot exceed %d hours", MaxExpirationHours)}
 	}
-	
+
 	return nil
 }
 
@@ -125,11 +589,78 @@ func (ur *UploadRequest) Validate() error {
 type UploadResponse struct {
 	RecipeID   int                   `json:"recipe_id"`
 	UploadURLs []ImageUploadURL      `json:"upload_urls"`
+	// Reused is set when a repeat of a recent request was answered with the recipe it created
+	Reused     bool                  `json:"reused,omitempty"`
 }
 
 // ImageUploadURL represents a pre-signed URL for image upload
 type ImageUploadURL struct {
 	ImageID   string `json:"image_id"`
 	UploadURL string `json:"upload_url"`
+	// ObjectKey is the storage object the URL uploads to, recipes/{storage_prefix}/images/{image_id}{extension}
+	ObjectKey string `json:"object_key"`
 	Fields    map[string]string `json:"fields,omitempty"`
-}
\ No newline at end of file
+}
+
+// RecipeImage is an image stored for a recipe, listed in display order
+type RecipeImage struct {
+	ID                  int       `json:"id" db:"id"`
+	RecipeID            int       `json:"recipe_id" db:"recipe_id"`
+	ObjectName          string    `json:"object_name" db:"object_name"`
+	ContentType         *string   `json:"content_type,omitempty" db:"content_type"`
+	SizeBytes           *int64    `json:"size_bytes,omitempty" db:"size_bytes"`
+	Position            int       `json:"position" db:"position"`
+	IsPrimary           bool      `json:"is_primary" db:"is_primary"`
+	ContentHash         *string   `json:"content_hash,omitempty" db:"content_hash"`
+	CreatedAt           time.Time `json:"created_at" db:"created_at"`
+	ThumbnailObjectName *string   `json:"thumbnail_object_name,omitempty" db:"thumbnail_object_name"`
+	ThumbnailFailed     bool      `json:"thumbnail_failed" db:"thumbnail_failed"`
+}
+
+// ConfirmImageRequest reports that an image was uploaded to a URL from an upload request
+type ConfirmImageRequest struct {
+	ImageID string `json:"image_id" binding:"required,max=100"`
+}
+
+// ImageConfirmation is the recorded image for a confirmed upload
+// Deduplicated is true when the recipe already had identical content and the new upload was discarded
+type ImageConfirmation struct {
+	Image        RecipeImage `json:"image"`
+	Deduplicated bool        `json:"deduplicated"`
+}
+
+// ReorderImagesRequest lists every image of a recipe in the desired display order
+type ReorderImagesRequest struct {
+	ImageIDs []int `json:"image_ids" binding:"required,min=1,max=100,dive,min=1"`
+}
+// MaxShoppingListRecipes caps how many recipes a single shopping list may combine
+const MaxShoppingListRecipes = 20
+
+// ShoppingListRecipe selects a recipe for a shopping list with an optional servings override
+type ShoppingListRecipe struct {
+	RecipeID int      `json:"recipe_id" binding:"required,min=1"`
+	Servings *float64 `json:"servings,omitempty" binding:"omitempty,gt=0"`
+}
+
+// ShoppingListRequest represents a request to combine several recipes into one shopping list
+// The max in the binding tag must match MaxShoppingListRecipes
+type ShoppingListRequest struct {
+	Recipes []ShoppingListRecipe `json:"recipes" binding:"required,min=1,max=20,dive"`
+}
+
+// ShoppingListItem is one line of a shopping list
+// Lines without a quantity (e.g. "salt to taste") keep Quantity nil
+type ShoppingListItem struct {
+	Name                  string   `json:"name"`
+	CanonicalIngredientID *int     `json:"canonical_ingredient_id,omitempty"`
+	Quantity              *float64 `json:"quantity,omitempty"`
+	Unit                  *string  `json:"unit,omitempty"`
+	RecipeIDs             []int    `json:"recipe_ids"`
+	OriginalText          []string `json:"original_text"`
+}
+
+// ShoppingList is the consolidated list of ingredients for a set of recipes
+type ShoppingList struct {
+	RecipeIDs []int              `json:"recipe_ids"`
+	Items     []ShoppingListItem `json:"items"`
+}
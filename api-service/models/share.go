@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// RecipeShare is a link that opens a recipe whatever its visibility
+// The token is only returned when the link is created
+type RecipeShare struct {
+	ID        int        `json:"id" db:"id"`
+	RecipeID  int        `json:"recipe_id" db:"recipe_id"`
+	CreatedBy *int       `json:"created_by,omitempty" db:"created_by"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty" db:"expires_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty" db:"revoked_at"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+}
+
+// RecipeShareInput creates a share link; links without an expiry last until revoked
+type RecipeShareInput struct {
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// RecipeShareLink is the response to creating a share link, including its token
+type RecipeShareLink struct {
+	RecipeShare
+	Token string `json:"token"`
+	Path  string `json:"path"`
+}
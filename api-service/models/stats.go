@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// Stats holds the aggregate figures shown on the admin dashboard
+// GeneratedAt is when the figures were computed; they may be reused for a short while
+type Stats struct {
+	Recipes              RecipeStats     `json:"recipes"`
+	Users                int             `json:"users"`
+	CanonicalIngredients IngredientStats `json:"canonical_ingredients"`
+	GeneratedAt          time.Time       `json:"generated_at"`
+}
+
+// RecipeStats counts recipes overall, by status and by how recently they were created
+type RecipeStats struct {
+	Total             int                `json:"total"`
+	ByStatus          RecipeStatusCounts `json:"by_status"`
+	CreatedLast7Days  int                `json:"created_last_7_days"`
+	CreatedLast30Days int                `json:"created_last_30_days"`
+}
+
+// RecipeStatusCounts counts recipes in each workflow status
+type RecipeStatusCounts struct {
+	Processing     int `json:"processing"`
+	ReviewRequired int `json:"review_required"`
+	Published      int `json:"published"`
+}
+
+// IngredientStats counts canonical ingredients by review state
+type IngredientStats struct {
+	Total    int `json:"total"`
+	Approved int `json:"approved"`
+	Pending  int `json:"pending"`
+}
@@ -0,0 +1,108 @@
+package models
+
+import (
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// MaxEmailLength matches the users.email column size
+const MaxEmailLength = 255
+
+// Password rules; bcrypt ignores everything past 72 bytes so longer passwords are rejected
+const (
+	MinPasswordLength = 8
+	MaxPasswordLength = 72
+)
+
+// User is the public view of an account; credentials are never part of it
+type User struct {
+	ID        int       `json:"id" db:"id"`
+	Email     string    `json:"email" db:"email"`
+	Name      string    `json:"name" db:"name"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// UpdateProfileRequest changes the current user's name and/or email; omitted fields are kept
+// Email is checked with ValidateEmail after normalization rather than by a binding rule,
+// so surrounding whitespace is forgiven and malformed addresses get a field-level error
+type UpdateProfileRequest struct {
+	Name  *string `json:"name" binding:"omitempty,max=255"`
+	Email *string `json:"email" binding:"omitempty,max=255"`
+}
+
+// NormalizeEmail returns the stored form of an email address: trimmed and lowercased,
+// so addresses differing only in case collide on the users.email unique constraint
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// ValidateEmail checks that a normalized email is a bare address with a dotted domain
+func ValidateEmail(email string) error {
+	if email == "" {
+		return &FieldError{Field: "email", Message: "email is required"}
+	}
+	if len(email) > MaxEmailLength {
+		return &FieldError{Field: "email", Message: fmt.Sprintf("email must be at most %d characters", MaxEmailLength)}
+	}
+
+	// ParseAddress also accepts display names and comments, so the parsed address must be the whole input
+	address, err := mail.ParseAddress(email)
+	if err != nil || address.Name != "" || address.Address != email {
+		return &FieldError{Field: "email", Message: "email must be a valid address"}
+	}
+
+	domain := email[strings.LastIndex(email, "@")+1:]
+	if !strings.Contains(domain, ".") || strings.HasPrefix(domain, ".") || strings.HasSuffix(domain, ".") {
+		return &FieldError{Field: "email", Message: "email must be a valid address"}
+	}
+	return nil
+}
+
+// ChangePasswordRequest replaces the current user's password after verifying the existing one
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required,max=72"`
+	NewPassword     string `json:"new_password" binding:"required"`
+}
+
+// Validate checks the new password against the strength rules
+func (r *ChangePasswordRequest) Validate() error {
+	if err := ValidatePasswordStrength(r.NewPassword); err != nil {
+		return err
+	}
+	if r.NewPassword == r.CurrentPassword {
+		return &FieldError{Field: "new_password", Message: "new password must differ from the current password"}
+	}
+	return nil
+}
+
+// ValidatePasswordStrength requires 8 to 72 bytes with at least one letter and one digit
+func ValidatePasswordStrength(password string) error {
+	if len(password) < MinPasswordLength {
+		return &FieldError{Field: "new_password", Message: fmt.Sprintf("password must be at least %d characters", MinPasswordLength)}
+	}
+	if len(password) > MaxPasswordLength {
+		return &FieldError{Field: "new_password", Message: fmt.Sprintf("password must be at most %d bytes", MaxPasswordLength)}
+	}
+
+	var hasLetter, hasDigit bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLetter(r):
+			hasLetter = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		}
+	}
+	if !hasLetter || !hasDigit {
+		return &FieldError{Field: "new_password", Message: "password must contain at least one letter and one digit"}
+	}
+	return nil
+}
+
+// DeleteAccountRequest confirms account deletion with the current password
+type DeleteAccountRequest struct {
+	Password string `json:"password" binding:"required,max=72"`
+}
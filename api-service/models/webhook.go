@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// Webhook is a registered receiver of recipe status notifications
+// The signing secret is only returned when the webhook is registered
+type Webhook struct {
+	ID        int       `json:"id" db:"id"`
+	URL       string    `json:"url" db:"url"`
+	Events    []string  `json:"events" db:"events"`
+	CreatedBy *int      `json:"created_by,omitempty" db:"created_by"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// WebhookInput registers a webhook; a secret is generated when none is supplied
+type WebhookInput struct {
+	URL    string   `json:"url" binding:"required,url,max=2000"`
+	Events []string `json:"events" binding:"required,min=1,max=10,dive,oneof=recipe.review_required recipe.published"`
+	Secret string   `json:"secret" binding:"omitempty,min=16,max=255"`
+}
+
+// WebhookRegistration is the response to registering a webhook, including its signing secret
+type WebhookRegistration struct {
+	Webhook
+	Secret string `json:"secret"`
+}
+
+// RecipeStatusEvent is the data sent with recipe status notifications
+type RecipeStatusEvent struct {
+	RecipeID       int    `json:"recipe_id"`
+	Title          string `json:"title"`
+	PreviousStatus string `json:"previous_status"`
+	Status         string `json:"status"`
+	UserID         int    `json:"user_id"`
+}
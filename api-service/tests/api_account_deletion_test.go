@@ -0,0 +1,132 @@
+package tests
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"digital-recipes/api-service/handlers"
+	"digital-recipes/api-service/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countUserRows returns how many rows of table belong to the user
+func (suite *RecipeAPITestSuite) countUserRows(table string, userID int) int {
+	var count int
+	column := "user_id"
+	if table == "users" {
+		column = "id"
+	}
+	err := suite.db.DB.QueryRow(fmt.Sprintf(`SELECT COUNT(*) FROM %s WHERE %s = $1`, table, column), userID).Scan(&count)
+	require.NoError(suite.T(), err)
+	return count
+}
+
+// TestDeleteAccount tests the account, its recipes and their stored images are removed
+func (suite *RecipeAPITestSuite) TestDeleteAccount() {
+	suite.storage.Reset()
+	suite.setUserPassword(suite.testUserID, "original1")
+	firstID := suite.createTestRecipe("First", "published")
+	secondID := suite.createTestRecipe("Second", "draft")
+	suite.createTestIngredient(firstID, "1 cup flour", nil)
+
+	otherID := suite.createTestUser("other@example.com")
+	var otherRecipeID int
+	err := suite.db.DB.QueryRow(`
+		INSERT INTO recipes (title, servings, instructions, status, user_id)
+		VALUES ('Kept', '2', 'Keep me', 'published', $1) RETURNING id
+	`, otherID).Scan(&otherRecipeID)
+	require.NoError(suite.T(), err)
+
+	firstImage := fmt.Sprintf("recipes/%s/images/0.jpg", suite.storagePrefix(firstID))
+	secondImage := fmt.Sprintf("recipes/%s/images/0.jpg", suite.storagePrefix(secondID))
+	otherImage := fmt.Sprintf("recipes/%s/images/0.jpg", suite.storagePrefix(otherRecipeID))
+	suite.storage.Put(firstImage)
+	suite.storage.Put(secondImage)
+	suite.storage.Put(otherImage)
+
+	w := suite.performJSON("DELETE", "/api/v1/users/me", `{"password": "original1"}`, suite.authHeader(suite.testUserID, middleware.RoleUser))
+	require.Equal(suite.T(), http.StatusNoContent, w.Code, w.Body.String())
+	assert.Empty(suite.T(), w.Body.String())
+
+	assert.Equal(suite.T(), 0, suite.countUserRows("users", suite.testUserID))
+	assert.Equal(suite.T(), 0, suite.countUserRows("recipes", suite.testUserID))
+
+	var ingredientCount int
+	err = suite.db.DB.QueryRow(`SELECT COUNT(*) FROM recipe_ingredients WHERE recipe_id = $1`, firstID).Scan(&ingredientCount)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 0, ingredientCount, "Ingredients cascade with their recipe")
+
+	assert.ElementsMatch(suite.T(), []string{firstImage, secondImage}, suite.storage.Deleted())
+	assert.True(suite.T(), suite.storage.Has(otherImage), "Other users' images are untouched")
+	assert.Equal(suite.T(), 1, suite.countUserRows("recipes", otherID))
+}
+
+// TestDeleteAccountWrongPassword tests a wrong password leaves everything in place
+func (suite *RecipeAPITestSuite) TestDeleteAccountWrongPassword() {
+	suite.storage.Reset()
+	suite.setUserPassword(suite.testUserID, "original1")
+	recipeID := suite.createTestRecipe("Kept", "published")
+	image := fmt.Sprintf("recipes/%s/images/0.jpg", suite.storagePrefix(recipeID))
+	suite.storage.Put(image)
+	auth := suite.authHeader(suite.testUserID, middleware.RoleUser)
+
+	w := suite.performJSON("DELETE", "/api/v1/users/me", `{"password": "guessing1"}`, auth)
+	assert.Equal(suite.T(), http.StatusUnauthorized, w.Code)
+
+	w = suite.performJSON("DELETE", "/api/v1/users/me", `{}`, auth)
+	assert.Equal(suite.T(), http.StatusBadRequest, w.Code, "Password confirmation is required")
+
+	assert.Equal(suite.T(), 1, suite.countUserRows("users", suite.testUserID))
+	assert.Equal(suite.T(), 1, suite.countUserRows("recipes", suite.testUserID))
+	assert.Empty(suite.T(), suite.storage.Deleted())
+	assert.True(suite.T(), suite.storage.Has(image))
+}
+
+// TestDeleteAccountUnauthenticated tests anonymous requests are rejected
+func (suite *RecipeAPITestSuite) TestDeleteAccountUnauthenticated() {
+	w := suite.performJSON("DELETE", "/api/v1/users/me", `{"password": "original1"}`, "")
+	assert.Equal(suite.T(), http.StatusUnauthorized, w.Code)
+	assert.Equal(suite.T(), 1, suite.countUserRows("users", suite.testUserID))
+}
+
+// TestDeleteAccountBodyLimitsWithoutDatabase tests the password confirmation body is capped and must be JSON like any write
+func TestDeleteAccountBodyLimitsWithoutDatabase(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.BodyLimitMiddleware(64), middleware.RequireJSONMiddleware())
+	router.DELETE("/api/v1/users/me", func(c *gin.Context) {
+		c.Set("user_id", 7)
+		c.Next()
+	}, handlers.NewUserHandler(openScriptedDatabase(t), nil).DeleteCurrentUser)
+
+	oversized := `{"password": "` + strings.Repeat("a", 128) + `"}`
+	tests := []struct {
+		name        string
+		body        io.Reader
+		contentType string
+		expected    int
+	}{
+		{"Declared length over the cap", strings.NewReader(oversized), "application/json", http.StatusRequestEntityTooLarge},
+		// Wrapping the reader hides the length, so the handler hits the cap while binding
+		{"Unknown length over the cap", io.MultiReader(strings.NewReader(oversized)), "application/json", http.StatusRequestEntityTooLarge},
+		{"Not JSON", strings.NewReader(`password=original1`), "application/x-www-form-urlencoded", http.StatusUnsupportedMediaType},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("DELETE", "/api/v1/users/me", tt.body)
+			req.Header.Set("Content-Type", tt.contentType)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expected, w.Code, w.Body.String())
+			assert.Empty(t, scriptedQueries(), "Nothing is looked up for a rejected body")
+		})
+	}
+}
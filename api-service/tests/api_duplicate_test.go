@@ -0,0 +1,68 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"digital-recipes/api-service/handlers"
+	"digital-recipes/api-service/middleware"
+	"digital-recipes/api-service/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestDuplicateRecipe tests cloning a published recipe into a new recipe owned by the caller
+func (suite *RecipeAPITestSuite) TestDuplicateRecipe() {
+	flourID := suite.createCanonicalIngredient("Flour", true)
+	sourceID := suite.createTestRecipe("Sourdough", "published")
+	suite.createTestIngredient(sourceID, "500 g flour", &flourID)
+	suite.createTestIngredient(sourceID, "10 g salt", nil)
+	callerID := suite.createTestUser("baker@example.com")
+
+	w := suite.performJSON("POST", fmt.Sprintf("/api/v1/recipes/%d/duplicate", sourceID), "", suite.authHeader(callerID, middleware.RoleUser))
+	require.Equal(suite.T(), http.StatusOK, w.Code, w.Body.String())
+
+	var response handlers.StandardResponse
+	require.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &response))
+	dataBytes, _ := json.Marshal(response.Data)
+	var clone models.RecipeWithIngredients
+	require.NoError(suite.T(), json.Unmarshal(dataBytes, &clone))
+
+	assert.NotEqual(suite.T(), sourceID, clone.ID, "Clone should have a new id")
+	assert.Equal(suite.T(), "Sourdough (Copy)", clone.Title)
+	assert.Equal(suite.T(), "review_required", clone.Status)
+	assert.Equal(suite.T(), callerID, clone.UserID, "Caller should own the clone")
+	require.NotNil(suite.T(), clone.Instructions)
+	assert.Equal(suite.T(), "Test instructions", *clone.Instructions)
+
+	require.Len(suite.T(), clone.Ingredients, 2)
+	assert.Equal(suite.T(), "500 g flour", clone.Ingredients[0].OriginalText)
+	require.NotNil(suite.T(), clone.Ingredients[0].CanonicalIngredientID)
+	assert.Equal(suite.T(), flourID, *clone.Ingredients[0].CanonicalIngredientID)
+	assert.Equal(suite.T(), "10 g salt", clone.Ingredients[1].OriginalText)
+
+	// The source recipe is untouched
+	var sourceIngredients int
+	require.NoError(suite.T(), suite.db.DB.QueryRow(`SELECT COUNT(*) FROM recipe_ingredients WHERE recipe_id = $1`, sourceID).Scan(&sourceIngredients))
+	assert.Equal(suite.T(), 2, sourceIngredients)
+}
+
+// TestDuplicateRecipeAccess tests drafts can only be duplicated by their owner
+func (suite *RecipeAPITestSuite) TestDuplicateRecipeAccess() {
+	draftID := suite.createTestRecipe("Secret Draft", "review_required")
+	otherUserID := suite.createTestUser("other@example.com")
+	path := fmt.Sprintf("/api/v1/recipes/%d/duplicate", draftID)
+
+	w := suite.performJSON("POST", path, "", suite.authHeader(otherUserID, middleware.RoleUser))
+	assert.Equal(suite.T(), http.StatusNotFound, w.Code)
+
+	w = suite.performJSON("POST", path, "", suite.authHeader(suite.testUserID, middleware.RoleUser))
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+
+	w = suite.performJSON("POST", fmt.Sprintf("/api/v1/recipes/%d/duplicate", NonExistentID), "", suite.authHeader(suite.testUserID, middleware.RoleUser))
+	assert.Equal(suite.T(), http.StatusNotFound, w.Code)
+
+	w = suite.performJSON("POST", path, "", "")
+	assert.Equal(suite.T(), http.StatusUnauthorized, w.Code)
+}
@@ -0,0 +1,159 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"digital-recipes/api-service/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// performGet sends a GET request with optional Authorization header to the suite router
+func (suite *RecipeAPITestSuite) performGet(path, authorization string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", path, nil)
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+	suite.router.ServeHTTP(w, req)
+	return w
+}
+
+// TestGetRecipeJSONLD tests exporting a published recipe as schema.org JSON-LD
+func (suite *RecipeAPITestSuite) TestGetRecipeJSONLD() {
+	recipeID := suite.createTestRecipe("Pancakes", "published")
+	suite.createTestIngredient(recipeID, "2 cups all-purpose flour", nil)
+	suite.createTestIngredient(recipeID, "2 large eggs", nil)
+
+	w := suite.performGet(fmt.Sprintf("/api/v1/recipes/%d/jsonld", recipeID), "")
+	require.Equal(suite.T(), http.StatusOK, w.Code, w.Body.String())
+	assert.Contains(suite.T(), w.Header().Get("Content-Type"), "application/ld+json")
+
+	var document map[string]interface{}
+	require.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &document))
+	assert.Equal(suite.T(), "https://schema.org", document["@context"])
+	assert.Equal(suite.T(), "Recipe", document["@type"])
+	assert.Equal(suite.T(), "Pancakes", document["name"])
+	assert.Equal(suite.T(), "Test instructions", document["recipeInstructions"])
+	assert.Equal(suite.T(), "4", document["recipeYield"])
+	assert.Equal(suite.T(),
+		[]interface{}{"2 cups all-purpose flour", "2 large eggs"},
+		document["recipeIngredient"])
+
+	author, ok := document["author"].(map[string]interface{})
+	require.True(suite.T(), ok, "Author should be included")
+	assert.Equal(suite.T(), "Person", author["@type"])
+	assert.Equal(suite.T(), "Test User", author["name"])
+}
+
+// TestGetRecipeJSONLDUnpublished tests that drafts are only exportable by their owner or an admin
+func (suite *RecipeAPITestSuite) TestGetRecipeJSONLDUnpublished() {
+	recipeID := suite.createTestRecipe("Draft", "review_required")
+	otherUserID := suite.createTestUser("other@example.com")
+	path := fmt.Sprintf("/api/v1/recipes/%d/jsonld", recipeID)
+
+	w := suite.performGet(path, "")
+	assert.Equal(suite.T(), http.StatusNotFound, w.Code, "Anonymous callers must not see drafts")
+
+	w = suite.performGet(path, suite.authHeader(otherUserID, middleware.RoleUser))
+	assert.Equal(suite.T(), http.StatusNotFound, w.Code, "Other users must not see drafts")
+
+	w = suite.performGet(path, suite.authHeader(suite.testUserID, middleware.RoleUser))
+	assert.Equal(suite.T(), http.StatusOK, w.Code, "Owner can export drafts")
+
+	w = suite.performGet(path, suite.authHeader(otherUserID, middleware.RoleAdmin))
+	assert.Equal(suite.T(), http.StatusOK, w.Code, "Admins can export drafts")
+
+	// An empty ingredient list is still an array
+	var document map[string]interface{}
+	require.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &document))
+	assert.Equal(suite.T(), []interface{}{}, document["recipeIngredient"])
+}
+
+// TestExportRecipeMarkdown tests a recipe renders to Markdown with heading, bullets and numbered steps
+func (suite *RecipeAPITestSuite) TestExportRecipeMarkdown() {
+	var recipeID int
+	err := suite.db.DB.QueryRow(`
+		INSERT INTO recipes (title, servings, instructions, tips, status, user_id)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id
+	`, "Banana Bread!", "1 loaf", "1. Preheat the oven.\n\nMash the bananas.\nBake for 1 hour.", "Use very ripe bananas.", "published", suite.testUserID).Scan(&recipeID)
+	require.NoError(suite.T(), err)
+	suite.createTestIngredient(recipeID, "3 ripe bananas", nil)
+	suite.createTestIngredient(recipeID, "2 cups flour", nil)
+
+	w := suite.performGet(fmt.Sprintf("/api/v1/recipes/%d/export?format=markdown", recipeID), "")
+	require.Equal(suite.T(), http.StatusOK, w.Code, w.Body.String())
+	assert.Contains(suite.T(), w.Header().Get("Content-Type"), "text/markdown")
+	assert.Equal(suite.T(), `attachment; filename="banana-bread.md"`, w.Header().Get("Content-Disposition"))
+
+	expected := "# Banana Bread!\n\n" +
+		"**Servings:** 1 loaf\n\n" +
+		"## Ingredients\n\n" +
+		"- 3 ripe bananas\n" +
+		"- 2 cups flour\n\n" +
+		"## Instructions\n\n" +
+		"1. Preheat the oven.\n" +
+		"2. Mash the bananas.\n" +
+		"3. Bake for 1 hour.\n\n" +
+		"## Tips\n\n" +
+		"Use very ripe bananas.\n"
+	assert.Equal(suite.T(), expected, w.Body.String())
+}
+
+// TestExportRecipeValidation tests unknown formats and drafts are rejected
+func (suite *RecipeAPITestSuite) TestExportRecipeValidation() {
+	publishedID := suite.createTestRecipe("Published", "published")
+	draftID := suite.createTestRecipe("Draft", "processing")
+
+	w := suite.performGet(fmt.Sprintf("/api/v1/recipes/%d/export?format=docx", publishedID), "")
+	assert.Equal(suite.T(), http.StatusBadRequest, w.Code)
+
+	w = suite.performGet(fmt.Sprintf("/api/v1/recipes/%d/export", draftID), "")
+	assert.Equal(suite.T(), http.StatusNotFound, w.Code)
+
+	w = suite.performGet(fmt.Sprintf("/api/v1/recipes/%d/export", draftID), suite.authHeader(suite.testUserID, middleware.RoleUser))
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+}
+
+// TestExportRecipePDF tests a recipe with long instructions renders to a multi-page PDF attachment
+func (suite *RecipeAPITestSuite) TestExportRecipePDF() {
+	longInstructions := strings.Repeat("Stir the crème brûlée mixture gently and keep watching the heat.\n", 120)
+	var recipeID int
+	err := suite.db.DB.QueryRow(`
+		INSERT INTO recipes (title, servings, instructions, tips, status, user_id)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id
+	`, "Crème Brûlée", "6", longInstructions, "Chill overnight.", "published", suite.testUserID).Scan(&recipeID)
+	require.NoError(suite.T(), err)
+	suite.createTestIngredient(recipeID, "500 ml heavy cream", nil)
+
+	w := suite.performGet(fmt.Sprintf("/api/v1/recipes/%d/export?format=pdf", recipeID), "")
+	require.Equal(suite.T(), http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(suite.T(), "application/pdf", w.Header().Get("Content-Type"))
+	assert.Equal(suite.T(), `attachment; filename="creme-brulee.pdf"`, w.Header().Get("Content-Disposition"))
+
+	body := w.Body.Bytes()
+	assert.True(suite.T(), bytes.HasPrefix(body, []byte("%PDF")), "Response should be a PDF document")
+	assert.Greater(suite.T(), len(body), 2000, "PDF should not be trivially small")
+	assert.GreaterOrEqual(suite.T(), bytes.Count(body, []byte("/Type /Page\n")), 2, "Long instructions should break across pages")
+}
+
+// TestExportRecipePDFUnpublished tests drafts can only be exported as PDF by their owner
+func (suite *RecipeAPITestSuite) TestExportRecipePDFUnpublished() {
+	recipeID := suite.createTestRecipe("Draft", "review_required")
+	otherUserID := suite.createTestUser("other@example.com")
+	path := fmt.Sprintf("/api/v1/recipes/%d/export?format=pdf", recipeID)
+
+	w := suite.performGet(path, suite.authHeader(otherUserID, middleware.RoleUser))
+	assert.Equal(suite.T(), http.StatusNotFound, w.Code)
+
+	w = suite.performGet(path, suite.authHeader(suite.testUserID, middleware.RoleUser))
+	require.Equal(suite.T(), http.StatusOK, w.Code)
+	assert.True(suite.T(), bytes.HasPrefix(w.Body.Bytes(), []byte("%PDF")))
+}
@@ -0,0 +1,119 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"digital-recipes/api-service/handlers"
+	"digital-recipes/api-service/middleware"
+	"digital-recipes/api-service/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// realisticJSONLD is shaped like the structured data embedded by popular recipe sites
+const realisticJSONLD = `{
+	"@context": "https://schema.org/",
+	"@type": "Recipe",
+	"name": "Classic Banana Bread",
+	"author": {"@type": "Person", "name": "Jane Baker"},
+	"url": "https://example.com/classic-banana-bread",
+	"recipeYield": ["1", "1 loaf"],
+	"prepTime": "PT15M",
+	"cookTime": "PT1H",
+	"recipeIngredient": [
+		"3 ripe bananas, mashed",
+		"1/3 cup melted butter",
+		"¾ cup sugar",
+		"1 large egg, beaten",
+		"1 teaspoon baking soda",
+		"Pinch of salt",
+		"1 1/2 cups all-purpose flour"
+	],
+	"recipeInstructions": [
+		{"@type": "HowToStep", "text": "Preheat the oven to 175°C."},
+		{"@type": "HowToStep", "text": "Mix butter into the mashed bananas."},
+		{"@type": "HowToStep", "text": "Stir in the remaining ingredients and bake for 1 hour."}
+	]
+}`
+
+// TestImportRecipeFromJSONLD tests a realistic JSON-LD payload creates a recipe awaiting review
+func (suite *RecipeAPITestSuite) TestImportRecipeFromJSONLD() {
+	auth := suite.authHeader(suite.testUserID, middleware.RoleUser)
+	w := suite.performJSON("POST", "/api/v1/recipes/import", realisticJSONLD, auth)
+	require.Equal(suite.T(), http.StatusOK, w.Code, w.Body.String())
+
+	var response handlers.StandardResponse
+	require.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &response))
+	dataBytes, _ := json.Marshal(response.Data)
+	var recipe models.RecipeWithIngredients
+	require.NoError(suite.T(), json.Unmarshal(dataBytes, &recipe))
+
+	assert.Equal(suite.T(), "Classic Banana Bread", recipe.Title)
+	assert.Equal(suite.T(), "review_required", recipe.Status)
+	assert.Equal(suite.T(), suite.testUserID, recipe.UserID)
+	require.NotNil(suite.T(), recipe.Servings)
+	assert.Equal(suite.T(), "1", *recipe.Servings)
+	require.NotNil(suite.T(), recipe.Instructions)
+	assert.Equal(suite.T(),
+		"Preheat the oven to 175°C.\nMix butter into the mashed bananas.\nStir in the remaining ingredients and bake for 1 hour.",
+		*recipe.Instructions)
+	require.NotNil(suite.T(), recipe.PrepTimeMinutes)
+	assert.Equal(suite.T(), 15, *recipe.PrepTimeMinutes)
+	require.NotNil(suite.T(), recipe.CookTimeMinutes)
+	assert.Equal(suite.T(), 60, *recipe.CookTimeMinutes)
+	assert.Nil(suite.T(), recipe.Difficulty, "schema.org has no difficulty")
+	require.NotNil(suite.T(), recipe.SourceName)
+	assert.Equal(suite.T(), "Jane Baker", *recipe.SourceName)
+	require.NotNil(suite.T(), recipe.SourceURL)
+	assert.Equal(suite.T(), "https://example.com/classic-banana-bread", *recipe.SourceURL)
+
+	require.Len(suite.T(), recipe.Ingredients, 7)
+	assert.Equal(suite.T(), "3 ripe bananas, mashed", recipe.Ingredients[0].OriginalText)
+	require.NotNil(suite.T(), recipe.Ingredients[1].Quantity)
+	assert.InDelta(suite.T(), 1.0/3, *recipe.Ingredients[1].Quantity, 0.001)
+	require.NotNil(suite.T(), recipe.Ingredients[1].Unit)
+	assert.Equal(suite.T(), "cup", *recipe.Ingredients[1].Unit)
+	require.NotNil(suite.T(), recipe.Ingredients[6].Quantity)
+	assert.Equal(suite.T(), 1.5, *recipe.Ingredients[6].Quantity)
+
+	// The imported recipe is persisted with its ingredients
+	w = suite.performGet(fmt.Sprintf("/api/v1/recipes/%d", recipe.ID), "")
+	require.Equal(suite.T(), http.StatusOK, w.Code)
+	assert.Contains(suite.T(), w.Body.String(), "1 teaspoon baking soda")
+	assert.Contains(suite.T(), w.Body.String(), `"prep_time_minutes":15`)
+	assert.Contains(suite.T(), w.Body.String(), `"cook_time_minutes":60`)
+}
+
+// TestImportRecipeRejectsNonRecipe tests documents that are not a schema.org Recipe return 400
+func (suite *RecipeAPITestSuite) TestImportRecipeRejectsNonRecipe() {
+	auth := suite.authHeader(suite.testUserID, middleware.RoleUser)
+	w := suite.performJSON("POST", "/api/v1/recipes/import", `{"@context": "https://schema.org", "@type": "Article", "name": "Not a recipe"}`, auth)
+	assert.Equal(suite.T(), http.StatusBadRequest, w.Code)
+	assert.Contains(suite.T(), w.Body.String(), "@type must be Recipe")
+
+	var count int
+	require.NoError(suite.T(), suite.db.DB.QueryRow(`SELECT COUNT(*) FROM recipes`).Scan(&count))
+	assert.Equal(suite.T(), 0, count)
+}
+
+// TestImportRecipeIngredientCap tests an import at the ingredient cap is created and one just over it is refused with 422
+func (suite *RecipeAPITestSuite) TestImportRecipeIngredientCap() {
+	auth := suite.authHeader(suite.testUserID, middleware.RoleUser)
+	document := func(n int) string {
+		lines := strings.TrimSuffix(strings.Repeat(`"1 egg",`, n), ",")
+		return `{"@context": "https://schema.org", "@type": "Recipe", "name": "Omelette", "recipeIngredient": [` + lines + `]}`
+	}
+
+	w := suite.performJSON("POST", "/api/v1/recipes/import", document(handlers.DefaultMaxIngredientsPerRecipe+1), auth)
+	require.Equal(suite.T(), http.StatusUnprocessableEntity, w.Code, w.Body.String())
+	assert.Contains(suite.T(), w.Body.String(), `"field":"recipeIngredient"`)
+
+	w = suite.performJSON("POST", "/api/v1/recipes/import", document(handlers.DefaultMaxIngredientsPerRecipe), auth)
+	require.Equal(suite.T(), http.StatusOK, w.Code, w.Body.String())
+	var ingredients int
+	require.NoError(suite.T(), suite.db.DB.QueryRow(`SELECT COUNT(*) FROM recipe_ingredients`).Scan(&ingredients))
+	assert.Equal(suite.T(), handlers.DefaultMaxIngredientsPerRecipe, ingredients)
+}
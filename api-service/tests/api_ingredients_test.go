@@ -0,0 +1,386 @@
+package tests
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"digital-recipes/api-service/handlers"
+	"digital-recipes/api-service/middleware"
+	"digital-recipes/api-service/models"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// decodeLinkOrCreate extracts the link-or-create payload from a standard response
+func (suite *RecipeAPITestSuite) decodeLinkOrCreate(body []byte) models.LinkOrCreateResponse {
+	var response handlers.StandardResponse
+	require.NoError(suite.T(), json.Unmarshal(body, &response))
+	dataBytes, _ := json.Marshal(response.Data)
+	var result models.LinkOrCreateResponse
+	require.NoError(suite.T(), json.Unmarshal(dataBytes, &result))
+	return result
+}
+
+// TestLinkOrCreateMatchesExisting tests that normalized text resolves to an existing ingredient
+func (suite *RecipeAPITestSuite) TestLinkOrCreateMatchesExisting() {
+	flourID := suite.createCanonicalIngredient("All-Purpose Flour", true)
+	eggID := suite.createCanonicalIngredient("Egg", true)
+	auth := suite.authHeader(suite.testUserID, middleware.RoleUser)
+
+	w := suite.performJSON("POST", "/api/v1/ingredients/link-or-create", `{"original_text": "2 cups All-Purpose Flour, sifted"}`, auth)
+	require.Equal(suite.T(), http.StatusOK, w.Code, w.Body.String())
+	result := suite.decodeLinkOrCreate(w.Body.Bytes())
+	assert.False(suite.T(), result.Created)
+	assert.Equal(suite.T(), flourID, result.Ingredient.ID)
+	assert.Equal(suite.T(), "all-purpose flour", result.NormalizedName)
+
+	// Plural text matches the singular canonical name
+	w = suite.performJSON("POST", "/api/v1/ingredients/link-or-create", `{"original_text": "3 large eggs"}`, auth)
+	require.Equal(suite.T(), http.StatusOK, w.Code, w.Body.String())
+	result = suite.decodeLinkOrCreate(w.Body.Bytes())
+	assert.False(suite.T(), result.Created)
+	assert.Equal(suite.T(), eggID, result.Ingredient.ID)
+
+	var count int
+	require.NoError(suite.T(), suite.db.DB.QueryRow(`SELECT COUNT(*) FROM canonical_ingredients`).Scan(&count))
+	assert.Equal(suite.T(), 2, count, "No new ingredients should be created")
+}
+
+// TestLinkOrCreateCreatesUnapproved tests that fresh text creates a single unapproved ingredient
+func (suite *RecipeAPITestSuite) TestLinkOrCreateCreatesUnapproved() {
+	auth := suite.authHeader(suite.testUserID, middleware.RoleUser)
+
+	w := suite.performJSON("POST", "/api/v1/ingredients/link-or-create", `{"original_text": "1 tbsp Smoked Paprika"}`, auth)
+	require.Equal(suite.T(), http.StatusOK, w.Code, w.Body.String())
+	first := suite.decodeLinkOrCreate(w.Body.Bytes())
+	assert.True(suite.T(), first.Created)
+	assert.Equal(suite.T(), "smoked paprika", first.Ingredient.Name)
+	assert.False(suite.T(), first.Ingredient.IsApproved)
+
+	// Repeating the request reuses the ingredient created above
+	w = suite.performJSON("POST", "/api/v1/ingredients/link-or-create", `{"original_text": "2 tsp smoked paprika"}`, auth)
+	require.Equal(suite.T(), http.StatusOK, w.Code, w.Body.String())
+	second := suite.decodeLinkOrCreate(w.Body.Bytes())
+	assert.False(suite.T(), second.Created)
+	assert.Equal(suite.T(), first.Ingredient.ID, second.Ingredient.ID)
+}
+
+// TestLinkOrCreateValidation tests empty names and unauthenticated requests are rejected
+func (suite *RecipeAPITestSuite) TestLinkOrCreateValidation() {
+	auth := suite.authHeader(suite.testUserID, middleware.RoleUser)
+
+	w := suite.performJSON("POST", "/api/v1/ingredients/link-or-create", `{"original_text": "2 cups"}`, auth)
+	assert.Equal(suite.T(), http.StatusBadRequest, w.Code)
+
+	w = suite.performJSON("POST", "/api/v1/ingredients/link-or-create", `{}`, auth)
+	assert.Equal(suite.T(), http.StatusBadRequest, w.Code)
+
+	w = suite.performJSON("POST", "/api/v1/ingredients/link-or-create", `{"original_text": "salt"}`, "")
+	assert.Equal(suite.T(), http.StatusUnauthorized, w.Code)
+}
+
+// TestCreateIngredientCaseInsensitiveConflict tests that admins cannot add a name differing only in case
+func (suite *RecipeAPITestSuite) TestCreateIngredientCaseInsensitiveConflict() {
+	auth := suite.authHeader(suite.testUserID, middleware.RoleAdmin)
+
+	w := suite.performJSON("POST", "/api/v1/ingredients", `{"name": "  Eggs "}`, auth)
+	require.Equal(suite.T(), http.StatusOK, w.Code, w.Body.String())
+	var response handlers.StandardResponse
+	require.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &response))
+	data := response.Data.(map[string]interface{})
+	assert.Equal(suite.T(), "Eggs", data["name"])
+	assert.Equal(suite.T(), true, data["is_approved"])
+
+	w = suite.performJSON("POST", "/api/v1/ingredients", `{"name": "eggs"}`, auth)
+	assert.Equal(suite.T(), http.StatusConflict, w.Code, w.Body.String())
+
+	var count int
+	require.NoError(suite.T(), suite.db.DB.QueryRow(`SELECT COUNT(*) FROM canonical_ingredients WHERE LOWER(name) = 'eggs'`).Scan(&count))
+	assert.Equal(suite.T(), 1, count)
+}
+
+// TestCreateIngredientValidation tests blank names and non-admin callers are rejected
+func (suite *RecipeAPITestSuite) TestCreateIngredientValidation() {
+	w := suite.performJSON("POST", "/api/v1/ingredients", `{"name": "   "}`, suite.authHeader(suite.testUserID, middleware.RoleAdmin))
+	assert.Equal(suite.T(), http.StatusBadRequest, w.Code)
+
+	w = suite.performJSON("POST", "/api/v1/ingredients", `{"name": "Saffron"}`, suite.authHeader(suite.testUserID, middleware.RoleUser))
+	assert.Equal(suite.T(), http.StatusForbidden, w.Code)
+}
+
+// TestMergeIngredientsRepointsRecipeIngredients tests every link to the source moves to the target and the source is removed
+func (suite *RecipeAPITestSuite) TestMergeIngredientsRepointsRecipeIngredients() {
+	targetID := suite.createCanonicalIngredient("all-purpose flour", true)
+	sourceID := suite.createCanonicalIngredient("all purpose flour", false)
+	otherID := suite.createCanonicalIngredient("sugar", true)
+
+	bread := suite.createTestRecipe("Bread", "published")
+	cake := suite.createTestRecipe("Cake", "published")
+	breadFlour := suite.createTestIngredient(bread, "500g all purpose flour", &sourceID)
+	cakeFlour := suite.createTestIngredient(cake, "2 cups all purpose flour", &sourceID)
+	cakeSugar := suite.createTestIngredient(cake, "1 cup sugar", &otherID)
+	alreadyLinked := suite.createTestIngredient(cake, "1 tbsp all-purpose flour", &targetID)
+
+	body := fmt.Sprintf(`{"source_id": %d, "target_id": %d}`, sourceID, targetID)
+	w := suite.performJSON("POST", "/api/v1/ingredients/merge", body, suite.authHeader(suite.testUserID, middleware.RoleAdmin))
+	require.Equal(suite.T(), http.StatusOK, w.Code, w.Body.String())
+
+	var response handlers.StandardResponse
+	require.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &response))
+	dataBytes, _ := json.Marshal(response.Data)
+	var merged models.MergeIngredientsResponse
+	require.NoError(suite.T(), json.Unmarshal(dataBytes, &merged))
+	assert.Equal(suite.T(), 2, merged.RecipeIngredientsRepointed)
+	assert.Equal(suite.T(), targetID, merged.Target.ID)
+	assert.Equal(suite.T(), "all-purpose flour", merged.Target.Name)
+
+	linkOf := func(ingredientID int) int {
+		var canonicalID int
+		require.NoError(suite.T(), suite.db.DB.QueryRow(`SELECT canonical_ingredient_id FROM recipe_ingredients WHERE id = $1`, ingredientID).Scan(&canonicalID))
+		return canonicalID
+	}
+	assert.Equal(suite.T(), targetID, linkOf(breadFlour))
+	assert.Equal(suite.T(), targetID, linkOf(cakeFlour))
+	assert.Equal(suite.T(), targetID, linkOf(alreadyLinked))
+	assert.Equal(suite.T(), otherID, linkOf(cakeSugar), "Links to other ingredients are untouched")
+
+	var exists bool
+	require.NoError(suite.T(), suite.db.DB.QueryRow(`SELECT EXISTS(SELECT 1 FROM canonical_ingredients WHERE id = $1)`, sourceID).Scan(&exists))
+	assert.False(suite.T(), exists, "The source ingredient is deleted")
+
+	// The source is gone, so merging it again finds nothing
+	w = suite.performJSON("POST", "/api/v1/ingredients/merge", body, suite.authHeader(suite.testUserID, middleware.RoleAdmin))
+	assert.Equal(suite.T(), http.StatusNotFound, w.Code, w.Body.String())
+}
+
+// TestMergeIngredientsRejections tests unknown targets and non-admin callers leave both ingredients in place
+func (suite *RecipeAPITestSuite) TestMergeIngredientsRejections() {
+	sourceID := suite.createCanonicalIngredient("cilantro", true)
+	recipeID := suite.createTestRecipe("Salsa", "published")
+	ingredientID := suite.createTestIngredient(recipeID, "1 bunch cilantro", &sourceID)
+
+	body := fmt.Sprintf(`{"source_id": %d, "target_id": %d}`, sourceID, NonExistentID)
+	w := suite.performJSON("POST", "/api/v1/ingredients/merge", body, suite.authHeader(suite.testUserID, middleware.RoleAdmin))
+	assert.Equal(suite.T(), http.StatusNotFound, w.Code, w.Body.String())
+	var response map[string]interface{}
+	require.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(suite.T(), "target ingredient not found", response["error"])
+
+	coriander := suite.createCanonicalIngredient("coriander", true)
+	body = fmt.Sprintf(`{"source_id": %d, "target_id": %d}`, sourceID, coriander)
+	w = suite.performJSON("POST", "/api/v1/ingredients/merge", body, suite.authHeader(suite.testUserID, middleware.RoleUser))
+	assert.Equal(suite.T(), http.StatusForbidden, w.Code)
+
+	var canonicalID int
+	require.NoError(suite.T(), suite.db.DB.QueryRow(`SELECT canonical_ingredient_id FROM recipe_ingredients WHERE id = $1`, ingredientID).Scan(&canonicalID))
+	assert.Equal(suite.T(), sourceID, canonicalID)
+}
+
+// TestMergeIngredientsValidationWithoutDatabase tests malformed and self-merges are rejected before any query runs
+func TestMergeIngredientsValidationWithoutDatabase(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	database := openScriptedDatabase(t)
+	handler := handlers.NewIngredientHandler(database)
+
+	router := gin.New()
+	router.POST("/api/v1/ingredients/merge", handler.MergeIngredients)
+
+	tests := []struct {
+		name    string
+		body    string
+		field   interface{}
+		message string
+	}{
+		{"Self merge", `{"source_id": 4, "target_id": 4}`, "target_id", "An ingredient cannot be merged into itself"},
+		{"Missing target", `{"source_id": 4}`, nil, "Invalid request format. Check source_id and target_id fields."},
+		{"Non-positive source", `{"source_id": -1, "target_id": 4}`, nil, "Invalid request format. Check source_id and target_id fields."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/api/v1/ingredients/merge", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(w, req)
+			require.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+
+			var response map[string]interface{}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.message, response["error"])
+			assert.Equal(t, tt.field, response["field"])
+		})
+	}
+	assert.Empty(t, scriptedQueries())
+}
+
+// listIngredients fetches the ingredient listing as an admin and decodes it
+func (suite *RecipeAPITestSuite) listIngredients(query string) ([]models.IngredientUsage, handlers.Pagination) {
+	w := suite.performJSON("GET", "/api/v1/ingredients"+query, "", suite.authHeader(suite.testUserID, middleware.RoleAdmin))
+	require.Equal(suite.T(), http.StatusOK, w.Code, w.Body.String())
+
+	var response struct {
+		Data       []models.IngredientUsage `json:"data"`
+		Pagination handlers.Pagination      `json:"pagination"`
+	}
+	require.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &response))
+	return response.Data, response.Pagination
+}
+
+// TestGetIngredientsSortedByUsage tests the most used ingredients across recipes are listed first
+func (suite *RecipeAPITestSuite) TestGetIngredientsSortedByUsage() {
+	salt := suite.createCanonicalIngredient("salt", true)
+	butter := suite.createCanonicalIngredient("butter", false)
+	basil := suite.createCanonicalIngredient("basil", false)
+	suite.createCanonicalIngredient("anise", false)
+
+	soup := suite.createTestRecipe("Soup", "published")
+	bread := suite.createTestRecipe("Bread", "published")
+	pesto := suite.createTestRecipe("Pesto", "published")
+	suite.createTestIngredient(soup, "1 tsp salt", &salt)
+	suite.createTestIngredient(bread, "2 tsp salt", &salt)
+	suite.createTestIngredient(pesto, "a pinch of salt", &salt)
+	suite.createTestIngredient(soup, "2 tbsp butter", &butter)
+	suite.createTestIngredient(bread, "50g butter", &butter)
+	suite.createTestIngredient(pesto, "1 cup basil", &basil)
+	suite.createTestIngredient(pesto, "pine nuts", nil)
+
+	names := func(listed []models.IngredientUsage) []string {
+		result := []string{}
+		for _, ingredient := range listed {
+			result = append(result, fmt.Sprintf("%s:%d", ingredient.Name, ingredient.UsageCount))
+		}
+		return result
+	}
+
+	listed, pagination := suite.listIngredients("?sort=usage")
+	assert.Equal(suite.T(), []string{"salt:3", "butter:2", "basil:1", "anise:0"}, names(listed))
+	assert.Equal(suite.T(), 4, pagination.Total)
+
+	listed, _ = suite.listIngredients("?sort=usage&approved=false")
+	assert.Equal(suite.T(), []string{"butter:2", "basil:1", "anise:0"}, names(listed), "Unapproved ingredients in order of use")
+
+	listed, pagination = suite.listIngredients("?sort=usage&per_page=2&page=2")
+	assert.Equal(suite.T(), []string{"basil:1", "anise:0"}, names(listed))
+	assert.Equal(suite.T(), 2, pagination.TotalPages)
+
+	listed, _ = suite.listIngredients("")
+	assert.Equal(suite.T(), []string{"anise:0", "basil:1", "butter:2", "salt:3"}, names(listed), "The default order is by name")
+
+	w := suite.performJSON("GET", "/api/v1/ingredients", "", suite.authHeader(suite.testUserID, middleware.RoleUser))
+	assert.Equal(suite.T(), http.StatusForbidden, w.Code)
+}
+
+// TestGetIngredientsValidationWithoutDatabase tests unknown sort orders and approval filters are rejected before querying
+func TestGetIngredientsValidationWithoutDatabase(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	database := openScriptedDatabase(t)
+	router := gin.New()
+	router.GET("/api/v1/ingredients", handlers.NewIngredientHandler(database).GetIngredients)
+
+	for query, field := range map[string]string{
+		"sort=popularity": "sort",
+		"approved=maybe":  "approved",
+		"page=0":          "page",
+	} {
+		t.Run(query, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/api/v1/ingredients?"+query, nil)
+			router.ServeHTTP(w, req)
+			require.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+
+			var response map[string]interface{}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, field, response["field"])
+		})
+	}
+	assert.Empty(t, scriptedQueries())
+}
+
+// TestCreateIngredientConflictWithoutDatabase tests the case-insensitive lookup short-circuits the insert
+func TestCreateIngredientConflictWithoutDatabase(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	database := openScriptedDatabase(t, scriptedResult{
+		match:   "WHERE LOWER(name) = LOWER($1)",
+		columns: []string{"id"},
+		rows:    [][]driver.Value{{int64(12)}},
+	})
+	handler := handlers.NewIngredientHandler(database)
+
+	router := gin.New()
+	router.POST("/api/v1/ingredients", handler.CreateIngredient)
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/v1/ingredients", strings.NewReader(`{"name": "eggs"}`))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code, w.Body.String())
+	for _, query := range scriptedQueries() {
+		assert.NotContains(t, query, "INSERT INTO canonical_ingredients")
+	}
+}
+
+// TestRenameIngredient tests a rename is normalized, shows on linked recipe ingredients and refuses another ingredient's name
+func (suite *RecipeAPITestSuite) TestRenameIngredient() {
+	tomatoID := suite.createCanonicalIngredient("Tomatoe", true)
+	suite.createCanonicalIngredient("Onion", true)
+	recipeID := suite.createTestRecipe("Salsa", "published")
+	suite.createTestIngredient(recipeID, "2 tomatoes", &tomatoID)
+	auth := suite.authHeader(suite.testUserID, middleware.RoleAdmin)
+	path := fmt.Sprintf("/api/v1/ingredients/%d", tomatoID)
+
+	w := suite.performJSON("PATCH", path, `{"name": "  Tomato  "}`, auth)
+	require.Equal(suite.T(), http.StatusOK, w.Code, w.Body.String())
+	var response handlers.StandardResponse
+	require.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &response))
+	data := response.Data.(map[string]interface{})
+	assert.Equal(suite.T(), float64(tomatoID), data["id"])
+	assert.Equal(suite.T(), "Tomato", data["name"])
+
+	// Linked recipe ingredients pick up the new name through their foreign key
+	w = suite.performGet(fmt.Sprintf("/api/v1/recipes/%d/ingredients", recipeID), "")
+	require.Equal(suite.T(), http.StatusOK, w.Code)
+	assert.Contains(suite.T(), w.Body.String(), `"canonical_name":"Tomato"`)
+
+	// Changing only the case is not a conflict with itself
+	w = suite.performJSON("PATCH", path, `{"name": "tomato"}`, auth)
+	assert.Equal(suite.T(), http.StatusOK, w.Code, w.Body.String())
+
+	w = suite.performJSON("PATCH", path, `{"name": "ONION"}`, auth)
+	assert.Equal(suite.T(), http.StatusConflict, w.Code, w.Body.String())
+	var name string
+	require.NoError(suite.T(), suite.db.DB.QueryRow(`SELECT name FROM canonical_ingredients WHERE id = $1`, tomatoID).Scan(&name))
+	assert.Equal(suite.T(), "tomato", name, "A conflicting rename changes nothing")
+
+	assert.Equal(suite.T(), http.StatusBadRequest, suite.performJSON("PATCH", path, `{"name": "   "}`, auth).Code)
+	assert.Equal(suite.T(), http.StatusNotFound, suite.performJSON("PATCH", fmt.Sprintf("/api/v1/ingredients/%d", NonExistentID), `{"name": "Leek"}`, auth).Code)
+	assert.Equal(suite.T(), http.StatusForbidden, suite.performJSON("PATCH", path, `{"name": "Tomatoes"}`, suite.authHeader(suite.testUserID, middleware.RoleUser)).Code)
+}
+
+// TestRenameIngredientConflictWithoutDatabase tests a name held by another ingredient is refused before the update
+func TestRenameIngredientConflictWithoutDatabase(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	database := openScriptedDatabase(t, scriptedResult{
+		match:   "WHERE LOWER(name) = LOWER($1) AND id <> $2",
+		columns: []string{"id"},
+		rows:    [][]driver.Value{{int64(12)}},
+	})
+	handler := handlers.NewIngredientHandler(database)
+
+	router := gin.New()
+	router.PATCH("/api/v1/ingredients/:id", handler.RenameIngredient)
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("PATCH", "/api/v1/ingredients/7", strings.NewReader(`{"name": "Eggs"}`))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code, w.Body.String())
+	for _, query := range scriptedQueries() {
+		assert.NotContains(t, query, "UPDATE canonical_ingredients")
+	}
+}
@@ -0,0 +1,98 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"digital-recipes/api-service/handlers"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenAPISpecEndpoint(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/openapi.json", handlers.GetOpenAPISpec)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/openapi.json", nil)
+	r.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Type"), "application/json")
+
+	var spec struct {
+		OpenAPI    string                            `json:"openapi"`
+		Paths      map[string]map[string]interface{} `json:"paths"`
+		Components struct {
+			Schemas map[string]interface{} `json:"schemas"`
+		} `json:"components"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &spec), "Spec should be valid JSON")
+
+	assert.Regexp(t, `^3\.`, spec.OpenAPI, "Spec should be OpenAPI 3")
+
+	expectedPaths := map[string]string{
+		"/health":                        "get",
+		"/api/v1/recipes":                "get",
+		"/api/v1/recipes/{id}":           "get",
+		"/api/v1/recipes/upload-request": "post",
+	}
+	for path, method := range expectedPaths {
+		require.Contains(t, spec.Paths, path, "Spec should describe %s", path)
+		assert.Contains(t, spec.Paths[path], method, "Spec should describe %s %s", method, path)
+	}
+
+	for _, schema := range []string{"StandardResponse", "Pagination", "AppError", "Recipe", "UploadRequest"} {
+		assert.Contains(t, spec.Components.Schemas, schema, "Spec should define the %s schema", schema)
+	}
+}
+
+// collectRefs walks a decoded JSON document and returns every $ref value
+func collectRefs(node interface{}, refs *[]string) {
+	switch value := node.(type) {
+	case map[string]interface{}:
+		for key, child := range value {
+			if ref, ok := child.(string); ok && key == "$ref" {
+				*refs = append(*refs, ref)
+				continue
+			}
+			collectRefs(child, refs)
+		}
+	case []interface{}:
+		for _, child := range value {
+			collectRefs(child, refs)
+		}
+	}
+}
+
+func TestOpenAPISpecReferencesResolve(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/openapi.json", handlers.GetOpenAPISpec)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/openapi.json", nil)
+	r.ServeHTTP(w, req)
+
+	var spec map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &spec))
+	components := spec["components"].(map[string]interface{})
+
+	var refs []string
+	collectRefs(spec, &refs)
+	require.NotEmpty(t, refs)
+
+	for _, ref := range refs {
+		// References have the form #/components/<section>/<name>
+		parts := strings.Split(strings.TrimPrefix(ref, "#/components/"), "/")
+		require.Len(t, parts, 2, "Unexpected reference format %s", ref)
+		section, ok := components[parts[0]].(map[string]interface{})
+		require.True(t, ok, "Missing components section for %s", ref)
+		assert.Contains(t, section, parts[1], "Unresolved reference %s", ref)
+	}
+}
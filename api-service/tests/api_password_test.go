@@ -0,0 +1,71 @@
+package tests
+
+import (
+	"database/sql"
+	"net/http"
+
+	"digital-recipes/api-service/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// setUserPassword stores a bcrypt hash of password for a user
+func (suite *RecipeAPITestSuite) setUserPassword(userID int, password string) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	require.NoError(suite.T(), err)
+	_, err = suite.db.DB.Exec(`UPDATE users SET password_hash = $1 WHERE id = $2`, string(hash), userID)
+	require.NoError(suite.T(), err, "Failed to set test password")
+}
+
+// storedPasswordMatches reports whether the user's stored hash matches password
+func (suite *RecipeAPITestSuite) storedPasswordMatches(userID int, password string) bool {
+	var hash sql.NullString
+	err := suite.db.DB.QueryRow(`SELECT password_hash FROM users WHERE id = $1`, userID).Scan(&hash)
+	require.NoError(suite.T(), err)
+	return hash.Valid && bcrypt.CompareHashAndPassword([]byte(hash.String), []byte(password)) == nil
+}
+
+// TestChangePassword tests a correct current password replaces the stored hash
+func (suite *RecipeAPITestSuite) TestChangePassword() {
+	suite.setUserPassword(suite.testUserID, "original1")
+	auth := suite.authHeader(suite.testUserID, middleware.RoleUser)
+
+	w := suite.performJSON("POST", "/api/v1/users/me/password", `{"current_password": "original1", "new_password": "replacement2"}`, auth)
+	require.Equal(suite.T(), http.StatusOK, w.Code, w.Body.String())
+
+	assert.True(suite.T(), suite.storedPasswordMatches(suite.testUserID, "replacement2"))
+	assert.False(suite.T(), suite.storedPasswordMatches(suite.testUserID, "original1"))
+	assert.NotContains(suite.T(), w.Body.String(), "$2a$", "Hashes are never returned")
+}
+
+// TestChangePasswordWrongCurrent tests a wrong current password is rejected with 401
+func (suite *RecipeAPITestSuite) TestChangePasswordWrongCurrent() {
+	suite.setUserPassword(suite.testUserID, "original1")
+	auth := suite.authHeader(suite.testUserID, middleware.RoleUser)
+
+	w := suite.performJSON("POST", "/api/v1/users/me/password", `{"current_password": "guessing1", "new_password": "replacement2"}`, auth)
+	assert.Equal(suite.T(), http.StatusUnauthorized, w.Code)
+	assert.True(suite.T(), suite.storedPasswordMatches(suite.testUserID, "original1"), "Password is unchanged")
+
+	// Accounts without a password cannot be changed through this endpoint
+	otherID := suite.createTestUser("nopassword@example.com")
+	w = suite.performJSON("POST", "/api/v1/users/me/password", `{"current_password": "anything1", "new_password": "replacement2"}`, suite.authHeader(otherID, middleware.RoleUser))
+	assert.Equal(suite.T(), http.StatusUnauthorized, w.Code)
+}
+
+// TestChangePasswordWeakNew tests new passwords failing the strength rules are rejected with 400
+func (suite *RecipeAPITestSuite) TestChangePasswordWeakNew() {
+	suite.setUserPassword(suite.testUserID, "original1")
+	auth := suite.authHeader(suite.testUserID, middleware.RoleUser)
+
+	for _, body := range []string{
+		`{"current_password": "original1", "new_password": "short1"}`,
+		`{"current_password": "original1", "new_password": "nodigitshere"}`,
+		`{"current_password": "original1"}`,
+	} {
+		w := suite.performJSON("POST", "/api/v1/users/me/password", body, auth)
+		assert.Equal(suite.T(), http.StatusBadRequest, w.Code, body)
+	}
+	assert.True(suite.T(), suite.storedPasswordMatches(suite.testUserID, "original1"))
+}
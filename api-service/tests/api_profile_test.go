@@ -0,0 +1,100 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"digital-recipes/api-service/handlers"
+	"digital-recipes/api-service/middleware"
+	"digital-recipes/api-service/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// decodeUser extracts a user from a standard response
+func (suite *RecipeAPITestSuite) decodeUser(body []byte) models.User {
+	var response handlers.StandardResponse
+	require.NoError(suite.T(), json.Unmarshal(body, &response))
+	dataBytes, _ := json.Marshal(response.Data)
+	var user models.User
+	require.NoError(suite.T(), json.Unmarshal(dataBytes, &user))
+	return user
+}
+
+// TestGetCurrentUser tests the profile endpoint returns the authenticated user
+func (suite *RecipeAPITestSuite) TestGetCurrentUser() {
+	w := suite.performGet("/api/v1/users/me", suite.authHeader(suite.testUserID, middleware.RoleUser))
+	require.Equal(suite.T(), http.StatusOK, w.Code, w.Body.String())
+
+	user := suite.decodeUser(w.Body.Bytes())
+	assert.Equal(suite.T(), suite.testUserID, user.ID)
+	assert.Equal(suite.T(), "testuser@example.com", user.Email)
+	assert.Equal(suite.T(), "Test User", user.Name)
+	assert.False(suite.T(), user.CreatedAt.IsZero())
+
+	w = suite.performGet("/api/v1/users/me", "")
+	assert.Equal(suite.T(), http.StatusUnauthorized, w.Code)
+
+	w = suite.performGet("/api/v1/users/me", suite.authHeader(NonExistentID, middleware.RoleUser))
+	assert.Equal(suite.T(), http.StatusNotFound, w.Code)
+}
+
+// TestUpdateCurrentUser tests partial profile updates
+func (suite *RecipeAPITestSuite) TestUpdateCurrentUser() {
+	auth := suite.authHeader(suite.testUserID, middleware.RoleUser)
+
+	w := suite.performJSON("PATCH", "/api/v1/users/me", `{"name": "  Renamed Cook  "}`, auth)
+	require.Equal(suite.T(), http.StatusOK, w.Code, w.Body.String())
+	user := suite.decodeUser(w.Body.Bytes())
+	assert.Equal(suite.T(), "Renamed Cook", user.Name)
+	assert.Equal(suite.T(), "testuser@example.com", user.Email, "Omitted email is kept")
+
+	w = suite.performJSON("PATCH", "/api/v1/users/me", `{"email": "New.Address@Example.com"}`, auth)
+	require.Equal(suite.T(), http.StatusOK, w.Code, w.Body.String())
+	user = suite.decodeUser(w.Body.Bytes())
+	assert.Equal(suite.T(), "new.address@example.com", user.Email)
+	assert.Equal(suite.T(), "Renamed Cook", user.Name, "Omitted name is kept")
+
+	// Re-saving your own email is not a conflict
+	w = suite.performJSON("PATCH", "/api/v1/users/me", `{"email": "new.address@example.com"}`, auth)
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+
+	for _, body := range []string{`{}`, `{"name": "   "}`, `{"email": "not-an-email"}`} {
+		w = suite.performJSON("PATCH", "/api/v1/users/me", body, auth)
+		assert.Equal(suite.T(), http.StatusBadRequest, w.Code, body)
+	}
+
+	w = suite.performJSON("PATCH", "/api/v1/users/me", `{"name": "Anonymous"}`, "")
+	assert.Equal(suite.T(), http.StatusUnauthorized, w.Code)
+}
+
+// TestUpdateCurrentUserEmailValidation tests emails are stored normalized and malformed ones are rejected by field
+func (suite *RecipeAPITestSuite) TestUpdateCurrentUserEmailValidation() {
+	auth := suite.authHeader(suite.testUserID, middleware.RoleUser)
+
+	w := suite.performJSON("PATCH", "/api/v1/users/me", `{"email": "  Padded.Cook@Example.COM\t"}`, auth)
+	require.Equal(suite.T(), http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(suite.T(), "padded.cook@example.com", suite.decodeUser(w.Body.Bytes()).Email)
+
+	for _, email := range []string{"not-an-email", "two@@example.com", "Cook <cook@example.com>", "cook@localhost", "cook@example.", "   "} {
+		body, _ := json.Marshal(map[string]string{"email": email})
+		w := suite.performJSON("PATCH", "/api/v1/users/me", string(body), auth)
+		assert.Equal(suite.T(), http.StatusBadRequest, w.Code, email)
+		assert.Contains(suite.T(), w.Body.String(), `"field":"email"`, email)
+	}
+
+	w = suite.performGet("/api/v1/users/me", auth)
+	assert.Equal(suite.T(), "padded.cook@example.com", suite.decodeUser(w.Body.Bytes()).Email, "Rejected emails change nothing")
+}
+
+// TestUpdateCurrentUserEmailConflict tests another account's email cannot be taken
+func (suite *RecipeAPITestSuite) TestUpdateCurrentUserEmailConflict() {
+	suite.createTestUser("taken@example.com")
+
+	w := suite.performJSON("PATCH", "/api/v1/users/me", `{"email": "Taken@Example.com"}`, suite.authHeader(suite.testUserID, middleware.RoleUser))
+	assert.Equal(suite.T(), http.StatusConflict, w.Code)
+	assert.Contains(suite.T(), w.Body.String(), "email is already in use")
+
+	w = suite.performGet("/api/v1/users/me", suite.authHeader(suite.testUserID, middleware.RoleUser))
+	assert.Equal(suite.T(), "testuser@example.com", suite.decodeUser(w.Body.Bytes()).Email, "Failed updates change nothing")
+}
@@ -0,0 +1,138 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"digital-recipes/api-service/handlers"
+	"digital-recipes/api-service/middleware"
+	"digital-recipes/api-service/models"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// decodeBatchStatusResponse extracts a batch status response from a standard response
+func decodeBatchStatusResponse(t *testing.T, body []byte) models.BatchStatusResponse {
+	var response handlers.StandardResponse
+	require.NoError(t, json.Unmarshal(body, &response))
+	dataBytes, _ := json.Marshal(response.Data)
+	var batch models.BatchStatusResponse
+	require.NoError(t, json.Unmarshal(dataBytes, &batch))
+	return batch
+}
+
+// TestBatchUpdateRecipeStatusMixedBatch tests each recipe in a batch gets its own outcome
+// and that failed recipes do not stop the legal transitions from being applied
+func (suite *RecipeAPITestSuite) TestBatchUpdateRecipeStatusMixedBatch() {
+	reviewed := suite.createTestRecipe("Reviewed Soup", "review_required")
+	processing := suite.createTestRecipe("Still Scanning", "processing")
+	alreadyPublished := suite.createTestRecipe("Published Bread", "published")
+
+	otherID := suite.createTestUser("other@example.com")
+	othersRecipe := suite.createTestRecipe("Someone Else's Pie", "review_required")
+	_, err := suite.db.DB.Exec(`UPDATE recipes SET user_id = $1 WHERE id = $2`, otherID, othersRecipe)
+	require.NoError(suite.T(), err)
+
+	body := fmt.Sprintf(`{"ids": [%d, %d, %d, %d, %d], "status": "published"}`,
+		processing, reviewed, othersRecipe, NonExistentID, alreadyPublished)
+	w := suite.performJSON("POST", "/api/v1/recipes/batch/status", body, suite.authHeader(suite.testUserID, middleware.RoleUser))
+	require.Equal(suite.T(), http.StatusOK, w.Code, w.Body.String())
+
+	batch := decodeBatchStatusResponse(suite.T(), w.Body.Bytes())
+	assert.Equal(suite.T(), 2, batch.Updated)
+	assert.Equal(suite.T(), 3, batch.Failed)
+	require.Len(suite.T(), batch.Results, 5)
+
+	// Results come back in request order regardless of the order rows were locked in
+	assert.Equal(suite.T(), models.BatchStatusResult{
+		ID: processing, Result: models.BatchResultFailed, Reason: "cannot change status from processing to published",
+	}, batch.Results[0])
+	assert.Equal(suite.T(), models.BatchStatusResult{ID: reviewed, Result: models.BatchResultUpdated, Status: "published"}, batch.Results[1])
+	assert.Equal(suite.T(), othersRecipe, batch.Results[2].ID)
+	assert.Equal(suite.T(), models.BatchResultForbidden, batch.Results[2].Result)
+	assert.NotEmpty(suite.T(), batch.Results[2].Reason)
+	assert.Equal(suite.T(), models.BatchStatusResult{ID: NonExistentID, Result: models.BatchResultNotFound, Reason: "recipe not found"}, batch.Results[3])
+	assert.Equal(suite.T(), models.BatchStatusResult{ID: alreadyPublished, Result: models.BatchResultUpdated, Status: "published"}, batch.Results[4])
+
+	statusOf := func(recipeID int) string {
+		var status string
+		require.NoError(suite.T(), suite.db.DB.QueryRow(`SELECT status FROM recipes WHERE id = $1`, recipeID).Scan(&status))
+		return status
+	}
+	assert.Equal(suite.T(), "published", statusOf(reviewed))
+	assert.Equal(suite.T(), "processing", statusOf(processing))
+	assert.Equal(suite.T(), "review_required", statusOf(othersRecipe))
+	assert.Equal(suite.T(), 1, suite.countRevisions(reviewed), "Batch updates keep the replaced state as a revision")
+	assert.Equal(suite.T(), 0, suite.countRevisions(processing))
+	assert.Equal(suite.T(), 0, suite.countRevisions(othersRecipe))
+
+	// Admins may change any recipe
+	body = fmt.Sprintf(`{"ids": [%d], "status": "published"}`, othersRecipe)
+	w = suite.performJSON("POST", "/api/v1/recipes/batch/status", body, suite.authHeader(suite.testUserID, middleware.RoleAdmin))
+	require.Equal(suite.T(), http.StatusOK, w.Code, w.Body.String())
+	batch = decodeBatchStatusResponse(suite.T(), w.Body.Bytes())
+	assert.Equal(suite.T(), 1, batch.Updated)
+	assert.Equal(suite.T(), "published", statusOf(othersRecipe))
+}
+
+// TestBatchUpdateRecipeStatusRequiresAuthentication tests anonymous callers cannot run a batch
+func (suite *RecipeAPITestSuite) TestBatchUpdateRecipeStatusRequiresAuthentication() {
+	recipeID := suite.createTestRecipe("Anonymous Target", "review_required")
+	body := fmt.Sprintf(`{"ids": [%d], "status": "published"}`, recipeID)
+	w := suite.performJSON("POST", "/api/v1/recipes/batch/status", body, "")
+	assert.Equal(suite.T(), http.StatusUnauthorized, w.Code)
+}
+
+// TestBatchUpdateRecipeStatusValidationWithoutDatabase tests malformed and oversized batches are rejected before any query runs
+func TestBatchUpdateRecipeStatusValidationWithoutDatabase(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	database := openScriptedDatabase(t)
+	handler := handlers.NewRecipeHandler(database, nil)
+
+	router := gin.New()
+	router.POST("/api/v1/recipes/batch/status", func(c *gin.Context) {
+		c.Set("user_id", 7)
+		c.Next()
+	}, handler.BatchUpdateRecipeStatus)
+
+	tooMany := make([]string, models.MaxBatchStatusRecipes+1)
+	for i := range tooMany {
+		tooMany[i] = fmt.Sprint(i + 1)
+	}
+
+	tests := []struct {
+		name    string
+		body    string
+		field   string
+		message string
+	}{
+		{"missing ids", `{"status": "published"}`, "ids", "ids must list at least one recipe"},
+		{"empty ids", `{"ids": [], "status": "published"}`, "ids", "ids must list at least one recipe"},
+		{"too many ids", `{"ids": [` + strings.Join(tooMany, ",") + `], "status": "published"}`, "ids",
+			fmt.Sprintf("at most %d recipes can be updated at once", models.MaxBatchStatusRecipes)},
+		{"duplicate ids", `{"ids": [3, 3], "status": "published"}`, "ids", "ids must be unique positive recipe IDs"},
+		{"non-positive id", `{"ids": [0], "status": "published"}`, "ids", "ids must be unique positive recipe IDs"},
+		{"unknown status", `{"ids": [3], "status": "archived"}`, "status", "status must be one of processing, review_required, published"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/api/v1/recipes/batch/status", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(w, req)
+			require.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+
+			var response map[string]interface{}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.field, response["field"])
+			assert.Equal(t, tt.message, response["error"])
+		})
+	}
+	assert.Empty(t, scriptedQueries(), "Validation happens before any recipe is loaded")
+}
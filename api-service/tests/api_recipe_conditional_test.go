@@ -0,0 +1,118 @@
+package tests
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"digital-recipes/api-service/handlers"
+	"digital-recipes/api-service/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestConditionalRecipeUpdate tests an edit based on a stale copy is refused while one based on the latest copy applies
+func (suite *RecipeAPITestSuite) TestConditionalRecipeUpdate() {
+	recipeID := suite.createTestRecipe("Stew", "review_required")
+	auth := suite.authHeader(suite.testUserID, middleware.RoleUser)
+	path := fmt.Sprintf("/api/v1/recipes/%d", recipeID)
+
+	// The first reviewer loaded the recipe an hour ago; another reviewer has saved since
+	staleCopy := time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat)
+	_, err := suite.db.DB.Exec(`UPDATE recipes SET title = 'Beef Stew' WHERE id = $1`, recipeID)
+	require.NoError(suite.T(), err)
+
+	conditional := func(method, body, since string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", auth)
+		req.Header.Set("If-Unmodified-Since", since)
+		suite.router.ServeHTTP(w, req)
+		return w
+	}
+
+	for _, method := range []string{"PUT", "PATCH"} {
+		w := conditional(method, `{"title": "Lamb Stew"}`, staleCopy)
+		require.Equal(suite.T(), http.StatusPreconditionFailed, w.Code, method)
+		var response map[string]interface{}
+		require.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(suite.T(), "PRECONDITION_FAILED", response["code"])
+	}
+
+	var title string
+	var revisions int
+	require.NoError(suite.T(), suite.db.DB.QueryRow(`SELECT title FROM recipes WHERE id = $1`, recipeID).Scan(&title))
+	require.NoError(suite.T(), suite.db.DB.QueryRow(`SELECT COUNT(*) FROM recipe_revisions WHERE recipe_id = $1`, recipeID).Scan(&revisions))
+	assert.Equal(suite.T(), "Beef Stew", title, "The other reviewer's change is kept")
+	assert.Zero(suite.T(), revisions, "Refused updates record no revision")
+
+	// Reloading gives a Last-Modified the next update can be made conditional on
+	w := suite.performGet(path, "")
+	require.Equal(suite.T(), http.StatusOK, w.Code)
+	lastModified := w.Header().Get("Last-Modified")
+	_, err = http.ParseTime(lastModified)
+	require.NoError(suite.T(), err, "GET sends the recipe's updated_at as Last-Modified")
+	w = conditional("PUT", `{"title": "Lamb Stew"}`, lastModified)
+	require.Equal(suite.T(), http.StatusOK, w.Code, w.Body.String())
+	assert.Contains(suite.T(), w.Body.String(), `"title":"Lamb Stew"`)
+	assert.NotEmpty(suite.T(), w.Header().Get("Last-Modified"))
+
+	// Dates that cannot be parsed are ignored rather than refusing the update
+	w = conditional("PATCH", `{"title": "Irish Stew"}`, "yesterday")
+	assert.Equal(suite.T(), http.StatusOK, w.Code, w.Body.String())
+}
+
+// TestConditionalRecipeUpdateWithoutDatabase tests the precondition is checked against the locked row before anything is written
+func TestConditionalRecipeUpdateWithoutDatabase(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	updatedAt := time.Date(2026, 3, 14, 9, 30, 15, 250000000, time.UTC)
+	recipeRow := []driver.Value{int64(5), "Stew", nil, nil, nil, "review_required", int64(7), updatedAt, updatedAt, nil, nil, nil, nil, nil, nil, "public", false, nil}
+	recipeColumns := []string{"id", "title", "servings", "instructions", "tips", "status", "user_id", "created_at", "updated_at", "prep_time_minutes", "cook_time_minutes", "difficulty", "source_name", "source_url", "last_modified_by", "visibility", "featured", "featured_at"}
+
+	update := func(since string) *httptest.ResponseRecorder {
+		database := openScriptedDatabase(t,
+			scriptedResult{match: "FOR UPDATE", columns: recipeColumns, rows: [][]driver.Value{recipeRow}},
+			scriptedResult{match: "SET title", columns: recipeColumns, rows: [][]driver.Value{recipeRow}},
+			scriptedResult{match: "FROM recipe_ingredients", columns: []string{"id", "recipe_id", "canonical_ingredient_id", "original_text", "quantity", "unit", "created_at", "updated_at", "canonical_name"}},
+			scriptedResult{match: "FROM recipes", columns: recipeColumns, rows: [][]driver.Value{recipeRow}},
+		)
+		router := gin.New()
+		router.PUT("/api/v1/recipes/:id", func(c *gin.Context) {
+			c.Set("user_id", 7)
+			c.Next()
+		}, handlers.NewRecipeHandler(database, nil).UpdateRecipe)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("PUT", "/api/v1/recipes/5", strings.NewReader(`{"title": "Stew"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("If-Unmodified-Since", since)
+		router.ServeHTTP(w, req)
+		return w
+	}
+	wroteRecipe := func() bool {
+		for _, query := range scriptedQueries() {
+			if strings.Contains(query, "SET title") || strings.Contains(query, "INSERT INTO recipe_revisions") {
+				return true
+			}
+		}
+		return false
+	}
+
+	w := update(updatedAt.Add(-time.Second).Format(http.TimeFormat))
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code, w.Body.String())
+	assert.False(t, wroteRecipe())
+	assert.Contains(t, scriptedQueries(), "ROLLBACK")
+
+	// HTTP dates drop the fraction of a second, so the Last-Modified sent for this version still matches it
+	w = update(updatedAt.Format(http.TimeFormat))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.True(t, wroteRecipe())
+	assert.Equal(t, "Sat, 14 Mar 2026 09:30:15 GMT", w.Header().Get("Last-Modified"))
+}
@@ -0,0 +1,202 @@
+package tests
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"digital-recipes/api-service/handlers"
+	"digital-recipes/api-service/middleware"
+	"digital-recipes/api-service/models"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// decodeRecipeDraft extracts a recipe draft from a standard response
+func decodeRecipeDraft(t *testing.T, body []byte) models.RecipeDraft {
+	var response handlers.StandardResponse
+	require.NoError(t, json.Unmarshal(body, &response))
+	dataBytes, _ := json.Marshal(response.Data)
+	var draft models.RecipeDraft
+	require.NoError(t, json.Unmarshal(dataBytes, &draft))
+	return draft
+}
+
+// TestRecipeDraftSaveAndPromote tests drafts are stored apart from the recipe until promoted into it
+func (suite *RecipeAPITestSuite) TestRecipeDraftSaveAndPromote() {
+	recipeID := suite.createTestRecipe("Live Title", "published")
+	suite.createTestIngredient(recipeID, "1 cup rice", nil)
+	auth := suite.authHeader(suite.testUserID, middleware.RoleUser)
+	path := fmt.Sprintf("/api/v1/recipes/%d/draft", recipeID)
+
+	w := suite.performGet(path, auth)
+	assert.Equal(suite.T(), http.StatusNotFound, w.Code, "No draft has been saved yet")
+
+	w = suite.performJSON("PUT", path, `{"title": "Draft Title"}`, auth)
+	require.Equal(suite.T(), http.StatusOK, w.Code, w.Body.String())
+	first := decodeRecipeDraft(suite.T(), w.Body.Bytes())
+
+	// A later autosave replaces the earlier draft
+	w = suite.performJSON("PUT", path, `{"title": "Draft Title", "tips": "Rest overnight", "ingredients": [{"original_text": "2 cups rice"}]}`, auth)
+	require.Equal(suite.T(), http.StatusOK, w.Code, w.Body.String())
+	saved := decodeRecipeDraft(suite.T(), w.Body.Bytes())
+	assert.Equal(suite.T(), first.CreatedAt.Unix(), saved.CreatedAt.Unix())
+
+	w = suite.performGet(path, auth)
+	require.Equal(suite.T(), http.StatusOK, w.Code, w.Body.String())
+	draft := decodeRecipeDraft(suite.T(), w.Body.Bytes())
+	assert.Equal(suite.T(), recipeID, draft.RecipeID)
+	require.NotNil(suite.T(), draft.Content.Title)
+	assert.Equal(suite.T(), "Draft Title", *draft.Content.Title)
+	require.NotNil(suite.T(), draft.Content.Tips)
+	assert.Equal(suite.T(), "Rest overnight", *draft.Content.Tips)
+	require.NotNil(suite.T(), draft.Content.Ingredients)
+	assert.Len(suite.T(), *draft.Content.Ingredients, 1)
+
+	// The live recipe is untouched while the draft exists
+	w = suite.performGet(fmt.Sprintf("/api/v1/recipes/%d", recipeID), "")
+	live := suite.decodeRecipe(w.Body.Bytes())
+	assert.Equal(suite.T(), "Live Title", live.Title)
+	assert.Equal(suite.T(), "1 cup rice", live.Ingredients[0].OriginalText)
+	assert.Equal(suite.T(), 0, suite.countRevisions(recipeID))
+
+	w = suite.performJSON("POST", path+"/promote", "", auth)
+	require.Equal(suite.T(), http.StatusOK, w.Code, w.Body.String())
+	promoted := suite.decodeRecipe(w.Body.Bytes())
+	assert.Equal(suite.T(), "Draft Title", promoted.Title)
+	require.NotNil(suite.T(), promoted.Tips)
+	assert.Equal(suite.T(), "Rest overnight", *promoted.Tips)
+	require.NotNil(suite.T(), promoted.Servings)
+	assert.Equal(suite.T(), "4", *promoted.Servings, "Fields missing from the draft keep their value")
+	assert.Equal(suite.T(), "published", promoted.Status)
+	require.Len(suite.T(), promoted.Ingredients, 1)
+	assert.Equal(suite.T(), "2 cups rice", promoted.Ingredients[0].OriginalText)
+	assert.Equal(suite.T(), 1, suite.countRevisions(recipeID), "Promoting keeps the replaced state as a revision")
+
+	// The draft is consumed by promotion
+	w = suite.performGet(path, auth)
+	assert.Equal(suite.T(), http.StatusNotFound, w.Code)
+	w = suite.performJSON("POST", path+"/promote", "", auth)
+	assert.Equal(suite.T(), http.StatusNotFound, w.Code)
+}
+
+// TestRecipeDraftOwnerOnly tests drafts cannot be read, saved or promoted by anyone but the owner
+func (suite *RecipeAPITestSuite) TestRecipeDraftOwnerOnly() {
+	recipeID := suite.createTestRecipe("Private Draft", "published")
+	path := fmt.Sprintf("/api/v1/recipes/%d/draft", recipeID)
+
+	w := suite.performJSON("PUT", path, `{"title": "Owner Draft"}`, suite.authHeader(suite.testUserID, middleware.RoleUser))
+	require.Equal(suite.T(), http.StatusOK, w.Code, w.Body.String())
+
+	otherID := suite.createTestUser("other@example.com")
+	for _, auth := range []string{
+		suite.authHeader(otherID, middleware.RoleUser),
+		suite.authHeader(otherID, middleware.RoleAdmin),
+	} {
+		assert.Equal(suite.T(), http.StatusForbidden, suite.performGet(path, auth).Code)
+		assert.Equal(suite.T(), http.StatusForbidden, suite.performJSON("PUT", path, `{"title": "Hijacked"}`, auth).Code)
+		assert.Equal(suite.T(), http.StatusForbidden, suite.performJSON("POST", path+"/promote", "", auth).Code)
+	}
+
+	assert.Equal(suite.T(), http.StatusUnauthorized, suite.performGet(path, "").Code)
+
+	w = suite.performGet(fmt.Sprintf("/api/v1/recipes/%d/draft", NonExistentID), suite.authHeader(suite.testUserID, middleware.RoleUser))
+	assert.Equal(suite.T(), http.StatusNotFound, w.Code)
+
+	w = suite.performGet(path, suite.authHeader(suite.testUserID, middleware.RoleUser))
+	require.Equal(suite.T(), http.StatusOK, w.Code)
+	draft := decodeRecipeDraft(suite.T(), w.Body.Bytes())
+	require.NotNil(suite.T(), draft.Content.Title)
+	assert.Equal(suite.T(), "Owner Draft", *draft.Content.Title)
+}
+
+// TestSaveRecipeDraftValidationWithoutDatabase tests invalid drafts are rejected before any query runs
+func TestSaveRecipeDraftValidationWithoutDatabase(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	database := openScriptedDatabase(t)
+	handler := handlers.NewRecipeHandler(database, nil)
+
+	router := gin.New()
+	router.PUT("/api/v1/recipes/:id/draft", func(c *gin.Context) {
+		c.Set("user_id", 7)
+		c.Next()
+	}, handler.SaveRecipeDraft)
+
+	save := func(body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("PUT", "/api/v1/recipes/5/draft", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	for _, body := range []string{
+		`{}`,
+		`{"status": "published"}`,
+		`{"difficulty": "impossible"}`,
+		`{"prep_time_minutes": -5}`,
+	} {
+		assert.Equal(t, http.StatusBadRequest, save(body).Code, body)
+	}
+
+	// Content is checked field by field like a PATCH, so a draft can never promote blank ingredient lines
+	fieldTests := []struct {
+		body     string
+		expected models.FieldErrors
+	}{
+		{`{"title": "  "}`, models.FieldErrors{{Field: "title", Message: "title cannot be empty"}}},
+		{`{"ingredients": [{"original_text": "2 cups rice"}, {"original_text": "   "}]}`, models.FieldErrors{{Field: "ingredients[1].original_text", Message: "original_text cannot be empty"}}},
+	}
+	for _, tt := range fieldTests {
+		w := save(tt.body)
+		require.Equal(t, http.StatusUnprocessableEntity, w.Code, tt.body)
+		_, errs := decodeFieldErrors(t, w.Body.Bytes())
+		assert.Equal(t, tt.expected, errs, tt.body)
+	}
+	assert.Empty(t, scriptedQueries(), "Validation happens before the recipe is loaded")
+}
+
+// TestSaveRecipeDraftWithoutDatabase tests the draft is stored as the PATCH-shaped body it was sent as
+func TestSaveRecipeDraftWithoutDatabase(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	now := time.Now()
+	content := `{"title":"Draft Title","prep_time_minutes":15}`
+	database := openScriptedDatabase(t,
+		scriptedResult{match: "SELECT user_id FROM recipes", columns: []string{"user_id"}, rows: [][]driver.Value{{int64(7)}}},
+		scriptedResult{
+			match:   "INSERT INTO recipe_drafts",
+			columns: []string{"recipe_id", "content", "created_at", "updated_at"},
+			rows:    [][]driver.Value{{int64(5), []byte(content), now, now}},
+		},
+	)
+	handler := handlers.NewRecipeHandler(database, nil)
+
+	router := gin.New()
+	router.PUT("/api/v1/recipes/:id/draft", func(c *gin.Context) {
+		c.Set("user_id", 7)
+		c.Next()
+	}, handler.SaveRecipeDraft)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("PUT", "/api/v1/recipes/5/draft", strings.NewReader(`{"title": "Draft Title", "prep_time_minutes": 15}`))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	draft := decodeRecipeDraft(t, w.Body.Bytes())
+	assert.Equal(t, 5, draft.RecipeID)
+	require.NotNil(t, draft.Content.Title)
+	assert.Equal(t, "Draft Title", *draft.Content.Title)
+	require.NotNil(t, draft.Content.PrepTimeMinutes)
+	assert.Equal(t, 15, *draft.Content.PrepTimeMinutes)
+
+	for _, query := range scriptedQueries() {
+		assert.NotContains(t, query, "UPDATE recipes", "Saving a draft never touches the live recipe")
+	}
+}
@@ -0,0 +1,119 @@
+package tests
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"digital-recipes/api-service/handlers"
+	"digital-recipes/api-service/middleware"
+	"digital-recipes/api-service/models"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// featuredRecipeTitles returns the titles GET /recipes/featured lists, in order
+func (suite *RecipeAPITestSuite) featuredRecipeTitles() []string {
+	w := suite.performGet("/api/v1/recipes/featured", "")
+	require.Equal(suite.T(), http.StatusOK, w.Code, w.Body.String())
+	var response handlers.StandardResponse
+	require.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &response))
+	dataBytes, _ := json.Marshal(response.Data)
+	var recipes []models.RecipeListItem
+	require.NoError(suite.T(), json.Unmarshal(dataBytes, &recipes))
+
+	titles := make([]string, 0, len(recipes))
+	for _, recipe := range recipes {
+		titles = append(titles, recipe.Title)
+	}
+	return titles
+}
+
+// TestFeatureRecipe tests admins feature recipes onto the homepage listing and take them off again
+func (suite *RecipeAPITestSuite) TestFeatureRecipe() {
+	pieID := suite.createTestRecipe("Apple Pie", "published")
+	stewID := suite.createTestRecipe("Beef Stew", "published")
+	draftID := suite.createTestRecipe("Draft Cake", "review_required")
+	privateID := suite.createRecipeWithVisibility("Secret Pie", models.VisibilityPrivate)
+	adminAuth := suite.authHeader(suite.createTestUser("admin@example.com"), middleware.RoleAdmin)
+	feature := func(recipeID int, featured bool, auth string) *httptest.ResponseRecorder {
+		return suite.performJSON("PATCH", fmt.Sprintf("/api/v1/recipes/%d/feature", recipeID), fmt.Sprintf(`{"featured": %t}`, featured), auth)
+	}
+
+	assert.Empty(suite.T(), suite.featuredRecipeTitles())
+
+	w := feature(pieID, true, adminAuth)
+	require.Equal(suite.T(), http.StatusOK, w.Code, w.Body.String())
+	pie := suite.decodeRecipe(w.Body.Bytes())
+	assert.True(suite.T(), pie.Featured)
+	require.NotNil(suite.T(), pie.FeaturedAt)
+
+	_, err := suite.db.DB.Exec(`UPDATE recipes SET featured_at = NOW() - INTERVAL '1 hour' WHERE id = $1`, pieID)
+	require.NoError(suite.T(), err)
+	for _, recipeID := range []int{stewID, draftID, privateID} {
+		require.Equal(suite.T(), http.StatusOK, feature(recipeID, true, adminAuth).Code)
+	}
+	assert.Equal(suite.T(), []string{"Beef Stew", "Apple Pie"}, suite.featuredRecipeTitles(), "Only published public recipes are listed, newest feature first")
+
+	// Featuring again keeps the recipe's place
+	require.Equal(suite.T(), http.StatusOK, feature(pieID, true, adminAuth).Code)
+	assert.Equal(suite.T(), []string{"Beef Stew", "Apple Pie"}, suite.featuredRecipeTitles())
+
+	w = feature(stewID, false, adminAuth)
+	require.Equal(suite.T(), http.StatusOK, w.Code, w.Body.String())
+	stew := suite.decodeRecipe(w.Body.Bytes())
+	assert.False(suite.T(), stew.Featured)
+	assert.Nil(suite.T(), stew.FeaturedAt)
+	assert.Equal(suite.T(), []string{"Apple Pie"}, suite.featuredRecipeTitles())
+
+	// Only admins curate the homepage, even for their own recipes
+	ownerAuth := suite.authHeader(suite.testUserID, middleware.RoleUser)
+	assert.Equal(suite.T(), http.StatusForbidden, feature(stewID, true, ownerAuth).Code)
+	assert.Equal(suite.T(), http.StatusUnauthorized, feature(stewID, true, "").Code)
+	assert.Equal(suite.T(), http.StatusNotFound, feature(999999, true, adminAuth).Code)
+	assert.Equal(suite.T(), []string{"Apple Pie"}, suite.featuredRecipeTitles())
+}
+
+// TestFeaturedRecipesWithoutDatabase tests the featured listing query and that malformed feature requests are refused without a query
+func TestFeaturedRecipesWithoutDatabase(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	now := time.Now()
+	database := openScriptedDatabase(t, scriptedResult{
+		match:   "total_count",
+		columns: []string{"id", "title", "servings", "instructions", "tips", "status", "user_id", "created_at", "updated_at", "prep_time_minutes", "cook_time_minutes", "difficulty", "source_name", "source_url", "last_modified_by", "visibility", "featured", "featured_at", "ingredient_count", "has_images", "total_count"},
+		rows: [][]driver.Value{
+			{int64(4), "Pancakes", nil, nil, nil, "published", int64(1), now, now, nil, nil, nil, nil, nil, nil, "public", true, now, int64(2), false, int64(1)},
+		},
+	})
+	router := gin.New()
+	router.GET("/api/v1/recipes/featured", handlers.NewRecipeHandler(database, nil).GetFeaturedRecipes)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v1/recipes/featured", nil)
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), `"featured":true`)
+	assert.Contains(t, w.Body.String(), `"total":1`)
+	require.Len(t, scriptedQueries(), 1)
+	query := scriptedQueries()[0]
+	for _, condition := range []string{"featured = $1", "status = $2", "visibility = $3", "ORDER BY featured_at DESC"} {
+		assert.Contains(t, query, condition)
+	}
+
+	router = gin.New()
+	router.PATCH("/api/v1/recipes/:id/feature", handlers.NewRecipeHandler(openScriptedDatabase(t), nil).FeatureRecipe)
+	for _, body := range []string{``, `{}`, `{"featured": "yes"}`} {
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest("PATCH", "/api/v1/recipes/4/feature", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code, body)
+		assert.Empty(t, scriptedQueries(), body)
+	}
+}
@@ -0,0 +1,503 @@
+package tests
+
+import (
+	"crypto/md5"
+	"database/sql/driver"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"image"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"digital-recipes/api-service/handlers"
+	"digital-recipes/api-service/middleware"
+	"digital-recipes/api-service/models"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// createTestImage stores an image record for a recipe and returns its ID
+func (suite *RecipeAPITestSuite) createTestImage(recipeID int, objectName string, position int, primary bool) int {
+	var imageID int
+	err := suite.db.DB.QueryRow(`
+		INSERT INTO recipe_images (recipe_id, object_name, content_type, position, is_primary)
+		VALUES ($1, $2, 'image/jpeg', $3, $4)
+		RETURNING id
+	`, recipeID, objectName, position, primary).Scan(&imageID)
+	require.NoError(suite.T(), err, "Failed to create test image")
+	return imageID
+}
+
+// decodeRecipeImages unmarshals the data array of an image list response
+func (suite *RecipeAPITestSuite) decodeRecipeImages(body []byte) []models.RecipeImage {
+	var response handlers.StandardResponse
+	require.NoError(suite.T(), json.Unmarshal(body, &response))
+	dataBytes, _ := json.Marshal(response.Data)
+	var images []models.RecipeImage
+	require.NoError(suite.T(), json.Unmarshal(dataBytes, &images))
+	return images
+}
+
+// imageIDs returns the IDs of images in list order
+func imageIDs(images []models.RecipeImage) []int {
+	ids := make([]int, len(images))
+	for i, image := range images {
+		ids[i] = image.ID
+	}
+	return ids
+}
+
+// TestReorderRecipeImages tests PUT /recipes/:id/images/order rewrites positions in request order
+func (suite *RecipeAPITestSuite) TestReorderRecipeImages() {
+	recipeID := suite.createTestRecipe("Gallery", "published")
+	first := suite.createTestImage(recipeID, "recipes/1/images/a.jpg", 0, true)
+	second := suite.createTestImage(recipeID, "recipes/1/images/b.jpg", 1, false)
+	third := suite.createTestImage(recipeID, "recipes/1/images/c.jpg", 2, false)
+	auth := suite.authHeader(suite.testUserID, middleware.RoleUser)
+	path := fmt.Sprintf("/api/v1/recipes/%d/images/order", recipeID)
+
+	w := suite.performJSON("PUT", path, fmt.Sprintf(`{"image_ids": [%d, %d, %d]}`, third, first, second), auth)
+	require.Equal(suite.T(), http.StatusOK, w.Code, w.Body.String())
+
+	images := suite.decodeRecipeImages(w.Body.Bytes())
+	assert.Equal(suite.T(), []int{third, first, second}, imageIDs(images))
+	for i, image := range images {
+		assert.Equal(suite.T(), i, image.Position)
+	}
+	// Reordering leaves the primary image alone
+	assert.True(suite.T(), images[1].IsPrimary)
+
+	// The public listing returns the new order
+	w = suite.performGet(fmt.Sprintf("/api/v1/recipes/%d/images", recipeID), "")
+	require.Equal(suite.T(), http.StatusOK, w.Code)
+	assert.Equal(suite.T(), []int{third, first, second}, imageIDs(suite.decodeRecipeImages(w.Body.Bytes())))
+
+	suite.Run("Incomplete or foreign lists are rejected", func() {
+		otherRecipe := suite.createTestRecipe("Other", "published")
+		foreign := suite.createTestImage(otherRecipe, "recipes/2/images/x.jpg", 0, true)
+
+		for name, body := range map[string]string{
+			"missing image":   fmt.Sprintf(`{"image_ids": [%d, %d]}`, first, second),
+			"duplicate image": fmt.Sprintf(`{"image_ids": [%d, %d, %d, %d]}`, first, second, third, first),
+			"foreign image":   fmt.Sprintf(`{"image_ids": [%d, %d, %d]}`, first, second, foreign),
+			"empty list":      `{"image_ids": []}`,
+		} {
+			w := suite.performJSON("PUT", path, body, auth)
+			assert.Equal(suite.T(), http.StatusBadRequest, w.Code, name)
+			assert.Contains(suite.T(), w.Body.String(), `"field":"image_ids"`, name)
+		}
+
+		// Rejected requests change nothing
+		w := suite.performGet(fmt.Sprintf("/api/v1/recipes/%d/images", recipeID), "")
+		assert.Equal(suite.T(), []int{third, first, second}, imageIDs(suite.decodeRecipeImages(w.Body.Bytes())))
+	})
+
+	suite.Run("Only the owner or an admin may reorder", func() {
+		otherUser := suite.createTestUser("reorder-other@example.com")
+		body := fmt.Sprintf(`{"image_ids": [%d, %d, %d]}`, first, second, third)
+
+		w := suite.performJSON("PUT", path, body, suite.authHeader(otherUser, middleware.RoleUser))
+		assert.Equal(suite.T(), http.StatusForbidden, w.Code)
+
+		w = suite.performJSON("PUT", path, body, suite.authHeader(otherUser, middleware.RoleAdmin))
+		assert.Equal(suite.T(), http.StatusOK, w.Code)
+	})
+}
+
+// TestSetPrimaryRecipeImage tests switching the primary image moves the flag in one step
+func (suite *RecipeAPITestSuite) TestSetPrimaryRecipeImage() {
+	recipeID := suite.createTestRecipe("Cover", "published")
+	first := suite.createTestImage(recipeID, "recipes/1/images/a.jpg", 0, true)
+	second := suite.createTestImage(recipeID, "recipes/1/images/b.jpg", 1, false)
+	auth := suite.authHeader(suite.testUserID, middleware.RoleUser)
+
+	w := suite.performJSON("PUT", fmt.Sprintf("/api/v1/recipes/%d/images/%d/primary", recipeID, second), "", auth)
+	require.Equal(suite.T(), http.StatusOK, w.Code, w.Body.String())
+
+	images := suite.decodeRecipeImages(w.Body.Bytes())
+	require.Len(suite.T(), images, 2)
+	assert.Equal(suite.T(), first, images[0].ID)
+	assert.False(suite.T(), images[0].IsPrimary)
+	assert.Equal(suite.T(), second, images[1].ID)
+	assert.True(suite.T(), images[1].IsPrimary)
+
+	// Setting the current primary again is a no-op
+	w = suite.performJSON("PUT", fmt.Sprintf("/api/v1/recipes/%d/images/%d/primary", recipeID, second), "", auth)
+	require.Equal(suite.T(), http.StatusOK, w.Code)
+
+	var primaries int
+	require.NoError(suite.T(), suite.db.DB.QueryRow(
+		`SELECT COUNT(*) FROM recipe_images WHERE recipe_id = $1 AND is_primary`, recipeID,
+	).Scan(&primaries))
+	assert.Equal(suite.T(), 1, primaries)
+
+	suite.Run("The database rejects a second primary image", func() {
+		_, err := suite.db.DB.Exec(`UPDATE recipe_images SET is_primary = TRUE WHERE id = $1`, first)
+		assert.Error(suite.T(), err)
+	})
+
+	suite.Run("Images of other recipes are not found", func() {
+		otherRecipe := suite.createTestRecipe("Other", "published")
+		foreign := suite.createTestImage(otherRecipe, "recipes/2/images/x.jpg", 0, true)
+
+		w := suite.performJSON("PUT", fmt.Sprintf("/api/v1/recipes/%d/images/%d/primary", recipeID, foreign), "", auth)
+		assert.Equal(suite.T(), http.StatusNotFound, w.Code)
+	})
+
+	suite.Run("Other users may not change the primary image", func() {
+		otherUser := suite.createTestUser("primary-other@example.com")
+		w := suite.performJSON("PUT", fmt.Sprintf("/api/v1/recipes/%d/images/%d/primary", recipeID, first), "",
+			suite.authHeader(otherUser, middleware.RoleUser))
+		assert.Equal(suite.T(), http.StatusForbidden, w.Code)
+	})
+}
+
+// TestGetRecipePrimaryImageURL tests single-recipe responses carry a download URL for the primary image
+func TestGetRecipePrimaryImageURL(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	now := time.Now()
+	recipeColumns := []string{"id", "title", "servings", "instructions", "tips", "status", "user_id", "created_at", "updated_at", "prep_time_minutes", "cook_time_minutes", "difficulty", "source_name", "source_url", "last_modified_by", "visibility", "featured", "featured_at"}
+	ingredientColumns := []string{"id", "recipe_id", "canonical_ingredient_id", "original_text", "quantity", "unit", "created_at", "updated_at", "canonical_name"}
+
+	get := func(handler *handlers.RecipeHandler) models.RecipeWithIngredients {
+		router := gin.New()
+		router.GET("/api/v1/recipes/:id", handler.GetRecipe)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/v1/recipes/1", nil)
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		var response handlers.StandardResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		dataBytes, _ := json.Marshal(response.Data)
+		var recipe models.RecipeWithIngredients
+		require.NoError(t, json.Unmarshal(dataBytes, &recipe))
+		return recipe
+	}
+
+	recipeResults := []scriptedResult{
+		{match: "WHERE ri.recipe_id = $1", columns: ingredientColumns},
+		{
+			match:   "FROM recipes",
+			columns: recipeColumns,
+			rows:    [][]driver.Value{{int64(1), "Cake", nil, nil, nil, "published", int64(1), now, now, nil, nil, nil, nil, nil, nil, "public", false, nil}},
+		},
+	}
+	storage := handlers.NewStorageServiceWithBackend("test-bucket", newMemoryBucket())
+
+	t.Run("Primary image URL is signed", func(t *testing.T) {
+		database := openScriptedDatabase(t, append([]scriptedResult{{
+			match:   "AND is_primary",
+			columns: []string{"object_name"},
+			rows:    [][]driver.Value{{"recipes/1/images/cover.jpg"}},
+		}}, recipeResults...)...)
+
+		recipe := get(handlers.NewRecipeHandler(database, storage))
+		require.NotNil(t, recipe.PrimaryImageURL)
+		assert.Equal(t, "https://storage.example.com/recipes/1/images/cover.jpg", *recipe.PrimaryImageURL)
+	})
+
+	t.Run("Recipes without images have no URL", func(t *testing.T) {
+		database := openScriptedDatabase(t, append([]scriptedResult{{
+			match:   "AND is_primary",
+			columns: []string{"object_name"},
+		}}, recipeResults...)...)
+
+		assert.Nil(t, get(handlers.NewRecipeHandler(database, storage)).PrimaryImageURL)
+	})
+
+	t.Run("Signing failures still return the recipe", func(t *testing.T) {
+		database := openScriptedDatabase(t, append([]scriptedResult{{
+			match:   "AND is_primary",
+			columns: []string{"object_name"},
+			rows:    [][]driver.Value{{"recipes/1/images/cover.jpg"}},
+		}}, recipeResults...)...)
+
+		failing := handlers.NewStorageServiceWithBackend("test-bucket", failingBucket{})
+		recipe := get(handlers.NewRecipeHandler(database, failing))
+		assert.Equal(t, "Cake", recipe.Title)
+		assert.Nil(t, recipe.PrimaryImageURL)
+	})
+
+	t.Run("Without storage the image lookup is skipped", func(t *testing.T) {
+		database := openScriptedDatabase(t, recipeResults...)
+
+		assert.Nil(t, get(handlers.NewRecipeHandler(database, nil)).PrimaryImageURL)
+		for _, query := range scriptedQueries() {
+			assert.NotContains(t, query, "recipe_images")
+		}
+	})
+}
+
+// decodeImageConfirmation unmarshals the data of an image confirmation response
+func decodeImageConfirmation(t *testing.T, body []byte) models.ImageConfirmation {
+	var response handlers.StandardResponse
+	require.NoError(t, json.Unmarshal(body, &response))
+	dataBytes, _ := json.Marshal(response.Data)
+	var confirmation models.ImageConfirmation
+	require.NoError(t, json.Unmarshal(dataBytes, &confirmation))
+	return confirmation
+}
+
+// TestConfirmRecipeImageDeduplicates tests uploads matching an existing image's hash are discarded
+func TestConfirmRecipeImageDeduplicates(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	now := time.Now()
+	const imageID = "recipe-5-1700000000-duplicate"
+	objectName := "recipes/5/images/" + imageID + ".jpg"
+	content := []byte("the same photo")
+	sum := md5.Sum(content)
+	hash := "md5:" + hex.EncodeToString(sum[:])
+
+	recipeColumns := []string{"id", "title", "servings", "instructions", "tips", "status", "user_id", "created_at", "updated_at", "prep_time_minutes", "cook_time_minutes", "difficulty", "source_name", "source_url", "last_modified_by", "visibility", "featured", "featured_at"}
+	imageColumns := []string{"id", "recipe_id", "object_name", "content_type", "size_bytes", "position", "is_primary", "content_hash", "created_at", "thumbnail_object_name", "thumbnail_failed"}
+	baseResults := []scriptedResult{
+		{match: "SELECT user_id, ", columns: []string{"user_id", "storage_prefix"}, rows: [][]driver.Value{{int64(7), "5"}}},
+		{match: "FOR UPDATE", columns: recipeColumns, rows: [][]driver.Value{{int64(5), "Cake", nil, nil, nil, "published", int64(7), now, now, nil, nil, nil, nil, nil, nil, "public", false, nil}}},
+		{match: "WHERE object_name = $1", columns: imageColumns},
+		{match: "SUM(size_bytes)", columns: []string{"coalesce"}, rows: [][]driver.Value{{int64(0)}}},
+	}
+
+	confirm := func(handler *handlers.RecipeHandler, body string) *httptest.ResponseRecorder {
+		router := gin.New()
+		router.POST("/api/v1/recipes/:id/images/confirm", func(c *gin.Context) {
+			c.Set("user_id", 7)
+			c.Next()
+		}, handler.ConfirmRecipeImage)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/v1/recipes/5/images/confirm", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("Identical content points to the existing image", func(t *testing.T) {
+		bucket := newMemoryBucket()
+		bucket.PutContent(objectName, content)
+		database := openScriptedDatabase(t, append([]scriptedResult{{
+			match:   "AND content_hash = $2",
+			columns: imageColumns,
+			rows:    [][]driver.Value{{int64(3), int64(5), "recipes/5/images/original.jpg", "image/jpeg", int64(14), int64(0), true, hash, now, "recipes/5/thumbnails/original.jpg", false}},
+		}}, baseResults...)...)
+		handler := handlers.NewRecipeHandler(database, handlers.NewStorageServiceWithBackend("test-bucket", bucket))
+
+		w := confirm(handler, `{"image_id": "`+imageID+`"}`)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		confirmation := decodeImageConfirmation(t, w.Body.Bytes())
+		assert.True(t, confirmation.Deduplicated)
+		assert.Equal(t, 3, confirmation.Image.ID)
+		assert.Equal(t, "recipes/5/images/original.jpg", confirmation.Image.ObjectName)
+
+		// The duplicate object is removed and no new image is recorded
+		assert.Equal(t, []string{objectName}, bucket.Deleted())
+		for _, query := range scriptedQueries() {
+			assert.NotContains(t, query, "INSERT INTO recipe_images")
+		}
+	})
+
+	t.Run("New content is recorded with its hash", func(t *testing.T) {
+		bucket := newMemoryBucket()
+		bucket.PutContent(objectName, content)
+		database := openScriptedDatabase(t, append([]scriptedResult{
+			{match: "AND content_hash = $2", columns: imageColumns},
+			{
+				match:   "INSERT INTO recipe_images",
+				columns: imageColumns,
+				rows:    [][]driver.Value{{int64(4), int64(5), objectName, "image/jpeg", int64(14), int64(0), true, hash, now, nil, false}},
+			},
+		}, baseResults...)...)
+		handler := handlers.NewRecipeHandler(database, handlers.NewStorageServiceWithBackend("test-bucket", bucket))
+
+		w := confirm(handler, `{"image_id": "`+imageID+`"}`)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		confirmation := decodeImageConfirmation(t, w.Body.Bytes())
+		assert.False(t, confirmation.Deduplicated)
+		assert.Equal(t, 4, confirmation.Image.ID)
+		require.NotNil(t, confirmation.Image.ContentHash)
+		assert.Equal(t, hash, *confirmation.Image.ContentHash)
+		assert.True(t, bucket.Has(objectName))
+		assert.Contains(t, scriptedQueries(), "COMMIT")
+
+		// The content is not a real image, so the thumbnail is marked failed without failing the request
+		assert.True(t, confirmation.Image.ThumbnailFailed)
+		assert.Nil(t, confirmation.Image.ThumbnailObjectName)
+		assert.Contains(t, scriptedQueries(), "UPDATE recipe_images SET thumbnail_failed = true WHERE id = $1")
+	})
+
+	t.Run("Decodable content gets a thumbnail", func(t *testing.T) {
+		photo := encodeFixture(t, "png", 40, 30)
+		bucket := newMemoryBucket()
+		bucket.PutContent(objectName, photo)
+		database := openScriptedDatabase(t, append([]scriptedResult{
+			{match: "AND content_hash = $2", columns: imageColumns},
+			{
+				match:   "INSERT INTO recipe_images",
+				columns: imageColumns,
+				rows:    [][]driver.Value{{int64(4), int64(5), objectName, "image/png", int64(len(photo)), int64(0), true, "md5:fresh", now, nil, false}},
+			},
+		}, baseResults...)...)
+		handler := handlers.NewRecipeHandler(database, handlers.NewStorageServiceWithBackend("test-bucket", bucket))
+
+		w := confirm(handler, `{"image_id": "`+imageID+`"}`)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		confirmation := decodeImageConfirmation(t, w.Body.Bytes())
+		thumbnailName := "recipes/5/thumbnails/" + imageID + ".jpg"
+		require.NotNil(t, confirmation.Image.ThumbnailObjectName)
+		assert.Equal(t, thumbnailName, *confirmation.Image.ThumbnailObjectName)
+		assert.False(t, confirmation.Image.ThumbnailFailed)
+		assert.True(t, bucket.Has(thumbnailName))
+		assert.Contains(t, scriptedQueries(), "UPDATE recipe_images SET thumbnail_object_name = $2 WHERE id = $1")
+	})
+
+	t.Run("Uploads taking the recipe over its storage cap are rejected and deleted", func(t *testing.T) {
+		bucket := newMemoryBucket()
+		bucket.PutContent(objectName, content)
+		// The recipe's images already use all but a few bytes of the cap
+		database := openScriptedDatabase(t, append([]scriptedResult{
+			{match: "AND content_hash = $2", columns: imageColumns},
+			{match: "SUM(size_bytes)", columns: []string{"coalesce"}, rows: [][]driver.Value{{models.MaxRecipeImageBytes - int64(len(content)) + 1}}},
+		}, baseResults...)...)
+		handler := handlers.NewRecipeHandler(database, handlers.NewStorageServiceWithBackend("test-bucket", bucket))
+
+		w := confirm(handler, `{"image_id": "`+imageID+`"}`)
+		require.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "image_id", response["field"])
+		assert.Contains(t, response["error"], "cannot exceed 100MB")
+
+		assert.Equal(t, []string{objectName}, bucket.Deleted())
+		assert.Contains(t, scriptedQueries(), "ROLLBACK")
+		for _, query := range scriptedQueries() {
+			assert.NotContains(t, query, "INSERT INTO recipe_images")
+		}
+	})
+
+	t.Run("Uploads filling the storage cap exactly are kept", func(t *testing.T) {
+		bucket := newMemoryBucket()
+		bucket.PutContent(objectName, content)
+		database := openScriptedDatabase(t, append([]scriptedResult{
+			{match: "AND content_hash = $2", columns: imageColumns},
+			{match: "SUM(size_bytes)", columns: []string{"coalesce"}, rows: [][]driver.Value{{models.MaxRecipeImageBytes - int64(len(content))}}},
+			{
+				match:   "INSERT INTO recipe_images",
+				columns: imageColumns,
+				rows:    [][]driver.Value{{int64(4), int64(5), objectName, "image/jpeg", int64(14), int64(3), false, hash, now, nil, true}},
+			},
+		}, baseResults...)...)
+		handler := handlers.NewRecipeHandler(database, handlers.NewStorageServiceWithBackend("test-bucket", bucket))
+
+		w := confirm(handler, `{"image_id": "`+imageID+`"}`)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.Empty(t, bucket.Deleted())
+		assert.True(t, bucket.Has(objectName))
+	})
+
+	t.Run("Images that were never uploaded are not found", func(t *testing.T) {
+		database := openScriptedDatabase(t, baseResults...)
+		handler := handlers.NewRecipeHandler(database, handlers.NewStorageServiceWithBackend("test-bucket", newMemoryBucket()))
+
+		w := confirm(handler, `{"image_id": "`+imageID+`"}`)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("Image IDs must be safe object name parts", func(t *testing.T) {
+		database := openScriptedDatabase(t, baseResults...)
+		handler := handlers.NewRecipeHandler(database, handlers.NewStorageServiceWithBackend("test-bucket", newMemoryBucket()))
+
+		w := confirm(handler, `{"image_id": "../../other-recipe"}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), `"field":"image_id"`)
+		assert.Empty(t, scriptedQueries())
+	})
+}
+
+// TestConfirmRecipeImage tests confirmed uploads are recorded in order and duplicates reuse the first image
+func (suite *RecipeAPITestSuite) TestConfirmRecipeImage() {
+	suite.storage.Reset()
+	recipeID := suite.createTestRecipe("Uploads", "processing")
+	auth := suite.authHeader(suite.testUserID, middleware.RoleUser)
+	path := fmt.Sprintf("/api/v1/recipes/%d/images/confirm", recipeID)
+	objectFor := func(imageID string) string {
+		return fmt.Sprintf("recipes/%s/images/%s.jpg", suite.storagePrefix(recipeID), imageID)
+	}
+
+	suite.storage.PutContent(objectFor("first-upload-0001"), []byte("photo one"))
+	suite.storage.PutContent(objectFor("second-upload-0002"), []byte("photo two"))
+	suite.storage.PutContent(objectFor("repeat-upload-0003"), []byte("photo one"))
+
+	w := suite.performJSON("POST", path, `{"image_id": "first-upload-0001"}`, auth)
+	require.Equal(suite.T(), http.StatusOK, w.Code, w.Body.String())
+	first := decodeImageConfirmation(suite.T(), w.Body.Bytes())
+	assert.False(suite.T(), first.Deduplicated)
+	assert.Equal(suite.T(), 0, first.Image.Position)
+	assert.True(suite.T(), first.Image.IsPrimary, "The first image becomes the primary image")
+
+	w = suite.performJSON("POST", path, `{"image_id": "second-upload-0002"}`, auth)
+	require.Equal(suite.T(), http.StatusOK, w.Code, w.Body.String())
+	second := decodeImageConfirmation(suite.T(), w.Body.Bytes())
+	assert.False(suite.T(), second.Deduplicated)
+	assert.Equal(suite.T(), 1, second.Image.Position)
+	assert.False(suite.T(), second.Image.IsPrimary)
+
+	w = suite.performJSON("POST", path, `{"image_id": "repeat-upload-0003"}`, auth)
+	require.Equal(suite.T(), http.StatusOK, w.Code, w.Body.String())
+	repeat := decodeImageConfirmation(suite.T(), w.Body.Bytes())
+	assert.True(suite.T(), repeat.Deduplicated)
+	assert.Equal(suite.T(), first.Image.ID, repeat.Image.ID)
+	assert.False(suite.T(), suite.storage.Has(objectFor("repeat-upload-0003")))
+	assert.True(suite.T(), suite.storage.Has(objectFor("first-upload-0001")))
+
+	// Confirming an upload twice is harmless
+	w = suite.performJSON("POST", path, `{"image_id": "second-upload-0002"}`, auth)
+	require.Equal(suite.T(), http.StatusOK, w.Code)
+	assert.Equal(suite.T(), second.Image.ID, decodeImageConfirmation(suite.T(), w.Body.Bytes()).Image.ID)
+
+	w = suite.performGet(fmt.Sprintf("/api/v1/recipes/%d/images", recipeID), "")
+	assert.Equal(suite.T(), []int{first.Image.ID, second.Image.ID}, imageIDs(suite.decodeRecipeImages(w.Body.Bytes())))
+}
+
+// TestConfirmRecipeImageThumbnail tests confirmed photos get a thumbnail that recipe lists link to
+func (suite *RecipeAPITestSuite) TestConfirmRecipeImageThumbnail() {
+	suite.storage.Reset()
+	recipeID := suite.createTestRecipe("Thumbnails", "published")
+	auth := suite.authHeader(suite.testUserID, middleware.RoleUser)
+	path := fmt.Sprintf("/api/v1/recipes/%d/images/confirm", recipeID)
+	prefix := suite.storagePrefix(recipeID)
+
+	suite.storage.PutContent(fmt.Sprintf("recipes/%s/images/cover-photo-0001.jpg", prefix), encodeFixture(suite.T(), "jpeg", 800, 600))
+	suite.storage.PutContent(fmt.Sprintf("recipes/%s/images/broken-photo-0002.jpg", prefix), []byte("truncated upload"))
+
+	w := suite.performJSON("POST", path, `{"image_id": "cover-photo-0001"}`, auth)
+	require.Equal(suite.T(), http.StatusOK, w.Code, w.Body.String())
+	cover := decodeImageConfirmation(suite.T(), w.Body.Bytes())
+	thumbnailName := fmt.Sprintf("recipes/%s/thumbnails/cover-photo-0001.jpg", prefix)
+	require.NotNil(suite.T(), cover.Image.ThumbnailObjectName)
+	assert.Equal(suite.T(), thumbnailName, *cover.Image.ThumbnailObjectName)
+	assert.Equal(suite.T(), image.Point{X: 320, Y: 240}, decodeThumbnail(suite.T(), suite.storage.Content(thumbnailName)))
+
+	// Undecodable uploads are still recorded, just without a thumbnail
+	w = suite.performJSON("POST", path, `{"image_id": "broken-photo-0002"}`, auth)
+	require.Equal(suite.T(), http.StatusOK, w.Code, w.Body.String())
+	broken := decodeImageConfirmation(suite.T(), w.Body.Bytes())
+	assert.True(suite.T(), broken.Image.ThumbnailFailed)
+	assert.Nil(suite.T(), broken.Image.ThumbnailObjectName)
+
+	w = suite.performGet("/api/v1/recipes", "")
+	require.Equal(suite.T(), http.StatusOK, w.Code, w.Body.String())
+	var response struct {
+		Data []models.RecipeListItem `json:"data"`
+	}
+	require.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &response))
+	require.Len(suite.T(), response.Data, 1)
+	require.NotNil(suite.T(), response.Data[0].ThumbnailURL)
+	assert.Equal(suite.T(), "https://storage.example.com/"+thumbnailName, *response.Data[0].ThumbnailURL)
+}
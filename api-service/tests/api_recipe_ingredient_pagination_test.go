@@ -0,0 +1,109 @@
+package tests
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"digital-recipes/api-service/handlers"
+	"digital-recipes/api-service/models"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ingredientPageResponse is a GetRecipe response body with ingredient pagination
+type ingredientPageResponse struct {
+	Data struct {
+		models.RecipeWithIngredients
+		IngredientsPagination *handlers.Pagination `json:"ingredients_pagination"`
+	} `json:"data"`
+	Field string `json:"field"`
+}
+
+func TestGetRecipeIngredientPagination(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	now := time.Now()
+	recipeColumns := []string{"id", "title", "servings", "instructions", "tips", "status", "user_id", "created_at", "updated_at", "prep_time_minutes", "cook_time_minutes", "difficulty", "source_name", "source_url", "last_modified_by", "visibility", "featured", "featured_at"}
+	ingredientColumns := []string{"id", "recipe_id", "canonical_ingredient_id", "original_text", "quantity", "unit", "created_at", "updated_at", "canonical_name"}
+
+	var ingredientRows [][]driver.Value
+	for id := 1; id <= 5; id++ {
+		ingredientRows = append(ingredientRows, []driver.Value{int64(id), int64(1), nil, fmt.Sprintf("ingredient %d", id), nil, nil, now, now, nil})
+	}
+	database := openScriptedDatabase(t,
+		scriptedResult{match: "WHERE ri.recipe_id = $1", columns: ingredientColumns, rows: ingredientRows},
+		scriptedResult{
+			match:   "FROM recipes",
+			columns: recipeColumns,
+			rows:    [][]driver.Value{{int64(1), "Long Stew", nil, nil, nil, "published", int64(1), now, now, nil, nil, nil, nil, nil, nil, "public", false, nil}},
+		},
+	)
+
+	router := gin.New()
+	router.GET("/api/v1/recipes/:id", handlers.NewRecipeHandler(database, nil).GetRecipe)
+	get := func(query string) (int, ingredientPageResponse) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/v1/recipes/1"+query, nil)
+		router.ServeHTTP(w, req)
+		var response ingredientPageResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return w.Code, response
+	}
+	ingredientIDs := func(response ingredientPageResponse) []int {
+		var ids []int
+		for _, ingredient := range response.Data.Ingredients {
+			ids = append(ids, ingredient.ID)
+		}
+		return ids
+	}
+
+	t.Run("All ingredients by default", func(t *testing.T) {
+		code, response := get("")
+		require.Equal(t, http.StatusOK, code)
+		assert.Equal(t, []int{1, 2, 3, 4, 5}, ingredientIDs(response))
+		assert.Nil(t, response.Data.IngredientsPagination)
+	})
+
+	t.Run("Limit and offset", func(t *testing.T) {
+		code, response := get("?ingredients_page=2&ingredients_per_page=2")
+		require.Equal(t, http.StatusOK, code)
+		assert.Equal(t, []int{3, 4}, ingredientIDs(response))
+		assert.Equal(t, &handlers.Pagination{Page: 2, PerPage: 2, Total: 5, TotalPages: 3}, response.Data.IngredientsPagination)
+		assert.Equal(t, "Long Stew", response.Data.Title)
+	})
+
+	t.Run("Last page is partial", func(t *testing.T) {
+		code, response := get("?ingredients_page=3&ingredients_per_page=2")
+		require.Equal(t, http.StatusOK, code)
+		assert.Equal(t, []int{5}, ingredientIDs(response))
+	})
+
+	t.Run("Page size alone starts at the first page", func(t *testing.T) {
+		code, response := get("?ingredients_per_page=3")
+		require.Equal(t, http.StatusOK, code)
+		assert.Equal(t, []int{1, 2, 3}, ingredientIDs(response))
+		assert.Equal(t, 1, response.Data.IngredientsPagination.Page)
+	})
+
+	t.Run("Pages past the end are empty", func(t *testing.T) {
+		code, response := get("?ingredients_page=9&ingredients_per_page=2")
+		require.Equal(t, http.StatusOK, code)
+		assert.Empty(t, ingredientIDs(response))
+		assert.Equal(t, 5, response.Data.IngredientsPagination.Total)
+	})
+
+	t.Run("Invalid parameters are reported by name", func(t *testing.T) {
+		code, response := get("?ingredients_per_page=1000")
+		assert.Equal(t, http.StatusBadRequest, code)
+		assert.Equal(t, "ingredients_per_page", response.Field)
+
+		code, response = get("?ingredients_page=0")
+		assert.Equal(t, http.StatusBadRequest, code)
+		assert.Equal(t, "ingredients_page", response.Field)
+	})
+}
@@ -0,0 +1,838 @@
+package tests
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"digital-recipes/api-service/handlers"
+	"digital-recipes/api-service/middleware"
+	"digital-recipes/api-service/models"
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestLinkRecipeIngredient tests linking an OCR'd ingredient to a canonical ingredient
+func (suite *RecipeAPITestSuite) TestLinkRecipeIngredient() {
+	recipeID := suite.createTestRecipe("Review Recipe", "review_required")
+	ingredientID := suite.createTestIngredient(recipeID, "2 cups all-purpose flour", nil)
+	flourID := suite.createCanonicalIngredient("Flour", true)
+
+	path := fmt.Sprintf("/api/v1/recipes/%d/ingredients/%d", recipeID, ingredientID)
+	body := fmt.Sprintf(`{"canonical_ingredient_id": %d, "quantity": 2, "unit": "cup"}`, flourID)
+	w := suite.performJSON("PATCH", path, body, suite.authHeader(suite.testUserID, middleware.RoleUser))
+
+	require.Equal(suite.T(), http.StatusOK, w.Code, w.Body.String())
+
+	var response handlers.StandardResponse
+	require.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &response))
+	dataBytes, _ := json.Marshal(response.Data)
+	var ingredient models.RecipeIngredient
+	require.NoError(suite.T(), json.Unmarshal(dataBytes, &ingredient))
+
+	require.NotNil(suite.T(), ingredient.CanonicalIngredientID)
+	assert.Equal(suite.T(), flourID, *ingredient.CanonicalIngredientID)
+	require.NotNil(suite.T(), ingredient.CanonicalName)
+	assert.Equal(suite.T(), "Flour", *ingredient.CanonicalName)
+	require.NotNil(suite.T(), ingredient.Quantity)
+	assert.Equal(suite.T(), 2.0, *ingredient.Quantity)
+	require.NotNil(suite.T(), ingredient.Unit)
+	assert.Equal(suite.T(), "cup", *ingredient.Unit)
+
+	// Verify the link was persisted
+	var storedCanonicalID int
+	err := suite.db.DB.QueryRow(`SELECT canonical_ingredient_id FROM recipe_ingredients WHERE id = $1`, ingredientID).Scan(&storedCanonicalID)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), flourID, storedCanonicalID)
+
+	// Linking is an edit of the recipe, so it is attributed and can be undone
+	var revisions int
+	var lastModifiedBy int
+	require.NoError(suite.T(), suite.db.DB.QueryRow(`SELECT COUNT(*) FROM recipe_revisions WHERE recipe_id = $1`, recipeID).Scan(&revisions))
+	require.NoError(suite.T(), suite.db.DB.QueryRow(`SELECT last_modified_by FROM recipes WHERE id = $1`, recipeID).Scan(&lastModifiedBy))
+	assert.Equal(suite.T(), 1, revisions)
+	assert.Equal(suite.T(), suite.testUserID, lastModifiedBy)
+}
+
+// TestLinkRecipeIngredientRejectsUnknownCanonical tests a non-existent canonical ingredient returns 404
+func (suite *RecipeAPITestSuite) TestLinkRecipeIngredientRejectsUnknownCanonical() {
+	recipeID := suite.createTestRecipe("Review Recipe", "review_required")
+	ingredientID := suite.createTestIngredient(recipeID, "1 pinch salt", nil)
+
+	path := fmt.Sprintf("/api/v1/recipes/%d/ingredients/%d", recipeID, ingredientID)
+	body := fmt.Sprintf(`{"canonical_ingredient_id": %d}`, NonExistentID)
+	w := suite.performJSON("PATCH", path, body, suite.authHeader(suite.testUserID, middleware.RoleUser))
+
+	assert.Equal(suite.T(), http.StatusNotFound, w.Code)
+	assert.Contains(suite.T(), w.Body.String(), "canonical ingredient not found")
+}
+
+// TestLinkRecipeIngredientRejectsUnapproved tests an unapproved canonical ingredient cannot be linked
+func (suite *RecipeAPITestSuite) TestLinkRecipeIngredientRejectsUnapproved() {
+	recipeID := suite.createTestRecipe("Review Recipe", "review_required")
+	ingredientID := suite.createTestIngredient(recipeID, "a dash of mystery", nil)
+	pendingID := suite.createCanonicalIngredient("Mystery", false)
+
+	path := fmt.Sprintf("/api/v1/recipes/%d/ingredients/%d", recipeID, ingredientID)
+	body := fmt.Sprintf(`{"canonical_ingredient_id": %d}`, pendingID)
+	w := suite.performJSON("PATCH", path, body, suite.authHeader(suite.testUserID, middleware.RoleUser))
+
+	assert.Equal(suite.T(), http.StatusBadRequest, w.Code)
+	assert.Contains(suite.T(), w.Body.String(), "not approved")
+}
+
+// TestLinkRecipeIngredientOwnership tests that only the owner or an admin can link ingredients
+func (suite *RecipeAPITestSuite) TestLinkRecipeIngredientOwnership() {
+	recipeID := suite.createTestRecipe("Review Recipe", "review_required")
+	ingredientID := suite.createTestIngredient(recipeID, "3 eggs", nil)
+	eggID := suite.createCanonicalIngredient("Egg", true)
+	otherUserID := suite.createTestUser("other@example.com")
+
+	path := fmt.Sprintf("/api/v1/recipes/%d/ingredients/%d", recipeID, ingredientID)
+	body := fmt.Sprintf(`{"canonical_ingredient_id": %d}`, eggID)
+
+	w := suite.performJSON("PATCH", path, body, suite.authHeader(otherUserID, middleware.RoleUser))
+	assert.Equal(suite.T(), http.StatusForbidden, w.Code)
+
+	w = suite.performJSON("PATCH", path, body, suite.authHeader(otherUserID, middleware.RoleAdmin))
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+}
+
+// TestLinkRecipeIngredientMissingEntities tests 404s for a missing recipe or ingredient
+func (suite *RecipeAPITestSuite) TestLinkRecipeIngredientMissingEntities() {
+	recipeID := suite.createTestRecipe("Review Recipe", "review_required")
+	eggID := suite.createCanonicalIngredient("Egg", true)
+	body := fmt.Sprintf(`{"canonical_ingredient_id": %d}`, eggID)
+	auth := suite.authHeader(suite.testUserID, middleware.RoleUser)
+
+	w := suite.performJSON("PATCH", fmt.Sprintf("/api/v1/recipes/%d/ingredients/1", NonExistentID), body, auth)
+	assert.Equal(suite.T(), http.StatusNotFound, w.Code)
+
+	w = suite.performJSON("PATCH", fmt.Sprintf("/api/v1/recipes/%d/ingredients/%d", recipeID, NonExistentID), body, auth)
+	assert.Equal(suite.T(), http.StatusNotFound, w.Code)
+	assert.Contains(suite.T(), w.Body.String(), "recipe ingredient not found")
+}
+
+// TestAddRecipeIngredientParsesQuantity tests quantity and unit are filled in from original_text
+func (suite *RecipeAPITestSuite) TestAddRecipeIngredientParsesQuantity() {
+	recipeID := suite.createTestRecipe("Review Recipe", "review_required")
+	auth := suite.authHeader(suite.testUserID, middleware.RoleUser)
+	path := fmt.Sprintf("/api/v1/recipes/%d/ingredients", recipeID)
+
+	w := suite.performJSON("POST", path, `{"original_text": "1 1/2 cups milk"}`, auth)
+	require.Equal(suite.T(), http.StatusOK, w.Code, w.Body.String())
+
+	var response handlers.StandardResponse
+	require.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &response))
+	dataBytes, _ := json.Marshal(response.Data)
+	var ingredient models.RecipeIngredient
+	require.NoError(suite.T(), json.Unmarshal(dataBytes, &ingredient))
+
+	assert.Equal(suite.T(), recipeID, ingredient.RecipeID)
+	require.NotNil(suite.T(), ingredient.Quantity)
+	assert.Equal(suite.T(), 1.5, *ingredient.Quantity)
+	require.NotNil(suite.T(), ingredient.Unit)
+	assert.Equal(suite.T(), "cup", *ingredient.Unit)
+
+	// Explicit values win over parsed ones and unparseable text stays nil
+	w = suite.performJSON("POST", path, `{"original_text": "2 cups flour", "unit": "mug"}`, auth)
+	require.Equal(suite.T(), http.StatusOK, w.Code, w.Body.String())
+	assert.Contains(suite.T(), w.Body.String(), `"unit":"mug"`)
+	assert.Contains(suite.T(), w.Body.String(), `"quantity":2`)
+
+	w = suite.performJSON("POST", path, `{"original_text": "Salt to taste"}`, auth)
+	require.Equal(suite.T(), http.StatusOK, w.Code, w.Body.String())
+	assert.NotContains(suite.T(), w.Body.String(), `"quantity"`)
+	assert.NotContains(suite.T(), w.Body.String(), `"unit"`)
+}
+
+// TestAddRecipeIngredientOwnership tests that only the owner or an admin can add ingredients
+func (suite *RecipeAPITestSuite) TestAddRecipeIngredientOwnership() {
+	recipeID := suite.createTestRecipe("Review Recipe", "review_required")
+	otherUserID := suite.createTestUser("other@example.com")
+	path := fmt.Sprintf("/api/v1/recipes/%d/ingredients", recipeID)
+	body := `{"original_text": "1 onion"}`
+
+	w := suite.performJSON("POST", path, body, suite.authHeader(otherUserID, middleware.RoleUser))
+	assert.Equal(suite.T(), http.StatusForbidden, w.Code)
+
+	w = suite.performJSON("POST", path, body, suite.authHeader(otherUserID, middleware.RoleAdmin))
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+
+	w = suite.performJSON("POST", fmt.Sprintf("/api/v1/recipes/%d/ingredients", NonExistentID), body, suite.authHeader(suite.testUserID, middleware.RoleUser))
+	assert.Equal(suite.T(), http.StatusNotFound, w.Code)
+}
+
+// TestReplaceRecipeIngredients tests a full ingredient list replaces the stored rows in one revisioned change
+func (suite *RecipeAPITestSuite) TestReplaceRecipeIngredients() {
+	recipeID := suite.createTestRecipe("Review Recipe", "review_required")
+	oldIDs := []int{
+		suite.createTestIngredient(recipeID, "2 cups flour", nil),
+		suite.createTestIngredient(recipeID, "1 cup sugar", nil),
+		suite.createTestIngredient(recipeID, "3 eggs", nil),
+	}
+	auth := suite.authHeader(suite.testUserID, middleware.RoleUser)
+	path := fmt.Sprintf("/api/v1/recipes/%d/ingredients", recipeID)
+
+	body := `{"ingredients": [{"original_text": "2 1/2 cups bread flour"}, {"original_text": "1 tsp salt", "quantity": 0}]}`
+	w := suite.performJSON("PUT", path, body, auth)
+	require.Equal(suite.T(), http.StatusOK, w.Code, w.Body.String())
+
+	var response handlers.StandardResponse
+	require.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &response))
+	dataBytes, _ := json.Marshal(response.Data)
+	var ingredients []models.RecipeIngredient
+	require.NoError(suite.T(), json.Unmarshal(dataBytes, &ingredients))
+	require.Len(suite.T(), ingredients, 2)
+	assert.Equal(suite.T(), "2 1/2 cups bread flour", ingredients[0].OriginalText)
+	require.NotNil(suite.T(), ingredients[0].Quantity)
+	assert.Equal(suite.T(), 2.5, *ingredients[0].Quantity)
+	assert.Equal(suite.T(), "1 tsp salt", ingredients[1].OriginalText)
+
+	var remaining int
+	err := suite.db.DB.QueryRow(`SELECT COUNT(*) FROM recipe_ingredients WHERE id = ANY($1)`, pq.Array(oldIDs)).Scan(&remaining)
+	require.NoError(suite.T(), err)
+	assert.Zero(suite.T(), remaining, "The previous ingredient rows are deleted")
+
+	var total int
+	err = suite.db.DB.QueryRow(`SELECT COUNT(*) FROM recipe_ingredients WHERE recipe_id = $1`, recipeID).Scan(&total)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 2, total)
+	assert.Equal(suite.T(), 1, suite.countRevisions(recipeID), "The replaced list is kept as a revision")
+
+	// An empty list clears the recipe's ingredients
+	w = suite.performJSON("PUT", path, `{"ingredients": []}`, auth)
+	require.Equal(suite.T(), http.StatusOK, w.Code, w.Body.String())
+	assert.Contains(suite.T(), w.Body.String(), `"data":[]`)
+}
+
+// TestReplaceRecipeIngredientsValidation tests invalid lists are rejected without touching the stored rows
+func (suite *RecipeAPITestSuite) TestReplaceRecipeIngredientsValidation() {
+	recipeID := suite.createTestRecipe("Review Recipe", "review_required")
+	suite.createTestIngredient(recipeID, "2 cups flour", nil)
+	auth := suite.authHeader(suite.testUserID, middleware.RoleUser)
+	path := fmt.Sprintf("/api/v1/recipes/%d/ingredients", recipeID)
+
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"Missing list", `{}`},
+		{"Missing original_text", `{"ingredients": [{"quantity": 1}]}`},
+		{"Blank original_text", `{"ingredients": [{"original_text": "   "}]}`},
+		{"Negative quantity", `{"ingredients": [{"original_text": "1 egg"}, {"original_text": "flour", "quantity": -2}]}`},
+	}
+
+	for _, tt := range tests {
+		suite.Run(tt.name, func() {
+			w := suite.performJSON("PUT", path, tt.body, auth)
+			assert.Equal(suite.T(), http.StatusBadRequest, w.Code, w.Body.String())
+		})
+	}
+
+	var total int
+	err := suite.db.DB.QueryRow(`SELECT COUNT(*) FROM recipe_ingredients WHERE recipe_id = $1`, recipeID).Scan(&total)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 1, total)
+	assert.Equal(suite.T(), 0, suite.countRevisions(recipeID))
+}
+
+// TestReplaceRecipeIngredientsOwnership tests that only the owner or an admin can replace ingredients
+func (suite *RecipeAPITestSuite) TestReplaceRecipeIngredientsOwnership() {
+	recipeID := suite.createTestRecipe("Review Recipe", "review_required")
+	ingredientID := suite.createTestIngredient(recipeID, "2 cups flour", nil)
+	otherUserID := suite.createTestUser("other@example.com")
+	path := fmt.Sprintf("/api/v1/recipes/%d/ingredients", recipeID)
+	body := `{"ingredients": [{"original_text": "1 onion"}]}`
+
+	w := suite.performJSON("PUT", path, body, suite.authHeader(otherUserID, middleware.RoleUser))
+	assert.Equal(suite.T(), http.StatusForbidden, w.Code)
+
+	var exists bool
+	err := suite.db.DB.QueryRow(`SELECT EXISTS(SELECT 1 FROM recipe_ingredients WHERE id = $1)`, ingredientID).Scan(&exists)
+	require.NoError(suite.T(), err)
+	assert.True(suite.T(), exists, "Forbidden requests leave the list alone")
+
+	w = suite.performJSON("PUT", path, body, suite.authHeader(otherUserID, middleware.RoleAdmin))
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+
+	w = suite.performJSON("PUT", fmt.Sprintf("/api/v1/recipes/%d/ingredients", NonExistentID), body, suite.authHeader(suite.testUserID, middleware.RoleUser))
+	assert.Equal(suite.T(), http.StatusNotFound, w.Code)
+}
+
+// TestReplaceRecipeIngredientsValidationWithoutDatabase tests invalid lists are rejected before any query runs
+func TestReplaceRecipeIngredientsValidationWithoutDatabase(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	database := openScriptedDatabase(t)
+	handler := handlers.NewRecipeHandler(database, nil)
+
+	router := gin.New()
+	router.PUT("/api/v1/recipes/:id/ingredients", handler.ReplaceRecipeIngredients)
+
+	for _, body := range []string{
+		`{"ingredients": [{"original_text": "flour", "quantity": -1}]}`,
+		`{"ingredients": [{"original_text": ""}]}`,
+		`{"ingredients": [{"original_text": "1 egg"}, {"original_text": " "}]}`,
+	} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("PUT", "/api/v1/recipes/5/ingredients", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code, body)
+	}
+	assert.Empty(t, scriptedQueries(), "Validation happens before the transaction starts")
+}
+
+// TestAddRecipeIngredientValidationWithoutDatabase tests a blank line is rejected before any query runs
+func TestAddRecipeIngredientValidationWithoutDatabase(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	database := openScriptedDatabase(t)
+	handler := handlers.NewRecipeHandler(database, nil)
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("user_id", 7)
+		c.Next()
+	})
+	router.POST("/api/v1/recipes/:id/ingredients", handler.AddRecipeIngredient)
+
+	for _, body := range []string{
+		`{"original_text": ""}`,
+		`{"original_text": "   "}`,
+		`{"original_text": "\t\n", "quantity": 1}`,
+	} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/v1/recipes/5/ingredients", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code, body)
+		assert.Contains(t, w.Body.String(), "original_text", body)
+	}
+	assert.Empty(t, scriptedQueries(), "Validation happens before the transaction starts")
+}
+
+// decodeWarnings returns the warnings listed in a response's metadata
+func decodeWarnings(t *testing.T, body []byte) []string {
+	var response handlers.StandardResponse
+	require.NoError(t, json.Unmarshal(body, &response))
+	require.NotNil(t, response.Meta)
+	return response.Meta.Warnings
+}
+
+// recipeIngredients returns a recipe's ingredient lines as GET /recipes/:id/ingredients lists them
+func (suite *RecipeAPITestSuite) recipeIngredients(recipeID int, auth string) []models.RecipeIngredient {
+	w := suite.performGet(fmt.Sprintf("/api/v1/recipes/%d/ingredients", recipeID), auth)
+	require.Equal(suite.T(), http.StatusOK, w.Code, w.Body.String())
+	var response handlers.StandardResponse
+	require.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &response))
+	dataBytes, _ := json.Marshal(response.Data)
+	var ingredients []models.RecipeIngredient
+	require.NoError(suite.T(), json.Unmarshal(dataBytes, &ingredients))
+	return ingredients
+}
+
+// TestIngredientWarnings tests saves succeed while unmatched and unparsed ingredients are listed as warnings
+func (suite *RecipeAPITestSuite) TestIngredientWarnings() {
+	recipeID := suite.createTestRecipe("Review Recipe", "review_required")
+	flourID := suite.createCanonicalIngredient("flour", true)
+	auth := suite.authHeader(suite.testUserID, middleware.RoleUser)
+
+	body := fmt.Sprintf(`{"title": "Review Recipe", "ingredients": [
+		{"original_text": "2 cups flour", "canonical_ingredient_id": %d},
+		{"original_text": "3 eggs"},
+		{"original_text": "Salt and pepper to taste"},
+		{"original_text": "1 tbsp zaatar"}
+	]}`, flourID)
+	w := suite.performJSON("PUT", fmt.Sprintf("/api/v1/recipes/%d", recipeID), body, auth)
+	require.Equal(suite.T(), http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(suite.T(), []string{
+		"3 ingredients could not be matched to known ingredients",
+		"1 ingredient had no quantity that could be parsed",
+	}, decodeWarnings(suite.T(), w.Body.Bytes()))
+
+	// A reviewer links the only line through the link endpoint, and the list read back is saved with its link
+	body = `{"ingredients": [{"original_text": "2 cups flour"}]}`
+	w = suite.performJSON("PUT", fmt.Sprintf("/api/v1/recipes/%d/ingredients", recipeID), body, auth)
+	require.Equal(suite.T(), http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(suite.T(), []string{"1 ingredient could not be matched to known ingredients"}, decodeWarnings(suite.T(), w.Body.Bytes()))
+	replaced := suite.recipeIngredients(recipeID, auth)
+	require.Len(suite.T(), replaced, 1)
+	w = suite.performJSON("PATCH", fmt.Sprintf("/api/v1/recipes/%d/ingredients/%d", recipeID, replaced[0].ID), fmt.Sprintf(`{"canonical_ingredient_id": %d}`, flourID), auth)
+	require.Equal(suite.T(), http.StatusOK, w.Code, w.Body.String())
+
+	var inputs []models.IngredientInput
+	for _, ingredient := range suite.recipeIngredients(recipeID, auth) {
+		inputs = append(inputs, models.IngredientInput{
+			OriginalText:          ingredient.OriginalText,
+			Quantity:              ingredient.Quantity,
+			Unit:                  ingredient.Unit,
+			CanonicalIngredientID: ingredient.CanonicalIngredientID,
+		})
+	}
+	encoded, err := json.Marshal(map[string]interface{}{"ingredients": inputs})
+	require.NoError(suite.T(), err)
+	// Once every line is linked and quantified there is nothing to warn about
+	w = suite.performJSON("PUT", fmt.Sprintf("/api/v1/recipes/%d/ingredients", recipeID), string(encoded), auth)
+	require.Equal(suite.T(), http.StatusOK, w.Code, w.Body.String())
+	assert.Empty(suite.T(), decodeWarnings(suite.T(), w.Body.Bytes()))
+	assert.NotContains(suite.T(), w.Body.String(), `"warnings"`)
+	linked := suite.recipeIngredients(recipeID, auth)
+	require.Len(suite.T(), linked, 1)
+	require.NotNil(suite.T(), linked[0].CanonicalIngredientID, "The link sent back is kept")
+	assert.Equal(suite.T(), flourID, *linked[0].CanonicalIngredientID)
+
+	// Imported lines are never linked, so every one of them is flagged for review
+	w = suite.performJSON("POST", "/api/v1/recipes/import", realisticJSONLD, auth)
+	require.Equal(suite.T(), http.StatusOK, w.Code, w.Body.String())
+	assert.Contains(suite.T(), decodeWarnings(suite.T(), w.Body.Bytes()), "7 ingredients could not be matched to known ingredients")
+}
+
+// TestAddRecipeIngredientWarningsWithoutDatabase tests a single added line is flagged when it is neither linked nor quantified
+func TestAddRecipeIngredientWarningsWithoutDatabase(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	now := time.Now()
+	recipeRow := []driver.Value{int64(5), "Stew", nil, nil, nil, "review_required", int64(7), now, now, nil, nil, nil, nil, nil, nil, "public", false, nil}
+	recipeColumns := []string{"id", "title", "servings", "instructions", "tips", "status", "user_id", "created_at", "updated_at", "prep_time_minutes", "cook_time_minutes", "difficulty", "source_name", "source_url", "last_modified_by", "visibility", "featured", "featured_at"}
+	database := openScriptedDatabase(t,
+		scriptedResult{match: "FOR UPDATE", columns: recipeColumns, rows: [][]driver.Value{recipeRow}},
+		scriptedResult{match: "SET title", columns: recipeColumns, rows: [][]driver.Value{recipeRow}},
+		scriptedResult{
+			match:   "INSERT INTO recipe_ingredients",
+			columns: []string{"id", "recipe_id", "canonical_ingredient_id", "original_text", "quantity", "unit", "created_at", "updated_at"},
+			rows:    [][]driver.Value{{int64(1), int64(5), nil, "A handful of herbs", nil, nil, now, now}},
+		},
+		scriptedResult{match: "FROM recipe_ingredients", columns: []string{"id", "recipe_id", "canonical_ingredient_id", "original_text", "quantity", "unit", "created_at", "updated_at", "canonical_name"}},
+	)
+	router := gin.New()
+	router.POST("/api/v1/recipes/:id/ingredients", func(c *gin.Context) {
+		c.Set("user_id", 7)
+		c.Next()
+	}, handlers.NewRecipeHandler(database, nil).AddRecipeIngredient)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/v1/recipes/5/ingredients", strings.NewReader(`{"original_text": "A handful of herbs"}`))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, []string{
+		"1 ingredient could not be matched to known ingredients",
+		"1 ingredient had no quantity that could be parsed",
+	}, decodeWarnings(t, w.Body.Bytes()))
+}
+
+// TestIngredientEditsAreRecipeChangesWithoutDatabase tests adding and linking a line record a revision and
+// attribute the edit like any recipe update, and are refused when the recipe changed after If-Unmodified-Since
+func TestIngredientEditsAreRecipeChangesWithoutDatabase(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	updatedAt := time.Date(2026, 3, 14, 9, 30, 15, 0, time.UTC)
+	recipeRow := []driver.Value{int64(5), "Stew", nil, nil, nil, "review_required", int64(7), updatedAt, updatedAt, nil, nil, nil, nil, nil, int64(7), "public", false, nil}
+	recipeColumns := []string{"id", "title", "servings", "instructions", "tips", "status", "user_id", "created_at", "updated_at", "prep_time_minutes", "cook_time_minutes", "difficulty", "source_name", "source_url", "last_modified_by", "visibility", "featured", "featured_at"}
+	ingredientColumns := []string{"id", "recipe_id", "canonical_ingredient_id", "original_text", "quantity", "unit", "created_at", "updated_at"}
+	ingredientRow := []driver.Value{int64(3), int64(5), int64(9), "2 cups flour", 2.0, "cup", updatedAt, updatedAt}
+
+	edits := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		write  string
+	}{
+		{"Add", "POST", "/api/v1/recipes/5/ingredients", `{"original_text": "2 cups flour"}`, "INSERT INTO recipe_ingredients"},
+		{"Link", "PATCH", "/api/v1/recipes/5/ingredients/3", `{"canonical_ingredient_id": 9}`, "UPDATE recipe_ingredients"},
+	}
+
+	for _, edit := range edits {
+		t.Run(edit.name, func(t *testing.T) {
+			send := func(since string) *httptest.ResponseRecorder {
+				database := openScriptedDatabase(t,
+					scriptedResult{match: "FOR UPDATE", columns: recipeColumns, rows: [][]driver.Value{recipeRow}},
+					scriptedResult{match: "SET title", columns: recipeColumns, rows: [][]driver.Value{recipeRow}},
+					scriptedResult{match: "is_approved", columns: []string{"name", "is_approved"}, rows: [][]driver.Value{{"flour", true}}},
+					scriptedResult{match: edit.write, columns: ingredientColumns, rows: [][]driver.Value{ingredientRow}},
+					scriptedResult{match: "FROM recipe_ingredients", columns: append(ingredientColumns, "canonical_name")},
+				)
+				handler := handlers.NewRecipeHandler(database, nil)
+				router := gin.New()
+				router.Use(func(c *gin.Context) {
+					c.Set("user_id", 7)
+					c.Next()
+				})
+				router.POST("/api/v1/recipes/:id/ingredients", handler.AddRecipeIngredient)
+				router.PATCH("/api/v1/recipes/:id/ingredients/:ingredientId", handler.LinkRecipeIngredient)
+
+				w := httptest.NewRecorder()
+				req, _ := http.NewRequest(edit.method, edit.path, strings.NewReader(edit.body))
+				req.Header.Set("Content-Type", "application/json")
+				if since != "" {
+					req.Header.Set("If-Unmodified-Since", since)
+				}
+				router.ServeHTTP(w, req)
+				return w
+			}
+			position := func(match string) int {
+				return slices.IndexFunc(scriptedQueries(), func(query string) bool { return strings.Contains(query, match) })
+			}
+
+			w := send("")
+			require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+			revision, update, write := position("INSERT INTO recipe_revisions"), position("last_modified_by = "), position(edit.write)
+			require.NotEqual(t, -1, revision, "The previous ingredients are recorded as a revision")
+			require.NotEqual(t, -1, update, "The recipe row is updated so updated_at and last_modified_by move")
+			assert.Less(t, revision, write)
+			assert.Less(t, update, write)
+			assert.Greater(t, position("COMMIT"), write, "Everything is written in one transaction")
+
+			w = send(updatedAt.Add(-time.Second).Format(http.TimeFormat))
+			assert.Equal(t, http.StatusPreconditionFailed, w.Code, w.Body.String())
+			assert.Equal(t, -1, position(edit.write))
+			assert.Equal(t, -1, position("INSERT INTO recipe_revisions"))
+		})
+	}
+}
+
+// TestCanonicalLinksCheckedWithoutDatabase tests every write that sends ingredient lines refuses links to missing or
+// unapproved canonical ingredients before the recipe is changed
+func TestCanonicalLinksCheckedWithoutDatabase(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	lines := `[{"original_text": "2 cups flour", "canonical_ingredient_id": 3}, {"original_text": "1 tsp sumac", "canonical_ingredient_id": 9}, {"original_text": "1 tbsp zaatar", "canonical_ingredient_id": 11}]`
+	listErrors := models.FieldErrors{
+		{Field: "ingredients[1].canonical_ingredient_id", Message: "canonical ingredient is not approved"},
+		{Field: "ingredients[2].canonical_ingredient_id", Message: "canonical ingredient not found"},
+	}
+
+	writes := []struct {
+		method   string
+		path     string
+		body     string
+		expected models.FieldErrors
+	}{
+		{"PUT", "/api/v1/recipes/5", `{"title": "Stew", "ingredients": ` + lines + `}`, listErrors},
+		{"PATCH", "/api/v1/recipes/5", `{"ingredients": ` + lines + `}`, listErrors},
+		{"PUT", "/api/v1/recipes/5/ingredients", `{"ingredients": ` + lines + `}`, listErrors},
+		{"PUT", "/api/v1/recipes/5/draft", `{"ingredients": ` + lines + `}`, listErrors},
+		{"POST", "/api/v1/recipes/5/ingredients", `{"original_text": "1 tsp sumac", "canonical_ingredient_id": 9}`, models.FieldErrors{
+			{Field: "canonical_ingredient_id", Message: "canonical ingredient is not approved"},
+		}},
+	}
+	for _, write := range writes {
+		database := openScriptedDatabase(t,
+			scriptedResult{match: "is_approved", columns: []string{"id", "is_approved"}, rows: [][]driver.Value{{int64(3), true}, {int64(9), false}}},
+			scriptedResult{match: "SELECT user_id FROM recipes", columns: []string{"user_id"}, rows: [][]driver.Value{{int64(7)}}},
+		)
+		handler := handlers.NewRecipeHandler(database, nil)
+		router := gin.New()
+		router.Use(func(c *gin.Context) {
+			c.Set("user_id", 7)
+			c.Next()
+		})
+		router.PUT("/api/v1/recipes/:id", handler.UpdateRecipe)
+		router.PATCH("/api/v1/recipes/:id", handler.PatchRecipe)
+		router.PUT("/api/v1/recipes/:id/ingredients", handler.ReplaceRecipeIngredients)
+		router.POST("/api/v1/recipes/:id/ingredients", handler.AddRecipeIngredient)
+		router.PUT("/api/v1/recipes/:id/draft", handler.SaveRecipeDraft)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(write.method, write.path, strings.NewReader(write.body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusUnprocessableEntity, w.Code, write.method+" "+write.path+": "+w.Body.String())
+		_, errs := decodeFieldErrors(t, w.Body.Bytes())
+		assert.Equal(t, write.expected, errs, write.method+" "+write.path)
+		assert.NotContains(t, scriptedQueries(), "BEGIN", write.method+" "+write.path)
+	}
+}
+
+// TestIngredientCapWithoutDatabase tests each ingredient write accepts a list at the cap and rejects one just over it
+func TestIngredientCapWithoutDatabase(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	now := time.Now()
+	recipeRow := []driver.Value{int64(5), "Stew", nil, nil, nil, "review_required", int64(7), now, now, nil, nil, nil, nil, nil, nil, "public", false, nil}
+	recipeColumns := []string{"id", "title", "servings", "instructions", "tips", "status", "user_id", "created_at", "updated_at", "prep_time_minutes", "cook_time_minutes", "difficulty", "source_name", "source_url", "last_modified_by", "visibility", "featured", "featured_at"}
+	ingredientColumns := []string{"id", "recipe_id", "canonical_ingredient_id", "original_text", "quantity", "unit", "created_at", "updated_at"}
+
+	send := func(method, path, body string, existing int) *httptest.ResponseRecorder {
+		var existingRows [][]driver.Value
+		for i := 1; i <= existing; i++ {
+			existingRows = append(existingRows, []driver.Value{int64(i), int64(5), nil, "1 egg", 1.0, nil, now, now, nil})
+		}
+		database := openScriptedDatabase(t,
+			scriptedResult{match: "FOR UPDATE", columns: recipeColumns, rows: [][]driver.Value{recipeRow}},
+			scriptedResult{match: "SET title", columns: recipeColumns, rows: [][]driver.Value{recipeRow}},
+			scriptedResult{match: "INSERT INTO recipe_ingredients", columns: ingredientColumns, rows: [][]driver.Value{{int64(1), int64(5), nil, "1 egg", 1.0, nil, now, now}}},
+			scriptedResult{match: "FROM recipe_ingredients", columns: append(ingredientColumns, "canonical_name"), rows: existingRows},
+			scriptedResult{match: "FROM recipes", columns: recipeColumns, rows: [][]driver.Value{recipeRow}},
+		)
+		handler := handlers.NewRecipeHandler(database, nil)
+		handler.SetMaxIngredients(3)
+		router := gin.New()
+		router.Use(func(c *gin.Context) {
+			c.Set("user_id", 7)
+			c.Next()
+		})
+		router.PUT("/api/v1/recipes/:id", handler.UpdateRecipe)
+		router.PATCH("/api/v1/recipes/:id", handler.PatchRecipe)
+		router.POST("/api/v1/recipes/:id/ingredients", handler.AddRecipeIngredient)
+		router.PUT("/api/v1/recipes/:id/ingredients", handler.ReplaceRecipeIngredients)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		return w
+	}
+	list := func(n int) string {
+		return "[" + strings.TrimSuffix(strings.Repeat(`{"original_text": "1 egg"},`, n), ",") + "]"
+	}
+
+	writes := []struct {
+		method string
+		path   string
+		body   func(n int) string
+	}{
+		{"PUT", "/api/v1/recipes/5", func(n int) string { return `{"title": "Stew", "ingredients": ` + list(n) + `}` }},
+		{"PATCH", "/api/v1/recipes/5", func(n int) string { return `{"ingredients": ` + list(n) + `}` }},
+		{"PUT", "/api/v1/recipes/5/ingredients", func(n int) string { return `{"ingredients": ` + list(n) + `}` }},
+	}
+	for _, write := range writes {
+		w := send(write.method, write.path, write.body(3), 0)
+		assert.Equal(t, http.StatusOK, w.Code, write.method+" "+write.path+": "+w.Body.String())
+
+		w = send(write.method, write.path, write.body(4), 0)
+		require.Equal(t, http.StatusUnprocessableEntity, w.Code, write.method+" "+write.path)
+		_, errs := decodeFieldErrors(t, w.Body.Bytes())
+		assert.Equal(t, models.FieldErrors{{Field: "ingredients", Message: "a recipe can have at most 3 ingredients"}}, errs)
+	}
+
+	// Adding one line counts the ingredients the recipe already has
+	assert.Equal(t, http.StatusOK, send("POST", "/api/v1/recipes/5/ingredients", `{"original_text": "1 egg"}`, 2).Code)
+	w := send("POST", "/api/v1/recipes/5/ingredients", `{"original_text": "1 egg"}`, 3)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code, w.Body.String())
+	assert.NotContains(t, strings.Join(scriptedQueries(), "\n"), "INSERT INTO recipe_ingredients")
+}
+
+// TestRestoreRevisionIngredientCapWithoutDatabase tests restoring a revision with more ingredients than the cap is refused before the recipe changes
+func TestRestoreRevisionIngredientCapWithoutDatabase(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	now := time.Now()
+	recipeRow := []driver.Value{int64(5), "Stew", nil, nil, nil, "review_required", int64(7), now, now, nil, nil, nil, nil, nil, nil, "public", false, nil}
+	recipeColumns := []string{"id", "title", "servings", "instructions", "tips", "status", "user_id", "created_at", "updated_at", "prep_time_minutes", "cook_time_minutes", "difficulty", "source_name", "source_url", "last_modified_by", "visibility", "featured", "featured_at"}
+	ingredientColumns := []string{"id", "recipe_id", "canonical_ingredient_id", "original_text", "quantity", "unit", "created_at", "updated_at"}
+
+	restore := func(snapshotIngredients int) *httptest.ResponseRecorder {
+		snapshot := models.RecipeWithIngredients{Recipe: models.Recipe{ID: 5, Title: "Stew", Status: "review_required"}}
+		for i := 1; i <= snapshotIngredients; i++ {
+			snapshot.Ingredients = append(snapshot.Ingredients, models.RecipeIngredient{ID: i, RecipeID: 5, OriginalText: "1 egg"})
+		}
+		encoded, err := json.Marshal(snapshot)
+		require.NoError(t, err)
+
+		database := openScriptedDatabase(t,
+			scriptedResult{match: "SELECT user_id FROM recipes", columns: []string{"user_id"}, rows: [][]driver.Value{{int64(7)}}},
+			scriptedResult{match: "FROM recipe_revisions", columns: []string{"id", "recipe_id", "revision_number", "snapshot", "changed_by", "created_at"}, rows: [][]driver.Value{{int64(2), int64(5), int64(1), encoded, int64(7), now}}},
+			scriptedResult{match: "FOR UPDATE", columns: recipeColumns, rows: [][]driver.Value{recipeRow}},
+			scriptedResult{match: "SET title", columns: recipeColumns, rows: [][]driver.Value{recipeRow}},
+			scriptedResult{match: "INSERT INTO recipe_ingredients", columns: ingredientColumns, rows: [][]driver.Value{{int64(1), int64(5), nil, "1 egg", 1.0, nil, now, now}}},
+			scriptedResult{match: "FROM recipe_ingredients", columns: append(ingredientColumns, "canonical_name")},
+			scriptedResult{match: "FROM recipes", columns: recipeColumns, rows: [][]driver.Value{recipeRow}},
+		)
+		handler := handlers.NewRecipeHandler(database, nil)
+		handler.SetMaxIngredients(3)
+		router := gin.New()
+		router.Use(func(c *gin.Context) {
+			c.Set("user_id", 7)
+			c.Next()
+		})
+		router.POST("/api/v1/recipes/:id/revisions/:revisionId/restore", handler.RestoreRecipeRevision)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/v1/recipes/5/revisions/2/restore", nil)
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := restore(3)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	w = restore(4)
+	require.Equal(t, http.StatusUnprocessableEntity, w.Code, w.Body.String())
+	_, errs := decodeFieldErrors(t, w.Body.Bytes())
+	assert.Equal(t, models.FieldErrors{{Field: "ingredients", Message: "a recipe can have at most 3 ingredients"}}, errs)
+	assert.NotContains(t, scriptedQueries(), "BEGIN")
+}
+
+func TestGetMaxIngredientsPerRecipe(t *testing.T) {
+	t.Setenv("MAX_INGREDIENTS_PER_RECIPE", "")
+	assert.Equal(t, handlers.DefaultMaxIngredientsPerRecipe, handlers.GetMaxIngredientsPerRecipe())
+
+	t.Setenv("MAX_INGREDIENTS_PER_RECIPE", "500")
+	assert.Equal(t, 500, handlers.GetMaxIngredientsPerRecipe())
+
+	for _, invalid := range []string{"0", "-1", "many"} {
+		t.Setenv("MAX_INGREDIENTS_PER_RECIPE", invalid)
+		assert.Equal(t, handlers.DefaultMaxIngredientsPerRecipe, handlers.GetMaxIngredientsPerRecipe(), invalid)
+	}
+}
+
+// TestNegativeIngredientQuantityRejected tests negative quantities are refused by the API and by the schema
+func (suite *RecipeAPITestSuite) TestNegativeIngredientQuantityRejected() {
+	recipeID := suite.createTestRecipe("Review Recipe", "review_required")
+	auth := suite.authHeader(suite.testUserID, middleware.RoleUser)
+
+	w := suite.performJSON("POST", fmt.Sprintf("/api/v1/recipes/%d/ingredients", recipeID), `{"original_text": "flour", "quantity": -1}`, auth)
+	assert.Equal(suite.T(), http.StatusBadRequest, w.Code, w.Body.String())
+
+	// Writes that bypass request validation still hit the CHECK constraint
+	_, err := suite.db.DB.Exec(`INSERT INTO recipe_ingredients (recipe_id, original_text, quantity) VALUES ($1, 'flour', -1)`, recipeID)
+	require.Error(suite.T(), err)
+	var pqErr *pq.Error
+	require.ErrorAs(suite.T(), err, &pqErr)
+	assert.Equal(suite.T(), "recipe_ingredients_quantity_non_negative", pqErr.Constraint)
+
+	_, err = suite.db.DB.Exec(`INSERT INTO recipe_ingredients (recipe_id, original_text, quantity) VALUES ($1, 'salt', 0)`, recipeID)
+	assert.NoError(suite.T(), err, "Zero is a valid quantity")
+}
+
+// decodeIngredientList unmarshals a paginated ingredient list response
+func decodeIngredientList(t *testing.T, body []byte) ([]models.RecipeIngredient, *handlers.Pagination) {
+	var response struct {
+		Data       []models.RecipeIngredient `json:"data"`
+		Pagination *handlers.Pagination      `json:"pagination"`
+	}
+	require.NoError(t, json.Unmarshal(body, &response))
+	return response.Data, response.Pagination
+}
+
+// TestGetRecipeIngredients tests the ingredient sub-resource lists lines with canonical names and its own pages
+func (suite *RecipeAPITestSuite) TestGetRecipeIngredients() {
+	recipeID := suite.createTestRecipe("Pancakes", "published")
+	flourID := suite.createCanonicalIngredient("Flour", true)
+	first := suite.createTestIngredient(recipeID, "2 cups flour", &flourID)
+	second := suite.createTestIngredient(recipeID, "1 cup milk", nil)
+	third := suite.createTestIngredient(recipeID, "2 eggs", nil)
+	path := fmt.Sprintf("/api/v1/recipes/%d/ingredients", recipeID)
+
+	w := suite.performGet(path, "")
+	require.Equal(suite.T(), http.StatusOK, w.Code, w.Body.String())
+	ingredients, pagination := decodeIngredientList(suite.T(), w.Body.Bytes())
+	require.Len(suite.T(), ingredients, 3)
+	assert.Equal(suite.T(), []int{first, second, third}, []int{ingredients[0].ID, ingredients[1].ID, ingredients[2].ID})
+	require.NotNil(suite.T(), ingredients[0].CanonicalName)
+	assert.Equal(suite.T(), "Flour", *ingredients[0].CanonicalName)
+	assert.Nil(suite.T(), ingredients[1].CanonicalName)
+	require.NotNil(suite.T(), pagination)
+	assert.Equal(suite.T(), 3, pagination.Total)
+	assert.NotContains(suite.T(), w.Body.String(), `"title"`, "The recipe itself is not included")
+
+	w = suite.performGet(path+"?page=2&per_page=2", "")
+	require.Equal(suite.T(), http.StatusOK, w.Code)
+	ingredients, pagination = decodeIngredientList(suite.T(), w.Body.Bytes())
+	require.Len(suite.T(), ingredients, 1)
+	assert.Equal(suite.T(), third, ingredients[0].ID)
+	assert.Equal(suite.T(), &handlers.Pagination{Page: 2, PerPage: 2, Total: 3, TotalPages: 2}, pagination)
+}
+
+// TestGetRecipeIngredientsEmptyAndMissing tests recipes without ingredients list [] and unknown recipes 404
+func (suite *RecipeAPITestSuite) TestGetRecipeIngredientsEmptyAndMissing() {
+	recipeID := suite.createTestRecipe("Water", "published")
+
+	w := suite.performGet(fmt.Sprintf("/api/v1/recipes/%d/ingredients", recipeID), "")
+	require.Equal(suite.T(), http.StatusOK, w.Code, w.Body.String())
+	assert.Contains(suite.T(), w.Body.String(), `"data":[]`)
+	_, pagination := decodeIngredientList(suite.T(), w.Body.Bytes())
+	require.NotNil(suite.T(), pagination)
+	assert.Equal(suite.T(), 0, pagination.Total)
+
+	w = suite.performGet(fmt.Sprintf("/api/v1/recipes/%d/ingredients", NonExistentID), "")
+	assert.Equal(suite.T(), http.StatusNotFound, w.Code)
+}
+
+// TestGetRecipeIngredientsWithoutDatabase tests the existence check, paging and parameter validation
+func TestGetRecipeIngredientsWithoutDatabase(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	now := time.Now()
+	ingredientColumns := []string{"id", "recipe_id", "canonical_ingredient_id", "original_text", "quantity", "unit", "created_at", "updated_at", "canonical_name"}
+	database := openScriptedDatabase(t,
+		scriptedResult{match: "SELECT user_id, visibility", columns: []string{"user_id", "visibility"}, rows: [][]driver.Value{{int64(1), "public"}}},
+		scriptedResult{
+			match:   "WHERE ri.recipe_id = $1",
+			columns: ingredientColumns,
+			rows: [][]driver.Value{
+				{int64(1), int64(5), int64(3), "2 cups flour", 2.0, "cup", now, now, "Flour"},
+				{int64(2), int64(5), nil, "1 cup milk", 1.0, "cup", now, now, nil},
+				{int64(3), int64(5), nil, "2 eggs", 2.0, nil, now, now, nil},
+			},
+		},
+	)
+
+	router := gin.New()
+	router.GET("/api/v1/recipes/:id/ingredients", handlers.NewRecipeHandler(database, nil).GetRecipeIngredients)
+	get := func(target string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", target, nil)
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := get("/api/v1/recipes/5/ingredients?per_page=2")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	ingredients, pagination := decodeIngredientList(t, w.Body.Bytes())
+	require.Len(t, ingredients, 2)
+	assert.Equal(t, "Flour", *ingredients[0].CanonicalName)
+	assert.Equal(t, &handlers.Pagination{Page: 1, PerPage: 2, Total: 3, TotalPages: 2}, pagination)
+
+	// Filtering happens before paging, so the totals describe the filtered list
+	w = get("/api/v1/recipes/5/ingredients?unmatched=true&per_page=1")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	ingredients, pagination = decodeIngredientList(t, w.Body.Bytes())
+	require.Len(t, ingredients, 1)
+	assert.Equal(t, 2, ingredients[0].ID)
+	assert.Equal(t, &handlers.Pagination{Page: 1, PerPage: 1, Total: 2, TotalPages: 2}, pagination)
+
+	w = get("/api/v1/recipes/5/ingredients?unmatched=false")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	ingredients, _ = decodeIngredientList(t, w.Body.Bytes())
+	require.Len(t, ingredients, 1)
+	assert.Equal(t, 1, ingredients[0].ID)
+
+	assert.Equal(t, http.StatusBadRequest, get("/api/v1/recipes/abc/ingredients").Code)
+	assert.Equal(t, http.StatusBadRequest, get("/api/v1/recipes/5/ingredients?page=0").Code)
+	assert.Equal(t, http.StatusBadRequest, get("/api/v1/recipes/5/ingredients?unmatched=maybe").Code)
+}
+
+// TestGetRecipeUnmatchedIngredients tests reviewers can list only the lines still lacking a canonical ingredient
+func (suite *RecipeAPITestSuite) TestGetRecipeUnmatchedIngredients() {
+	recipeID := suite.createTestRecipe("Pancakes", "review_required")
+	flourID := suite.createCanonicalIngredient("Flour", true)
+	suite.createTestIngredient(recipeID, "2 cups flour", &flourID)
+	milk := suite.createTestIngredient(recipeID, "1 cup milk", nil)
+	suite.createTestIngredient(recipeID, "1 cup flour", &flourID)
+	eggs := suite.createTestIngredient(recipeID, "2 eggs", nil)
+	path := fmt.Sprintf("/api/v1/recipes/%d/ingredients?unmatched=true", recipeID)
+
+	w := suite.performGet(path, "")
+	require.Equal(suite.T(), http.StatusOK, w.Code, w.Body.String())
+	ingredients, pagination := decodeIngredientList(suite.T(), w.Body.Bytes())
+	require.Len(suite.T(), ingredients, 2)
+	assert.Equal(suite.T(), []int{milk, eggs}, []int{ingredients[0].ID, ingredients[1].ID})
+	assert.Nil(suite.T(), ingredients[0].CanonicalIngredientID)
+	assert.Equal(suite.T(), 2, pagination.Total)
+
+	// Linking an ingredient takes it off the list
+	_, err := suite.db.DB.Exec(`UPDATE recipe_ingredients SET canonical_ingredient_id = $1 WHERE id = $2`, flourID, milk)
+	require.NoError(suite.T(), err)
+	w = suite.performGet(path, "")
+	require.Equal(suite.T(), http.StatusOK, w.Code)
+	ingredients, _ = decodeIngredientList(suite.T(), w.Body.Bytes())
+	require.Len(suite.T(), ingredients, 1)
+	assert.Equal(suite.T(), eggs, ingredients[0].ID)
+}
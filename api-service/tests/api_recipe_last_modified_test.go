@@ -0,0 +1,48 @@
+package tests
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+
+	"digital-recipes/api-service/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRecipeLastModifiedBy tests updates record the editor, so an admin's edit is attributed to the admin rather than the owner
+func (suite *RecipeAPITestSuite) TestRecipeLastModifiedBy() {
+	recipeID := suite.createTestRecipe("Stew", "review_required")
+	adminID := suite.createTestUser("admin@example.com")
+	path := fmt.Sprintf("/api/v1/recipes/%d", recipeID)
+
+	w := suite.performGet(path, "")
+	require.Equal(suite.T(), http.StatusOK, w.Code)
+	assert.Nil(suite.T(), suite.decodeRecipe(w.Body.Bytes()).LastModifiedBy, "A recipe nobody has edited has no last editor")
+
+	w = suite.performJSON("PATCH", path, `{"title": "Beef Stew"}`, suite.authHeader(adminID, middleware.RoleAdmin))
+	require.Equal(suite.T(), http.StatusOK, w.Code, w.Body.String())
+	edited := suite.decodeRecipe(w.Body.Bytes())
+	require.NotNil(suite.T(), edited.LastModifiedBy)
+	assert.Equal(suite.T(), adminID, *edited.LastModifiedBy)
+	assert.Equal(suite.T(), suite.testUserID, edited.UserID, "The owner is unchanged")
+
+	var lastModifiedBy sql.NullInt64
+	require.NoError(suite.T(), suite.db.DB.QueryRow(`SELECT last_modified_by FROM recipes WHERE id = $1`, recipeID).Scan(&lastModifiedBy))
+	assert.Equal(suite.T(), int64(adminID), lastModifiedBy.Int64)
+
+	// The owner's next edit takes the attribution back
+	w = suite.performJSON("PUT", path, `{"title": "Lamb Stew"}`, suite.authHeader(suite.testUserID, middleware.RoleUser))
+	require.Equal(suite.T(), http.StatusOK, w.Code, w.Body.String())
+	edited = suite.decodeRecipe(w.Body.Bytes())
+	require.NotNil(suite.T(), edited.LastModifiedBy)
+	assert.Equal(suite.T(), suite.testUserID, *edited.LastModifiedBy)
+
+	// Deleting the editor's account keeps the recipe and clears the attribution
+	_, err := suite.db.DB.Exec(`UPDATE recipes SET last_modified_by = $1 WHERE id = $2`, adminID, recipeID)
+	require.NoError(suite.T(), err)
+	_, err = suite.db.DB.Exec(`DELETE FROM users WHERE id = $1`, adminID)
+	require.NoError(suite.T(), err)
+	require.NoError(suite.T(), suite.db.DB.QueryRow(`SELECT last_modified_by FROM recipes WHERE id = $1`, recipeID).Scan(&lastModifiedBy))
+	assert.False(suite.T(), lastModifiedBy.Valid)
+}
@@ -0,0 +1,119 @@
+package tests
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"digital-recipes/api-service/handlers"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestGetRecipeContentNegotiation tests GET /recipes/:id serves JSON, JSON-LD or Markdown from the Accept header
+func (suite *RecipeAPITestSuite) TestGetRecipeContentNegotiation() {
+	recipeID := suite.createTestRecipe("Pancakes", "published")
+	suite.createTestIngredient(recipeID, "2 eggs", nil)
+	path := fmt.Sprintf("/api/v1/recipes/%d", recipeID)
+
+	get := func(accept string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", path, nil)
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		suite.router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := get("")
+	require.Equal(suite.T(), http.StatusOK, w.Code)
+	assert.Contains(suite.T(), w.Header().Get("Content-Type"), "application/json")
+	assert.Contains(suite.T(), w.Header().Values("Vary"), "Accept")
+
+	w = get("application/ld+json")
+	require.Equal(suite.T(), http.StatusOK, w.Code, w.Body.String())
+	assert.Contains(suite.T(), w.Header().Get("Content-Type"), "application/ld+json")
+	var document map[string]interface{}
+	require.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &document))
+	assert.Equal(suite.T(), "Recipe", document["@type"])
+	assert.Equal(suite.T(), []interface{}{"2 eggs"}, document["recipeIngredient"])
+
+	w = get("text/markdown")
+	require.Equal(suite.T(), http.StatusOK, w.Code)
+	assert.Contains(suite.T(), w.Header().Get("Content-Type"), "text/markdown")
+	assert.Contains(suite.T(), w.Body.String(), "# Pancakes")
+	assert.Empty(suite.T(), w.Header().Get("Content-Disposition"), "Markdown is shown inline, not downloaded")
+
+	w = get("text/html")
+	assert.Equal(suite.T(), http.StatusNotAcceptable, w.Code)
+
+	// Other representations keep the export rule that only owners see unpublished recipes
+	draftID := suite.createTestRecipe("Secret Draft", "review_required")
+	w = httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", fmt.Sprintf("/api/v1/recipes/%d", draftID), nil)
+	req.Header.Set("Accept", "text/markdown")
+	suite.router.ServeHTTP(w, req)
+	assert.Equal(suite.T(), http.StatusNotFound, w.Code)
+}
+
+// TestGetRecipeAcceptHeader tests how Accept headers, wildcards and quality values pick the recipe representation
+func TestGetRecipeAcceptHeader(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	now := time.Now()
+	recipeColumns := []string{"id", "title", "servings", "instructions", "tips", "status", "user_id", "created_at", "updated_at", "prep_time_minutes", "cook_time_minutes", "difficulty", "source_name", "source_url", "last_modified_by", "visibility", "featured", "featured_at"}
+	database := openScriptedDatabase(t,
+		scriptedResult{match: "FROM recipe_ingredients", columns: []string{"id", "recipe_id", "canonical_ingredient_id", "original_text", "quantity", "unit", "created_at", "updated_at", "canonical_name"}},
+		scriptedResult{match: "FROM recipes", columns: recipeColumns, rows: [][]driver.Value{{int64(5), "Pancakes", nil, nil, nil, "published", int64(7), now, now, nil, nil, nil, nil, nil, nil, "public", false, nil}}},
+		scriptedResult{match: "FROM users", columns: []string{"name"}, rows: [][]driver.Value{{"Jane Baker"}}},
+	)
+	router := gin.New()
+	router.GET("/api/v1/recipes/:id", handlers.NewRecipeHandler(database, nil).GetRecipe)
+
+	tests := []struct {
+		accept      string
+		status      int
+		contentType string
+	}{
+		{"", http.StatusOK, "application/json"},
+		{"application/json", http.StatusOK, "application/json"},
+		{"*/*", http.StatusOK, "application/json"},
+		{"text/html,application/xhtml+xml,*/*;q=0.8", http.StatusOK, "application/json"},
+		{"application/ld+json", http.StatusOK, "application/ld+json"},
+		{"application/*", http.StatusOK, "application/json"},
+		{"text/markdown", http.StatusOK, "text/markdown"},
+		{"text/*", http.StatusOK, "text/markdown"},
+		{"application/json;q=0.5, text/markdown", http.StatusOK, "text/markdown"},
+		{"application/ld+json, */*;q=0", http.StatusOK, "application/ld+json"},
+		{"not a media type", http.StatusOK, "application/json"},
+		{"text/html", http.StatusNotAcceptable, "application/json"},
+		{"application/xml, image/*", http.StatusNotAcceptable, "application/json"},
+		{"application/json;q=0", http.StatusNotAcceptable, "application/json"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.accept, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/api/v1/recipes/5", nil)
+			req.Header.Set("Accept", tt.accept)
+			router.ServeHTTP(w, req)
+
+			require.Equal(t, tt.status, w.Code, w.Body.String())
+			assert.Contains(t, w.Header().Get("Content-Type"), tt.contentType)
+			assert.Contains(t, w.Header().Values("Vary"), "Accept")
+		})
+	}
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v1/recipes/5", nil)
+	req.Header.Set("Accept", "text/html")
+	router.ServeHTTP(w, req)
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "NOT_ACCEPTABLE", response["code"])
+}
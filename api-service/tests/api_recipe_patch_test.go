@@ -0,0 +1,85 @@
+package tests
+
+import (
+	"fmt"
+	"net/http"
+
+	"digital-recipes/api-service/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPatchRecipeTitleOnly tests a title-only patch leaves every other field and the ingredients alone
+func (suite *RecipeAPITestSuite) TestPatchRecipeTitleOnly() {
+	recipeID := suite.createTestRecipe("Original Title", "review_required")
+	suite.createTestIngredient(recipeID, "1 cup rice", nil)
+	auth := suite.authHeader(suite.testUserID, middleware.RoleUser)
+
+	w := suite.performJSON("PATCH", fmt.Sprintf("/api/v1/recipes/%d", recipeID), `{"title": "  Patched Title "}`, auth)
+	require.Equal(suite.T(), http.StatusOK, w.Code, w.Body.String())
+
+	patched := suite.decodeRecipe(w.Body.Bytes())
+	assert.Equal(suite.T(), "Patched Title", patched.Title)
+	assert.Equal(suite.T(), "review_required", patched.Status)
+	require.NotNil(suite.T(), patched.Servings)
+	assert.Equal(suite.T(), "4", *patched.Servings)
+	require.NotNil(suite.T(), patched.Instructions)
+	assert.Equal(suite.T(), "Test instructions", *patched.Instructions)
+	require.NotNil(suite.T(), patched.Tips)
+	assert.Equal(suite.T(), "Test tips", *patched.Tips)
+	require.Len(suite.T(), patched.Ingredients, 1, "Ingredients are untouched without an ingredients array")
+	assert.Equal(suite.T(), "1 cup rice", patched.Ingredients[0].OriginalText)
+	assert.Equal(suite.T(), 1, suite.countRevisions(recipeID), "Patches are recorded like full updates")
+}
+
+// TestPatchRecipeTipsOnly tests a tips-only patch, including clearing tips to an empty string
+func (suite *RecipeAPITestSuite) TestPatchRecipeTipsOnly() {
+	recipeID := suite.createTestRecipe("Tipped", "published")
+	auth := suite.authHeader(suite.testUserID, middleware.RoleUser)
+	path := fmt.Sprintf("/api/v1/recipes/%d", recipeID)
+
+	w := suite.performJSON("PATCH", path, `{"tips": "Rest the dough overnight"}`, auth)
+	require.Equal(suite.T(), http.StatusOK, w.Code, w.Body.String())
+	patched := suite.decodeRecipe(w.Body.Bytes())
+	require.NotNil(suite.T(), patched.Tips)
+	assert.Equal(suite.T(), "Rest the dough overnight", *patched.Tips)
+	assert.Equal(suite.T(), "Tipped", patched.Title)
+	assert.Equal(suite.T(), "published", patched.Status)
+
+	w = suite.performJSON("PATCH", path, `{"tips": ""}`, auth)
+	require.Equal(suite.T(), http.StatusOK, w.Code, w.Body.String())
+	patched = suite.decodeRecipe(w.Body.Bytes())
+	require.NotNil(suite.T(), patched.Tips, "An empty string is stored rather than treated as omitted")
+	assert.Equal(suite.T(), "", *patched.Tips)
+	require.NotNil(suite.T(), patched.Instructions)
+	assert.Equal(suite.T(), "Test instructions", *patched.Instructions)
+}
+
+// TestPatchRecipeValidation tests patches follow the same ownership and status rules as full updates
+func (suite *RecipeAPITestSuite) TestPatchRecipeValidation() {
+	recipeID := suite.createTestRecipe("Published", "published")
+	otherUserID := suite.createTestUser("patch-other@example.com")
+	auth := suite.authHeader(suite.testUserID, middleware.RoleUser)
+	path := fmt.Sprintf("/api/v1/recipes/%d", recipeID)
+
+	w := suite.performJSON("PATCH", path, `{"status": "processing"}`, auth)
+	assert.Equal(suite.T(), http.StatusBadRequest, w.Code)
+	assert.Contains(suite.T(), w.Body.String(), "cannot change status from published to processing")
+
+	w = suite.performJSON("PATCH", path, `{"title": "   "}`, auth)
+	assert.Equal(suite.T(), http.StatusUnprocessableEntity, w.Code)
+
+	w = suite.performJSON("PATCH", path, `{}`, auth)
+	assert.Equal(suite.T(), http.StatusBadRequest, w.Code)
+
+	w = suite.performJSON("PATCH", path, `{"tips": "Hijacked"}`, suite.authHeader(otherUserID, middleware.RoleUser))
+	assert.Equal(suite.T(), http.StatusForbidden, w.Code)
+
+	w = suite.performJSON("PATCH", path, `{"tips": "Anonymous"}`, "")
+	assert.Equal(suite.T(), http.StatusUnauthorized, w.Code)
+
+	w = suite.performJSON("PATCH", "/api/v1/recipes/999999", `{"tips": "Missing"}`, auth)
+	assert.Equal(suite.T(), http.StatusNotFound, w.Code)
+
+	assert.Equal(suite.T(), 0, suite.countRevisions(recipeID), "Rejected patches change nothing")
+}
@@ -0,0 +1,126 @@
+package tests
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"digital-recipes/api-service/handlers"
+	"digital-recipes/api-service/middleware"
+	"digital-recipes/api-service/models"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// decodeRecipeShareLink extracts a newly created share link from a standard response
+func decodeRecipeShareLink(t *testing.T, body []byte) models.RecipeShareLink {
+	var response handlers.StandardResponse
+	require.NoError(t, json.Unmarshal(body, &response))
+	dataBytes, _ := json.Marshal(response.Data)
+	var link models.RecipeShareLink
+	require.NoError(t, json.Unmarshal(dataBytes, &link))
+	return link
+}
+
+// TestRecipeShareLinks tests a share link opens a private recipe until it is revoked or expires
+func (suite *RecipeAPITestSuite) TestRecipeShareLinks() {
+	recipeID := suite.createRecipeWithVisibility("Secret Pie", models.VisibilityPrivate)
+	suite.createTestIngredient(recipeID, "2 apples", nil)
+	auth := suite.authHeader(suite.testUserID, middleware.RoleUser)
+	sharePath := fmt.Sprintf("/api/v1/recipes/%d/share", recipeID)
+
+	w := suite.performJSON("POST", sharePath, ``, auth)
+	require.Equal(suite.T(), http.StatusOK, w.Code, w.Body.String())
+	link := decodeRecipeShareLink(suite.T(), w.Body.Bytes())
+	assert.Len(suite.T(), link.Token, 64)
+	assert.Equal(suite.T(), "/api/v1/shared/"+link.Token, link.Path)
+	assert.Nil(suite.T(), link.ExpiresAt)
+
+	// The recipe stays hidden from anonymous callers, but the link opens it
+	assert.Equal(suite.T(), http.StatusNotFound, suite.performGet(fmt.Sprintf("/api/v1/recipes/%d", recipeID), "").Code)
+	w = suite.performGet(link.Path, "")
+	require.Equal(suite.T(), http.StatusOK, w.Code, w.Body.String())
+	shared := suite.decodeRecipe(w.Body.Bytes())
+	assert.Equal(suite.T(), "Secret Pie", shared.Title)
+	require.Len(suite.T(), shared.Ingredients, 1)
+	assert.Equal(suite.T(), "no-store", w.Header().Get("Cache-Control"))
+
+	// Listing shows the link without its token
+	w = suite.performGet(sharePath, auth)
+	require.Equal(suite.T(), http.StatusOK, w.Code)
+	assert.Contains(suite.T(), w.Body.String(), fmt.Sprintf(`"id":%d`, link.ID))
+	assert.NotContains(suite.T(), w.Body.String(), link.Token)
+
+	// Only the owner or an admin can share the recipe
+	otherAuth := suite.authHeader(suite.createTestUser("other@example.com"), middleware.RoleUser)
+	assert.Equal(suite.T(), http.StatusForbidden, suite.performJSON("POST", sharePath, ``, otherAuth).Code)
+	assert.Equal(suite.T(), http.StatusForbidden, suite.performJSON("DELETE", fmt.Sprintf("%s/%d", sharePath, link.ID), ``, otherAuth).Code)
+
+	w = suite.performJSON("DELETE", fmt.Sprintf("%s/%d", sharePath, link.ID), ``, auth)
+	require.Equal(suite.T(), http.StatusOK, w.Code, w.Body.String())
+	assert.Contains(suite.T(), w.Body.String(), `"revoked_at"`)
+	assert.Equal(suite.T(), http.StatusNotFound, suite.performGet(link.Path, "").Code, "Revoked links stop working")
+
+	// A link with an expiry works until then
+	w = suite.performJSON("POST", sharePath, fmt.Sprintf(`{"expires_at": %q}`, time.Now().Add(time.Hour).Format(time.RFC3339)), auth)
+	require.Equal(suite.T(), http.StatusOK, w.Code, w.Body.String())
+	expiring := decodeRecipeShareLink(suite.T(), w.Body.Bytes())
+	require.NotNil(suite.T(), expiring.ExpiresAt)
+	assert.Equal(suite.T(), http.StatusOK, suite.performGet(expiring.Path, "").Code)
+	_, err := suite.db.DB.Exec(`UPDATE share_tokens SET expires_at = NOW() - INTERVAL '1 minute' WHERE id = $1`, expiring.ID)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusNotFound, suite.performGet(expiring.Path, "").Code, "Expired links stop working")
+
+	w = suite.performJSON("POST", sharePath, `{"expires_at": "2001-01-01T00:00:00Z"}`, auth)
+	assert.Equal(suite.T(), http.StatusBadRequest, w.Code)
+}
+
+// TestGetSharedRecipeWithoutDatabase tests tokens are looked up by hash and only links still in effect open a recipe
+func TestGetSharedRecipeWithoutDatabase(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	now := time.Now()
+	recipeColumns := []string{"id", "title", "servings", "instructions", "tips", "status", "user_id", "created_at", "updated_at", "prep_time_minutes", "cook_time_minutes", "difficulty", "source_name", "source_url", "last_modified_by", "visibility", "featured", "featured_at"}
+	token := strings.Repeat("ab", 32)
+
+	get := func(path string, shares ...[]driver.Value) *httptest.ResponseRecorder {
+		database := openScriptedDatabase(t,
+			scriptedResult{match: "FROM share_tokens", columns: []string{"recipe_id"}, rows: shares},
+			scriptedResult{match: "FROM recipe_ingredients", columns: []string{"id", "recipe_id", "canonical_ingredient_id", "original_text", "quantity", "unit", "created_at", "updated_at", "canonical_name"}},
+			scriptedResult{match: "FROM recipes", columns: recipeColumns, rows: [][]driver.Value{{int64(5), "Secret Pie", nil, nil, nil, "review_required", int64(7), now, now, nil, nil, nil, nil, nil, nil, models.VisibilityPrivate, false, nil}}},
+		)
+		router := gin.New()
+		router.GET("/api/v1/shared/:token", handlers.NewRecipeHandler(database, nil).GetSharedRecipe)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", path, nil)
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := get("/api/v1/shared/"+token, []driver.Value{int64(5)})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), `"title":"Secret Pie"`, "Shared recipes are returned whatever their status or visibility")
+	var tokenQuery string
+	for _, query := range scriptedQueries() {
+		if strings.Contains(query, "FROM share_tokens") {
+			tokenQuery = query
+		}
+	}
+	assert.Contains(t, tokenQuery, "revoked_at IS NULL")
+	assert.Contains(t, tokenQuery, "expires_at > NOW()")
+
+	// A revoked or expired link matches no row
+	assert.Equal(t, http.StatusNotFound, get("/api/v1/shared/"+token).Code)
+
+	for _, malformed := range []string{"abc", strings.Repeat("zz", 32), token + "00"} {
+		w = get("/api/v1/shared/"+malformed, []driver.Value{int64(5)})
+		assert.Equal(t, http.StatusNotFound, w.Code, malformed)
+		assert.Empty(t, scriptedQueries(), "Malformed tokens are refused without a query")
+	}
+}
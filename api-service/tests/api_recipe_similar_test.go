@@ -0,0 +1,120 @@
+package tests
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"digital-recipes/api-service/handlers"
+	"digital-recipes/api-service/models"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// decodeSimilarRecipes extracts the similar recipes and pagination from a standard response
+func decodeSimilarRecipes(t *testing.T, body []byte) ([]models.SimilarRecipe, *handlers.Pagination) {
+	var response handlers.StandardResponse
+	require.NoError(t, json.Unmarshal(body, &response))
+	dataBytes, _ := json.Marshal(response.Data)
+	var recipes []models.SimilarRecipe
+	require.NoError(t, json.Unmarshal(dataBytes, &recipes))
+	return recipes, response.Pagination
+}
+
+// TestGetSimilarRecipes tests recipes are ranked by shared canonical ingredients
+func (suite *RecipeAPITestSuite) TestGetSimilarRecipes() {
+	flour := suite.createCanonicalIngredient("flour", true)
+	sugar := suite.createCanonicalIngredient("sugar", true)
+	egg := suite.createCanonicalIngredient("egg", true)
+	butter := suite.createCanonicalIngredient("butter", true)
+
+	target := suite.createTestRecipe("Pound Cake", "published")
+	for _, id := range []int{flour, sugar, egg, butter} {
+		canonicalID := id
+		suite.createTestIngredient(target, "ingredient", &canonicalID)
+	}
+	suite.createTestIngredient(target, "a pinch of love", nil)
+
+	link := func(recipeID int, ids ...int) {
+		for _, id := range ids {
+			canonicalID := id
+			suite.createTestIngredient(recipeID, "ingredient", &canonicalID)
+		}
+	}
+
+	cookies := suite.createTestRecipe("Cookies", "published")
+	link(cookies, flour, sugar, butter)
+	pancakes := suite.createTestRecipe("Pancakes", "published")
+	link(pancakes, flour, egg)
+	omelette := suite.createTestRecipe("Omelette", "published")
+	link(omelette, egg, egg)
+	draft := suite.createTestRecipe("Draft Cake", "review_required")
+	link(draft, flour, sugar, egg, butter)
+	suite.createTestRecipe("Salad", "published")
+
+	w := suite.performGet(fmt.Sprintf("/api/v1/recipes/%d/similar", target), "")
+	require.Equal(suite.T(), http.StatusOK, w.Code, w.Body.String())
+	recipes, pagination := decodeSimilarRecipes(suite.T(), w.Body.Bytes())
+
+	require.Len(suite.T(), recipes, 3, "Drafts, the target and recipes sharing nothing are excluded")
+	assert.Equal(suite.T(), []int{cookies, pancakes, omelette}, []int{recipes[0].ID, recipes[1].ID, recipes[2].ID})
+	assert.Equal(suite.T(), []int{3, 2, 1}, []int{recipes[0].SharedIngredients, recipes[1].SharedIngredients, recipes[2].SharedIngredients})
+	require.NotNil(suite.T(), pagination)
+	assert.Equal(suite.T(), 3, pagination.Total)
+
+	w = suite.performGet(fmt.Sprintf("/api/v1/recipes/%d/similar?per_page=1&page=2", target), "")
+	require.Equal(suite.T(), http.StatusOK, w.Code, w.Body.String())
+	recipes, pagination = decodeSimilarRecipes(suite.T(), w.Body.Bytes())
+	require.Len(suite.T(), recipes, 1)
+	assert.Equal(suite.T(), pancakes, recipes[0].ID)
+	assert.Equal(suite.T(), 3, pagination.TotalPages)
+
+	w = suite.performGet(fmt.Sprintf("/api/v1/recipes/%d/similar", NonExistentID), "")
+	assert.Equal(suite.T(), http.StatusNotFound, w.Code)
+}
+
+// TestGetSimilarRecipesWithoutDatabase tests the ranked rows and total are passed through unchanged
+func TestGetSimilarRecipesWithoutDatabase(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	now := time.Now()
+	database := openScriptedDatabase(t,
+		scriptedResult{match: "SELECT user_id, visibility", columns: []string{"user_id", "visibility"}, rows: [][]driver.Value{{int64(1), "public"}}},
+		scriptedResult{
+			match:   "FROM recipe_ingredients target",
+			columns: []string{"id", "title", "servings", "instructions", "tips", "status", "user_id", "created_at", "updated_at", "prep_time_minutes", "cook_time_minutes", "difficulty", "source_name", "source_url", "last_modified_by", "visibility", "featured", "featured_at", "shared_ingredients", "total_count"},
+			rows: [][]driver.Value{
+				{int64(9), "Cookies", nil, nil, nil, "published", int64(1), now, now, nil, nil, nil, nil, nil, nil, "public", false, nil, int64(3), int64(4)},
+				{int64(4), "Pancakes", nil, nil, nil, "published", int64(1), now, now, nil, nil, nil, nil, nil, nil, "public", false, nil, int64(2), int64(4)},
+			},
+		},
+	)
+	handler := handlers.NewRecipeHandler(database, nil)
+
+	router := gin.New()
+	router.GET("/api/v1/recipes/:id/similar", handler.GetSimilarRecipes)
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v1/recipes/5/similar?per_page=2", nil)
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	recipes, pagination := decodeSimilarRecipes(t, w.Body.Bytes())
+	require.Len(t, recipes, 2)
+	assert.Equal(t, 9, recipes[0].ID)
+	assert.Equal(t, 3, recipes[0].SharedIngredients)
+	assert.Equal(t, 4, recipes[1].ID)
+	assert.Equal(t, 4, pagination.Total)
+	assert.Equal(t, 2, pagination.TotalPages)
+	require.Len(t, scriptedQueries(), 2)
+	assert.Contains(t, scriptedQueries()[1], "r.id, r.title, r.servings", "Recipe columns come from the shared list, qualified by the join alias")
+	assert.Contains(t, scriptedQueries()[1], "r.featured, r.featured_at,")
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/v1/recipes/abc/similar", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
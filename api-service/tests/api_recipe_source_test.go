@@ -0,0 +1,71 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"digital-recipes/api-service/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestUpdateRecipeSource tests source name and URL round-trip through PUT and PATCH and clear when blank
+func (suite *RecipeAPITestSuite) TestUpdateRecipeSource() {
+	recipeID := suite.createTestRecipe("Banana Bread", "review_required")
+	auth := suite.authHeader(suite.testUserID, middleware.RoleUser)
+	path := fmt.Sprintf("/api/v1/recipes/%d", recipeID)
+
+	w := suite.performJSON("PUT", path, `{"title": "Banana Bread", "source_name": "Grandma's Cookbook", "source_url": "https://example.com/banana-bread"}`, auth)
+	require.Equal(suite.T(), http.StatusOK, w.Code, w.Body.String())
+
+	w = suite.performGet(path, "")
+	require.Equal(suite.T(), http.StatusOK, w.Code)
+	assert.Contains(suite.T(), w.Body.String(), `"source_name":"Grandma's Cookbook"`)
+	assert.Contains(suite.T(), w.Body.String(), `"source_url":"https://example.com/banana-bread"`)
+
+	// Patching one field keeps the other, and a blank value clears it
+	w = suite.performJSON("PATCH", path, `{"source_name": "  "}`, auth)
+	require.Equal(suite.T(), http.StatusOK, w.Code, w.Body.String())
+	assert.NotContains(suite.T(), w.Body.String(), `"source_name"`)
+	assert.Contains(suite.T(), w.Body.String(), `"source_url":"https://example.com/banana-bread"`)
+
+	// A full update without them clears them
+	w = suite.performJSON("PUT", path, `{"title": "Banana Bread"}`, auth)
+	require.Equal(suite.T(), http.StatusOK, w.Code, w.Body.String())
+	assert.NotContains(suite.T(), w.Body.String(), `"source_url"`)
+
+	for _, body := range []string{
+		`{"title": "Banana Bread", "source_url": "example.com/banana-bread"}`,
+		`{"title": "Banana Bread", "source_url": "javascript:alert(1)"}`,
+		`{"title": "Banana Bread", "source_name": "` + strings.Repeat("a", 256) + `"}`,
+	} {
+		w = suite.performJSON("PUT", path, body, auth)
+		assert.Equal(suite.T(), http.StatusUnprocessableEntity, w.Code, body)
+	}
+
+	w = suite.performJSON("PATCH", path, `{"source_url": "ftp://example.com/bread"}`, auth)
+	require.Equal(suite.T(), http.StatusUnprocessableEntity, w.Code)
+	var response map[string]interface{}
+	require.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(suite.T(), "source_url", response["field"])
+}
+
+// TestGetRecipeJSONLDCreditsSource tests exported recipes credit their source and link to it
+func (suite *RecipeAPITestSuite) TestGetRecipeJSONLDCreditsSource() {
+	recipeID := suite.createTestRecipe("Banana Bread", "published")
+	_, err := suite.db.DB.Exec(`UPDATE recipes SET source_name = $1, source_url = $2 WHERE id = $3`,
+		"Jane Baker", "https://example.com/banana-bread", recipeID)
+	require.NoError(suite.T(), err)
+
+	w := suite.performGet(fmt.Sprintf("/api/v1/recipes/%d/jsonld", recipeID), "")
+	require.Equal(suite.T(), http.StatusOK, w.Code, w.Body.String())
+
+	var document map[string]interface{}
+	require.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &document))
+	assert.Equal(suite.T(), "https://example.com/banana-bread", document["url"])
+	author, ok := document["author"].(map[string]interface{})
+	require.True(suite.T(), ok, "Author should be included")
+	assert.Equal(suite.T(), "Jane Baker", author["name"], "The source is credited instead of the user who added it")
+}
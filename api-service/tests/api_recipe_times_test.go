@@ -0,0 +1,125 @@
+package tests
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"digital-recipes/api-service/handlers"
+	"digital-recipes/api-service/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setRecipeTimes sets a recipe's prep and cook times and difficulty; nil leaves a column NULL
+func (suite *RecipeAPITestSuite) setRecipeTimes(recipeID int, prep, cook *int, difficulty *string) {
+	_, err := suite.db.DB.Exec(`
+		UPDATE recipes SET prep_time_minutes = $1, cook_time_minutes = $2, difficulty = $3 WHERE id = $4
+	`, prep, cook, difficulty, recipeID)
+	require.NoError(suite.T(), err, "Failed to set test recipe times")
+}
+
+// TestUpdateRecipeTimesAndDifficulty tests times and difficulty round-trip through PUT and PATCH
+func (suite *RecipeAPITestSuite) TestUpdateRecipeTimesAndDifficulty() {
+	recipeID := suite.createTestRecipe("Stew", "review_required")
+	auth := suite.authHeader(suite.testUserID, middleware.RoleUser)
+	path := fmt.Sprintf("/api/v1/recipes/%d", recipeID)
+
+	w := suite.performJSON("PUT", path, `{"title": "Stew", "prep_time_minutes": 20, "cook_time_minutes": 95, "difficulty": "medium"}`, auth)
+	require.Equal(suite.T(), http.StatusOK, w.Code, w.Body.String())
+
+	w = suite.performGet(path, "")
+	require.Equal(suite.T(), http.StatusOK, w.Code)
+	assert.Contains(suite.T(), w.Body.String(), `"prep_time_minutes":20`)
+	assert.Contains(suite.T(), w.Body.String(), `"cook_time_minutes":95`)
+	assert.Contains(suite.T(), w.Body.String(), `"difficulty":"medium"`)
+
+	// Patching one field keeps the others
+	w = suite.performJSON("PATCH", path, `{"cook_time_minutes": 60}`, auth)
+	require.Equal(suite.T(), http.StatusOK, w.Code, w.Body.String())
+	assert.Contains(suite.T(), w.Body.String(), `"prep_time_minutes":20`)
+	assert.Contains(suite.T(), w.Body.String(), `"cook_time_minutes":60`)
+	assert.Contains(suite.T(), w.Body.String(), `"difficulty":"medium"`)
+
+	// A full update without them clears them
+	w = suite.performJSON("PUT", path, `{"title": "Stew"}`, auth)
+	require.Equal(suite.T(), http.StatusOK, w.Code, w.Body.String())
+	assert.NotContains(suite.T(), w.Body.String(), `"prep_time_minutes"`)
+	assert.NotContains(suite.T(), w.Body.String(), `"difficulty"`)
+
+	for _, body := range []string{
+		`{"title": "Stew", "difficulty": "expert"}`,
+		`{"title": "Stew", "prep_time_minutes": -5}`,
+		`{"title": "Stew", "cook_time_minutes": 20000}`,
+	} {
+		w = suite.performJSON("PUT", path, body, auth)
+		assert.Equal(suite.T(), http.StatusUnprocessableEntity, w.Code, body)
+	}
+	for _, body := range []string{`{"difficulty": "Easy"}`, `{"difficulty": ""}`} {
+		w = suite.performJSON("PATCH", path, body, auth)
+		assert.Equal(suite.T(), http.StatusUnprocessableEntity, w.Code, body)
+	}
+}
+
+// TestGetRecipesTimeAndDifficultyFilters tests max_total_time and difficulty narrow the list and count
+func (suite *RecipeAPITestSuite) TestGetRecipesTimeAndDifficultyFilters() {
+	minutes := func(n int) *int { return &n }
+	level := func(d string) *string { return &d }
+
+	quick := suite.createTestRecipe("Quick Salad", "published")
+	suite.setRecipeTimes(quick, minutes(10), nil, level("easy"))
+	weeknight := suite.createTestRecipe("Weeknight Pasta", "published")
+	suite.setRecipeTimes(weeknight, minutes(10), minutes(20), level("easy"))
+	roast := suite.createTestRecipe("Sunday Roast", "published")
+	suite.setRecipeTimes(roast, minutes(30), minutes(120), level("hard"))
+	unknown := suite.createTestRecipe("Family Secret", "published")
+	suite.setRecipeTimes(unknown, nil, nil, nil)
+
+	listed := func(query string) []int {
+		w := suite.performGet("/api/v1/recipes?"+query, "")
+		require.Equal(suite.T(), http.StatusOK, w.Code, w.Body.String())
+		var ids []int
+		for _, recipe := range decodeRecipeList(suite.T(), w.Body.Bytes()) {
+			ids = append(ids, recipe.ID)
+		}
+		return ids
+	}
+
+	assert.ElementsMatch(suite.T(), []int{quick, weeknight}, listed("max_total_time=30"), "Bounds are inclusive and missing times count as zero")
+	assert.ElementsMatch(suite.T(), []int{quick}, listed("max_total_time=29"))
+	assert.ElementsMatch(suite.T(), []int{roast}, listed("difficulty=hard"))
+	assert.ElementsMatch(suite.T(), []int{quick}, listed("difficulty=easy&max_total_time=15"))
+	assert.NotContains(suite.T(), listed("max_total_time=10000"), unknown, "Recipes without times never match a time filter")
+
+	w := suite.performGet("/api/v1/recipes?count_only=true&difficulty=easy", "")
+	require.Equal(suite.T(), http.StatusOK, w.Code)
+	assert.Equal(suite.T(), 2, suite.decodeStandardResponse(w).Pagination.Total)
+}
+
+// TestGetRecipesTimeAndDifficultyInvalid tests malformed filters are rejected before querying
+func TestGetRecipesTimeAndDifficultyInvalid(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/api/v1/recipes", handlers.NewRecipeHandler(nil, nil).GetRecipes)
+
+	tests := []struct {
+		query         string
+		expectedField string
+	}{
+		{"max_total_time=soon", "max_total_time"},
+		{"max_total_time=-1", "max_total_time"},
+		{"difficulty=expert", "difficulty"},
+		{"difficulty=EASY", "difficulty"},
+	}
+
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/v1/recipes?"+tt.query, nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, tt.query)
+		assert.Contains(t, w.Body.String(), `"field":"`+tt.expectedField+`"`, tt.query)
+	}
+}
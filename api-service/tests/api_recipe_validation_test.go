@@ -0,0 +1,170 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"digital-recipes/api-service/handlers"
+	"digital-recipes/api-service/middleware"
+	"digital-recipes/api-service/models"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// decodeFieldErrors unmarshals the errors listed by a 422 validation response
+func decodeFieldErrors(t *testing.T, body []byte) (string, models.FieldErrors) {
+	var response struct {
+		Code   string             `json:"code"`
+		Errors models.FieldErrors `json:"errors"`
+	}
+	require.NoError(t, json.Unmarshal(body, &response))
+	return response.Code, response.Errors
+}
+
+// TestUpdateRecipeReportsEveryInvalidField tests an update with several problems reports all of them and changes nothing
+func (suite *RecipeAPITestSuite) TestUpdateRecipeReportsEveryInvalidField() {
+	recipeID := suite.createTestRecipe("Stew", "review_required")
+	auth := suite.authHeader(suite.testUserID, middleware.RoleUser)
+
+	w := suite.performJSON("PUT", fmt.Sprintf("/api/v1/recipes/%d", recipeID),
+		`{"title": " ", "status": "archived", "ingredients": [{"original_text": "1 onion"}, {"original_text": "beef", "quantity": -1}]}`, auth)
+	require.Equal(suite.T(), http.StatusUnprocessableEntity, w.Code, w.Body.String())
+	code, errs := decodeFieldErrors(suite.T(), w.Body.Bytes())
+	assert.Equal(suite.T(), "VALIDATION_FAILED", code)
+	assert.Len(suite.T(), errs, 3)
+
+	var title string
+	require.NoError(suite.T(), suite.db.DB.QueryRow(`SELECT title FROM recipes WHERE id = $1`, recipeID).Scan(&title))
+	assert.Equal(suite.T(), "Stew", title)
+	assert.Zero(suite.T(), suite.countRevisions(recipeID))
+}
+
+// TestRecipeWriteValidationAggregatesErrors tests PUT and PATCH report binding and model failures together before touching the database
+func TestRecipeWriteValidationAggregatesErrors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	database := openScriptedDatabase(t)
+	handler := handlers.NewRecipeHandler(database, nil)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("user_id", 7)
+		c.Next()
+	})
+	router.PUT("/api/v1/recipes/:id", handler.UpdateRecipe)
+	router.PATCH("/api/v1/recipes/:id", handler.PatchRecipe)
+
+	send := func(method, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, "/api/v1/recipes/5", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		body   string
+		want   models.FieldErrors
+	}{
+		{
+			name:   "Missing title, bad status and a negative quantity",
+			method: "PUT",
+			body:   `{"status": "archived", "ingredients": [{"original_text": "1 egg"}, {"original_text": "flour", "quantity": -2}]}`,
+			want: models.FieldErrors{
+				{Field: "title", Message: "title is required"},
+				{Field: "status", Message: "status must be one of processing, review_required, published"},
+				{Field: "ingredients[1].quantity", Message: "ingredients[1].quantity cannot be negative"},
+			},
+		},
+		{
+			name:   "Binding rules and model checks together",
+			method: "PUT",
+			body:   `{"title": "   ", "cook_time_minutes": 20000, "ingredients": [{"original_text": " "}], "source_url": "example.com/stew"}`,
+			want: models.FieldErrors{
+				{Field: "cook_time_minutes", Message: "cook_time_minutes must be at most 10080"},
+				{Field: "title", Message: "title is required"},
+				{Field: "ingredients[0].original_text", Message: "original_text cannot be empty"},
+				{Field: "source_url", Message: "source_url must be an absolute http or https URL"},
+			},
+		},
+		{
+			name:   "Patch",
+			method: "PATCH",
+			body:   `{"title": "", "difficulty": "expert", "servings": "` + strings.Repeat("4", 51) + `"}`,
+			want: models.FieldErrors{
+				{Field: "servings", Message: "servings must be at most 50 characters"},
+				{Field: "difficulty", Message: "difficulty must be one of easy, medium, hard"},
+				{Field: "title", Message: "title cannot be empty"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := send(tt.method, tt.body)
+			require.Equal(t, http.StatusUnprocessableEntity, w.Code, w.Body.String())
+			code, errs := decodeFieldErrors(t, w.Body.Bytes())
+			assert.Equal(t, "VALIDATION_FAILED", code)
+			assert.Equal(t, tt.want, errs)
+		})
+	}
+	assert.Empty(t, scriptedQueries(), "Invalid writes are rejected before any query")
+
+	// A single failure keeps the message and field at the top level too
+	w := send("PATCH", `{"source_url": "ftp://example.com/stew"}`)
+	require.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "source_url", response["field"])
+	assert.Equal(t, "source_url must be an absolute http or https URL", response["error"])
+
+	// Malformed JSON cannot be checked field by field and stays a 400
+	assert.Equal(t, http.StatusBadRequest, send("PUT", `{"title": 5}`).Code)
+}
+
+// TestRecipeTitleTrimmingAndLimit tests titles are stored trimmed, blank titles are refused and the length limit is exact
+func (suite *RecipeAPITestSuite) TestRecipeTitleTrimmingAndLimit() {
+	recipeID := suite.createTestRecipe("Stew", "review_required")
+	auth := suite.authHeader(suite.testUserID, middleware.RoleUser)
+	path := fmt.Sprintf("/api/v1/recipes/%d", recipeID)
+	storedTitle := func() string {
+		var title string
+		require.NoError(suite.T(), suite.db.DB.QueryRow(`SELECT title FROM recipes WHERE id = $1`, recipeID).Scan(&title))
+		return title
+	}
+
+	w := suite.performJSON("PUT", path, `{"title": "  Beef Stew \t"}`, auth)
+	require.Equal(suite.T(), http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(suite.T(), "Beef Stew", storedTitle())
+
+	atLimit := strings.Repeat("a", models.MaxTitleLength)
+	w = suite.performJSON("PATCH", path, `{"title": " `+atLimit+` "}`, auth)
+	require.Equal(suite.T(), http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(suite.T(), atLimit, storedTitle())
+
+	for _, tt := range []struct {
+		method, body, message string
+	}{
+		{"PUT", `{"title": "` + atLimit + `a"}`, "title must be at most 200 characters"},
+		{"PATCH", `{"title": "` + atLimit + `a"}`, "title must be at most 200 characters"},
+		{"PUT", `{"title": "   "}`, "title is required"},
+		{"PATCH", `{"title": "   "}`, "title cannot be empty"},
+	} {
+		w = suite.performJSON(tt.method, path, tt.body, auth)
+		require.Equal(suite.T(), http.StatusUnprocessableEntity, w.Code, tt.method+" "+w.Body.String())
+		_, errs := decodeFieldErrors(suite.T(), w.Body.Bytes())
+		assert.Equal(suite.T(), models.FieldErrors{{Field: "title", Message: tt.message}}, errs)
+	}
+	assert.Equal(suite.T(), atLimit, storedTitle(), "Rejected titles change nothing")
+
+	// The database refuses titles the handlers would have rejected
+	_, err := suite.db.DB.Exec(`UPDATE recipes SET title = $1 WHERE id = $2`, atLimit+"a", recipeID)
+	assert.Error(suite.T(), err)
+	_, err = suite.db.DB.Exec(`UPDATE recipes SET title = '  ' WHERE id = $1`, recipeID)
+	assert.Error(suite.T(), err)
+}
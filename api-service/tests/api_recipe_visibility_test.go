@@ -0,0 +1,149 @@
+package tests
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"digital-recipes/api-service/handlers"
+	"digital-recipes/api-service/middleware"
+	"digital-recipes/api-service/models"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// createRecipeWithVisibility creates a published recipe owned by the test user with the given visibility
+func (suite *RecipeAPITestSuite) createRecipeWithVisibility(title, visibility string) int {
+	recipeID := suite.createTestRecipe(title, "published")
+	_, err := suite.db.DB.Exec(`UPDATE recipes SET visibility = $1 WHERE id = $2`, visibility, recipeID)
+	require.NoError(suite.T(), err)
+	return recipeID
+}
+
+// listedRecipeTitles returns the titles GET /recipes lists for a caller
+func (suite *RecipeAPITestSuite) listedRecipeTitles(authorization string) []string {
+	w := suite.performGet("/api/v1/recipes", authorization)
+	require.Equal(suite.T(), http.StatusOK, w.Code, w.Body.String())
+	var response handlers.StandardResponse
+	require.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &response))
+	dataBytes, _ := json.Marshal(response.Data)
+	var recipes []models.RecipeListItem
+	require.NoError(suite.T(), json.Unmarshal(dataBytes, &recipes))
+
+	titles := make([]string, 0, len(recipes))
+	for _, recipe := range recipes {
+		titles = append(titles, recipe.Title)
+	}
+	return titles
+}
+
+// TestRecipeVisibility tests what owners and everyone else can list and open at each visibility level
+func (suite *RecipeAPITestSuite) TestRecipeVisibility() {
+	recipeIDs := map[string]int{
+		models.VisibilityPublic:   suite.createRecipeWithVisibility("Public Pie", models.VisibilityPublic),
+		models.VisibilityUnlisted: suite.createRecipeWithVisibility("Unlisted Pie", models.VisibilityUnlisted),
+		models.VisibilityPrivate:  suite.createRecipeWithVisibility("Private Pie", models.VisibilityPrivate),
+	}
+	otherUserID := suite.createTestUser("other@example.com")
+
+	callers := []struct {
+		name          string
+		authorization string
+		listed        []string
+		openable      []string
+	}{
+		{"Anonymous", "", []string{"Public Pie"}, []string{models.VisibilityPublic, models.VisibilityUnlisted}},
+		{"Other user", suite.authHeader(otherUserID, middleware.RoleUser), []string{"Public Pie"}, []string{models.VisibilityPublic, models.VisibilityUnlisted}},
+		{"Owner", suite.authHeader(suite.testUserID, middleware.RoleUser), []string{"Public Pie", "Unlisted Pie", "Private Pie"}, []string{models.VisibilityPublic, models.VisibilityUnlisted, models.VisibilityPrivate}},
+		{"Admin", suite.authHeader(otherUserID, middleware.RoleAdmin), []string{"Public Pie", "Unlisted Pie", "Private Pie"}, []string{models.VisibilityPublic, models.VisibilityUnlisted, models.VisibilityPrivate}},
+	}
+
+	for _, caller := range callers {
+		assert.ElementsMatch(suite.T(), caller.listed, suite.listedRecipeTitles(caller.authorization), caller.name)
+
+		for visibility, recipeID := range recipeIDs {
+			want := http.StatusNotFound
+			for _, openable := range caller.openable {
+				if openable == visibility {
+					want = http.StatusOK
+				}
+			}
+			for _, path := range []string{"/api/v1/recipes/%d", "/api/v1/recipes/%d/ingredients", "/api/v1/recipes/%d/jsonld"} {
+				w := suite.performGet(fmt.Sprintf(path, recipeID), caller.authorization)
+				assert.Equal(suite.T(), want, w.Code, "%s opening %s %s", caller.name, visibility, path)
+			}
+		}
+	}
+}
+
+// TestUpdateRecipeVisibility tests owners change visibility through PATCH and PUT, and a PUT without it keeps the current one
+func (suite *RecipeAPITestSuite) TestUpdateRecipeVisibility() {
+	recipeID := suite.createTestRecipe("Pie", "published")
+	auth := suite.authHeader(suite.testUserID, middleware.RoleUser)
+	path := fmt.Sprintf("/api/v1/recipes/%d", recipeID)
+
+	w := suite.performGet(path, "")
+	require.Equal(suite.T(), http.StatusOK, w.Code)
+	assert.Equal(suite.T(), models.VisibilityPublic, suite.decodeRecipe(w.Body.Bytes()).Visibility, "Recipes are public by default")
+
+	w = suite.performJSON("PATCH", path, `{"visibility": "private"}`, auth)
+	require.Equal(suite.T(), http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(suite.T(), models.VisibilityPrivate, suite.decodeRecipe(w.Body.Bytes()).Visibility)
+	assert.Equal(suite.T(), "published", suite.decodeRecipe(w.Body.Bytes()).Status, "Visibility does not change the status")
+
+	w = suite.performJSON("PUT", path, `{"title": "Apple Pie"}`, auth)
+	require.Equal(suite.T(), http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(suite.T(), models.VisibilityPrivate, suite.decodeRecipe(w.Body.Bytes()).Visibility)
+
+	w = suite.performJSON("PATCH", path, `{"visibility": "friends"}`, auth)
+	require.Equal(suite.T(), http.StatusUnprocessableEntity, w.Code)
+	_, errs := decodeFieldErrors(suite.T(), w.Body.Bytes())
+	assert.Equal(suite.T(), models.FieldErrors{{Field: "visibility", Message: "visibility must be one of public, unlisted, private"}}, errs)
+}
+
+// TestGetPrivateRecipeWithoutDatabase tests a private recipe is only returned to its owner or an admin
+func TestGetPrivateRecipeWithoutDatabase(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	now := time.Now()
+	recipeColumns := []string{"id", "title", "servings", "instructions", "tips", "status", "user_id", "created_at", "updated_at", "prep_time_minutes", "cook_time_minutes", "difficulty", "source_name", "source_url", "last_modified_by", "visibility", "featured", "featured_at"}
+	database := openScriptedDatabase(t,
+		scriptedResult{match: "FROM recipe_ingredients", columns: []string{"id", "recipe_id", "canonical_ingredient_id", "original_text", "quantity", "unit", "created_at", "updated_at", "canonical_name"}},
+		scriptedResult{match: "FROM recipes", columns: recipeColumns, rows: [][]driver.Value{{int64(5), "Secret Pie", nil, nil, nil, "published", int64(7), now, now, nil, nil, nil, nil, nil, nil, models.VisibilityPrivate, false, nil}}},
+	)
+	handler := handlers.NewRecipeHandler(database, nil)
+
+	tests := []struct {
+		name   string
+		userID int
+		role   string
+		want   int
+	}{
+		{"Anonymous", 0, "", http.StatusNotFound},
+		{"Other user", 8, middleware.RoleUser, http.StatusNotFound},
+		{"Owner", 7, middleware.RoleUser, http.StatusOK},
+		{"Admin", 8, middleware.RoleAdmin, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.GET("/api/v1/recipes/:id", func(c *gin.Context) {
+				if tt.userID != 0 {
+					c.Set("user_id", tt.userID)
+					c.Set("user_role", tt.role)
+				}
+				c.Next()
+			}, handler.GetRecipe)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/api/v1/recipes/5", nil)
+			router.ServeHTTP(w, req)
+			assert.Equal(t, tt.want, w.Code, w.Body.String())
+		})
+	}
+}
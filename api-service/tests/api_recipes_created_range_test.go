@@ -0,0 +1,76 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"digital-recipes/api-service/handlers"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setRecipeCreatedAt backdates a recipe's creation time
+func (suite *RecipeAPITestSuite) setRecipeCreatedAt(recipeID int, createdAt time.Time) {
+	_, err := suite.db.DB.Exec(`UPDATE recipes SET created_at = $1 WHERE id = $2`, createdAt, recipeID)
+	require.NoError(suite.T(), err, "Failed to backdate test recipe")
+}
+
+// decodeStandardResponse unmarshals a standard response envelope
+func (suite *RecipeAPITestSuite) decodeStandardResponse(w *httptest.ResponseRecorder) handlers.StandardResponse {
+	var response handlers.StandardResponse
+	require.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &response))
+	return response
+}
+
+// TestGetRecipesCreatedRange tests only recipes created within the range are listed and counted
+func (suite *RecipeAPITestSuite) TestGetRecipesCreatedRange() {
+	before := suite.createTestRecipe("Before Week", "published")
+	suite.setRecipeCreatedAt(before, time.Date(2024, 2, 25, 12, 0, 0, 0, time.UTC))
+	inside := suite.createTestRecipe("During Week", "published")
+	suite.setRecipeCreatedAt(inside, time.Date(2024, 3, 4, 9, 30, 0, 0, time.UTC))
+	boundary := suite.createTestRecipe("Week Start", "published")
+	suite.setRecipeCreatedAt(boundary, time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC))
+	after := suite.createTestRecipe("After Week", "published")
+	suite.setRecipeCreatedAt(after, time.Date(2024, 3, 12, 8, 0, 0, 0, time.UTC))
+
+	w := suite.performGet("/api/v1/recipes?created_after=2024-03-04T00:00:00Z&created_before=2024-03-10T23:59:59Z", "")
+	require.Equal(suite.T(), http.StatusOK, w.Code, w.Body.String())
+
+	var ids []int
+	for _, recipe := range decodeRecipeList(suite.T(), w.Body.Bytes()) {
+		ids = append(ids, recipe.ID)
+	}
+	assert.ElementsMatch(suite.T(), []int{inside, boundary}, ids, "Bounds are inclusive")
+
+	response := suite.decodeStandardResponse(w)
+	require.NotNil(suite.T(), response.Pagination)
+	assert.Equal(suite.T(), 2, response.Pagination.Total, "The window total respects the range")
+
+	w = suite.performGet("/api/v1/recipes?count_only=true&created_after=2024-03-04T00:00:00Z", "")
+	require.Equal(suite.T(), http.StatusOK, w.Code)
+	assert.Equal(suite.T(), 3, suite.decodeStandardResponse(w).Pagination.Total)
+}
+
+// TestGetRecipesCreatedRangeInvalid tests malformed or inverted dates are rejected before querying
+func TestGetRecipesCreatedRangeInvalid(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/api/v1/recipes", handlers.NewRecipeHandler(nil, nil).GetRecipes)
+
+	for _, query := range []string{
+		"created_after=last-week",
+		"created_before=2024-03-10",
+		"created_after=2024-03-10T00:00:00Z&created_before=2024-03-04T00:00:00Z",
+	} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/v1/recipes?"+query, nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+		assert.Contains(t, w.Body.String(), `"field":"created_`, query)
+	}
+}
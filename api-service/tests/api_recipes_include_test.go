@@ -0,0 +1,155 @@
+package tests
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"digital-recipes/api-service/handlers"
+	"digital-recipes/api-service/models"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// decodeRecipeList unmarshals the data array of a recipe list response
+func decodeRecipeList(t *testing.T, body []byte) []models.RecipeListItem {
+	var response handlers.StandardResponse
+	require.NoError(t, json.Unmarshal(body, &response))
+	dataBytes, _ := json.Marshal(response.Data)
+	var recipes []models.RecipeListItem
+	require.NoError(t, json.Unmarshal(dataBytes, &recipes))
+	return recipes
+}
+
+// TestGetRecipesIncludeIngredientsBatch tests ingredients are attached with exactly one extra query
+func TestGetRecipesIncludeIngredientsBatch(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	now := time.Now()
+	recipeColumns := []string{"id", "title", "servings", "instructions", "tips", "status", "user_id", "created_at", "updated_at", "prep_time_minutes", "cook_time_minutes", "difficulty", "source_name", "source_url", "last_modified_by", "visibility", "featured", "featured_at", "ingredient_count", "has_images", "total_count"}
+	ingredientColumns := []string{"id", "recipe_id", "canonical_ingredient_id", "original_text", "quantity", "unit", "created_at", "updated_at", "canonical_name"}
+
+	database := openScriptedDatabase(t,
+		scriptedResult{
+			match:   "WHERE ri.recipe_id = ANY($1)",
+			columns: ingredientColumns,
+			rows: [][]driver.Value{
+				{int64(11), int64(1), nil, "2 cups flour", 2.0, "cup", now, now, nil},
+				{int64(12), int64(1), int64(5), "1 egg", 1.0, nil, now, now, "egg"},
+				{int64(13), int64(3), nil, "salt", nil, nil, now, now, nil},
+			},
+		},
+		scriptedResult{
+			match:   "FROM recipes",
+			columns: recipeColumns,
+			rows: [][]driver.Value{
+				{int64(1), "Bread", nil, nil, nil, "published", int64(1), now, now, nil, nil, nil, nil, nil, nil, "public", false, nil, int64(2), true, int64(3)},
+				{int64(2), "Water", nil, nil, nil, "published", int64(1), now, now, nil, nil, nil, nil, nil, nil, "public", false, nil, int64(0), false, int64(3)},
+				{int64(3), "Salted Water", nil, nil, nil, "published", int64(1), now, now, nil, nil, nil, nil, nil, nil, "public", false, nil, int64(1), false, int64(3)},
+			},
+		},
+	)
+
+	router := gin.New()
+	router.GET("/api/v1/recipes", handlers.NewRecipeHandler(database, nil).GetRecipes)
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", path, nil)
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("Default responses stay lean", func(t *testing.T) {
+		before := len(scriptedQueries())
+		w := get("/api/v1/recipes")
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		assert.Len(t, scriptedQueries()[before:], 1)
+		assert.NotContains(t, w.Body.String(), `"ingredients"`)
+	})
+
+	t.Run("Ingredients are attached with one extra query", func(t *testing.T) {
+		before := len(scriptedQueries())
+		w := get("/api/v1/recipes?include=ingredients")
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		queries := scriptedQueries()[before:]
+		require.Len(t, queries, 2, "One recipe query and one ingredient query regardless of page size")
+		assert.Contains(t, queries[1], "ANY($1)")
+
+		recipes := decodeRecipeList(t, w.Body.Bytes())
+		require.Len(t, recipes, 3)
+		require.Len(t, recipes[0].Ingredients, 2)
+		assert.Equal(t, "2 cups flour", recipes[0].Ingredients[0].OriginalText)
+		require.NotNil(t, recipes[0].Ingredients[1].CanonicalName)
+		assert.Equal(t, "egg", *recipes[0].Ingredients[1].CanonicalName)
+		assert.Empty(t, recipes[1].Ingredients)
+		require.Len(t, recipes[2].Ingredients, 1)
+		assert.Equal(t, "salt", recipes[2].Ingredients[0].OriginalText)
+		assert.Equal(t, 2, recipes[0].IngredientCount)
+		assert.True(t, recipes[0].HasImages)
+		assert.False(t, recipes[1].HasImages)
+	})
+
+	t.Run("Unknown include values are rejected", func(t *testing.T) {
+		before := len(scriptedQueries())
+		w := get("/api/v1/recipes?include=ingredients,steps")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Empty(t, scriptedQueries()[before:])
+	})
+}
+
+// TestGetRecipesIncludeIngredients tests ingredients are listed only when requested
+func (suite *RecipeAPITestSuite) TestGetRecipesIncludeIngredients() {
+	firstID := suite.createTestRecipe("With Ingredients", "published")
+	suite.createTestIngredient(firstID, "1 cup rice", nil)
+	suite.createTestIngredient(firstID, "2 cups water", nil)
+	secondID := suite.createTestRecipe("Without Ingredients", "published")
+
+	w := suite.performGet("/api/v1/recipes", "")
+	require.Equal(suite.T(), http.StatusOK, w.Code)
+	assert.False(suite.T(), strings.Contains(w.Body.String(), `"ingredients"`), "Lean response by default")
+
+	w = suite.performGet("/api/v1/recipes?include=ingredients", "")
+	require.Equal(suite.T(), http.StatusOK, w.Code)
+
+	counts := map[int]int{}
+	for _, recipe := range decodeRecipeList(suite.T(), w.Body.Bytes()) {
+		counts[recipe.ID] = len(recipe.Ingredients)
+	}
+	assert.Equal(suite.T(), map[int]int{firstID: 2, secondID: 0}, counts)
+
+	w = suite.performGet("/api/v1/recipes?include=ingredients&status=published&per_page=1", "")
+	require.Equal(suite.T(), http.StatusOK, w.Code)
+	assert.Len(suite.T(), decodeRecipeList(suite.T(), w.Body.Bytes()), 1)
+}
+
+// TestGetRecipesCompleteness tests ingredient_count and has_images match the stored data
+func (suite *RecipeAPITestSuite) TestGetRecipesCompleteness() {
+	completeID := suite.createTestRecipe("Complete", "published")
+	suite.createTestIngredient(completeID, "1 cup rice", nil)
+	suite.createTestIngredient(completeID, "2 cups water", nil)
+	suite.createTestIngredient(completeID, "salt", nil)
+	_, err := suite.db.DB.Exec(`INSERT INTO recipe_images (recipe_id, object_name) VALUES ($1, $2), ($1, $3)`,
+		completeID, "recipes/complete/0.jpg", "recipes/complete/1.jpg")
+	require.NoError(suite.T(), err)
+	bareID := suite.createTestRecipe("Bare", "published")
+
+	w := suite.performGet("/api/v1/recipes", "")
+	require.Equal(suite.T(), http.StatusOK, w.Code)
+
+	byID := map[int]models.RecipeListItem{}
+	for _, recipe := range decodeRecipeList(suite.T(), w.Body.Bytes()) {
+		byID[recipe.ID] = recipe
+	}
+	require.Len(suite.T(), byID, 2)
+	assert.Equal(suite.T(), 3, byID[completeID].IngredientCount)
+	assert.True(suite.T(), byID[completeID].HasImages)
+	assert.Equal(suite.T(), 0, byID[bareID].IngredientCount)
+	assert.False(suite.T(), byID[bareID].HasImages)
+	assert.Contains(suite.T(), w.Body.String(), `"has_images":false`, "Flags are always present")
+}
@@ -1,18 +1,22 @@
 package tests
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
 	"digital-recipes/api-service/db"
 	"digital-recipes/api-service/handlers"
+	"digital-recipes/api-service/middleware"
 	"digital-recipes/api-service/models"
+	"digital-recipes/api-service/webhooks"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -24,9 +28,25 @@ type RecipeAPITestSuite struct {
 	suite.Suite
 	db     *db.Database
 	router *gin.Engine
+	authConfig *middleware.AuthConfig
+	webhooks   *webhooks.Dispatcher
+	statusBroker *handlers.StatusBroker
+	storage    *memoryBucket
 	testUserID int
 }
 
+// testJWTSecret signs tokens for authenticated test requests
+const testJWTSecret = "test-secret-that-is-at-least-32-characters-long"
+
+// newTestAuthConfig creates an auth configuration for tests without reading the environment
+func newTestAuthConfig() *middleware.AuthConfig {
+	return &middleware.AuthConfig{
+		JWTSecret:     testJWTSecret,
+		TokenDuration: time.Hour,
+		Issuer:        "digital-recipes-test",
+	}
+}
+
 // SetupSuite runs before all tests in the suite
 func (suite *RecipeAPITestSuite) SetupSuite() {
 	// Set up test database connection
@@ -50,15 +70,134 @@ func (suite *RecipeAPITestSuite) SetupSuite() {
 
 	// Set up the router with handlers
 	suite.router = gin.New()
-	// Storage service not needed for recipe GET tests
-	recipeHandler := handlers.NewRecipeHandler(suite.db, nil)
-	
+	// Image confirmation and account deletion use stored objects in an in-memory bucket
+	suite.storage = newMemoryBucket()
+	storageService := handlers.NewStorageServiceWithBackend("test-bucket", suite.storage)
+	recipeHandler := handlers.NewRecipeHandler(suite.db, storageService)
+	ingredientHandler := handlers.NewIngredientHandler(suite.db)
+	webhookHandler := handlers.NewWebhookHandler(suite.db)
+	userHandler := handlers.NewUserHandler(suite.db, storageService)
+	// Tests seed different data, so figures are never reused between them
+	statsHandler := handlers.NewStatsHandler(suite.db)
+	statsHandler.SetCacheTTL(0)
+
+	// Webhook deliveries retry quickly so tests can wait for them
+	suite.webhooks = webhooks.NewDispatcher(suite.db)
+	suite.webhooks.MaxAttempts = 2
+	suite.webhooks.InitialBackoff = 10 * time.Millisecond
+	recipeHandler.SetWebhookDispatcher(suite.webhooks)
+	suite.statusBroker = recipeHandler.StatusBroker()
+	
+	suite.authConfig = newTestAuthConfig()
+
 	// Register routes
 	v1 := suite.router.Group("/api/v1")
+	v1.Use(middleware.IdentifyUserMiddleware(suite.authConfig))
 	{
 		v1.GET("/recipes", recipeHandler.GetRecipes)
+		v1.GET("/recipes/featured", recipeHandler.GetFeaturedRecipes)
 		v1.GET("/recipes/:id", recipeHandler.GetRecipe)
+		v1.GET("/recipes/:id/ingredients", recipeHandler.GetRecipeIngredients)
+		v1.GET("/recipes/:id/images", recipeHandler.GetRecipeImages)
+		v1.GET("/recipes/:id/similar", recipeHandler.GetSimilarRecipes)
+		v1.GET("/recipes/:id/jsonld", recipeHandler.GetRecipeJSONLD)
+		v1.GET("/recipes/:id/export", recipeHandler.ExportRecipe)
+		v1.POST("/shopping-list", recipeHandler.CreateShoppingList)
+		v1.GET("/shared/:token", recipeHandler.GetSharedRecipe)
+	}
+
+	// Register authenticated routes
+	protected := suite.router.Group("/api/v1")
+	protected.Use(middleware.AuthMiddleware(suite.authConfig))
+	{
+		protected.POST("/recipes/import", recipeHandler.ImportRecipe)
+		protected.POST("/recipes/:id/duplicate", recipeHandler.DuplicateRecipe)
+		protected.PUT("/recipes/:id", recipeHandler.UpdateRecipe)
+		protected.PATCH("/recipes/:id", recipeHandler.PatchRecipe)
+		protected.GET("/recipes/:id/revisions", recipeHandler.GetRecipeRevisions)
+		protected.POST("/recipes/:id/revisions/:revisionId/restore", recipeHandler.RestoreRecipeRevision)
+		protected.POST("/recipes/batch/status", recipeHandler.BatchUpdateRecipeStatus)
+		protected.PUT("/recipes/:id/draft", recipeHandler.SaveRecipeDraft)
+		protected.GET("/recipes/:id/draft", recipeHandler.GetRecipeDraft)
+		protected.POST("/recipes/:id/draft/promote", recipeHandler.PromoteRecipeDraft)
+		protected.POST("/recipes/:id/share", recipeHandler.CreateRecipeShare)
+		protected.GET("/recipes/:id/share", recipeHandler.GetRecipeShares)
+		protected.DELETE("/recipes/:id/share/:shareId", recipeHandler.RevokeRecipeShare)
+		protected.GET("/recipes/:id/status/stream", recipeHandler.StreamRecipeStatus)
+		protected.POST("/recipes/:id/ingredients", recipeHandler.AddRecipeIngredient)
+		protected.PUT("/recipes/:id/ingredients", recipeHandler.ReplaceRecipeIngredients)
+		protected.PATCH("/recipes/:id/ingredients/:ingredientId", recipeHandler.LinkRecipeIngredient)
+		protected.POST("/ingredients/link-or-create", ingredientHandler.LinkOrCreate)
+		protected.GET("/ingredients", middleware.RequireRole(middleware.RoleAdmin), ingredientHandler.GetIngredients)
+		protected.POST("/ingredients", middleware.RequireRole(middleware.RoleAdmin), ingredientHandler.CreateIngredient)
+		protected.PATCH("/ingredients/:id", middleware.RequireRole(middleware.RoleAdmin), ingredientHandler.RenameIngredient)
+		protected.POST("/ingredients/merge", middleware.RequireRole(middleware.RoleAdmin), ingredientHandler.MergeIngredients)
+		protected.PATCH("/recipes/:id/feature", middleware.RequireRole(middleware.RoleAdmin), recipeHandler.FeatureRecipe)
+		protected.POST("/recipes/upload-request", recipeHandler.PostUploadRequest)
+		protected.POST("/recipes/:id/images/confirm", recipeHandler.ConfirmRecipeImage)
+		protected.PUT("/recipes/:id/images/order", recipeHandler.ReorderRecipeImages)
+		protected.PUT("/recipes/:id/images/:imageId/primary", recipeHandler.SetPrimaryRecipeImage)
+		protected.POST("/webhooks", webhookHandler.CreateWebhook)
+		protected.GET("/webhooks", webhookHandler.GetWebhooks)
+		protected.DELETE("/webhooks/:id", webhookHandler.DeleteWebhook)
+		protected.GET("/users", middleware.RequireRole(middleware.RoleAdmin), userHandler.GetUsers)
+		protected.GET("/stats", middleware.RequireRole(middleware.RoleAdmin), statsHandler.GetStats)
+		protected.GET("/users/me", userHandler.GetCurrentUser)
+		protected.PATCH("/users/me", userHandler.UpdateCurrentUser)
+		protected.POST("/users/me/password", userHandler.ChangePassword)
+		protected.DELETE("/users/me", userHandler.DeleteCurrentUser)
+	}
+}
+
+// authHeader returns an Authorization header value for the given user and role
+func (suite *RecipeAPITestSuite) authHeader(userID int, role string) string {
+	token, err := middleware.GenerateTokenWithRole(suite.authConfig, userID, "user@example.com", "Test User", role)
+	require.NoError(suite.T(), err, "Failed to generate test token")
+	return "Bearer " + token
+}
+
+// createTestUser creates an additional user and returns its ID
+func (suite *RecipeAPITestSuite) createTestUser(email string) int {
+	var userID int
+	err := suite.db.DB.QueryRow(`
+		INSERT INTO users (email, name) VALUES ($1, $2) RETURNING id
+	`, email, "Other User").Scan(&userID)
+	require.NoError(suite.T(), err, "Failed to create test user")
+	return userID
+}
+
+// createTestIngredient adds a recipe ingredient and returns its ID
+func (suite *RecipeAPITestSuite) createTestIngredient(recipeID int, originalText string, canonicalID *int) int {
+	var ingredientID int
+	err := suite.db.DB.QueryRow(`
+		INSERT INTO recipe_ingredients (recipe_id, canonical_ingredient_id, original_text)
+		VALUES ($1, $2, $3)
+		RETURNING id
+	`, recipeID, canonicalID, originalText).Scan(&ingredientID)
+	require.NoError(suite.T(), err, "Failed to create test ingredient")
+	return ingredientID
+}
+
+// createCanonicalIngredient adds a canonical ingredient and returns its ID
+func (suite *RecipeAPITestSuite) createCanonicalIngredient(name string, approved bool) int {
+	var ingredientID int
+	err := suite.db.DB.QueryRow(`
+		INSERT INTO canonical_ingredients (name, is_approved) VALUES ($1, $2) RETURNING id
+	`, name, approved).Scan(&ingredientID)
+	require.NoError(suite.T(), err, "Failed to create canonical ingredient")
+	return ingredientID
+}
+
+// performJSON sends a JSON request with optional Authorization header to the suite router
+func (suite *RecipeAPITestSuite) performJSON(method, path, body, authorization string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
 	}
+	suite.router.ServeHTTP(w, req)
+	return w
 }
 
 // TearDownSuite runs after all tests in the suite
@@ -92,7 +231,7 @@ func (suite *RecipeAPITestSuite) cleanupTestData() {
 	defer tx.Rollback()
 	
 	// Order matters for foreign key constraints
-	tables := []string{"recipe_ingredients", "recipes", "canonical_ingredients", "users"}
+	tables := []string{"webhooks", "share_tokens", "recipe_images", "recipe_drafts", "recipe_revisions", "recipe_ingredients", "recipes", "canonical_ingredients", "users"}
 	
 	for _, table := range tables {
 		_, err := tx.Exec(fmt.Sprintf("TRUNCATE %s RESTART IDENTITY CASCADE", table))
@@ -281,6 +420,33 @@ func (suite *RecipeAPITestSuite) TestGetRecipeByID() {
 	assert.NotNil(suite.T(), recipe.Ingredients, "Ingredients should be included")
 }
 
+// TestGetRecipeByIDPreparedStatements tests GET /recipes/:id returns each recipe's own data through the cached statements
+func (suite *RecipeAPITestSuite) TestGetRecipeByIDPreparedStatements() {
+	require.NoError(suite.T(), handlers.PrepareRecipeStatements(context.Background(), suite.db))
+
+	firstID := suite.createTestRecipe("First Prepared", "published")
+	secondID := suite.createTestRecipe("Second Prepared", "draft")
+	suite.createTestIngredient(secondID, "2 eggs", nil)
+
+	for _, expected := range []struct {
+		id          int
+		title       string
+		ingredients int
+	}{
+		{firstID, "First Prepared", 0},
+		{secondID, "Second Prepared", 1},
+		{firstID, "First Prepared", 0},
+	} {
+		w := suite.performGet("/api/v1/recipes/"+strconv.Itoa(expected.id), "")
+		require.Equal(suite.T(), http.StatusOK, w.Code)
+
+		recipe := suite.decodeRecipe(w.Body.Bytes())
+		assert.Equal(suite.T(), expected.id, recipe.ID)
+		assert.Equal(suite.T(), expected.title, recipe.Title)
+		assert.Len(suite.T(), recipe.Ingredients, expected.ingredients)
+	}
+}
+
 // TestGetRecipeByIDNotFound tests GET /recipes/:id endpoint with non-existent ID
 func (suite *RecipeAPITestSuite) TestGetRecipeByIDNotFound() {
 	w := httptest.NewRecorder()
@@ -395,6 +561,28 @@ func (suite *RecipeAPITestSuite) TestGetRecipesInvalidPagination() {
 	assert.Equal(suite.T(), http.StatusBadRequest, w.Code)
 }
 
+// TestGetRecipesCountOnly tests GET /recipes?count_only=true returns the total without data
+func (suite *RecipeAPITestSuite) TestGetRecipesCountOnly() {
+	suite.createTestRecipe("Published One", "published")
+	suite.createTestRecipe("Published Two", "published")
+	suite.createTestRecipe("Draft", "review_required")
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v1/recipes?count_only=true&status=published&per_page=1", nil)
+	suite.router.ServeHTTP(w, req)
+
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+
+	var response handlers.StandardResponse
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	require.NoError(suite.T(), err, "Failed to unmarshal response")
+
+	assert.Nil(suite.T(), response.Data, "Count-only response should not include recipe data")
+	require.NotNil(suite.T(), response.Pagination, "Count-only response should include pagination")
+	assert.Equal(suite.T(), 2, response.Pagination.Total, "Total should respect the status filter")
+	assert.Equal(suite.T(), 2, response.Pagination.TotalPages)
+}
+
 // Run the test suite
 func TestRecipeAPITestSuite(t *testing.T) {
 	suite.Run(t, new(RecipeAPITestSuite))
@@ -0,0 +1,143 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"digital-recipes/api-service/handlers"
+	"digital-recipes/api-service/middleware"
+	"digital-recipes/api-service/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// decodeRecipe extracts a recipe with ingredients from a standard response
+func (suite *RecipeAPITestSuite) decodeRecipe(body []byte) models.RecipeWithIngredients {
+	var response handlers.StandardResponse
+	require.NoError(suite.T(), json.Unmarshal(body, &response))
+	dataBytes, _ := json.Marshal(response.Data)
+	var recipe models.RecipeWithIngredients
+	require.NoError(suite.T(), json.Unmarshal(dataBytes, &recipe))
+	return recipe
+}
+
+// countRevisions returns the number of revisions stored for a recipe
+func (suite *RecipeAPITestSuite) countRevisions(recipeID int) int {
+	var count int
+	err := suite.db.DB.QueryRow(`SELECT COUNT(*) FROM recipe_revisions WHERE recipe_id = $1`, recipeID).Scan(&count)
+	require.NoError(suite.T(), err)
+	return count
+}
+
+// TestUpdateRecipeWritesRevision tests each update stores the previous state as a revision
+func (suite *RecipeAPITestSuite) TestUpdateRecipeWritesRevision() {
+	recipeID := suite.createTestRecipe("Original Title", "review_required")
+	suite.createTestIngredient(recipeID, "1 cup rice", nil)
+	auth := suite.authHeader(suite.testUserID, middleware.RoleUser)
+	path := fmt.Sprintf("/api/v1/recipes/%d", recipeID)
+
+	w := suite.performJSON("PUT", path, `{"title": "Second Title", "servings": "2", "ingredients": [{"original_text": "2 cups rice"}]}`, auth)
+	require.Equal(suite.T(), http.StatusOK, w.Code, w.Body.String())
+	updated := suite.decodeRecipe(w.Body.Bytes())
+	assert.Equal(suite.T(), "Second Title", updated.Title)
+	assert.Equal(suite.T(), "review_required", updated.Status, "Omitted status keeps the current status")
+	require.Len(suite.T(), updated.Ingredients, 1)
+	assert.Equal(suite.T(), "2 cups rice", updated.Ingredients[0].OriginalText)
+	assert.Equal(suite.T(), 1, suite.countRevisions(recipeID))
+
+	w = suite.performJSON("PUT", path, `{"title": "Third Title", "status": "published"}`, auth)
+	require.Equal(suite.T(), http.StatusOK, w.Code, w.Body.String())
+	updated = suite.decodeRecipe(w.Body.Bytes())
+	assert.Equal(suite.T(), "published", updated.Status)
+	require.Len(suite.T(), updated.Ingredients, 1, "Omitted ingredients keep the current list")
+	assert.Equal(suite.T(), 2, suite.countRevisions(recipeID))
+
+	// Revisions are listed newest first with the author of each change
+	w = suite.performGet(path+"/revisions", auth)
+	require.Equal(suite.T(), http.StatusOK, w.Code, w.Body.String())
+	var response handlers.StandardResponse
+	require.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &response))
+	require.NotNil(suite.T(), response.Pagination)
+	assert.Equal(suite.T(), 2, response.Pagination.Total)
+	dataBytes, _ := json.Marshal(response.Data)
+	var revisions []models.RecipeRevision
+	require.NoError(suite.T(), json.Unmarshal(dataBytes, &revisions))
+	require.Len(suite.T(), revisions, 2)
+	assert.Equal(suite.T(), 2, revisions[0].RevisionNumber)
+	assert.Equal(suite.T(), "Second Title", revisions[0].Snapshot.Title)
+	assert.Equal(suite.T(), 1, revisions[1].RevisionNumber)
+	assert.Equal(suite.T(), "Original Title", revisions[1].Snapshot.Title)
+	require.Len(suite.T(), revisions[1].Snapshot.Ingredients, 1)
+	assert.Equal(suite.T(), "1 cup rice", revisions[1].Snapshot.Ingredients[0].OriginalText)
+	require.NotNil(suite.T(), revisions[1].ChangedBy)
+	assert.Equal(suite.T(), suite.testUserID, *revisions[1].ChangedBy)
+}
+
+// TestRestoreRecipeRevision tests restoring reconstructs the prior state and is itself revisioned
+func (suite *RecipeAPITestSuite) TestRestoreRecipeRevision() {
+	flourID := suite.createCanonicalIngredient("Flour", true)
+	recipeID := suite.createTestRecipe("Grandma's Bread", "review_required")
+	suite.createTestIngredient(recipeID, "3 cups flour", &flourID)
+	auth := suite.authHeader(suite.testUserID, middleware.RoleUser)
+	path := fmt.Sprintf("/api/v1/recipes/%d", recipeID)
+
+	w := suite.performJSON("PUT", path, `{"title": "Ruined Bread", "instructions": "Burn it.", "ingredients": []}`, auth)
+	require.Equal(suite.T(), http.StatusOK, w.Code, w.Body.String())
+	assert.Empty(suite.T(), suite.decodeRecipe(w.Body.Bytes()).Ingredients)
+
+	var revisionID int
+	require.NoError(suite.T(), suite.db.DB.QueryRow(`SELECT id FROM recipe_revisions WHERE recipe_id = $1 AND revision_number = 1`, recipeID).Scan(&revisionID))
+
+	w = suite.performJSON("POST", fmt.Sprintf("%s/revisions/%d/restore", path, revisionID), "", auth)
+	require.Equal(suite.T(), http.StatusOK, w.Code, w.Body.String())
+	restored := suite.decodeRecipe(w.Body.Bytes())
+
+	assert.Equal(suite.T(), "Grandma's Bread", restored.Title)
+	require.NotNil(suite.T(), restored.Instructions)
+	assert.Equal(suite.T(), "Test instructions", *restored.Instructions)
+	require.NotNil(suite.T(), restored.Servings)
+	assert.Equal(suite.T(), "4", *restored.Servings)
+	require.Len(suite.T(), restored.Ingredients, 1)
+	assert.Equal(suite.T(), "3 cups flour", restored.Ingredients[0].OriginalText)
+	require.NotNil(suite.T(), restored.Ingredients[0].CanonicalIngredientID)
+	assert.Equal(suite.T(), flourID, *restored.Ingredients[0].CanonicalIngredientID)
+
+	assert.Equal(suite.T(), 2, suite.countRevisions(recipeID), "Restoring saves the replaced state")
+
+	w = suite.performJSON("POST", fmt.Sprintf("%s/revisions/%d/restore", path, NonExistentID), "", auth)
+	assert.Equal(suite.T(), http.StatusNotFound, w.Code)
+}
+
+// TestUpdateRecipeValidation tests status transitions, ownership and input validation
+func (suite *RecipeAPITestSuite) TestUpdateRecipeValidation() {
+	recipeID := suite.createTestRecipe("Published", "published")
+	otherUserID := suite.createTestUser("other@example.com")
+	auth := suite.authHeader(suite.testUserID, middleware.RoleUser)
+	path := fmt.Sprintf("/api/v1/recipes/%d", recipeID)
+
+	w := suite.performJSON("PUT", path, `{"title": "Back to processing", "status": "processing"}`, auth)
+	assert.Equal(suite.T(), http.StatusBadRequest, w.Code)
+	assert.Contains(suite.T(), w.Body.String(), "cannot change status from published to processing")
+
+	w = suite.performJSON("PUT", path, `{"title": "   "}`, auth)
+	assert.Equal(suite.T(), http.StatusUnprocessableEntity, w.Code)
+
+	w = suite.performJSON("PUT", path, `{"title": "Bogus", "status": "archived"}`, auth)
+	assert.Equal(suite.T(), http.StatusUnprocessableEntity, w.Code)
+
+	w = suite.performJSON("PUT", path, `{"title": "Hijacked"}`, suite.authHeader(otherUserID, middleware.RoleUser))
+	assert.Equal(suite.T(), http.StatusForbidden, w.Code)
+
+	w = suite.performGet(path+"/revisions", suite.authHeader(otherUserID, middleware.RoleUser))
+	assert.Equal(suite.T(), http.StatusForbidden, w.Code)
+
+	w = suite.performJSON("PUT", fmt.Sprintf("/api/v1/recipes/%d", NonExistentID), `{"title": "Missing"}`, auth)
+	assert.Equal(suite.T(), http.StatusNotFound, w.Code)
+
+	assert.Equal(suite.T(), 0, suite.countRevisions(recipeID), "Rejected updates must not write revisions")
+
+	// Published recipes may go back to review
+	w = suite.performJSON("PUT", path, `{"title": "Needs fixes", "status": "review_required"}`, auth)
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+}
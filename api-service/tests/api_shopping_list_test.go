@@ -0,0 +1,145 @@
+package tests
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"digital-recipes/api-service/handlers"
+	"digital-recipes/api-service/models"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// addQuantifiedIngredient adds a recipe ingredient with explicit quantity and unit
+func (suite *RecipeAPITestSuite) addQuantifiedIngredient(recipeID int, originalText string, canonicalID *int, quantity float64, unit string) {
+	_, err := suite.db.DB.Exec(`
+		INSERT INTO recipe_ingredients (recipe_id, canonical_ingredient_id, original_text, quantity, unit)
+		VALUES ($1, $2, $3, $4, $5)
+	`, recipeID, canonicalID, originalText, quantity, unit)
+	require.NoError(suite.T(), err, "Failed to create quantified ingredient")
+}
+
+// requestShoppingList posts a shopping list request and decodes the result
+func (suite *RecipeAPITestSuite) requestShoppingList(body string) models.ShoppingList {
+	w := suite.performJSON("POST", "/api/v1/shopping-list", body, "")
+	require.Equal(suite.T(), http.StatusOK, w.Code, w.Body.String())
+
+	var response handlers.StandardResponse
+	require.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &response))
+	dataBytes, _ := json.Marshal(response.Data)
+	var list models.ShoppingList
+	require.NoError(suite.T(), json.Unmarshal(dataBytes, &list))
+	return list
+}
+
+// TestShoppingListCombinesRecipes tests shared ingredients are summed when units match and split when they don't
+func (suite *RecipeAPITestSuite) TestShoppingListCombinesRecipes() {
+	flourID := suite.createCanonicalIngredient("Flour", true)
+	pancakesID := suite.createTestRecipe("Pancakes", "published")
+	breadID := suite.createTestRecipe("Bread", "published")
+
+	suite.addQuantifiedIngredient(pancakesID, "1 cup flour", &flourID, 1, "cup")
+	suite.addQuantifiedIngredient(pancakesID, "2 tbsp sugar", nil, 2, "tbsp")
+	suite.addQuantifiedIngredient(breadID, "8 tbsp flour", &flourID, 8, "tbsp")
+	suite.addQuantifiedIngredient(breadID, "500 g flour", &flourID, 500, "g")
+	suite.addQuantifiedIngredient(breadID, "1 tbsp Sugar", nil, 1, "tbsp")
+	suite.createTestIngredient(breadID, "Salt to taste", nil)
+
+	list := suite.requestShoppingList(fmt.Sprintf(`{"recipes": [{"recipe_id": %d}, {"recipe_id": %d}]}`, pancakesID, breadID))
+	assert.Equal(suite.T(), []int{pancakesID, breadID}, list.RecipeIDs)
+	require.Len(suite.T(), list.Items, 4)
+
+	// Cups and tablespoons of flour combine into the first unit seen
+	volumeFlour := list.Items[0]
+	assert.Equal(suite.T(), "Flour", volumeFlour.Name)
+	require.NotNil(suite.T(), volumeFlour.Quantity)
+	assert.InDelta(suite.T(), 1.5, *volumeFlour.Quantity, 0.001)
+	assert.Equal(suite.T(), "cup", *volumeFlour.Unit)
+	assert.Equal(suite.T(), []int{pancakesID, breadID}, volumeFlour.RecipeIDs)
+
+	// Unlinked ingredients are matched by normalized name
+	sugar := list.Items[1]
+	assert.Equal(suite.T(), "sugar", sugar.Name)
+	assert.InDelta(suite.T(), 3, *sugar.Quantity, 0.001)
+	assert.Equal(suite.T(), []string{"2 tbsp sugar", "1 tbsp Sugar"}, sugar.OriginalText)
+
+	// Grams cannot be combined with cups and are listed separately
+	massFlour := list.Items[2]
+	assert.Equal(suite.T(), "Flour", massFlour.Name)
+	assert.InDelta(suite.T(), 500, *massFlour.Quantity, 0.001)
+	assert.Equal(suite.T(), "g", *massFlour.Unit)
+
+	salt := list.Items[3]
+	assert.Nil(suite.T(), salt.Quantity)
+	assert.Equal(suite.T(), []string{"Salt to taste"}, salt.OriginalText)
+}
+
+// TestShoppingListServingsOverride tests quantities scale with the requested servings
+func (suite *RecipeAPITestSuite) TestShoppingListServingsOverride() {
+	recipeID := suite.createTestRecipe("Pancakes", "published") // 4 servings
+	suite.addQuantifiedIngredient(recipeID, "2 cups milk", nil, 2, "cup")
+
+	list := suite.requestShoppingList(fmt.Sprintf(`{"recipes": [{"recipe_id": %d, "servings": 6}]}`, recipeID))
+	require.Len(suite.T(), list.Items, 1)
+	assert.InDelta(suite.T(), 3, *list.Items[0].Quantity, 0.001)
+}
+
+// TestRecipeServingsCountWithoutDatabase tests the servings text is kept as sent while its parsed count is returned and used for scaling
+func TestRecipeServingsCountWithoutDatabase(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	now := time.Now()
+	recipeColumns := []string{"id", "title", "servings", "instructions", "tips", "status", "user_id", "created_at", "updated_at", "prep_time_minutes", "cook_time_minutes", "difficulty", "source_name", "source_url", "last_modified_by", "visibility", "featured", "featured_at"}
+	database := openScriptedDatabase(t,
+		scriptedResult{match: "FROM recipe_ingredients", columns: []string{"id", "recipe_id", "canonical_ingredient_id", "original_text", "quantity", "unit", "created_at", "updated_at", "canonical_name"}, rows: [][]driver.Value{
+			{int64(1), int64(5), nil, "2 cups milk", 2.0, "cup", now, now, nil},
+		}},
+		scriptedResult{match: "FROM recipes", columns: recipeColumns, rows: [][]driver.Value{{int64(5), "Pancakes", "Serves 2-4", nil, nil, "published", int64(7), now, now, nil, nil, nil, nil, nil, nil, "public", false, nil}}},
+	)
+	handler := handlers.NewRecipeHandler(database, nil)
+	router := gin.New()
+	router.GET("/api/v1/recipes/:id", handler.GetRecipe)
+	router.POST("/api/v1/shopping-list", handler.CreateShoppingList)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v1/recipes/5", nil)
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), `"servings":"Serves 2-4"`)
+	assert.Contains(t, w.Body.String(), `"servings_count":4`)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/api/v1/shopping-list", strings.NewReader(`{"recipes": [{"recipe_id": 5, "servings": 8}]}`))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), `"quantity":4`, "Doubling four servings doubles the milk")
+}
+
+// TestShoppingListValidation tests the recipe cap, missing recipes and drafts
+func (suite *RecipeAPITestSuite) TestShoppingListValidation() {
+	recipeID := suite.createTestRecipe("Published", "published")
+	draftID := suite.createTestRecipe("Draft", "review_required")
+
+	selections := make([]string, models.MaxShoppingListRecipes+1)
+	for i := range selections {
+		selections[i] = fmt.Sprintf(`{"recipe_id": %d}`, recipeID)
+	}
+	w := suite.performJSON("POST", "/api/v1/shopping-list", `{"recipes": [`+strings.Join(selections, ",")+`]}`, "")
+	assert.Equal(suite.T(), http.StatusBadRequest, w.Code, "Too many recipes should be rejected")
+
+	w = suite.performJSON("POST", "/api/v1/shopping-list", `{"recipes": []}`, "")
+	assert.Equal(suite.T(), http.StatusBadRequest, w.Code)
+
+	w = suite.performJSON("POST", "/api/v1/shopping-list", fmt.Sprintf(`{"recipes": [{"recipe_id": %d}]}`, NonExistentID), "")
+	assert.Equal(suite.T(), http.StatusNotFound, w.Code)
+
+	w = suite.performJSON("POST", "/api/v1/shopping-list", fmt.Sprintf(`{"recipes": [{"recipe_id": %d}]}`, draftID), "")
+	assert.Equal(suite.T(), http.StatusNotFound, w.Code, "Drafts of other users are not visible")
+}
@@ -0,0 +1,139 @@
+package tests
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"digital-recipes/api-service/handlers"
+	"digital-recipes/api-service/middleware"
+	"digital-recipes/api-service/models"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// decodeStats unmarshals the data of a stats response
+func decodeStats(t *testing.T, body []byte) models.Stats {
+	var response handlers.StandardResponse
+	require.NoError(t, json.Unmarshal(body, &response))
+	dataBytes, _ := json.Marshal(response.Data)
+	var stats models.Stats
+	require.NoError(t, json.Unmarshal(dataBytes, &stats))
+	return stats
+}
+
+// TestGetStats tests the dashboard figures count seeded recipes, users and canonical ingredients
+func (suite *RecipeAPITestSuite) TestGetStats() {
+	suite.createTestRecipe("Fresh Draft", "processing")
+	suite.createTestRecipe("Needs Review", "review_required")
+	suite.createTestRecipe("Bread", "published")
+	lastFortnight := suite.createTestRecipe("Soup", "published")
+	lastQuarter := suite.createTestRecipe("Stew", "published")
+	_, err := suite.db.DB.Exec(`UPDATE recipes SET created_at = NOW() - INTERVAL '10 days' WHERE id = $1`, lastFortnight)
+	require.NoError(suite.T(), err)
+	_, err = suite.db.DB.Exec(`UPDATE recipes SET created_at = NOW() - INTERVAL '90 days' WHERE id = $1`, lastQuarter)
+	require.NoError(suite.T(), err)
+
+	suite.createTestUser("cook@example.com")
+	suite.createCanonicalIngredient("flour", true)
+	suite.createCanonicalIngredient("salt", true)
+	suite.createCanonicalIngredient("zaatar", false)
+
+	w := suite.performGet("/api/v1/stats", suite.authHeader(suite.testUserID, middleware.RoleAdmin))
+	require.Equal(suite.T(), http.StatusOK, w.Code, w.Body.String())
+
+	stats := decodeStats(suite.T(), w.Body.Bytes())
+	assert.Equal(suite.T(), models.RecipeStats{
+		Total:             5,
+		ByStatus:          models.RecipeStatusCounts{Processing: 1, ReviewRequired: 1, Published: 3},
+		CreatedLast7Days:  3,
+		CreatedLast30Days: 4,
+	}, stats.Recipes)
+	assert.Equal(suite.T(), 2, stats.Users)
+	assert.Equal(suite.T(), models.IngredientStats{Total: 3, Approved: 2, Pending: 1}, stats.CanonicalIngredients)
+	assert.WithinDuration(suite.T(), time.Now(), stats.GeneratedAt, time.Minute)
+
+	w = suite.performGet("/api/v1/stats", suite.authHeader(suite.testUserID, middleware.RoleUser))
+	assert.Equal(suite.T(), http.StatusForbidden, w.Code)
+}
+
+// TestGetStatsCachesFigures tests figures are computed once per cache period and failures are not cached
+func TestGetStatsCachesFigures(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	get := func(handler *handlers.StatsHandler) *httptest.ResponseRecorder {
+		router := gin.New()
+		router.GET("/api/v1/stats", handler.GetStats)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/v1/stats", nil)
+		router.ServeHTTP(w, req)
+		return w
+	}
+	countQueries := func(fragment string) int {
+		count := 0
+		for _, query := range scriptedQueries() {
+			if strings.Contains(query, fragment) {
+				count++
+			}
+		}
+		return count
+	}
+
+	database := openScriptedDatabase(t,
+		scriptedResult{
+			match:   "GROUP BY status",
+			columns: []string{"status", "count", "count", "count"},
+			rows: [][]driver.Value{
+				{"processing", int64(2), int64(2), int64(2)},
+				{"published", int64(8), int64(1), int64(5)},
+			},
+		},
+		scriptedResult{match: "FROM canonical_ingredients", columns: []string{"count", "count", "count"}, rows: [][]driver.Value{{int64(4), int64(12), int64(9)}}},
+	)
+	handler := handlers.NewStatsHandler(database)
+
+	w := get(handler)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	stats := decodeStats(t, w.Body.Bytes())
+	assert.Equal(t, 10, stats.Recipes.Total)
+	assert.Equal(t, models.RecipeStatusCounts{Processing: 2, Published: 8}, stats.Recipes.ByStatus)
+	assert.Equal(t, 3, stats.Recipes.CreatedLast7Days)
+	assert.Equal(t, 7, stats.Recipes.CreatedLast30Days)
+	assert.Equal(t, 4, stats.Users)
+	assert.Equal(t, models.IngredientStats{Total: 12, Approved: 9, Pending: 3}, stats.CanonicalIngredients)
+
+	w = get(handler)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, stats, decodeStats(t, w.Body.Bytes()), "Cached figures are returned unchanged")
+	assert.Equal(t, 1, countQueries("GROUP BY status"))
+
+	handler.SetCacheTTL(0)
+	get(handler)
+	assert.Equal(t, 2, countQueries("GROUP BY status"), "Without a cache period every request recomputes")
+
+	// A database that knows no queries fails the recipe counts; the failure is not cached, so each request retries
+	failing := handlers.NewStatsHandler(openScriptedDatabase(t))
+	assert.Equal(t, http.StatusInternalServerError, get(failing).Code)
+	assert.Equal(t, http.StatusInternalServerError, get(failing).Code)
+	assert.Equal(t, 2, countQueries("GROUP BY status"))
+}
+
+func TestGetStatsCacheTTL(t *testing.T) {
+	t.Setenv("STATS_CACHE_SECONDS", "")
+	assert.Equal(t, handlers.DefaultStatsCacheSeconds*time.Second, handlers.GetStatsCacheTTL())
+
+	t.Setenv("STATS_CACHE_SECONDS", "0")
+	assert.Equal(t, time.Duration(0), handlers.GetStatsCacheTTL())
+
+	t.Setenv("STATS_CACHE_SECONDS", "300")
+	assert.Equal(t, 300*time.Second, handlers.GetStatsCacheTTL())
+
+	for _, invalid := range []string{"-1", "soon"} {
+		t.Setenv("STATS_CACHE_SECONDS", invalid)
+		assert.Equal(t, handlers.DefaultStatsCacheSeconds*time.Second, handlers.GetStatsCacheTTL(), invalid)
+	}
+}
@@ -0,0 +1,126 @@
+package tests
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"digital-recipes/api-service/middleware"
+	"digital-recipes/api-service/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sseEvent is one event read from a Server-Sent Events stream
+type sseEvent struct {
+	name string
+	data string
+}
+
+// readSSEEvent reads the next event from a stream, skipping comments
+func readSSEEvent(reader *bufio.Reader) (sseEvent, error) {
+	var event sseEvent
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return event, err
+		}
+		line = strings.TrimRight(line, "\n")
+		switch {
+		case line == "" && event.name != "":
+			return event, nil
+		case strings.HasPrefix(line, "event: "):
+			event.name = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			event.data = strings.TrimPrefix(line, "data: ")
+		}
+	}
+}
+
+// readStatusEvent reads the next status event or fails the test after a timeout
+func (suite *RecipeAPITestSuite) readStatusEvent(reader *bufio.Reader) models.RecipeStatusEvent {
+	type result struct {
+		event sseEvent
+		err   error
+	}
+	done := make(chan result, 1)
+	go func() {
+		event, err := readSSEEvent(reader)
+		done <- result{event, err}
+	}()
+
+	select {
+	case r := <-done:
+		require.NoError(suite.T(), r.err)
+		assert.Equal(suite.T(), "status", r.event.name)
+		var status models.RecipeStatusEvent
+		require.NoError(suite.T(), json.Unmarshal([]byte(r.event.data), &status))
+		return status
+	case <-time.After(5 * time.Second):
+		suite.T().Fatal("Timed out waiting for status event")
+		return models.RecipeStatusEvent{}
+	}
+}
+
+// openStatusStream starts a status stream request against a live server
+func (suite *RecipeAPITestSuite) openStatusStream(ctx context.Context, serverURL string, recipeID int, authorization string) *http.Response {
+	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%s/api/v1/recipes/%d/status/stream", serverURL, recipeID), nil)
+	require.NoError(suite.T(), err)
+	req.Header.Set("Authorization", authorization)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(suite.T(), err)
+	return resp
+}
+
+// TestStreamRecipeStatus tests the stream emits the current status and then each change
+func (suite *RecipeAPITestSuite) TestStreamRecipeStatus() {
+	server := httptest.NewServer(suite.router)
+	defer server.Close()
+
+	recipeID := suite.createTestRecipe("Streaming Stew", "processing")
+	auth := suite.authHeader(suite.testUserID, middleware.RoleUser)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	resp := suite.openStatusStream(ctx, server.URL, recipeID, auth)
+	defer resp.Body.Close()
+	require.Equal(suite.T(), http.StatusOK, resp.StatusCode)
+	assert.Equal(suite.T(), "text/event-stream", resp.Header.Get("Content-Type"))
+
+	reader := bufio.NewReader(resp.Body)
+	initial := suite.readStatusEvent(reader)
+	assert.Equal(suite.T(), "processing", initial.Status)
+
+	w := suite.performJSON("PUT", fmt.Sprintf("/api/v1/recipes/%d", recipeID), `{"title": "Streaming Stew", "status": "review_required"}`, auth)
+	require.Equal(suite.T(), http.StatusOK, w.Code, w.Body.String())
+
+	changed := suite.readStatusEvent(reader)
+	assert.Equal(suite.T(), recipeID, changed.RecipeID)
+	assert.Equal(suite.T(), "processing", changed.PreviousStatus)
+	assert.Equal(suite.T(), "review_required", changed.Status)
+
+	// Disconnecting releases the subscription
+	cancel()
+	assert.Eventually(suite.T(), func() bool {
+		return suite.statusBroker.SubscriberCount(recipeID) == 0
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+// TestStreamRecipeStatusAccess tests only the owner may follow a recipe's status
+func (suite *RecipeAPITestSuite) TestStreamRecipeStatusAccess() {
+	recipeID := suite.createTestRecipe("Private Stew", "processing")
+	otherUserID := suite.createTestUser("other@example.com")
+	path := fmt.Sprintf("/api/v1/recipes/%d/status/stream", recipeID)
+
+	w := suite.performGet(path, suite.authHeader(otherUserID, middleware.RoleUser))
+	assert.Equal(suite.T(), http.StatusForbidden, w.Code)
+
+	w = suite.performGet(fmt.Sprintf("/api/v1/recipes/%d/status/stream", NonExistentID), suite.authHeader(suite.testUserID, middleware.RoleUser))
+	assert.Equal(suite.T(), http.StatusNotFound, w.Code)
+
+	assert.Equal(suite.T(), 0, suite.statusBroker.SubscriberCount(recipeID), "Rejected requests do not leave subscriptions behind")
+}
@@ -0,0 +1,120 @@
+package tests
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"digital-recipes/api-service/handlers"
+	"digital-recipes/api-service/middleware"
+	"digital-recipes/api-service/models"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// requestUpload posts an upload request and decodes the upload response
+func (suite *RecipeAPITestSuite) requestUpload(body string) models.UploadResponse {
+	w := suite.performJSON("POST", "/api/v1/recipes/upload-request", body, suite.authHeader(suite.testUserID, middleware.RoleUser))
+	require.Equal(suite.T(), http.StatusOK, w.Code, w.Body.String())
+
+	var response struct {
+		Data models.UploadResponse `json:"data"`
+	}
+	require.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &response))
+	return response.Data
+}
+
+// TestUploadRequestRapidDuplicate tests a quick repeat of the same upload request reuses the recipe it created
+func (suite *RecipeAPITestSuite) TestUploadRequestRapidDuplicate() {
+	body := `{"image_count": 2, "max_file_size_mb": 5, "allowed_types": ["image/jpeg", "image/png"]}`
+
+	first := suite.requestUpload(body)
+	assert.False(suite.T(), first.Reused)
+
+	second := suite.requestUpload(body)
+	assert.True(suite.T(), second.Reused)
+	assert.Equal(suite.T(), first.RecipeID, second.RecipeID)
+	require.Len(suite.T(), second.UploadURLs, 2)
+	prefix := suite.storagePrefix(first.RecipeID)
+	for _, upload := range second.UploadURLs {
+		assert.Contains(suite.T(), upload.UploadURL, "recipes/"+prefix+"/images/", "Fresh URLs point at the existing recipe")
+	}
+
+	var count int
+	require.NoError(suite.T(), suite.db.DB.QueryRow(`SELECT COUNT(*) FROM recipes WHERE user_id = $1`, suite.testUserID).Scan(&count))
+	assert.Equal(suite.T(), 1, count)
+}
+
+// TestUploadRequestNotDuplicate tests requests that differ, arrive late or follow a started upload create new recipes
+func (suite *RecipeAPITestSuite) TestUploadRequestNotDuplicate() {
+	body := `{"image_count": 1, "max_file_size_mb": 5}`
+	first := suite.requestUpload(body)
+
+	// A different size is a different request
+	different := suite.requestUpload(`{"image_count": 1, "max_file_size_mb": 8}`)
+	assert.False(suite.T(), different.Reused)
+	assert.NotEqual(suite.T(), first.RecipeID, different.RecipeID)
+
+	// A recipe with a confirmed image has been started on and is never reused
+	_, err := suite.db.DB.Exec(`INSERT INTO recipe_images (recipe_id, object_name, content_type) VALUES ($1, $2, 'image/jpeg')`,
+		first.RecipeID, "recipes/"+suite.storagePrefix(first.RecipeID)+"/images/confirmed.jpg")
+	require.NoError(suite.T(), err)
+	afterConfirm := suite.requestUpload(body)
+	assert.False(suite.T(), afterConfirm.Reused)
+	assert.NotEqual(suite.T(), first.RecipeID, afterConfirm.RecipeID)
+
+	// Repeats outside the window are new uploads
+	_, err = suite.db.DB.Exec(`UPDATE recipes SET created_at = created_at - INTERVAL '1 minute' WHERE id = $1`, afterConfirm.RecipeID)
+	require.NoError(suite.T(), err)
+	late := suite.requestUpload(body)
+	assert.False(suite.T(), late.Reused)
+	assert.NotEqual(suite.T(), afterConfirm.RecipeID, late.RecipeID)
+
+	// Recipes that have left processing are not reused either
+	_, err = suite.db.DB.Exec(`UPDATE recipes SET status = 'review_required' WHERE id = $1`, late.RecipeID)
+	require.NoError(suite.T(), err)
+	afterReview := suite.requestUpload(body)
+	assert.False(suite.T(), afterReview.Reused)
+	assert.NotEqual(suite.T(), late.RecipeID, afterReview.RecipeID)
+}
+
+// TestPostUploadRequestReusesDuplicateWithoutDatabase tests a matching recent recipe is answered without inserting a new one
+func TestPostUploadRequestReusesDuplicateWithoutDatabase(t *testing.T) {
+	database := openScriptedDatabase(t, scriptedResult{
+		match:   "upload_fingerprint = $2",
+		columns: []string{"id", "storage_prefix"},
+		rows:    [][]driver.Value{{int64(42), "0f3c9a6e2b7d4e1f8a5c3b9d7e6f1a2b"}},
+	})
+	recipeHandler := handlers.NewRecipeHandler(database, handlers.NewStorageServiceWithBackend("test-bucket", newMemoryBucket()))
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/api/v1/recipes/upload-request", func(c *gin.Context) {
+		c.Set("user_id", 7)
+		c.Next()
+	}, recipeHandler.PostUploadRequest)
+
+	req, err := http.NewRequest("POST", "/api/v1/recipes/upload-request", strings.NewReader(`{"image_count": 1}`))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var response struct {
+		Data models.UploadResponse `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 42, response.Data.RecipeID)
+	assert.True(t, response.Data.Reused)
+	require.Len(t, response.Data.UploadURLs, 1)
+	assert.Contains(t, response.Data.UploadURLs[0].UploadURL, "recipes/0f3c9a6e2b7d4e1f8a5c3b9d7e6f1a2b/images/")
+
+	for _, query := range scriptedQueries() {
+		assert.NotContains(t, query, "INSERT INTO recipes")
+	}
+}
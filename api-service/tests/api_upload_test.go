@@ -2,14 +2,18 @@ package tests
 
 import (
 	"bytes"
+	"database/sql/driver"
 	"encoding/json"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
 
 	"digital-recipes/api-service/db"
 	"digital-recipes/api-service/handlers"
+	"digital-recipes/api-service/middleware"
 	"digital-recipes/api-service/models"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
@@ -134,7 +138,7 @@ func TestPostUploadRequest(t *testing.T) {
 		{
 			name: "Invalid request - image count too high",
 			requestBody: models.UploadRequest{
-				ImageCount: 15, // Max is 10
+				ImageCount: 15, // Max is 5
 			},
 			expectedStatus: http.StatusBadRequest,
 			checkResponse: func(t *testing.T, response map[string]interface{}) {
@@ -204,8 +208,8 @@ func TestPostUploadRequest(t *testing.T) {
 				assert.Equal(t, tt.expectedStatus, w.Code)
 			} else {
 				// For dynamic status - accept either success or error
-				assert.True(t, w.Code == http.StatusOK || w.Code == http.StatusInternalServerError,
-					"Expected either 200 (success) or 500 (storage unavailable), got %d", w.Code)
+				assert.True(t, w.Code == http.StatusOK || w.Code == http.StatusServiceUnavailable || w.Code == http.StatusInternalServerError,
+					"Expected 200 (success), 503 (storage not configured) or 500 (storage error), got %d", w.Code)
 			}
 
 			// Parse response
@@ -250,8 +254,8 @@ func TestPostUploadRequestDatabaseIntegration(t *testing.T) {
 	w := httptest.NewRecorder()
 	r.ServeHTTP(w, req)
 
-	// Should succeed (or fail gracefully if no S3 credentials)
-	assert.True(t, w.Code == http.StatusOK || w.Code == http.StatusInternalServerError)
+	// Should succeed (or fail gracefully if no storage credentials)
+	assert.True(t, w.Code == http.StatusOK || w.Code == http.StatusServiceUnavailable || w.Code == http.StatusInternalServerError)
 
 	if w.Code == http.StatusOK {
 		// Parse response
@@ -280,4 +284,227 @@ func TestPostUploadRequestDatabaseIntegration(t *testing.T) {
 		assert.Equal(t, "processing", status)
 		assert.Equal(t, 1, userID) // MVP default user ID
 	}
-}
\ No newline at end of file
+}
+func TestPostUploadRequestBodyTooLarge(t *testing.T) {
+	// Body limit is enforced before any database or storage access
+	recipeHandler := handlers.NewRecipeHandler(nil, nil)
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(middleware.BodyLimitMiddleware(32))
+	r.POST("/api/v1/recipes/upload-request", recipeHandler.PostUploadRequest)
+
+	body := `{"image_count": 1, "allowed_types": ["image/jpeg", "image/png", "image/webp"]}`
+
+	// Declared length over the limit is rejected by the middleware
+	req, err := http.NewRequest("POST", "/api/v1/recipes/upload-request", bytes.NewBufferString(body))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+
+	// Unknown length is rejected by the handler once the reader hits the cap
+	req, err = http.NewRequest("POST", "/api/v1/recipes/upload-request", io.MultiReader(strings.NewReader(body)))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "PAYLOAD_TOO_LARGE", response["code"])
+}
+
+// TestPostUploadRequestOversizedAllowedTypes tests huge or malformed allowed_types arrays are refused before Validate sees them
+func TestPostUploadRequestOversizedAllowedTypes(t *testing.T) {
+	recipeHandler := handlers.NewRecipeHandler(nil, nil)
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(middleware.BodyLimitMiddleware(middleware.DefaultMaxUploadRequestBodyBytes))
+	r.POST("/api/v1/recipes/upload-request", recipeHandler.PostUploadRequest)
+	post := func(body string) (int, map[string]interface{}) {
+		req, err := http.NewRequest("POST", "/api/v1/recipes/upload-request", strings.NewReader(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return w.Code, response
+	}
+	allowedTypes := func(count int) string {
+		types := make([]string, count)
+		for i := range types {
+			types[i] = `"image/png"`
+		}
+		return `{"image_count": 1, "allowed_types": [` + strings.Join(types, ",") + `]}`
+	}
+
+	// Arrays too long for any real request never get decoded
+	code, _ := post(allowedTypes(1000))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, code)
+
+	// Arrays that fit the body limit still get the field error for the cap
+	code, response := post(allowedTypes(50))
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Equal(t, "allowed_types", response["field"])
+	assert.Equal(t, "at most 4 allowed file types may be specified", response["error"])
+
+	for _, body := range []string{
+		`{"image_count": 1, "allowed_types": "image/png"}`,
+		`{"image_count": 1, "allowed_types": [["image/png"], ["image/webp"]]}`,
+		`{"image_count": 1, "allowed_types": [{"type": "image/png"}]}`,
+	} {
+		code, response = post(body)
+		assert.Equal(t, http.StatusBadRequest, code, body)
+		assert.Equal(t, "allowed_types", response["field"], body)
+		assert.Equal(t, "invalid value for allowed_types", response["error"], body)
+	}
+}
+
+func TestPostUploadRequestFieldErrors(t *testing.T) {
+	// Validation failures are returned before any database or storage access
+	recipeHandler := handlers.NewRecipeHandler(nil, nil)
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/api/v1/recipes/upload-request", recipeHandler.PostUploadRequest)
+
+	tests := []struct {
+		name          string
+		body          string
+		expectedField string
+		expectedError string
+	}{
+		{
+			name:          "Image count over binding max",
+			body:          `{"image_count": 6}`,
+			expectedField: "image_count",
+			expectedError: "maximum 5 images allowed per upload request",
+		},
+		{
+			name:          "File size over binding max",
+			body:          `{"image_count": 1, "max_file_size_mb": 26}`,
+			expectedField: "max_file_size_mb",
+			expectedError: "maximum file size is 25MB per image",
+		},
+		{
+			name:          "Total size passes binding but fails Validate",
+			body:          `{"image_count": 5, "max_file_size_mb": 25}`,
+			expectedField: "max_file_size_mb",
+			expectedError: "total upload size cannot exceed 100MB (currently 125MB)",
+		},
+		{
+			name:          "Unsupported type",
+			body:          `{"image_count": 1, "allowed_types": ["image/gif"]}`,
+			expectedField: "allowed_types",
+			expectedError: "unsupported file type: image/gif. Allowed types: image/jpeg, image/png, image/webp",
+		},
+		{
+			name:          "Allowed types over binding max",
+			body:          `{"image_count": 1, "allowed_types": ["image/png", "image/png", "image/png", "image/png", "image/png"]}`,
+			expectedField: "allowed_types",
+			expectedError: "at most 4 allowed file types may be specified",
+		},
+		{
+			name:          "Both JPEG spellings",
+			body:          `{"image_count": 1, "allowed_types": ["image/jpg", "image/jpeg"]}`,
+			expectedField: "allowed_types",
+			expectedError: "image/jpeg and image/jpg are the same type; specify only one",
+		},
+		{
+			name:          "Content types over binding max",
+			body:          `{"image_count": 1, "content_types": ["image/png", "image/png", "image/png", "image/png", "image/png", "image/png"]}`,
+			expectedField: "content_types",
+			expectedError: "content_types must list one type per image (got 6 for 1 images)",
+		},
+		{
+			name:          "Content type outside the allowed types",
+			body:          `{"image_count": 1, "allowed_types": ["image/png"], "content_types": ["image/webp"]}`,
+			expectedField: "content_types",
+			expectedError: "content type image/webp is not in allowed_types",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest("POST", "/api/v1/recipes/upload-request", bytes.NewBufferString(tt.body))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+
+			var response map[string]interface{}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, "validation", response["type"])
+			assert.Equal(t, tt.expectedField, response["field"])
+			assert.Equal(t, tt.expectedError, response["error"])
+		})
+	}
+}
+
+// TestPostUploadRequestRollsBackOnStorageFailure tests the recipe insert is rolled back when URL signing fails
+func TestPostUploadRequestRollsBackOnStorageFailure(t *testing.T) {
+	database := openScriptedDatabase(t,
+		scriptedResult{
+			match:   "INSERT INTO recipes",
+			columns: []string{"id", "storage_prefix"},
+			rows:    [][]driver.Value{{int64(42), "0f3c9a6e2b7d4e1f8a5c3b9d7e6f1a2b"}},
+		},
+		scriptedResult{match: "upload_fingerprint = $2", columns: []string{"id", "storage_prefix"}},
+	)
+	recipeHandler := handlers.NewRecipeHandler(database, handlers.NewStorageServiceWithBackend("test-bucket", failingBucket{}))
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/api/v1/recipes/upload-request", func(c *gin.Context) {
+		c.Set("user_id", 7)
+		c.Next()
+	}, recipeHandler.PostUploadRequest)
+
+	req, err := http.NewRequest("POST", "/api/v1/recipes/upload-request", strings.NewReader(`{"image_count": 1}`))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), "STORAGE_ERROR")
+	queries := scriptedQueries()
+	require.Len(t, queries, 5)
+	assert.Equal(t, "BEGIN", queries[0])
+	assert.Contains(t, queries[1], "pg_advisory_xact_lock")
+	assert.Contains(t, queries[2], "upload_fingerprint = $2")
+	assert.Contains(t, queries[3], "INSERT INTO recipes")
+	assert.Equal(t, "ROLLBACK", queries[4], "The recipe row must not outlive a failed upload request")
+}
+
+// TestPostUploadRequestStorageUnavailable tests no recipe is created when storage is not configured
+func TestPostUploadRequestStorageUnavailable(t *testing.T) {
+	database := openScriptedDatabase(t)
+	recipeHandler := handlers.NewRecipeHandler(database, nil)
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/api/v1/recipes/upload-request", func(c *gin.Context) {
+		c.Set("user_id", 7)
+		c.Next()
+	}, recipeHandler.PostUploadRequest)
+
+	req, err := http.NewRequest("POST", "/api/v1/recipes/upload-request", strings.NewReader(`{"image_count": 1}`))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "SERVICE_UNAVAILABLE", response["code"])
+	assert.Empty(t, scriptedQueries(), "No recipe row is inserted without storage")
+}
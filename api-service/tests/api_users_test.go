@@ -0,0 +1,74 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"digital-recipes/api-service/handlers"
+	"digital-recipes/api-service/middleware"
+	"digital-recipes/api-service/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// listUsers requests a page of users as an admin and decodes the response
+func (suite *RecipeAPITestSuite) listUsers(query string) ([]models.User, *handlers.Pagination) {
+	w := suite.performGet("/api/v1/users"+query, suite.authHeader(suite.testUserID, middleware.RoleAdmin))
+	require.Equal(suite.T(), http.StatusOK, w.Code, w.Body.String())
+
+	var response handlers.StandardResponse
+	require.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &response))
+	dataBytes, _ := json.Marshal(response.Data)
+	var users []models.User
+	require.NoError(suite.T(), json.Unmarshal(dataBytes, &users))
+	return users, response.Pagination
+}
+
+// TestGetUsersPagination tests users are paginated in ID order
+func (suite *RecipeAPITestSuite) TestGetUsersPagination() {
+	for i := 1; i <= 4; i++ {
+		suite.createTestUser(fmt.Sprintf("cook%d@example.com", i))
+	}
+
+	users, pagination := suite.listUsers("?page=1&per_page=2")
+	require.Len(suite.T(), users, 2)
+	assert.Equal(suite.T(), "testuser@example.com", users[0].Email)
+	assert.Equal(suite.T(), "cook1@example.com", users[1].Email)
+	require.NotNil(suite.T(), pagination)
+	assert.Equal(suite.T(), 5, pagination.Total)
+	assert.Equal(suite.T(), 3, pagination.TotalPages)
+
+	users, _ = suite.listUsers("?page=3&per_page=2")
+	require.Len(suite.T(), users, 1)
+	assert.Equal(suite.T(), "cook4@example.com", users[0].Email)
+
+	w := suite.performGet("/api/v1/users?per_page=1000", suite.authHeader(suite.testUserID, middleware.RoleAdmin))
+	assert.Equal(suite.T(), http.StatusBadRequest, w.Code)
+}
+
+// TestGetUsersEmailFilter tests the email filter matches substrings literally and case-insensitively
+func (suite *RecipeAPITestSuite) TestGetUsersEmailFilter() {
+	suite.createTestUser("chef_anna@kitchen.example.com")
+	suite.createTestUser("chefxbob@kitchen.example.com")
+
+	users, pagination := suite.listUsers("?email=KITCHEN")
+	assert.Len(suite.T(), users, 2)
+	assert.Equal(suite.T(), 2, pagination.Total)
+
+	users, _ = suite.listUsers("?email=chef_")
+	require.Len(suite.T(), users, 1, "Underscores match literally, not as wildcards")
+	assert.Equal(suite.T(), "chef_anna@kitchen.example.com", users[0].Email)
+
+	users, _ = suite.listUsers("?email=nobody")
+	assert.Empty(suite.T(), users)
+}
+
+// TestGetUsersRequiresAdmin tests non-admins cannot list users
+func (suite *RecipeAPITestSuite) TestGetUsersRequiresAdmin() {
+	w := suite.performGet("/api/v1/users", suite.authHeader(suite.testUserID, middleware.RoleUser))
+	assert.Equal(suite.T(), http.StatusForbidden, w.Code)
+
+	w = suite.performGet("/api/v1/users", "")
+	assert.Equal(suite.T(), http.StatusUnauthorized, w.Code)
+}
@@ -0,0 +1,130 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+
+	"digital-recipes/api-service/handlers"
+	"digital-recipes/api-service/middleware"
+	"digital-recipes/api-service/models"
+	"digital-recipes/api-service/webhooks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// registerWebhook registers a webhook as an admin and returns the registration
+func (suite *RecipeAPITestSuite) registerWebhook(body string) models.WebhookRegistration {
+	w := suite.performJSON("POST", "/api/v1/webhooks", body, suite.authHeader(suite.testUserID, middleware.RoleAdmin))
+	require.Equal(suite.T(), http.StatusOK, w.Code, w.Body.String())
+
+	var response handlers.StandardResponse
+	require.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &response))
+	dataBytes, _ := json.Marshal(response.Data)
+	var registration models.WebhookRegistration
+	require.NoError(suite.T(), json.Unmarshal(dataBytes, &registration))
+	return registration
+}
+
+// TestWebhookRegistration tests registering, listing and unregistering webhooks
+func (suite *RecipeAPITestSuite) TestWebhookRegistration() {
+	admin := suite.authHeader(suite.testUserID, middleware.RoleAdmin)
+
+	generated := suite.registerWebhook(`{"url": "https://ocr.example.com/hooks", "events": ["recipe.published", "recipe.published"]}`)
+	assert.Len(suite.T(), generated.Secret, 64, "A secret is generated when none is supplied")
+	assert.Equal(suite.T(), []string{webhooks.EventRecipePublished}, generated.Events)
+	require.NotNil(suite.T(), generated.CreatedBy)
+	assert.Equal(suite.T(), suite.testUserID, *generated.CreatedBy)
+
+	supplied := suite.registerWebhook(`{"url": "https://ocr.example.com/other", "events": ["recipe.review_required"], "secret": "a-secret-of-enough-length"}`)
+	assert.Equal(suite.T(), "a-secret-of-enough-length", supplied.Secret)
+
+	w := suite.performGet("/api/v1/webhooks", admin)
+	require.Equal(suite.T(), http.StatusOK, w.Code)
+	assert.NotContains(suite.T(), w.Body.String(), "a-secret-of-enough-length", "Secrets are never listed")
+	assert.NotContains(suite.T(), w.Body.String(), generated.Secret)
+	assert.Contains(suite.T(), w.Body.String(), "https://ocr.example.com/other")
+
+	w = suite.performJSON("DELETE", fmt.Sprintf("/api/v1/webhooks/%d", generated.ID), "", admin)
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+	w = suite.performJSON("DELETE", fmt.Sprintf("/api/v1/webhooks/%d", generated.ID), "", admin)
+	assert.Equal(suite.T(), http.StatusNotFound, w.Code)
+}
+
+// TestWebhookRegistrationValidation tests webhook management is admin only and validates input
+func (suite *RecipeAPITestSuite) TestWebhookRegistrationValidation() {
+	admin := suite.authHeader(suite.testUserID, middleware.RoleAdmin)
+	user := suite.authHeader(suite.testUserID, middleware.RoleUser)
+
+	w := suite.performJSON("POST", "/api/v1/webhooks", `{"url": "https://example.com/hook", "events": ["recipe.published"]}`, user)
+	assert.Equal(suite.T(), http.StatusForbidden, w.Code)
+	w = suite.performGet("/api/v1/webhooks", user)
+	assert.Equal(suite.T(), http.StatusForbidden, w.Code)
+
+	invalid := []string{
+		`{"url": "ftp://example.com/hook", "events": ["recipe.published"]}`,
+		`{"url": "not a url", "events": ["recipe.published"]}`,
+		`{"url": "https://example.com/hook", "events": []}`,
+		`{"url": "https://example.com/hook", "events": ["recipe.deleted"]}`,
+		`{"url": "https://example.com/hook", "events": ["recipe.published"], "secret": "short"}`,
+	}
+	for _, body := range invalid {
+		w = suite.performJSON("POST", "/api/v1/webhooks", body, admin)
+		assert.Equal(suite.T(), http.StatusBadRequest, w.Code, body)
+	}
+}
+
+// TestWebhookDeliveredOnStatusChange tests subscribers receive a signed payload when a recipe is published
+func (suite *RecipeAPITestSuite) TestWebhookDeliveredOnStatusChange() {
+	var mu sync.Mutex
+	var deliveries []*http.Request
+	var bodies [][]byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		deliveries = append(deliveries, r)
+		bodies = append(bodies, body)
+		mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	registration := suite.registerWebhook(fmt.Sprintf(`{"url": %q, "events": ["recipe.published"]}`, server.URL))
+
+	recipeID := suite.createTestRecipe("Webhook Soup", "review_required")
+	auth := suite.authHeader(suite.testUserID, middleware.RoleUser)
+	path := fmt.Sprintf("/api/v1/recipes/%d", recipeID)
+
+	// A content-only edit does not change status and is not announced
+	w := suite.performJSON("PUT", path, `{"title": "Webhook Soup"}`, auth)
+	require.Equal(suite.T(), http.StatusOK, w.Code)
+	suite.webhooks.Wait()
+	assert.Empty(suite.T(), deliveries)
+
+	w = suite.performJSON("PUT", path, `{"title": "Webhook Soup", "status": "published"}`, auth)
+	require.Equal(suite.T(), http.StatusOK, w.Code)
+	suite.webhooks.Wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(suite.T(), deliveries, 1)
+	assert.Equal(suite.T(), webhooks.EventRecipePublished, deliveries[0].Header.Get(webhooks.EventHeader))
+	assert.Equal(suite.T(), webhooks.Sign(registration.Secret, bodies[0]), deliveries[0].Header.Get(webhooks.SignatureHeader))
+
+	var payload struct {
+		Event string                   `json:"event"`
+		Data  models.RecipeStatusEvent `json:"data"`
+	}
+	require.NoError(suite.T(), json.Unmarshal(bodies[0], &payload))
+	assert.Equal(suite.T(), webhooks.EventRecipePublished, payload.Event)
+	assert.Equal(suite.T(), models.RecipeStatusEvent{
+		RecipeID:       recipeID,
+		Title:          "Webhook Soup",
+		PreviousStatus: "review_required",
+		Status:         "published",
+		UserID:         suite.testUserID,
+	}, payload.Data)
+}
@@ -0,0 +1,129 @@
+package tests
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"digital-recipes/api-service/middleware"
+	"github.com/gin-gonic/gin"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// bodyLoggingRouter echoes JSON request bodies back behind DebugBodyLoggingMiddleware
+func bodyLoggingRouter(enabled bool) *gin.Engine {
+	r := gin.New()
+	r.Use(middleware.DebugBodyLoggingMiddleware(enabled))
+	r.POST("/echo", func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		c.Data(http.StatusOK, "application/json; charset=utf-8", body)
+	})
+	r.GET("/image", func(c *gin.Context) {
+		c.Data(http.StatusOK, "image/jpeg", []byte("\xff\xd8\xff\xe0 not really a jpeg"))
+	})
+	return r
+}
+
+func TestDebugBodyLoggingRedactsCredentials(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	hook := logtest.NewGlobal()
+	defer hook.Reset()
+
+	body := `{"email": "jane@example.com", "current_password": "hunter2", "new_password":"c0rrect \"horse\"", "share": {"token": "abc123"}}`
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/echo", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer secret-jwt")
+	req.Header.Set("Cookie", "session=secret-cookie")
+	bodyLoggingRouter(true).ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, body, w.Body.String(), "Logging leaves the bodies themselves untouched")
+
+	entry := findLogEntry(hook, "Request and response bodies")
+	require.NotNil(t, entry)
+	logged, err := entry.String()
+	require.NoError(t, err)
+	for _, secret := range []string{"hunter2", "horse", "abc123", "secret-jwt", "secret-cookie"} {
+		assert.NotContains(t, logged, secret)
+	}
+	assert.Contains(t, entry.Data["request_body"], "jane@example.com")
+	assert.Contains(t, entry.Data["response_body"], `"current_password":"[REDACTED]"`)
+	assert.Equal(t, len(body), entry.Data["request_body_bytes"])
+	headers := entry.Data["request_headers"].(map[string]string)
+	assert.Equal(t, "[REDACTED]", headers["Authorization"])
+	assert.Equal(t, "application/json", headers["Content-Type"])
+}
+
+func TestDebugBodyLoggingCapsAndSkipsBinaryBodies(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	hook := logtest.NewGlobal()
+	defer hook.Reset()
+	router := bodyLoggingRouter(true)
+
+	// A password cut off by the size cap is still hidden
+	body := `{"notes": "` + strings.Repeat("a", middleware.DebugBodyLogMaxBytes-30) + `", "password": "` + strings.Repeat("p", 100) + `"}`
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/echo", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	entry := findLogEntry(hook, "Request and response bodies")
+	require.NotNil(t, entry)
+	assert.Equal(t, true, entry.Data["request_body_truncated"])
+	assert.Equal(t, len(body), entry.Data["request_body_bytes"])
+	assert.True(t, strings.HasSuffix(entry.Data["request_body"].(string), `"password":"[REDACTED]"`), "Only the first DebugBodyLogMaxBytes are logged")
+	assert.NotContains(t, entry.Data["request_body"], "ppp")
+
+	hook.Reset()
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/image", nil)
+	router.ServeHTTP(w, req)
+	entry = findLogEntry(hook, "Request and response bodies")
+	require.NotNil(t, entry)
+	assert.Equal(t, "[22 bytes of image/jpeg omitted]", entry.Data["response_body"])
+	assert.NotContains(t, entry.Data, "request_body", "Empty bodies are only counted")
+}
+
+func TestDebugBodyLoggingDisabled(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	hook := logtest.NewGlobal()
+	defer hook.Reset()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/echo", strings.NewReader(`{"password": "hunter2"}`))
+	req.Header.Set("Content-Type", "application/json")
+	bodyLoggingRouter(false).ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `{"password": "hunter2"}`, w.Body.String())
+	assert.Empty(t, hook.AllEntries(), "Nothing is logged when body logging is off")
+}
+
+func TestGetDebugLogBodies(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		ginMode  string
+		expected bool
+	}{
+		{"Unset is off", "", "debug", false},
+		{"On in debug mode", "true", "debug", true},
+		{"Ignored in release mode", "true", "release", false},
+		{"Ignored without a debug mode", "1", "", false},
+		{"Off in debug mode", "false", "debug", false},
+		{"Garbage is off", "yes please", "debug", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("DEBUG_LOG_BODIES", tt.value)
+			t.Setenv("GIN_MODE", tt.ginMode)
+			assert.Equal(t, tt.expected, middleware.GetDebugLogBodies())
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package tests
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"digital-recipes/api-service/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newCompressionRouter serves JSON bodies of a requested size and a short event stream through GzipMiddleware
+func newCompressionRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(middleware.GzipMiddleware(1024))
+	r.GET("/sized", func(c *gin.Context) {
+		var size int
+		fmt.Sscan(c.Query("size"), &size)
+		c.JSON(http.StatusOK, gin.H{"padding": strings.Repeat("a", size)})
+	})
+	r.GET("/stream", func(c *gin.Context) {
+		c.Header("Content-Type", "text/event-stream")
+		c.Status(http.StatusOK)
+		for i := 0; i < 3; i++ {
+			fmt.Fprintf(c.Writer, "event: status\ndata: %s\n\n", strings.Repeat("b", 600))
+			c.Writer.Flush()
+		}
+	})
+	r.GET("/empty", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+	return r
+}
+
+func performCompressed(r *gin.Engine, path, acceptEncoding string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", path, nil)
+	if acceptEncoding != "" {
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+	}
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestGzipMiddlewareCompressesLargeResponses(t *testing.T) {
+	r := newCompressionRouter()
+
+	w := performCompressed(r, "/sized?size=5000", "gzip, deflate, br")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+	assert.Contains(t, w.Header().Values("Vary"), "Accept-Encoding")
+	assert.Less(t, w.Body.Len(), 5000, "Repetitive JSON shrinks once compressed")
+
+	reader, err := gzip.NewReader(w.Body)
+	require.NoError(t, err)
+	body, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"padding": "`+strings.Repeat("a", 5000)+`"}`, string(body))
+}
+
+func TestGzipMiddlewareLeavesResponsesUncompressed(t *testing.T) {
+	r := newCompressionRouter()
+
+	tests := []struct {
+		name           string
+		path           string
+		acceptEncoding string
+	}{
+		{"Small response", "/sized?size=10", "gzip"},
+		{"Client without gzip", "/sized?size=5000", ""},
+		{"Client refusing gzip", "/sized?size=5000", "gzip;q=0, identity"},
+		{"Client preferring another encoding", "/sized?size=5000", "br"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := performCompressed(r, tt.path, tt.acceptEncoding)
+			require.Equal(t, http.StatusOK, w.Code)
+			assert.Empty(t, w.Header().Get("Content-Encoding"))
+			assert.Contains(t, w.Header().Values("Vary"), "Accept-Encoding")
+			assert.True(t, strings.HasPrefix(w.Body.String(), `{"padding":"`), w.Body.String())
+		})
+	}
+
+	t.Run("No content", func(t *testing.T) {
+		w := performCompressed(r, "/empty", "gzip")
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		assert.Empty(t, w.Header().Get("Content-Encoding"))
+		assert.Zero(t, w.Body.Len())
+	})
+}
+
+func TestGzipMiddlewareKeepsEventStreamsStreaming(t *testing.T) {
+	r := newCompressionRouter()
+
+	w := performCompressed(r, "/stream", "gzip")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.True(t, w.Flushed)
+	assert.Empty(t, w.Header().Get("Content-Encoding"), "Events are flushed as written rather than compressed")
+	assert.Equal(t, 3, strings.Count(w.Body.String(), "event: status\n"))
+}
+
+func TestGetGzipMinBytes(t *testing.T) {
+	t.Setenv("GZIP_MIN_BYTES", "")
+	assert.Equal(t, middleware.DefaultGzipMinBytes, middleware.GetGzipMinBytes())
+
+	t.Setenv("GZIP_MIN_BYTES", "4096")
+	assert.Equal(t, 4096, middleware.GetGzipMinBytes())
+
+	for _, invalid := range []string{"0", "-5", "big"} {
+		t.Setenv("GZIP_MIN_BYTES", invalid)
+		assert.Equal(t, middleware.DefaultGzipMinBytes, middleware.GetGzipMinBytes(), invalid)
+	}
+}
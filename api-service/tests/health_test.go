@@ -0,0 +1,143 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/storage"
+	"digital-recipes/api-service/buildinfo"
+	"digital-recipes/api-service/handlers"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHealthReportsBuildInfo(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// Simulate values injected with -ldflags "-X"
+	original := buildinfo.Get()
+	buildinfo.Version, buildinfo.Commit, buildinfo.BuildTime = "1.2.3-test", "abc1234", "2024-05-01T12:00:00Z"
+	defer func() {
+		buildinfo.Version, buildinfo.Commit, buildinfo.BuildTime = original.Version, original.Commit, original.BuildTime
+	}()
+
+	database := openScriptedDatabase(t)
+	r := gin.New()
+	r.GET("/health", handlers.NewHealthHandler(database, nil).GetHealth)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/health", nil)
+	r.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var body map[string]string
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, "1.2.3-test", body["version"])
+	assert.Equal(t, "abc1234", body["commit"])
+	assert.Equal(t, "2024-05-01T12:00:00Z", body["build_time"])
+	assert.Equal(t, "healthy", body["database"])
+	assert.Equal(t, "not_configured", body["storage"])
+}
+
+// countingBucket is an in-memory backend that counts storage health checks
+type countingBucket struct {
+	*memoryBucket
+	attrs atomic.Int32
+}
+
+func (b *countingBucket) Attrs(ctx context.Context) (*storage.BucketAttrs, error) {
+	b.attrs.Add(1)
+	return b.memoryBucket.Attrs(ctx)
+}
+
+// countPings returns how many database health checks have run since the database was opened
+func countPings() int {
+	pings := 0
+	for _, query := range scriptedQueries() {
+		if query == "PING" {
+			pings++
+		}
+	}
+	return pings
+}
+
+func TestHealthCachesDependencyChecks(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	database := openScriptedDatabase(t)
+	bucket := &countingBucket{memoryBucket: newMemoryBucket()}
+	handler := handlers.NewHealthHandler(database, handlers.NewStorageServiceWithBackend("test-bucket", bucket))
+	r := gin.New()
+	r.GET("/health", handler.GetHealth)
+
+	get := func() map[string]string {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/health", nil)
+		r.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+		var body map[string]string
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		return body
+	}
+
+	t.Run("Repeated calls within the window reuse the result", func(t *testing.T) {
+		handler.SetCacheTTL(time.Minute)
+		first := get()
+		for i := 0; i < 4; i++ {
+			body := get()
+			assert.Equal(t, first["checked_at"], body["checked_at"])
+		}
+		assert.Equal(t, "healthy", first["database"])
+		assert.Equal(t, "healthy", first["storage"])
+		assert.Equal(t, 1, countPings())
+		assert.Equal(t, int32(1), bucket.attrs.Load())
+	})
+
+	t.Run("Expired results are checked again", func(t *testing.T) {
+		handler.SetCacheTTL(20 * time.Millisecond)
+		pings, attrs := countPings(), bucket.attrs.Load()
+		get()
+		get()
+		assert.Equal(t, pings+1, countPings())
+		assert.Equal(t, attrs+1, bucket.attrs.Load())
+
+		time.Sleep(30 * time.Millisecond)
+		get()
+		assert.Equal(t, pings+2, countPings())
+		assert.Equal(t, attrs+2, bucket.attrs.Load())
+	})
+
+	t.Run("Zero checks on every request", func(t *testing.T) {
+		handler.SetCacheTTL(0)
+		pings := countPings()
+		get()
+		get()
+		assert.Equal(t, pings+2, countPings())
+	})
+}
+
+func TestGetHealthCacheTTL(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		expected time.Duration
+	}{
+		{"Unset uses the default", "", handlers.DefaultHealthCacheSeconds * time.Second},
+		{"Positive value is used", "30", 30 * time.Second},
+		{"Zero disables caching", "0", 0},
+		{"Negative falls back to the default", "-1", handlers.DefaultHealthCacheSeconds * time.Second},
+		{"Garbage falls back to the default", "often", handlers.DefaultHealthCacheSeconds * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("HEALTH_CACHE_SECONDS", tt.value)
+			assert.Equal(t, tt.expected, handlers.GetHealthCacheTTL())
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package tests
+
+import (
+	"testing"
+
+	"digital-recipes/api-service/ingredients"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeIngredientName(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"2 cups All-Purpose Flour", "all-purpose flour"},
+		{"1 tsp. salt", "salt"},
+		{"3 large eggs, beaten", "eggs"},
+		{"½ cup whole milk", "milk"},
+		{"1/2 lb ground beef (80% lean)", "ground beef"},
+		{"2-3 cloves of garlic", "garlic"},
+		{"  Olive   Oil  ", "olive oil"},
+		{"Salt and pepper to taste", "salt and pepper to taste"},
+		{"2 cups", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ingredients.NormalizeName(tt.input))
+		})
+	}
+}
+
+func TestIngredientNameCandidates(t *testing.T) {
+	assert.Equal(t, []string{"eggs", "egg"}, ingredients.NameCandidates("eggs"))
+	assert.Equal(t, []string{"cherries", "cherry"}, ingredients.NameCandidates("cherries"))
+	assert.Equal(t, []string{"tomatoes", "tomato"}, ingredients.NameCandidates("tomatoes"))
+	assert.Equal(t, []string{"watercress"}, ingredients.NameCandidates("watercress"))
+	assert.Equal(t, []string{"flour"}, ingredients.NameCandidates("flour"))
+}
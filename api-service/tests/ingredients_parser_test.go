@@ -0,0 +1,83 @@
+package tests
+
+import (
+	"testing"
+
+	"digital-recipes/api-service/ingredients"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseIngredientLine(t *testing.T) {
+	quantity := func(v float64) *float64 { return &v }
+	unit := func(u string) *string { return &u }
+
+	tests := []struct {
+		name             string
+		input            string
+		expectedQuantity *float64
+		expectedUnit     *string
+		expectedName     string
+	}{
+		// Whole numbers and decimals
+		{"Whole number with unit", "2 cups all-purpose flour", quantity(2), unit("cup"), "all-purpose flour"},
+		{"Decimal quantity", "1.5 kg potatoes", quantity(1.5), unit("kg"), "potatoes"},
+		{"Quantity without unit", "3 eggs", quantity(3), nil, "eggs"},
+		{"Abbreviated unit with period", "1 tsp. salt", quantity(1), unit("tsp"), "salt"},
+		{"Unit case is ignored", "2 Tablespoons Butter", quantity(2), unit("tbsp"), "Butter"},
+		{"Unit attached to number", "200g dark chocolate", quantity(200), unit("g"), "dark chocolate"},
+		{"Unit followed by of", "2 cloves of garlic", quantity(2), unit("clove"), "garlic"},
+
+		// Fractions
+		{"Simple fraction", "1/2 cup sugar", quantity(0.5), unit("cup"), "sugar"},
+		{"Mixed number", "1 1/2 cups milk", quantity(1.5), unit("cup"), "milk"},
+		{"Fraction without unit", "3/4 onion", quantity(0.75), nil, "onion"},
+		{"Zero denominator is not a quantity", "1/0 cup sugar", nil, nil, "1/0 cup sugar"},
+
+		// Unicode fractions
+		{"Unicode fraction", "½ tsp vanilla extract", quantity(0.5), unit("tsp"), "vanilla extract"},
+		{"Unicode mixed number attached", "1½ cups water", quantity(1.5), unit("cup"), "water"},
+		{"Unicode mixed number spaced", "2 ¼ cups flour", quantity(2.25), unit("cup"), "flour"},
+		{"Unicode eighth", "⅛ tsp cayenne", quantity(0.125), unit("tsp"), "cayenne"},
+
+		// Ranges resolve to the upper bound
+		{"Hyphen range", "2-3 tbsp olive oil", quantity(3), unit("tbsp"), "olive oil"},
+		{"En dash range", "1–2 lbs chicken", quantity(2), unit("lb"), "chicken"},
+		{"Spaced range", "2 - 3 carrots", quantity(3), nil, "carrots"},
+		{"Range with to", "4 to 6 ounces cheese", quantity(6), unit("oz"), "cheese"},
+		{"Fraction range", "1/2-1 cup broth", quantity(1), unit("cup"), "broth"},
+		{"Descending range is not a quantity", "3-2 apples", nil, nil, "3-2 apples"},
+
+		// Plain text leaves quantity and unit nil
+		{"Plain text", "Salt and pepper to taste", nil, nil, "Salt and pepper to taste"},
+		{"Unit word without quantity", "Cups of coffee for serving", nil, unit("cup"), "coffee for serving"},
+		{"Pinch of", "pinch of nutmeg", nil, unit("pinch"), "nutmeg"},
+		{"Unit-like word alone is a name", "Can opener", nil, nil, "Can opener"},
+		{"Empty text", "", nil, nil, ""},
+		{"Only whitespace", "   ", nil, nil, ""},
+		{"Scientific notation is not a quantity", "1e3 grams salt", nil, nil, "1e3 grams salt"},
+		{"Quantity and unit only", "2 cups", quantity(2), unit("cup"), ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotQuantity, gotUnit, gotName := ingredients.ParseIngredientLine(tt.input)
+
+			if tt.expectedQuantity == nil {
+				assert.Nil(t, gotQuantity, "Quantity should be nil")
+			} else {
+				require.NotNil(t, gotQuantity, "Quantity should be parsed")
+				assert.InDelta(t, *tt.expectedQuantity, *gotQuantity, 0.0001)
+			}
+
+			if tt.expectedUnit == nil {
+				assert.Nil(t, gotUnit, "Unit should be nil")
+			} else {
+				require.NotNil(t, gotUnit, "Unit should be parsed")
+				assert.Equal(t, *tt.expectedUnit, *gotUnit)
+			}
+
+			assert.Equal(t, tt.expectedName, gotName)
+		})
+	}
+}
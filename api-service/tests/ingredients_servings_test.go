@@ -0,0 +1,43 @@
+package tests
+
+import (
+	"testing"
+
+	"digital-recipes/api-service/ingredients"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseServings(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected float64
+	}{
+		{"Plain number", "4", 4},
+		{"Surrounding whitespace", "  8 ", 8},
+		{"Number with noun", "4 servings", 4},
+		{"Leading verb", "serves 4", 4},
+		{"Capitalised with colon", "Yield: 6 portions", 6},
+		{"Makes with unit", "Makes 12 cookies", 12},
+		{"Hyphenated range", "4-6", 6},
+		{"Range with en dash", "Serves 4–6", 6},
+		{"Spaced range", "4 to 6 people", 6},
+		{"Spaced hyphen range", "serves 2 - 3", 3},
+		{"Parenthesised", "1 loaf (serves 8)", 1},
+		{"Fraction", "1½ dozen", 1.5},
+		{"Decimal", "2.5", 2.5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			servings := ingredients.ParseServings(tt.input)
+			require.NotNil(t, servings)
+			assert.InDelta(t, tt.expected, *servings, 0.001)
+		})
+	}
+
+	for _, input := range []string{"", "a few", "one loaf", "0", "serves 0", "6-4"} {
+		assert.Nil(t, ingredients.ParseServings(input), input)
+	}
+}
@@ -0,0 +1,53 @@
+package tests
+
+import (
+	"testing"
+
+	"digital-recipes/api-service/ingredients"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConvertQuantity(t *testing.T) {
+	tests := []struct {
+		name     string
+		quantity float64
+		from     string
+		to       string
+		expected float64
+		ok       bool
+	}{
+		{"Same unit", 2, "cup", "cup", 2, true},
+		{"Same counted unit", 3, "clove", "clove", 3, true},
+		{"Tablespoons to cups", 16, "tbsp", "cup", 1, true},
+		{"Teaspoons to tablespoons", 3, "tsp", "tbsp", 1, true},
+		{"Liters to milliliters", 1.5, "l", "ml", 1500, true},
+		{"Pounds to ounces", 1, "lb", "oz", 16, true},
+		{"Kilograms to grams", 0.25, "kg", "g", 250, true},
+		{"Volume to mass is incompatible", 1, "cup", "g", 0, false},
+		{"Counted units do not convert", 1, "clove", "can", 0, false},
+		{"Unknown unit", 1, "handful", "cup", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ingredients.ConvertQuantity(tt.quantity, tt.from, tt.to)
+			assert.Equal(t, tt.ok, ok)
+			if tt.ok {
+				assert.InDelta(t, tt.expected, got, 0.01)
+			}
+		})
+	}
+}
+
+func TestUnitDimension(t *testing.T) {
+	dimension, ok := ingredients.UnitDimension("tbsp")
+	assert.True(t, ok)
+	assert.Equal(t, ingredients.Volume, dimension)
+
+	dimension, ok = ingredients.UnitDimension("oz")
+	assert.True(t, ok)
+	assert.Equal(t, ingredients.Mass, dimension)
+
+	_, ok = ingredients.UnitDimension("pinch")
+	assert.False(t, ok)
+}
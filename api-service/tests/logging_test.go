@@ -0,0 +1,57 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"digital-recipes/api-service/middleware"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewLogFormatter(t *testing.T) {
+	tests := []struct {
+		format    string
+		expected  logrus.Formatter
+		expectErr bool
+	}{
+		{"", &logrus.JSONFormatter{}, false},
+		{"json", &logrus.JSONFormatter{}, false},
+		{" JSON ", &logrus.JSONFormatter{}, false},
+		{"text", &logrus.TextFormatter{}, false},
+		{"Text", &logrus.TextFormatter{}, false},
+		{"xml", &logrus.JSONFormatter{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run("Format "+tt.format, func(t *testing.T) {
+			formatter, err := middleware.NewLogFormatter(tt.format)
+			if tt.expectErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.IsType(t, tt.expected, formatter, "Unknown formats fall back to JSON")
+		})
+	}
+}
+
+func TestOpenLogFileAppends(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "api.log")
+	require.NoError(t, os.WriteFile(path, []byte("existing line\n"), 0640))
+
+	file, err := middleware.OpenLogFile(path)
+	require.NoError(t, err)
+	_, err = file.WriteString("new line\n")
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "existing line\nnew line\n", string(content))
+
+	_, err = middleware.OpenLogFile(filepath.Join(t.TempDir(), "missing", "api.log"))
+	assert.Error(t, err, "Missing directories are not created")
+}
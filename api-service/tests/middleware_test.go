@@ -0,0 +1,698 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"digital-recipes/api-service/middleware"
+	"github.com/gin-gonic/gin"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newBodyLimitRouter creates a router with a single write route behind the body limit
+func newBodyLimitRouter(maxBytes int64) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(middleware.BodyLimitMiddleware(maxBytes))
+	r.POST("/echo", func(c *gin.Context) {
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Request body too large"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"size": len(body)})
+	})
+	r.GET("/echo", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	})
+	return r
+}
+
+func TestBodyLimitMiddleware(t *testing.T) {
+	t.Run("Body within limit is accepted", func(t *testing.T) {
+		r := newBodyLimitRouter(64)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/echo", bytes.NewBufferString(`{"image_count":1}`))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("Declared Content-Length over limit returns 413", func(t *testing.T) {
+		r := newBodyLimitRouter(64)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/echo", bytes.NewBufferString(strings.Repeat("a", 65)))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+		assert.Contains(t, w.Body.String(), "Request body too large")
+	})
+
+	t.Run("Body of unknown length is cut off at the limit", func(t *testing.T) {
+		r := newBodyLimitRouter(64)
+		w := httptest.NewRecorder()
+		// Wrapping the reader hides the length so the request is sent without Content-Length
+		req, _ := http.NewRequest("POST", "/echo", io.MultiReader(strings.NewReader(strings.Repeat("a", 128))))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	})
+
+	t.Run("Read-only methods are not limited", func(t *testing.T) {
+		r := newBodyLimitRouter(1)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/echo", nil)
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+}
+
+func TestRequireJSONMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(middleware.RequireJSONMiddleware())
+	r.POST("/api/v1/recipes/upload-request", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	})
+	r.POST("/api/v1/recipes/:id/duplicate", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	})
+
+	rejected := []struct {
+		name        string
+		contentType string
+	}{
+		{"Plain text", "text/plain"},
+		{"Form encoded", "application/x-www-form-urlencoded"},
+		{"Missing", ""},
+		{"Unparseable", "application/json; charset"},
+	}
+	for _, tt := range rejected {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/api/v1/recipes/upload-request", strings.NewReader(`{"image_count":1}`))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+			var response map[string]interface{}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, "Content-Type must be application/json", response["error"])
+		})
+	}
+
+	for _, contentType := range []string{"application/json", "application/json; charset=utf-8", "Application/JSON", "application/ld+json"} {
+		t.Run(contentType, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/api/v1/recipes/upload-request", strings.NewReader(`{"image_count":1}`))
+			req.Header.Set("Content-Type", contentType)
+			r.ServeHTTP(w, req)
+			assert.Equal(t, http.StatusOK, w.Code)
+		})
+	}
+
+	t.Run("Request without a body", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/v1/recipes/5/duplicate", nil)
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+}
+
+func TestAuthMiddlewareRoleClaim(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	config := &middleware.AuthConfig{
+		JWTSecret:     "test-secret-that-is-at-least-32-characters-long",
+		TokenDuration: time.Hour,
+		Issuer:        "digital-recipes-test",
+	}
+
+	r := gin.New()
+	r.Use(middleware.AuthMiddleware(config))
+	r.GET("/whoami", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{
+			"user_id":  middleware.GetUserID(c),
+			"role":     middleware.GetUserRole(c),
+			"is_admin": middleware.IsAdmin(c),
+		})
+	})
+
+	t.Run("Admin role is exposed in context", func(t *testing.T) {
+		token, err := middleware.GenerateTokenWithRole(config, 7, "admin@example.com", "Admin", middleware.RoleAdmin)
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/whoami", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		r.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"user_id": 7, "role": "admin", "is_admin": true}`, w.Body.String())
+	})
+
+	t.Run("Tokens without a role default to user", func(t *testing.T) {
+		token, err := middleware.GenerateToken(config, 8, "user@example.com", "User")
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/whoami", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		r.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"user_id": 8, "role": "user", "is_admin": false}`, w.Body.String())
+	})
+}
+
+// TestAuthMiddlewareHeaderFormats tests the bearer scheme is matched case-insensitively with extra whitespace allowed
+func TestAuthMiddlewareHeaderFormats(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	config := &middleware.AuthConfig{
+		JWTSecret:     "test-secret-that-is-at-least-32-characters-long",
+		TokenDuration: time.Hour,
+		Issuer:        "digital-recipes-test",
+	}
+
+	r := gin.New()
+	r.Use(middleware.AuthMiddleware(config))
+	r.GET("/whoami", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"user_id": middleware.GetUserID(c)})
+	})
+
+	token, err := middleware.GenerateToken(config, 7, "user@example.com", "User")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name          string
+		authorization string
+		expectedCode  int
+		expectedError string
+	}{
+		{"Canonical header", "Bearer " + token, http.StatusOK, ""},
+		{"Lowercase scheme", "bearer " + token, http.StatusOK, ""},
+		{"Uppercase scheme", "BEARER " + token, http.StatusOK, ""},
+		{"Extra spaces between scheme and token", "Bearer   " + token, http.StatusOK, ""},
+		{"Surrounding whitespace and a tab", "  Bearer\t" + token + "  ", http.StatusOK, ""},
+		{"Other scheme", "Basic " + token, http.StatusUnauthorized, "Invalid authorization header format"},
+		{"Scheme without token", "Bearer   ", http.StatusUnauthorized, "Invalid authorization header format"},
+		{"Token without scheme", token, http.StatusUnauthorized, "Invalid authorization header format"},
+		{"Scheme joined to token", "Bearer" + token, http.StatusUnauthorized, "Invalid authorization header format"},
+		{"Trailing data after token", "Bearer " + token + " extra", http.StatusUnauthorized, "Invalid authorization header format"},
+		{"Well-formed header with bad token", "bearer not-a-token", http.StatusUnauthorized, "Invalid token"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/whoami", nil)
+			req.Header.Set("Authorization", tt.authorization)
+			r.ServeHTTP(w, req)
+
+			require.Equal(t, tt.expectedCode, w.Code, w.Body.String())
+			if tt.expectedError == "" {
+				assert.JSONEq(t, `{"user_id": 7}`, w.Body.String())
+				return
+			}
+			var body map[string]interface{}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			assert.Equal(t, tt.expectedError, body["error"])
+		})
+	}
+}
+
+func TestIdentifyUserMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	config := &middleware.AuthConfig{
+		JWTSecret:     "test-secret-that-is-at-least-32-characters-long",
+		TokenDuration: time.Hour,
+		Issuer:        "digital-recipes-test",
+	}
+
+	r := gin.New()
+	r.Use(middleware.IdentifyUserMiddleware(config))
+	r.GET("/whoami", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"user_id": middleware.GetUserID(c)})
+	})
+
+	validToken, err := middleware.GenerateToken(config, 7, "user@example.com", "User")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name          string
+		authorization string
+		expectedBody  string
+	}{
+		{"Valid token identifies the user", "Bearer " + validToken, `{"user_id": 7}`},
+		{"Missing header stays anonymous", "", `{"user_id": 0}`},
+		{"Invalid token stays anonymous", "Bearer not-a-token", `{"user_id": 0}`},
+		{"Malformed header stays anonymous", "Token " + validToken, `{"user_id": 0}`},
+		{"Lowercase scheme with extra spaces identifies the user", "bearer   " + validToken, `{"user_id": 7}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/whoami", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			r.ServeHTTP(w, req)
+
+			require.Equal(t, http.StatusOK, w.Code, "Public routes are never rejected")
+			assert.JSONEq(t, tt.expectedBody, w.Body.String())
+		})
+	}
+}
+
+func TestRequireRole(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	config := &middleware.AuthConfig{
+		JWTSecret:     "test-secret-that-is-at-least-32-characters-long",
+		TokenDuration: time.Hour,
+		Issuer:        "digital-recipes-test",
+	}
+
+	r := gin.New()
+	r.Use(middleware.IdentifyUserMiddleware(config))
+	r.GET("/admin", middleware.RequireRole(middleware.RoleAdmin), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	})
+
+	adminToken, err := middleware.GenerateTokenWithRole(config, 1, "admin@example.com", "Admin", middleware.RoleAdmin)
+	require.NoError(t, err)
+	userToken, err := middleware.GenerateToken(config, 2, "user@example.com", "User")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name          string
+		authorization string
+		expectedCode  int
+	}{
+		{"Admin is allowed", "Bearer " + adminToken, http.StatusOK},
+		{"Regular user is forbidden", "Bearer " + userToken, http.StatusForbidden},
+		{"Anonymous request is unauthorized", "", http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/admin", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+		})
+	}
+}
+
+// TestCreateRecipeRateLimit tests recipe writes past the per-user limit are rejected with retry hints
+func TestCreateRecipeRateLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		if userID, err := strconv.Atoi(c.GetHeader("X-Test-User")); err == nil {
+			c.Set("user_id", userID)
+		}
+		c.Next()
+	})
+	// One limiter applied to several routes shares a single budget
+	limit := middleware.CreateRecipeRateLimit(3)
+	r.POST("/recipes/import", limit, func(c *gin.Context) { c.Status(http.StatusOK) })
+	r.PUT("/recipes/:id", limit, func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	send := func(method, path, user string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, path, nil)
+		req.Header.Set("X-Test-User", user)
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	for i, method := range []string{"POST", "PUT", "POST"} {
+		path := "/recipes/import"
+		if method == "PUT" {
+			path = "/recipes/1"
+		}
+		w := send(method, path, "7")
+		require.Equal(t, http.StatusOK, w.Code, "request %d should be allowed", i+1)
+		assert.Equal(t, "3", w.Header().Get("X-RateLimit-Limit"))
+		assert.Equal(t, strconv.Itoa(2-i), w.Header().Get("X-RateLimit-Remaining"))
+	}
+
+	w := send("PUT", "/recipes/1", "7")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "0", w.Header().Get("X-RateLimit-Remaining"))
+	assert.NotEmpty(t, w.Header().Get("X-RateLimit-Reset"))
+	retryAfter, err := strconv.Atoi(w.Header().Get("Retry-After"))
+	require.NoError(t, err, "Retry-After should be a number of seconds")
+	assert.True(t, retryAfter >= 1 && retryAfter <= 60, "Retry-After %d should fall within the one-minute window", retryAfter)
+
+	// Other users keep their own budget
+	w = send("POST", "/recipes/import", "8")
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestGetRecipeRateLimit(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		expected int
+	}{
+		{"Unset uses the default", "", middleware.DefaultRecipeRateLimit},
+		{"Positive value is used", "50", 50},
+		{"Zero falls back to the default", "0", middleware.DefaultRecipeRateLimit},
+		{"Garbage falls back to the default", "lots", middleware.DefaultRecipeRateLimit},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("RECIPE_RATE_LIMIT", tt.value)
+			assert.Equal(t, tt.expected, middleware.GetRecipeRateLimit())
+		})
+	}
+}
+
+func TestGetMaxUploadRequestBodyBytes(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		expected int64
+	}{
+		{"Unset uses the default", "", middleware.DefaultMaxUploadRequestBodyBytes},
+		{"Positive value is used", "8192", 8192},
+		{"Zero falls back to the default", "0", middleware.DefaultMaxUploadRequestBodyBytes},
+		{"Garbage falls back to the default", "4KB", middleware.DefaultMaxUploadRequestBodyBytes},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("MAX_UPLOAD_REQUEST_BODY_BYTES", tt.value)
+			assert.Equal(t, tt.expected, middleware.GetMaxUploadRequestBodyBytes())
+		})
+	}
+}
+
+// TestRateLimitErrorEnvelope tests rejected requests get the same error shape as handler errors
+func TestRateLimitErrorEnvelope(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	config, err := middleware.NewMemoryRateLimit("1-M")
+	require.NoError(t, err)
+
+	r := gin.New()
+	r.Use(middleware.RequestIDMiddleware())
+	r.Use(middleware.RateLimitMiddleware(config))
+	r.GET("/limited", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	send := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/limited", nil)
+		req.Header.Set("X-Request-ID", "req-limited")
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	require.Equal(t, http.StatusOK, send().Code)
+	w := send()
+	require.Equal(t, http.StatusTooManyRequests, w.Code)
+
+	var body map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, "rate_limit", body["type"])
+	assert.Equal(t, "RATE_LIMIT_EXCEEDED", body["code"])
+	assert.Equal(t, "req-limited", body["request_id"])
+	assert.Contains(t, body["error"], "Too many requests")
+	assert.NotContains(t, body, "retry_after", "The wait is only reported through Retry-After")
+
+	assert.Equal(t, "1", w.Header().Get("X-RateLimit-Limit"))
+	assert.Equal(t, "0", w.Header().Get("X-RateLimit-Remaining"))
+	assert.NotEmpty(t, w.Header().Get("X-RateLimit-Reset"))
+	assert.NotEmpty(t, w.Header().Get("Retry-After"))
+}
+
+// TestRateLimitRequestCorrelation tests a rejected request carries the same request ID in its response and the warning log
+func TestRateLimitRequestCorrelation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	hook := logtest.NewGlobal()
+	defer hook.Reset()
+
+	config, err := middleware.NewMemoryRateLimit("1-M")
+	require.NoError(t, err)
+
+	r := gin.New()
+	r.Use(middleware.RequestIDMiddleware())
+	r.Use(middleware.RateLimitMiddleware(config))
+	r.GET("/limited", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	send := func(requestID string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/limited", nil)
+		if requestID != "" {
+			req.Header.Set("X-Request-ID", requestID)
+		}
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	require.Equal(t, http.StatusOK, send("").Code)
+
+	for _, requestID := range []string{"req-throttled", ""} {
+		hook.Reset()
+		w := send(requestID)
+		require.Equal(t, http.StatusTooManyRequests, w.Code)
+
+		// Without a client-supplied ID the generated one is used throughout
+		expected := w.Header().Get("X-Request-ID")
+		require.NotEmpty(t, expected)
+		if requestID != "" {
+			assert.Equal(t, requestID, expected)
+		}
+
+		var body map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		assert.Equal(t, expected, body["request_id"])
+
+		entry := findLogEntry(hook, "Rate limit exceeded")
+		require.NotNil(t, entry)
+		assert.Equal(t, expected, entry.Data["request_id"])
+		assert.Equal(t, "/limited", entry.Data["path"])
+	}
+}
+
+// TestRateLimitSkipsHealthAndMetrics tests probes and scrapes never count against the limit
+// TestRequestIDMiddlewareValidatesClientIDs tests well-formed client request IDs are kept and others are replaced everywhere
+func TestRequestIDMiddlewareValidatesClientIDs(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	hook := logtest.NewGlobal()
+	defer hook.Reset()
+
+	r := gin.New()
+	r.Use(middleware.RequestIDMiddleware())
+	r.GET("/echo", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"context": middleware.GetRequestID(c), "header": c.GetHeader("X-Request-ID")})
+	})
+	send := func(requestID string) (*httptest.ResponseRecorder, map[string]string) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/echo", nil)
+		req.Header.Set("X-Request-ID", requestID)
+		r.ServeHTTP(w, req)
+		var body map[string]string
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		return w, body
+	}
+
+	for _, requestID := range []string{
+		"3f1c9f7e-8a2b-4c1d-9e0f-1a2b3c4d5e6f",
+		"req-limited",
+		"1-67891233-abcdef0123456789:span.42",
+		strings.Repeat("a", 128),
+	} {
+		w, body := send(requestID)
+		assert.Equal(t, requestID, w.Header().Get("X-Request-ID"))
+		assert.Equal(t, map[string]string{"context": requestID, "header": requestID}, body)
+	}
+	assert.Empty(t, hook.Entries, "Valid IDs are kept without a warning")
+
+	for _, requestID := range []string{
+		"abc\nlevel=error msg=\"forged\"",
+		"<script>alert(1)</script>",
+		"id with spaces",
+		strings.Repeat("a", 129),
+	} {
+		hook.Reset()
+		w, body := send(requestID)
+		generated := w.Header().Get("X-Request-ID")
+		assert.NotEqual(t, requestID, generated)
+		assert.Len(t, generated, 36, "A UUID replaces %q", requestID)
+		assert.Equal(t, map[string]string{"context": generated, "header": generated}, body, "Handlers and logs only see the replacement")
+		require.NotNil(t, hook.LastEntry())
+		assert.Equal(t, "Replaced invalid X-Request-ID", hook.LastEntry().Message)
+		assert.Equal(t, generated, hook.LastEntry().Data["request_id"])
+	}
+}
+
+func TestRateLimitSkipsHealthAndMetrics(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	config, err := middleware.NewMemoryRateLimit("2-M")
+	require.NoError(t, err)
+
+	r := gin.New()
+	r.Use(middleware.RateLimitMiddleware(config))
+	for _, path := range []string{"/health", "/health/ready", "/metrics", "/api/v1/recipes"} {
+		r.GET(path, func(c *gin.Context) { c.Status(http.StatusOK) })
+	}
+
+	send := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", path, nil)
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	for i := 0; i < 20; i++ {
+		for _, path := range []string{"/health", "/health/ready", "/metrics"} {
+			w := send(path)
+			require.Equal(t, http.StatusOK, w.Code, "%s request %d was rate limited", path, i+1)
+			assert.Empty(t, w.Header().Get("X-RateLimit-Limit"), "Skipped paths carry no rate limit headers")
+		}
+	}
+
+	// Other paths still have the whole budget, unaffected by the probes above
+	assert.Equal(t, http.StatusOK, send("/api/v1/recipes").Code)
+	assert.Equal(t, http.StatusOK, send("/api/v1/recipes").Code)
+	assert.Equal(t, http.StatusTooManyRequests, send("/api/v1/recipes").Code)
+}
+
+func TestGetRateLimitSkipPaths(t *testing.T) {
+	t.Setenv("RATE_LIMIT_SKIP_PATHS", "")
+	assert.Equal(t, middleware.DefaultRateLimitSkipPaths, middleware.GetRateLimitSkipPaths())
+
+	t.Setenv("RATE_LIMIT_SKIP_PATHS", " /healthz, /internal/* ,")
+	assert.Equal(t, []string{"/healthz", "/internal/*"}, middleware.GetRateLimitSkipPaths())
+}
+
+// TestConfigureTrustedProxies tests X-Forwarded-For is only honoured from trusted proxies
+func TestConfigureTrustedProxies(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	clientIP := func(t *testing.T, proxies []string, remoteAddr string) string {
+		r := gin.New()
+		require.NoError(t, middleware.ConfigureTrustedProxies(r, proxies))
+		r.GET("/ip", func(c *gin.Context) { c.String(http.StatusOK, c.ClientIP()) })
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/ip", nil)
+		req.RemoteAddr = remoteAddr
+		req.Header.Set("X-Forwarded-For", "203.0.113.7")
+		r.ServeHTTP(w, req)
+		return w.Body.String()
+	}
+
+	tests := []struct {
+		name       string
+		proxies    []string
+		remoteAddr string
+		expected   string
+	}{
+		{"Trusted proxy forwards the client address", []string{"10.0.0.0/8"}, "10.1.2.3:4321", "203.0.113.7"},
+		{"Single trusted proxy IP", []string{"192.0.2.10"}, "192.0.2.10:4321", "203.0.113.7"},
+		{"Untrusted peer cannot spoof its address", []string{"10.0.0.0/8"}, "198.51.100.9:4321", "198.51.100.9"},
+		{"No trusted proxies uses the direct address", nil, "10.1.2.3:4321", "10.1.2.3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, clientIP(t, tt.proxies, tt.remoteAddr))
+		})
+	}
+
+	t.Run("Invalid entries are rejected", func(t *testing.T) {
+		assert.Error(t, middleware.ConfigureTrustedProxies(gin.New(), []string{"not-an-ip"}))
+	})
+}
+
+func TestGetTrustedProxies(t *testing.T) {
+	t.Setenv("TRUSTED_PROXIES", "")
+	assert.Nil(t, middleware.GetTrustedProxies())
+
+	t.Setenv("TRUSTED_PROXIES", "10.0.0.0/8, 192.0.2.10")
+	assert.Equal(t, []string{"10.0.0.0/8", "192.0.2.10"}, middleware.GetTrustedProxies())
+}
+
+// newCORSRouter creates a router with one route behind the CORS middleware
+func newCORSRouter(maxAgeSeconds int) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(middleware.CORSMiddleware("http://localhost:3000, https://recipes.example.com", maxAgeSeconds))
+	r.GET("/recipes", func(c *gin.Context) { c.Status(http.StatusOK) })
+	return r
+}
+
+// TestCORSPreflightMaxAge tests preflight responses carry the configured cache lifetime
+func TestCORSPreflightMaxAge(t *testing.T) {
+	send := func(r *gin.Engine, method, origin string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, "/recipes", nil)
+		req.Header.Set("Origin", origin)
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("Configured value on allowed preflight", func(t *testing.T) {
+		w := send(newCORSRouter(1800), "OPTIONS", "https://recipes.example.com")
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		assert.Equal(t, "https://recipes.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+		assert.Equal(t, "1800", w.Header().Get("Access-Control-Max-Age"))
+	})
+
+	t.Run("Not sent on simple requests", func(t *testing.T) {
+		w := send(newCORSRouter(1800), "GET", "https://recipes.example.com")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "https://recipes.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+		assert.Empty(t, w.Header().Get("Access-Control-Max-Age"))
+	})
+
+	t.Run("Not sent to disallowed origins", func(t *testing.T) {
+		w := send(newCORSRouter(1800), "OPTIONS", "https://evil.example.com")
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Empty(t, w.Header().Get("Access-Control-Max-Age"))
+	})
+}
+
+func TestGetCORSMaxAge(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		expected int
+	}{
+		{"Unset uses the default", "", middleware.DefaultCORSMaxAge},
+		{"Positive value is used", "3600", 3600},
+		{"Zero disables caching", "0", 0},
+		{"Negative falls back to the default", "-1", middleware.DefaultCORSMaxAge},
+		{"Garbage falls back to the default", "forever", middleware.DefaultCORSMaxAge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("CORS_MAX_AGE", tt.value)
+			assert.Equal(t, tt.expected, middleware.GetCORSMaxAge())
+		})
+	}
+	assert.Equal(t, 600, middleware.DefaultCORSMaxAge)
+}
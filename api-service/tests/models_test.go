@@ -0,0 +1,533 @@
+package tests
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"digital-recipes/api-service/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUploadRequestValidateBoundaries(t *testing.T) {
+	tests := []struct {
+		name          string
+		request       models.UploadRequest
+		expectedField string
+		expectedError string
+	}{
+		{
+			name:    "Exactly 5 images is allowed",
+			request: models.UploadRequest{ImageCount: 5},
+		},
+		{
+			name:          "6 images is rejected",
+			request:       models.UploadRequest{ImageCount: 6},
+			expectedField: "image_count",
+			expectedError: "maximum 5 images allowed per upload request",
+		},
+		{
+			name:          "Zero images is rejected",
+			request:       models.UploadRequest{ImageCount: 0},
+			expectedField: "image_count",
+			expectedError: "image count must be positive",
+		},
+		{
+			name:    "Exactly 25MB per image is allowed",
+			request: models.UploadRequest{ImageCount: 4, MaxFileSizeMB: 25},
+		},
+		{
+			name:          "26MB per image is rejected",
+			request:       models.UploadRequest{ImageCount: 1, MaxFileSizeMB: 26},
+			expectedField: "max_file_size_mb",
+			expectedError: "maximum file size is 25MB per image",
+		},
+		{
+			name:          "Total size over 100MB is rejected",
+			request:       models.UploadRequest{ImageCount: 5, MaxFileSizeMB: 25},
+			expectedField: "max_file_size_mb",
+			expectedError: "total upload size cannot exceed 100MB (currently 125MB)",
+		},
+		{
+			name:          "Unsupported type is rejected",
+			request:       models.UploadRequest{ImageCount: 1, AllowedTypes: []string{"image/gif"}},
+			expectedField: "allowed_types",
+			expectedError: "unsupported file type: image/gif. Allowed types: image/jpeg, image/png, image/webp",
+		},
+		{
+			name:    "Four allowed types is allowed",
+			request: models.UploadRequest{ImageCount: 1, AllowedTypes: []string{"image/png", "image/webp", "image/png", "image/jpeg"}},
+		},
+		{
+			name:          "Five allowed types is rejected",
+			request:       models.UploadRequest{ImageCount: 1, AllowedTypes: []string{"image/png", "image/png", "image/png", "image/png", "image/png"}},
+			expectedField: "allowed_types",
+			expectedError: "at most 4 allowed file types may be specified",
+		},
+		{
+			name:          "Both JPEG spellings are rejected",
+			request:       models.UploadRequest{ImageCount: 1, AllowedTypes: []string{"image/jpeg", "image/jpg"}},
+			expectedField: "allowed_types",
+			expectedError: "image/jpeg and image/jpg are the same type; specify only one",
+		},
+		{
+			name:    "Per-image content types within the allowed types",
+			request: models.UploadRequest{ImageCount: 3, AllowedTypes: []string{"image/jpeg", "image/png"}, ContentTypes: []string{"image/png", "image/jpg", "image/jpeg"}},
+		},
+		{
+			name:          "Content types must cover every image",
+			request:       models.UploadRequest{ImageCount: 2, ContentTypes: []string{"image/png"}},
+			expectedField: "content_types",
+			expectedError: "content_types must list one type per image (got 1 for 2 images)",
+		},
+		{
+			name:          "Content type outside the allowed types is rejected",
+			request:       models.UploadRequest{ImageCount: 2, AllowedTypes: []string{"image/jpeg"}, ContentTypes: []string{"image/jpeg", "image/webp"}},
+			expectedField: "content_types",
+			expectedError: "content type image/webp is not in allowed_types",
+		},
+		{
+			name:          "Unsupported content type is rejected",
+			request:       models.UploadRequest{ImageCount: 1, ContentTypes: []string{"image/gif"}},
+			expectedField: "content_types",
+			expectedError: "unsupported file type: image/gif. Allowed types: image/jpeg, image/png, image/webp",
+		},
+		{
+			name:          "Expiration over 24 hours is rejected",
+			request:       models.UploadRequest{ImageCount: 1, ExpirationHours: 25},
+			expectedField: "expiration_hours",
+			expectedError: "expiration time cannot exceed 24 hours",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.request.Validate()
+			if tt.expectedError == "" {
+				assert.NoError(t, err)
+				return
+			}
+
+			require.Error(t, err)
+			var fieldErr *models.FieldError
+			require.True(t, errors.As(err, &fieldErr), "Validate should return a field error")
+			assert.Equal(t, tt.expectedField, fieldErr.Field)
+			assert.Equal(t, tt.expectedError, fieldErr.Message)
+		})
+	}
+}
+
+func TestUploadRequestContentTypeFor(t *testing.T) {
+	request := models.UploadRequest{ImageCount: 2, ContentTypes: []string{"image/png", "image/webp"}}
+	assert.Equal(t, "image/png", request.ContentTypeFor(0))
+	assert.Equal(t, "image/webp", request.ContentTypeFor(1))
+
+	// Without per-image types every image gets the first allowed type
+	request = models.UploadRequest{ImageCount: 2, AllowedTypes: []string{"image/webp", "image/png"}}
+	assert.Equal(t, "image/webp", request.ContentTypeFor(0))
+	assert.Equal(t, "image/webp", request.ContentTypeFor(1))
+}
+
+func TestCanonicalizeContentType(t *testing.T) {
+	assert.Equal(t, "image/jpeg", models.CanonicalizeContentType("image/jpg"))
+	assert.Equal(t, "image/jpeg", models.CanonicalizeContentType(" Image/JPG "))
+	assert.Equal(t, "image/jpeg", models.CanonicalizeContentType("image/jpeg"))
+	assert.Equal(t, "image/png", models.CanonicalizeContentType("IMAGE/PNG"))
+	assert.Equal(t, "application/pdf", models.CanonicalizeContentType("application/pdf"), "Unknown types are left for validation to reject")
+}
+
+func TestUploadRequestFingerprint(t *testing.T) {
+	base := models.UploadRequest{ImageCount: 2, MaxFileSizeMB: 5, AllowedTypes: []string{"image/jpeg", "image/png"}}
+
+	same := []models.UploadRequest{
+		{ImageCount: 2, MaxFileSizeMB: 5, AllowedTypes: []string{"image/png", "image/jpeg"}, ContentTypes: []string{"image/jpeg", "image/jpeg"}},
+		{ImageCount: 2, MaxFileSizeMB: 5, AllowedTypes: []string{"image/jpg", "image/png"}},
+		{ImageCount: 2, MaxFileSizeMB: 5, AllowedTypes: []string{"image/jpeg", "image/png"}, ExpirationHours: 6},
+		{ImageCount: 2, MaxFileSizeMB: 5, AllowedTypes: []string{"image/jpeg", "image/png"}, ContentTypes: []string{"image/jpeg", "image/jpeg"}},
+	}
+	for _, request := range same {
+		assert.Equal(t, base.Fingerprint(), request.Fingerprint(), "%+v", request)
+	}
+
+	different := []models.UploadRequest{
+		{ImageCount: 3, MaxFileSizeMB: 5, AllowedTypes: []string{"image/jpeg", "image/png"}},
+		{ImageCount: 2, MaxFileSizeMB: 10, AllowedTypes: []string{"image/jpeg", "image/png"}},
+		{ImageCount: 2, MaxFileSizeMB: 5, AllowedTypes: []string{"image/jpeg"}},
+		{ImageCount: 2, MaxFileSizeMB: 5, AllowedTypes: []string{"image/png", "image/jpeg"}},
+		{ImageCount: 2, MaxFileSizeMB: 5, AllowedTypes: []string{"image/jpeg", "image/png"}, ContentTypes: []string{"image/jpeg", "image/png"}},
+	}
+	for _, request := range different {
+		assert.NotEqual(t, base.Fingerprint(), request.Fingerprint(), "%+v", request)
+	}
+
+	// Omitted sizes and types fingerprint the same as their defaults
+	assert.Equal(t,
+		(&models.UploadRequest{ImageCount: 1}).Fingerprint(),
+		(&models.UploadRequest{ImageCount: 1, MaxFileSizeMB: models.DefaultFileSizeMB, AllowedTypes: []string{"image/jpeg", "image/png", "image/webp"}}).Fingerprint())
+}
+
+func TestUploadRequestValidateDeduplicatesAllowedTypes(t *testing.T) {
+	request := models.UploadRequest{ImageCount: 1, AllowedTypes: []string{"image/png", "image/webp", "image/png", "image/webp"}}
+	require.NoError(t, request.Validate())
+	assert.Equal(t, []string{"image/png", "image/webp"}, request.AllowedTypes)
+	assert.Equal(t, []string{"image/png", "image/webp"}, request.GetAllowedTypes())
+
+	// Omitted types keep the defaults rather than being filled in
+	request = models.UploadRequest{ImageCount: 1}
+	require.NoError(t, request.Validate())
+	assert.Nil(t, request.AllowedTypes)
+}
+
+func TestRecipePatchApply(t *testing.T) {
+	servings, instructions, tips := "4", "Stir.", "Serve warm."
+	current := models.Recipe{ID: 1, Title: "Soup", Servings: &servings, Instructions: &instructions, Tips: &tips, Status: "published"}
+
+	t.Run("Only the title changes", func(t *testing.T) {
+		title := "Better Soup"
+		patch := models.RecipePatch{Title: &title}
+		require.False(t, patch.IsEmpty())
+
+		input := patch.Apply(current)
+		assert.Equal(t, "Better Soup", input.Title)
+		assert.Equal(t, &servings, input.Servings)
+		assert.Equal(t, &instructions, input.Instructions)
+		assert.Equal(t, &tips, input.Tips)
+		assert.Equal(t, "published", input.Status)
+		assert.Nil(t, input.Ingredients, "Ingredients are left untouched")
+	})
+
+	t.Run("Only the tips change", func(t *testing.T) {
+		empty := ""
+		input := models.RecipePatch{Tips: &empty}.Apply(current)
+		require.NotNil(t, input.Tips)
+		assert.Equal(t, "", *input.Tips)
+		assert.Equal(t, "Soup", input.Title)
+		assert.Equal(t, &instructions, input.Instructions)
+	})
+
+	t.Run("Times and difficulty", func(t *testing.T) {
+		prep, difficulty := 20, "hard"
+		withTimes := current
+		cook := 45
+		withTimes.CookTimeMinutes = &cook
+
+		patch := models.RecipePatch{PrepTimeMinutes: &prep, Difficulty: &difficulty}
+		require.False(t, patch.IsEmpty())
+		input := patch.Apply(withTimes)
+		assert.Equal(t, &prep, input.PrepTimeMinutes)
+		assert.Equal(t, &cook, input.CookTimeMinutes, "Unsent times keep their values")
+		assert.Equal(t, &difficulty, input.Difficulty)
+	})
+
+	t.Run("Nothing to change", func(t *testing.T) {
+		assert.True(t, models.RecipePatch{}.IsEmpty())
+	})
+}
+
+func TestNormalizeEmail(t *testing.T) {
+	assert.Equal(t, "user@example.com", models.NormalizeEmail("User@Example.com"))
+	assert.Equal(t, "user@example.com", models.NormalizeEmail("  user@EXAMPLE.com\n"))
+	assert.Equal(t, models.NormalizeEmail("User@Example.com"), models.NormalizeEmail("user@example.com"))
+}
+
+func TestValidateEmail(t *testing.T) {
+	for _, email := range []string{"user@example.com", "first.last+recipes@mail.example.co.uk", "o'brien@example.ie"} {
+		assert.NoError(t, models.ValidateEmail(email), email)
+	}
+
+	for _, email := range []string{
+		"",
+		"plainaddress",
+		"@example.com",
+		"user@",
+		"user@@example.com",
+		"user@localhost",
+		"user@.example.com",
+		"user@example.com.",
+		"User <user@example.com>",
+		"user@example.com (work)",
+		"user name@example.com",
+		strings.Repeat("a", 250) + "@example.com",
+	} {
+		err := models.ValidateEmail(email)
+		require.Error(t, err, email)
+		var fieldErr *models.FieldError
+		require.True(t, errors.As(err, &fieldErr), email)
+		assert.Equal(t, "email", fieldErr.Field)
+	}
+}
+
+func TestValidateSourceURL(t *testing.T) {
+	valid := []string{"", "https://example.com/banana-bread", "http://example.com/recipes?id=4#steps"}
+	for _, sourceURL := range valid {
+		assert.NoError(t, models.ValidateSourceURL(&sourceURL), sourceURL)
+	}
+	assert.NoError(t, models.ValidateSourceURL(nil))
+
+	for _, sourceURL := range []string{"example.com/bread", "/recipes/4", "ftp://example.com/bread", "javascript:alert(1)", "https://"} {
+		err := models.ValidateSourceURL(&sourceURL)
+		require.Error(t, err, sourceURL)
+		var fieldErr *models.FieldError
+		require.True(t, errors.As(err, &fieldErr), sourceURL)
+		assert.Equal(t, "source_url", fieldErr.Field)
+	}
+}
+
+func TestRecipeInputValidate(t *testing.T) {
+	assert.Empty(t, models.RecipeInput{Title: "Soup"}.Validate())
+
+	sourceURL := "example.com/soup"
+	ingredients := []models.IngredientInput{{OriginalText: "1 onion"}, {OriginalText: "  "}}
+	errs := models.RecipeInput{Title: " ", Ingredients: &ingredients, SourceURL: &sourceURL}.Validate()
+	assert.Equal(t, models.FieldErrors{
+		{Field: "title", Message: "title is required"},
+		{Field: "ingredients[1].original_text", Message: "original_text cannot be empty"},
+		{Field: "source_url", Message: "source_url must be an absolute http or https URL"},
+	}, errs)
+
+	// Patches only check the fields they send
+	assert.Empty(t, models.RecipePatch{SourceURL: new(string)}.Validate())
+	assert.Len(t, models.RecipePatch{Title: new(string), Ingredients: &ingredients}.Validate(), 2)
+
+	merged := models.FieldErrors{{Field: "title", Message: "title is required"}}.Merge(errs)
+	assert.Equal(t, errs, merged, "Merge keeps one message per field")
+}
+
+func TestValidateTitle(t *testing.T) {
+	tests := []struct {
+		name    string
+		title   string
+		wantErr string
+	}{
+		{"Ordinary title", "Pancakes", ""},
+		{"At the limit", strings.Repeat("a", models.MaxTitleLength), ""},
+		{"Limit counts characters, not bytes", strings.Repeat("é", models.MaxTitleLength), ""},
+		{"Surrounding whitespace does not count", "  " + strings.Repeat("a", models.MaxTitleLength) + "\n", ""},
+		{"One over the limit", strings.Repeat("a", models.MaxTitleLength+1), "title must be at most 200 characters"},
+		{"Empty", "", "title cannot be empty"},
+		{"Only whitespace", " \t ", "title cannot be empty"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := models.ValidateTitle(tt.title)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+
+	// Full updates keep reporting a missing title as required
+	long := strings.Repeat("a", models.MaxTitleLength+1)
+	assert.Equal(t, models.FieldErrors{{Field: "title", Message: "title must be at most 200 characters"}}, models.RecipeInput{Title: long}.Validate())
+	assert.Equal(t, models.FieldErrors{{Field: "title", Message: "title must be at most 200 characters"}}, models.RecipePatch{Title: &long}.Validate())
+}
+
+func TestParseJSONLDRecipe(t *testing.T) {
+	t.Run("String instructions and numeric yield", func(t *testing.T) {
+		document, err := models.ParseJSONLDRecipe([]byte(`{
+			"@context": "https://schema.org",
+			"@type": "Recipe",
+			"name": "Simple Toast",
+			"recipeYield": 2,
+			"recipeIngredient": ["2 slices bread", " ", "1 tbsp butter"],
+			"recipeInstructions": "Toast the bread. Spread with butter."
+		}`))
+		require.NoError(t, err)
+		assert.Equal(t, "Simple Toast", document.Name)
+		require.NotNil(t, document.Yield())
+		assert.Equal(t, "2", *document.Yield())
+		assert.Equal(t, []string{"2 slices bread", "1 tbsp butter"}, document.Ingredients())
+		require.NotNil(t, document.Instructions())
+		assert.Equal(t, "Toast the bread. Spread with butter.", *document.Instructions())
+	})
+
+	t.Run("Array instructions with steps and sections", func(t *testing.T) {
+		document, err := models.ParseJSONLDRecipe([]byte(`{
+			"@type": ["Recipe", "NewsArticle"],
+			"name": "Layered Cake",
+			"recipeYield": ["8", "8 slices"],
+			"recipeInstructions": [
+				"Preheat the oven.",
+				{"@type": "HowToStep", "text": "Mix the batter."},
+				{"@type": "HowToSection", "name": "Frosting", "itemListElement": [
+					{"@type": "HowToStep", "text": "Whip the cream."},
+					{"@type": "HowToStep", "name": "Spread evenly."}
+				]}
+			]
+		}`))
+		require.NoError(t, err)
+		require.NotNil(t, document.Yield())
+		assert.Equal(t, "8", *document.Yield())
+		require.NotNil(t, document.Instructions())
+		assert.Equal(t, "Preheat the oven.\nMix the batter.\nFrosting:\nWhip the cream.\nSpread evenly.", *document.Instructions())
+	})
+
+	t.Run("Recipe inside @graph", func(t *testing.T) {
+		document, err := models.ParseJSONLDRecipe([]byte(`{
+			"@context": "https://schema.org",
+			"@graph": [
+				{"@type": "WebPage", "name": "Blog"},
+				{"@type": "Recipe", "name": "Graph Soup"}
+			]
+		}`))
+		require.NoError(t, err)
+		assert.Equal(t, "Graph Soup", document.Name)
+		assert.Nil(t, document.Instructions())
+		assert.Nil(t, document.Yield())
+	})
+
+	t.Run("Prep and cook durations", func(t *testing.T) {
+		minutes := func(n int) *int { return &n }
+		durations := []struct {
+			value    string
+			expected *int
+		}{
+			{"PT15M", minutes(15)},
+			{"PT1H30M", minutes(90)},
+			{"pt2h", minutes(120)},
+			{"P1DT2H", minutes(26 * 60)},
+			{"PT10M30S", minutes(10)},
+			{"PT0M", minutes(0)},
+			{"", nil},
+			{"PT", nil},
+			{"15 minutes", nil},
+			{"P8D", nil},
+		}
+		for _, tt := range durations {
+			document := models.JSONLDRecipeDocument{PrepTime: tt.value, CookTime: tt.value}
+			assert.Equal(t, tt.expected, document.PrepTimeMinutes(), tt.value)
+			assert.Equal(t, tt.expected, document.CookTimeMinutes(), tt.value)
+		}
+	})
+
+	t.Run("Author and source URL", func(t *testing.T) {
+		stringPtr := func(s string) *string { return &s }
+		authors := []struct {
+			author   string
+			expected *string
+		}{
+			{`"Jane Baker"`, stringPtr("Jane Baker")},
+			{`{"@type": "Person", "name": " Jane Baker "}`, stringPtr("Jane Baker")},
+			{`[{"@type": "Person", "name": "Jane"}, "Sam", {"@type": "Organization", "name": "Test Kitchen"}]`, stringPtr("Jane, Sam, Test Kitchen")},
+			{`{"@type": "Person", "@id": "https://example.com/#jane"}`, nil},
+			{`""`, nil},
+		}
+		for _, tt := range authors {
+			document, err := models.ParseJSONLDRecipe([]byte(`{"@type": "Recipe", "name": "Bread", "author": ` + tt.author + `}`))
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, document.SourceName(), tt.author)
+		}
+
+		long := models.JSONLDRecipeDocument{Author: json.RawMessage(`"` + strings.Repeat("é", 300) + `"`)}
+		require.NotNil(t, long.SourceName())
+		assert.Equal(t, models.MaxSourceNameLength, utf8.RuneCountInString(*long.SourceName()))
+
+		urls := []struct {
+			url      string
+			expected *string
+		}{
+			{"https://example.com/banana-bread", stringPtr("https://example.com/banana-bread")},
+			{" http://example.com/bread ", stringPtr("http://example.com/bread")},
+			{"/recipes/banana-bread", nil},
+			{"javascript:alert(1)", nil},
+			{"", nil},
+		}
+		for _, tt := range urls {
+			document := models.JSONLDRecipeDocument{URL: tt.url}
+			assert.Equal(t, tt.expected, document.SourceURL(), tt.url)
+		}
+	})
+
+	rejected := []struct {
+		name          string
+		payload       string
+		expectedField string
+	}{
+		{"Non-Recipe type", `{"@type": "Article", "name": "News"}`, "@type"},
+		{"Missing type", `{"name": "Untyped"}`, "@type"},
+		{"Not an object", `["Recipe"]`, "@type"},
+		{"Missing name", `{"@type": "Recipe", "name": "  "}`, "name"},
+		{"Yield too long", `{"@type": "Recipe", "name": "Big", "recipeYield": "` + strings.Repeat("x", 51) + `"}`, "recipeYield"},
+	}
+
+	for _, tt := range rejected {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := models.ParseJSONLDRecipe([]byte(tt.payload))
+			require.Error(t, err)
+			var fieldErr *models.FieldError
+			require.True(t, errors.As(err, &fieldErr))
+			assert.Equal(t, tt.expectedField, fieldErr.Field)
+		})
+	}
+}
+
+func TestChangePasswordRequestValidate(t *testing.T) {
+	tests := []struct {
+		name          string
+		request       models.ChangePasswordRequest
+		expectedError string
+	}{
+		{
+			name:    "Letters and digits of sufficient length are allowed",
+			request: models.ChangePasswordRequest{CurrentPassword: "old-pass1", NewPassword: "tomato42"},
+		},
+		{
+			name:          "Short passwords are rejected",
+			request:       models.ChangePasswordRequest{CurrentPassword: "old-pass1", NewPassword: "tom4to"},
+			expectedError: "password must be at least 8 characters",
+		},
+		{
+			name:          "Passwords over 72 bytes are rejected",
+			request:       models.ChangePasswordRequest{CurrentPassword: "old-pass1", NewPassword: strings.Repeat("a1", 37)},
+			expectedError: "password must be at most 72 bytes",
+		},
+		{
+			name:          "Letters only are rejected",
+			request:       models.ChangePasswordRequest{CurrentPassword: "old-pass1", NewPassword: "tomatosoup"},
+			expectedError: "password must contain at least one letter and one digit",
+		},
+		{
+			name:          "Digits only are rejected",
+			request:       models.ChangePasswordRequest{CurrentPassword: "old-pass1", NewPassword: "12345678"},
+			expectedError: "password must contain at least one letter and one digit",
+		},
+		{
+			name:          "Reusing the current password is rejected",
+			request:       models.ChangePasswordRequest{CurrentPassword: "tomato42", NewPassword: "tomato42"},
+			expectedError: "new password must differ from the current password",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.request.Validate()
+			if tt.expectedError == "" {
+				assert.NoError(t, err)
+				return
+			}
+
+			var fieldErr *models.FieldError
+			require.True(t, errors.As(err, &fieldErr), "Validate should return a field error")
+			assert.Equal(t, "new_password", fieldErr.Field)
+			assert.Equal(t, tt.expectedError, fieldErr.Message)
+		})
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	assert.Nil(t, models.FormatDuration(nil))
+	for minutes, expected := range map[int]string{0: "PT0M", 45: "PT45M", 60: "PT1H", 90: "PT1H30M", 26 * 60: "PT26H"} {
+		formatted := models.FormatDuration(&minutes)
+		require.NotNil(t, formatted)
+		assert.Equal(t, expected, *formatted)
+
+		parsed := models.JSONLDRecipeDocument{PrepTime: expected}
+		assert.Equal(t, &minutes, parsed.PrepTimeMinutes(), "Exported durations import back unchanged")
+	}
+}
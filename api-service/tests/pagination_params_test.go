@@ -0,0 +1,243 @@
+package tests
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"digital-recipes/api-service/handlers"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPaginationParameterErrors tests each bad pagination parameter is named in the error field
+func TestPaginationParameterErrors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	// Pagination is validated before any database access
+	router.GET("/api/v1/recipes", handlers.NewRecipeHandler(nil, nil).GetRecipes)
+
+	tests := []struct {
+		query string
+		field string
+	}{
+		{"page=0", "page"},
+		{"page=-1", "page"},
+		{"page=1.5", "page"},
+		{"page=99999999999999999999", "page"},
+		{"page=abc", "page"},
+		{"per_page=0", "per_page"},
+		{"per_page=200", "per_page"},
+		{"per_page=ten", "per_page"},
+		{"page=2&per_page=500", "per_page"},
+		{"page=0&per_page=500", "page"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/api/v1/recipes?"+tt.query, nil)
+			router.ServeHTTP(w, req)
+
+			require.Equal(t, http.StatusBadRequest, w.Code)
+			var response map[string]interface{}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.field, response["field"])
+			assert.Equal(t, "validation", response["type"])
+			assert.Contains(t, response["error"], "invalid "+tt.field+" parameter")
+		})
+	}
+}
+
+// TestRecipesPagePastTheEnd tests pages past the last one, even beyond the queried range, are empty rather than rejected
+func TestRecipesPagePastTheEnd(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	database := openScriptedDatabase(t,
+		scriptedResult{
+			match:   "total_count",
+			columns: []string{"id", "title", "servings", "instructions", "tips", "status", "user_id", "created_at", "updated_at", "prep_time_minutes", "cook_time_minutes", "difficulty", "source_name", "source_url", "last_modified_by", "visibility", "featured", "featured_at", "ingredient_count", "has_images", "total_count"},
+		},
+		scriptedResult{match: "SELECT COUNT(*)", columns: []string{"count"}, rows: [][]driver.Value{{int64(23)}}},
+	)
+	// Without an offset ceiling only the page ceiling bounds how deep a page can be
+	recipeHandler := handlers.NewRecipeHandler(database, nil)
+	recipeHandler.SetPagination(handlers.PaginationConfig{DefaultPerPage: 10, MaxPerPage: 100})
+	router := gin.New()
+	router.GET("/api/v1/recipes", recipeHandler.GetRecipes)
+
+	for _, page := range []int{4, 20000} {
+		t.Run(fmt.Sprintf("page=%d", page), func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", fmt.Sprintf("/api/v1/recipes?page=%d&per_page=10", page), nil)
+			router.ServeHTTP(w, req)
+			require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+			var response struct {
+				Data       []interface{}       `json:"data"`
+				Pagination handlers.Pagination `json:"pagination"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.NotNil(t, response.Data)
+			assert.Empty(t, response.Data)
+			assert.Equal(t, handlers.Pagination{Page: page, PerPage: 10, Total: 23, TotalPages: 3}, response.Pagination)
+		})
+	}
+
+	// The page past the end runs the list and count queries; the page beyond the ceiling only counts
+	queries := scriptedQueries()
+	require.Len(t, queries, 3)
+	assert.Contains(t, queries[0], "OFFSET")
+	assert.NotContains(t, queries[2], "OFFSET")
+
+	// A page beyond the ceiling that does hold recipes is refused rather than answered empty
+	database = openScriptedDatabase(t, scriptedResult{match: "SELECT COUNT(*)", columns: []string{"count"}, rows: [][]driver.Value{{int64(300000)}}})
+	recipeHandler = handlers.NewRecipeHandler(database, nil)
+	recipeHandler.SetPagination(handlers.PaginationConfig{DefaultPerPage: 10, MaxPerPage: 100})
+	router = gin.New()
+	router.GET("/api/v1/recipes", recipeHandler.GetRecipes)
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v1/recipes?page=20000&per_page=10", nil)
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "OFFSET_TOO_LARGE", response["code"])
+	assert.Contains(t, response["error"], "created_before")
+	assert.Len(t, scriptedQueries(), 1, "Only the count runs")
+}
+
+// TestPageCeilingOnOtherListings tests listings that do not page open-endedly still reject pages beyond the ceiling
+func TestPageCeilingOnOtherListings(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/api/v1/recipes/:id/revisions", handlers.NewRecipeHandler(nil, nil).GetRecipeRevisions)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v1/recipes/5/revisions?page=20000", nil)
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "page", response["field"])
+	assert.Contains(t, response["error"], "between 1 and 10000")
+}
+
+// TestCustomPaginationConfig tests a raised max and default apply while larger pages still 400
+func TestCustomPaginationConfig(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	database := openScriptedDatabase(t, scriptedResult{
+		match:   "FROM recipes",
+		columns: []string{"id", "title", "servings", "instructions", "tips", "status", "user_id", "created_at", "updated_at", "prep_time_minutes", "cook_time_minutes", "difficulty", "source_name", "source_url", "last_modified_by", "visibility", "featured", "featured_at", "ingredient_count", "has_images", "total_count"},
+	})
+	recipeHandler := handlers.NewRecipeHandler(database, nil)
+	recipeHandler.SetPagination(handlers.PaginationConfig{DefaultPerPage: 25, MaxPerPage: 250})
+
+	router := gin.New()
+	router.GET("/api/v1/recipes", recipeHandler.GetRecipes)
+	get := func(query string) (*httptest.ResponseRecorder, map[string]interface{}) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/v1/recipes"+query, nil)
+		router.ServeHTTP(w, req)
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return w, response
+	}
+
+	w, response := get("?per_page=200")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, float64(200), response["pagination"].(map[string]interface{})["per_page"])
+
+	w, response = get("")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, float64(25), response["pagination"].(map[string]interface{})["per_page"], "Configured default applies")
+
+	w, response = get("?per_page=251")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "per_page", response["field"])
+	assert.Contains(t, response["error"], "between 1 and 250")
+}
+
+// TestPaginationOffsetCeiling tests pages reaching past the configured offset are rejected before any query
+func TestPaginationOffsetCeiling(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	database := openScriptedDatabase(t,
+		scriptedResult{
+			match:   "total_count",
+			columns: []string{"id", "title", "servings", "instructions", "tips", "status", "user_id", "created_at", "updated_at", "prep_time_minutes", "cook_time_minutes", "difficulty", "source_name", "source_url", "last_modified_by", "visibility", "featured", "featured_at", "ingredient_count", "has_images", "total_count"},
+		},
+		scriptedResult{match: "SELECT COUNT(*)", columns: []string{"count"}, rows: [][]driver.Value{{int64(1500)}}},
+	)
+	recipeHandler := handlers.NewRecipeHandler(database, nil)
+	recipeHandler.SetPagination(handlers.PaginationConfig{DefaultPerPage: 10, MaxPerPage: 100, MaxOffset: 1000})
+
+	router := gin.New()
+	router.GET("/api/v1/recipes", recipeHandler.GetRecipes)
+	router.GET("/api/v1/recipes/:id/revisions", recipeHandler.GetRecipeRevisions)
+	get := func(path string) (*httptest.ResponseRecorder, map[string]interface{}) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", path, nil)
+		router.ServeHTTP(w, req)
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return w, response
+	}
+
+	// page 11 of 100 skips exactly the ceiling
+	w, _ := get("/api/v1/recipes?page=11&per_page=100")
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.Len(t, scriptedQueries(), 2, "The page at the ceiling is listed and counted")
+
+	// Pages beyond the page ceiling are refused like any other page past the offset ceiling
+	for _, path := range []string{"/api/v1/recipes?page=12&per_page=100", "/api/v1/recipes?page=102", "/api/v1/recipes?page=10001&per_page=100", "/api/v1/recipes/5/revisions?page=102"} {
+		w, response := get(path)
+		require.Equal(t, http.StatusBadRequest, w.Code, path)
+		assert.Equal(t, "OFFSET_TOO_LARGE", response["code"], path)
+		assert.Equal(t, "page", response["field"], path)
+		assert.Contains(t, response["error"], "more than the 1000 that can be paged through", path)
+	}
+	_, response := get("/api/v1/recipes?page=102")
+	assert.Contains(t, response["error"], "with created_before instead", "Recipe listings point at keyset paging")
+	_, response = get("/api/v1/recipes/5/revisions?page=102")
+	assert.Contains(t, response["error"], "Narrow the results with filters instead")
+	assert.Len(t, scriptedQueries(), 2, "Pages past the ceiling are not queried")
+
+	// Counting alone skips no rows, so it stays available at any depth
+	w, _ = get("/api/v1/recipes?page=500&count_only=true")
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+}
+
+func TestGetPaginationConfig(t *testing.T) {
+	t.Setenv("PAGINATION_DEFAULT", "")
+	t.Setenv("PAGINATION_MAX", "")
+	config, err := handlers.GetPaginationConfig()
+	require.NoError(t, err)
+	assert.Equal(t, handlers.DefaultPaginationConfig(), config)
+	assert.Equal(t, 10, config.DefaultPerPage)
+	assert.Equal(t, 100, config.MaxPerPage)
+
+	t.Setenv("PAGINATION_DEFAULT", "50")
+	t.Setenv("PAGINATION_MAX", "500")
+	config, err = handlers.GetPaginationConfig()
+	require.NoError(t, err)
+	assert.Equal(t, handlers.PaginationConfig{DefaultPerPage: 50, MaxPerPage: 500, MaxOffset: handlers.DefaultMaxOffset}, config)
+
+	t.Setenv("PAGINATION_MAX_OFFSET", "5000")
+	config, err = handlers.GetPaginationConfig()
+	require.NoError(t, err)
+	assert.Equal(t, 5000, config.MaxOffset)
+	t.Setenv("PAGINATION_MAX_OFFSET", "")
+
+	t.Setenv("PAGINATION_MAX", "lots")
+	config, err = handlers.GetPaginationConfig()
+	require.NoError(t, err, "Unparseable values fall back to the built-in bound")
+	assert.Equal(t, 100, config.MaxPerPage)
+
+	t.Setenv("PAGINATION_DEFAULT", "200")
+	t.Setenv("PAGINATION_MAX", "100")
+	_, err = handlers.GetPaginationConfig()
+	assert.Error(t, err, "Default above max is rejected")
+}
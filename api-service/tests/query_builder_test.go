@@ -0,0 +1,119 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"digital-recipes/api-service/handlers"
+	"github.com/stretchr/testify/assert"
+)
+
+// normalizeSQL collapses whitespace so queries can be compared independent of formatting
+func normalizeSQL(query string) string {
+	return strings.Join(strings.Fields(query), " ")
+}
+
+func TestRecipesCountQueryBuilder(t *testing.T) {
+	t.Run("Without filters", func(t *testing.T) {
+		query, args := handlers.NewRecipesCountQueryBuilder().Build()
+
+		assert.Equal(t, "SELECT COUNT(*) FROM recipes", normalizeSQL(query))
+		assert.Empty(t, args)
+	})
+
+	t.Run("With status filter", func(t *testing.T) {
+		query, args := handlers.NewRecipesCountQueryBuilder().WithStatus("published").Build()
+
+		assert.Equal(t, "SELECT COUNT(*) FROM recipes WHERE status = $1", normalizeSQL(query))
+		assert.Equal(t, []interface{}{"published"}, args)
+	})
+
+	t.Run("Ignores unknown status", func(t *testing.T) {
+		query, args := handlers.NewRecipesCountQueryBuilder().WithStatus("bogus").Build()
+
+		assert.NotContains(t, query, "WHERE")
+		assert.Empty(t, args)
+	})
+}
+
+func TestRecipesQueryBuilderCompleteness(t *testing.T) {
+	query, args := handlers.NewRecipesQueryBuilder().WithStatus("published").WithPagination(10, 20).Build()
+	normalized := normalizeSQL(query)
+
+	assert.Contains(t, normalized, "(SELECT COUNT(*) FROM recipe_ingredients ri WHERE ri.recipe_id = recipes.id) as ingredient_count")
+	assert.Contains(t, normalized, "EXISTS (SELECT 1 FROM recipe_images img WHERE img.recipe_id = recipes.id) as has_images")
+	assert.Contains(t, normalized, "COUNT(*) OVER() as total_count", "Window total is kept")
+	assert.Contains(t, normalized, "WHERE status = $1")
+	assert.Equal(t, "published", args[0])
+}
+
+func TestQueryBuilderWhereConditions(t *testing.T) {
+	qb := handlers.NewQueryBuilder("SELECT id, (SELECT COUNT(*) FROM tags WHERE tags.item_id = items.id) FROM items")
+	qb.AddWhereCondition("status", "published")
+	qb.AddWhereCondition("user_id", 7)
+	query, args := qb.Build()
+
+	assert.Equal(t, "SELECT id, (SELECT COUNT(*) FROM tags WHERE tags.item_id = items.id) FROM items WHERE status = $1 AND user_id = $2", normalizeSQL(query))
+	assert.Equal(t, []interface{}{"published", 7}, args)
+}
+
+func TestRecipesQueryBuilderCreatedRange(t *testing.T) {
+	after := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	before := time.Date(2024, 1, 7, 23, 59, 59, 0, time.UTC)
+
+	t.Run("Both bounds", func(t *testing.T) {
+		query, args := handlers.NewRecipesCountQueryBuilder().WithStatus("published").WithCreatedRange(&after, &before).Build()
+
+		assert.Equal(t, "SELECT COUNT(*) FROM recipes WHERE status = $1 AND created_at >= $2 AND created_at <= $3", normalizeSQL(query))
+		assert.Equal(t, []interface{}{"published", after, before}, args)
+	})
+
+	t.Run("Open bounds add no conditions", func(t *testing.T) {
+		query, args := handlers.NewRecipesCountQueryBuilder().WithCreatedRange(nil, &before).Build()
+
+		assert.Equal(t, "SELECT COUNT(*) FROM recipes WHERE created_at <= $1", normalizeSQL(query))
+		assert.Equal(t, []interface{}{before}, args)
+	})
+
+	t.Run("Unknown operators are ignored", func(t *testing.T) {
+		qb := handlers.NewQueryBuilder("SELECT id FROM recipes")
+		qb.AddComparison("created_at", "; DROP TABLE recipes; --", after)
+		query, args := qb.Build()
+
+		assert.Equal(t, "SELECT id FROM recipes", query)
+		assert.Empty(t, args)
+	})
+}
+
+func TestRecipesQueryBuilderTimeAndDifficulty(t *testing.T) {
+	t.Run("Both filters", func(t *testing.T) {
+		query, args := handlers.NewRecipesCountQueryBuilder().WithMaxTotalTime(45).WithDifficulty("easy").Build()
+
+		assert.Equal(t, "SELECT COUNT(*) FROM recipes WHERE COALESCE(prep_time_minutes + cook_time_minutes, prep_time_minutes, cook_time_minutes) <= $1 AND difficulty = $2", normalizeSQL(query))
+		assert.Equal(t, []interface{}{45, "easy"}, args)
+	})
+
+	t.Run("Ignores unknown difficulty", func(t *testing.T) {
+		query, args := handlers.NewRecipesCountQueryBuilder().WithDifficulty("expert").Build()
+
+		assert.NotContains(t, query, "WHERE")
+		assert.Empty(t, args)
+	})
+}
+
+func TestRecipesQueryBuilderVisibleTo(t *testing.T) {
+	t.Run("Signed-in users also see their own recipes", func(t *testing.T) {
+		query, args := handlers.NewRecipesCountQueryBuilder().WithVisibleTo(7).WithStatus("published").Build()
+
+		assert.Equal(t, "SELECT COUNT(*) FROM recipes WHERE (visibility = 'public' OR user_id = $1) AND status = $2", normalizeSQL(query))
+		assert.Equal(t, []interface{}{7, "published"}, args)
+	})
+
+	t.Run("Anonymous callers only see public recipes", func(t *testing.T) {
+		query, args := handlers.NewRecipesCountQueryBuilder().WithVisibleTo(0).Build()
+
+		assert.Equal(t, "SELECT COUNT(*) FROM recipes WHERE visibility = $1", normalizeSQL(query))
+		assert.Equal(t, []interface{}{"public"}, args)
+	})
+}
@@ -0,0 +1,144 @@
+package tests
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"os"
+	"testing"
+	"time"
+
+	"digital-recipes/api-service/db"
+	"digital-recipes/api-service/logctx"
+	"github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// delayDriver is a database driver whose queries sleep for the duration named by the DSN
+// and return no rows, standing in for a slow query without a real database
+type delayDriver struct{}
+
+type delayConn struct{ delay time.Duration }
+
+type delayStmt struct{ delay time.Duration }
+
+type emptyRows struct{}
+
+func (delayDriver) Open(name string) (driver.Conn, error) {
+	delay, err := time.ParseDuration(name)
+	if err != nil {
+		return nil, err
+	}
+	return delayConn{delay: delay}, nil
+}
+
+func (c delayConn) Prepare(query string) (driver.Stmt, error) { return delayStmt{delay: c.delay}, nil }
+func (delayConn) Close() error                                { return nil }
+func (delayConn) Begin() (driver.Tx, error)                   { return nil, errors.New("transactions not supported") }
+
+func (delayStmt) Close() error  { return nil }
+func (delayStmt) NumInput() int { return -1 }
+func (delayStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return nil, errors.New("exec not supported")
+}
+func (s delayStmt) Query(args []driver.Value) (driver.Rows, error) {
+	time.Sleep(s.delay)
+	return emptyRows{}, nil
+}
+
+func (emptyRows) Columns() []string              { return []string{"n"} }
+func (emptyRows) Close() error                   { return nil }
+func (emptyRows) Next(dest []driver.Value) error { return io.EOF }
+
+func init() {
+	sql.Register("delay-test", delayDriver{})
+}
+
+// openDelayDatabase opens a database whose queries take the given time
+func openDelayDatabase(t *testing.T, delay string, threshold time.Duration) *db.Database {
+	sqlDB, err := sql.Open("delay-test", delay)
+	require.NoError(t, err)
+	t.Cleanup(func() { sqlDB.Close() })
+	return &db.Database{DB: sqlDB, SlowQueryThreshold: threshold}
+}
+
+func TestQueryContextLogged(t *testing.T) {
+	hook := logtest.NewGlobal()
+	defer hook.Reset()
+	ctx := logctx.WithLogger(context.Background(), logrus.WithField("request_id", "req-slow-1"))
+
+	t.Run("Slow queries log the SQL template and duration", func(t *testing.T) {
+		hook.Reset()
+		database := openDelayDatabase(t, "30ms", 10*time.Millisecond)
+
+		rows, err := database.QueryContextLogged(ctx, "SELECT id\n\t\tFROM recipes\n\t\tWHERE status = $1", "published")
+		require.NoError(t, err)
+		rows.Close()
+
+		entry := findLogEntry(hook, "Slow database query")
+		require.NotNil(t, entry, "Slow query warning should fire")
+		assert.Equal(t, logrus.WarnLevel, entry.Level)
+		assert.Equal(t, "SELECT id FROM recipes WHERE status = $1", entry.Data["query"])
+		assert.GreaterOrEqual(t, entry.Data["duration_ms"], int64(30))
+		assert.Equal(t, int64(10), entry.Data["threshold_ms"])
+		assert.Equal(t, "req-slow-1", entry.Data["request_id"])
+		assert.NotContains(t, entry.Data, "args", "Query arguments are never logged")
+	})
+
+	t.Run("Single-row queries are timed too", func(t *testing.T) {
+		hook.Reset()
+		database := openDelayDatabase(t, "30ms", 10*time.Millisecond)
+
+		var n int
+		err := database.Logged(ctx).QueryRow("SELECT COUNT(*) FROM recipes").Scan(&n)
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		assert.NotNil(t, findLogEntry(hook, "Slow database query"))
+	})
+
+	t.Run("Fast queries are not logged", func(t *testing.T) {
+		hook.Reset()
+		database := openDelayDatabase(t, "0s", time.Second)
+
+		rows, err := database.QueryContextLogged(ctx, "SELECT 1")
+		require.NoError(t, err)
+		rows.Close()
+		assert.Nil(t, findLogEntry(hook, "Slow database query"))
+	})
+
+	t.Run("A zero threshold disables logging", func(t *testing.T) {
+		hook.Reset()
+		database := openDelayDatabase(t, "20ms", 0)
+
+		rows, err := database.QueryContextLogged(ctx, "SELECT 1")
+		require.NoError(t, err)
+		rows.Close()
+		assert.Nil(t, findLogEntry(hook, "Slow database query"))
+	})
+}
+
+func TestGetSlowQueryThreshold(t *testing.T) {
+	original, had := os.LookupEnv("SLOW_QUERY_MS")
+	defer func() {
+		if had {
+			os.Setenv("SLOW_QUERY_MS", original)
+		} else {
+			os.Unsetenv("SLOW_QUERY_MS")
+		}
+	}()
+
+	os.Unsetenv("SLOW_QUERY_MS")
+	assert.Equal(t, db.DefaultSlowQueryThreshold, db.GetSlowQueryThreshold())
+
+	os.Setenv("SLOW_QUERY_MS", "250")
+	assert.Equal(t, 250*time.Millisecond, db.GetSlowQueryThreshold())
+
+	os.Setenv("SLOW_QUERY_MS", "0")
+	assert.Equal(t, time.Duration(0), db.GetSlowQueryThreshold())
+
+	os.Setenv("SLOW_QUERY_MS", "fast")
+	assert.Equal(t, db.DefaultSlowQueryThreshold, db.GetSlowQueryThreshold())
+}
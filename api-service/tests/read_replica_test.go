@@ -0,0 +1,77 @@
+package tests
+
+import (
+	"database/sql/driver"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"digital-recipes/api-service/handlers"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestDatabaseRead tests reads fall back to the primary until a replica is configured
+func TestDatabaseRead(t *testing.T) {
+	database := openScriptedDatabase(t)
+	assert.Same(t, database.DB, database.Read())
+
+	withScriptedReplica(t, database)
+	assert.Same(t, database.ReadDB, database.Read())
+	assert.NotSame(t, database.DB, database.Read())
+}
+
+// TestRecipeReadsUseReplica tests recipe list and detail queries go to the read pool while writes stay on the primary
+func TestRecipeReadsUseReplica(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	now := time.Now()
+	recipeColumns := []string{"id", "title", "servings", "instructions", "tips", "status", "user_id", "created_at", "updated_at", "prep_time_minutes", "cook_time_minutes", "difficulty", "source_name", "source_url", "last_modified_by", "visibility", "featured", "featured_at"}
+	recipeRow := []driver.Value{int64(5), "Pancakes", nil, nil, nil, "published", int64(7), now, now, nil, nil, nil, nil, nil, nil, "public", false, nil}
+	ingredientColumns := []string{"id", "recipe_id", "canonical_ingredient_id", "original_text", "quantity", "unit", "created_at", "updated_at", "canonical_name"}
+
+	// The primary only answers the write path, so a read sent to it fails the request
+	database := openScriptedDatabase(t,
+		scriptedResult{match: "FOR UPDATE", columns: recipeColumns, rows: [][]driver.Value{recipeRow}},
+		scriptedResult{match: "SET title", columns: recipeColumns, rows: [][]driver.Value{recipeRow}},
+		scriptedResult{match: "FROM recipe_ingredients", columns: ingredientColumns},
+		scriptedResult{match: "FROM recipes", columns: recipeColumns, rows: [][]driver.Value{recipeRow}},
+	)
+	withScriptedReplica(t, database,
+		scriptedResult{match: "total_count", columns: append(append([]string(nil), recipeColumns...), "ingredient_count", "has_images", "total_count"),
+			rows: [][]driver.Value{append(append([]driver.Value(nil), recipeRow...), int64(0), false, int64(1))}},
+		scriptedResult{match: "SELECT COUNT(*)", columns: []string{"count"}, rows: [][]driver.Value{{int64(1)}}},
+		scriptedResult{match: "FROM recipe_ingredients", columns: ingredientColumns},
+		scriptedResult{match: "FROM recipes", columns: recipeColumns, rows: [][]driver.Value{recipeRow}},
+	)
+	handler := handlers.NewRecipeHandler(database, nil)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("user_id", 7)
+		c.Next()
+	})
+	router.GET("/api/v1/recipes", handler.GetRecipes)
+	router.GET("/api/v1/recipes/:id", handler.GetRecipe)
+	router.PATCH("/api/v1/recipes/:id", handler.PatchRecipe)
+
+	for _, path := range []string{"/api/v1/recipes", "/api/v1/recipes?include=ingredients", "/api/v1/recipes?count_only=true", "/api/v1/recipes/5"} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", path, nil)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code, path+" "+w.Body.String())
+	}
+	assert.Empty(t, scriptedQueries(), "Reads do not touch the primary")
+	assert.Len(t, scriptedReplicaQueries(), 6, "List, list with ingredients, count, and recipe with ingredients")
+
+	// A write and the recipe it responds with come from the primary, so the response shows the change
+	replicaReads := len(scriptedReplicaQueries())
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("PATCH", "/api/v1/recipes/5", strings.NewReader(`{"title": "Pancakes"}`))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Contains(t, scriptedQueries(), "COMMIT")
+	assert.Len(t, scriptedReplicaQueries(), replicaReads)
+}
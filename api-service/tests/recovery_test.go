@@ -0,0 +1,78 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"digital-recipes/api-service/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecoveryMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	hook := logtest.NewGlobal()
+	defer hook.Reset()
+
+	config := &middleware.AuthConfig{
+		JWTSecret:     "test-secret-that-is-at-least-32-characters-long",
+		TokenDuration: time.Hour,
+		Issuer:        "digital-recipes-test",
+	}
+
+	r := gin.New()
+	r.Use(middleware.RequestIDMiddleware())
+	r.Use(middleware.RecoveryMiddleware())
+	r.Use(middleware.IdentifyUserMiddleware(config))
+	r.GET("/boom", func(c *gin.Context) {
+		panic("something exploded")
+	})
+	r.GET("/fine", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	})
+
+	token, err := middleware.GenerateToken(config, 7, "user@example.com", "User")
+	require.NoError(t, err)
+
+	t.Run("Panics return a 500 error envelope", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/boom", nil)
+		req.Header.Set("X-Request-ID", "req-panic-1")
+		req.Header.Set("Authorization", "Bearer "+token)
+		r.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusInternalServerError, w.Code)
+		var body map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		assert.Equal(t, "Internal server error", body["error"])
+		assert.Equal(t, "internal", body["type"])
+		assert.Equal(t, "INTERNAL_ERROR", body["code"])
+		assert.Equal(t, "req-panic-1", body["request_id"])
+		assert.NotContains(t, w.Body.String(), "something exploded", "Panic details are never sent to clients")
+	})
+
+	t.Run("Panics are logged with request context", func(t *testing.T) {
+		entry := findLogEntry(hook, "Recovered from panic")
+		require.NotNil(t, entry)
+		assert.Equal(t, logrus.ErrorLevel, entry.Level)
+		assert.Equal(t, "req-panic-1", entry.Data["request_id"])
+		assert.Equal(t, 7, entry.Data["user_id"])
+		assert.Equal(t, "/boom", entry.Data["path"])
+		assert.Equal(t, "something exploded", entry.Data["panic"])
+		assert.Contains(t, entry.Data["stack"], "recovery_test.go")
+	})
+
+	t.Run("Requests without panics are untouched", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/fine", nil)
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+}
@@ -0,0 +1,230 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"digital-recipes/api-service/handlers"
+	"digital-recipes/api-service/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// paginatedResponse performs a request against a handler that returns fixed pagination metadata
+func paginatedResponse(t *testing.T, target string, pagination handlers.Pagination) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/api/v1/recipes", func(c *gin.Context) {
+		p := pagination
+		handlers.SuccessResponseWithPagination(c, []string{}, &p)
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", target, nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	return w
+}
+
+func TestPaginationLinkHeaders(t *testing.T) {
+	t.Run("Middle page has all relations", func(t *testing.T) {
+		w := paginatedResponse(t, "/api/v1/recipes?page=2&per_page=2&status=published",
+			handlers.Pagination{Page: 2, PerPage: 2, Total: 5, TotalPages: 3})
+
+		assert.Equal(t,
+			`</api/v1/recipes?page=1&per_page=2&status=published>; rel="first", `+
+				`</api/v1/recipes?page=1&per_page=2&status=published>; rel="prev", `+
+				`</api/v1/recipes?page=3&per_page=2&status=published>; rel="next", `+
+				`</api/v1/recipes?page=3&per_page=2&status=published>; rel="last"`,
+			w.Header().Get("Link"))
+	})
+
+	t.Run("First page omits prev", func(t *testing.T) {
+		w := paginatedResponse(t, "/api/v1/recipes?per_page=2",
+			handlers.Pagination{Page: 1, PerPage: 2, Total: 5, TotalPages: 3})
+
+		link := w.Header().Get("Link")
+		assert.NotContains(t, link, `rel="prev"`)
+		assert.Equal(t,
+			`</api/v1/recipes?page=1&per_page=2>; rel="first", `+
+				`</api/v1/recipes?page=2&per_page=2>; rel="next", `+
+				`</api/v1/recipes?page=3&per_page=2>; rel="last"`,
+			link)
+	})
+
+	t.Run("Last page omits next", func(t *testing.T) {
+		w := paginatedResponse(t, "/api/v1/recipes?page=3&per_page=2",
+			handlers.Pagination{Page: 3, PerPage: 2, Total: 5, TotalPages: 3})
+
+		link := w.Header().Get("Link")
+		assert.NotContains(t, link, `rel="next"`)
+		assert.Equal(t,
+			`</api/v1/recipes?page=1&per_page=2>; rel="first", `+
+				`</api/v1/recipes?page=2&per_page=2>; rel="prev", `+
+				`</api/v1/recipes?page=3&per_page=2>; rel="last"`,
+			link)
+	})
+
+	t.Run("Empty result has only first and last", func(t *testing.T) {
+		w := paginatedResponse(t, "/api/v1/recipes",
+			handlers.Pagination{Page: 1, PerPage: 10, Total: 0, TotalPages: 0})
+
+		assert.Equal(t,
+			`</api/v1/recipes?page=1&per_page=10>; rel="first", `+
+				`</api/v1/recipes?page=1&per_page=10>; rel="last"`,
+			w.Header().Get("Link"))
+	})
+}
+
+// TestPaginationTotalCountHeader tests the X-Total-Count header matches the total in the body
+// and that browsers are allowed to read it cross-origin
+func TestPaginationTotalCountHeader(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(middleware.CORSMiddleware("https://recipes.example.com", middleware.DefaultCORSMaxAge))
+	r.GET("/api/v1/recipes", func(c *gin.Context) {
+		handlers.SuccessResponseWithPagination(c, []string{}, &handlers.Pagination{Page: 2, PerPage: 10, Total: 37, TotalPages: 4})
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v1/recipes?page=2", nil)
+	req.Header.Set("Origin", "https://recipes.example.com")
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var response handlers.StandardResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.NotNil(t, response.Pagination)
+	assert.Equal(t, fmt.Sprint(response.Pagination.Total), w.Header().Get("X-Total-Count"))
+
+	exposed := strings.Split(w.Header().Get("Access-Control-Expose-Headers"), ",")
+	for i := range exposed {
+		exposed[i] = strings.TrimSpace(exposed[i])
+	}
+	assert.Contains(t, exposed, "X-Total-Count")
+	assert.Contains(t, exposed, "Link")
+
+	t.Run("Empty results report zero", func(t *testing.T) {
+		w := paginatedResponse(t, "/api/v1/recipes", handlers.Pagination{Page: 1, PerPage: 10, Total: 0, TotalPages: 0})
+		assert.Equal(t, "0", w.Header().Get("X-Total-Count"))
+	})
+
+	t.Run("Unpaginated responses omit the header", func(t *testing.T) {
+		r := gin.New()
+		r.GET("/api/v1/recipes/1", func(c *gin.Context) { handlers.SuccessResponse(c, "recipe") })
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/v1/recipes/1", nil)
+		r.ServeHTTP(w, req)
+		assert.Empty(t, w.Header().Values("X-Total-Count"))
+	})
+}
+
+func TestResponseMetaRequestID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(middleware.RequestIDMiddleware())
+	r.GET("/ok", func(c *gin.Context) {
+		handlers.SuccessResponse(c, gin.H{"hello": "world"})
+	})
+	r.GET("/list", func(c *gin.Context) {
+		handlers.SuccessResponseWithPagination(c, []string{}, &handlers.Pagination{Page: 1, PerPage: 10})
+	})
+	r.GET("/bad", func(c *gin.Context) {
+		handlers.BadRequestError(c, "bad input")
+	})
+	r.GET("/missing", func(c *gin.Context) {
+		handlers.NotFoundError(c, "recipe not found")
+	})
+
+	for _, path := range []string{"/ok", "/list", "/bad"} {
+		t.Run("Generated request ID in meta for "+path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", path, nil)
+			r.ServeHTTP(w, req)
+
+			var response handlers.StandardResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			require.NotNil(t, response.Meta, "Response should include meta")
+
+			headerID := w.Header().Get("X-Request-ID")
+			assert.NotEmpty(t, headerID)
+			assert.Equal(t, headerID, response.Meta.RequestID)
+
+			timestamp, err := time.Parse(time.RFC3339, response.Meta.Timestamp)
+			require.NoError(t, err, "Timestamp should be RFC3339")
+			assert.WithinDuration(t, time.Now(), timestamp, 5*time.Second)
+		})
+	}
+
+	t.Run("Client request ID is echoed in meta", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/ok", nil)
+		req.Header.Set("X-Request-ID", "client-supplied-id")
+		r.ServeHTTP(w, req)
+
+		var response handlers.StandardResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "client-supplied-id", response.Meta.RequestID)
+	})
+
+	t.Run("Error envelope carries generated request ID", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/missing", nil)
+		r.ServeHTTP(w, req)
+
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Equal(t, w.Header().Get("X-Request-ID"), response["request_id"])
+	})
+}
+
+// TestDatabaseErrorCheckConstraint tests CHECK constraint violations become validation errors instead of 500s
+func TestDatabaseErrorCheckConstraint(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name          string
+		constraint    string
+		expectedField string
+	}{
+		{"Negative ingredient quantity", "recipe_ingredients_quantity_non_negative", "quantity"},
+		{"Other check constraint", "recipes_status_check", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dbErr := &pq.Error{
+				Code:       "23514",
+				Message:    fmt.Sprintf(`new row for relation "recipe_ingredients" violates check constraint "%s"`, tt.constraint),
+				Constraint: tt.constraint,
+			}
+			r := gin.New()
+			r.GET("/fail", func(c *gin.Context) {
+				handlers.DatabaseError(c, fmt.Errorf("insert ingredient: %w", dbErr), "add recipe ingredient")
+			})
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/fail", nil)
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			var body map[string]interface{}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			assert.Equal(t, "validation", body["type"])
+			if tt.expectedField != "" {
+				assert.Equal(t, tt.expectedField, body["field"])
+			} else {
+				assert.NotContains(t, body, "field")
+			}
+			assert.NotContains(t, w.Body.String(), tt.constraint, "Constraint names are not exposed")
+		})
+	}
+}
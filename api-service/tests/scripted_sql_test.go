@@ -0,0 +1,178 @@
+package tests
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"digital-recipes/api-service/db"
+	"github.com/stretchr/testify/require"
+)
+
+// scriptedResult is the canned result returned for queries containing match
+type scriptedResult struct {
+	match   string
+	columns []string
+	rows    [][]driver.Value
+}
+
+// scriptedDriver is a database driver that answers queries from canned results
+// and records every statement it runs, so handlers can be exercised without a real database
+// Statements run in transactions are recorded too, followed by COMMIT or ROLLBACK
+type scriptedDriver struct {
+	mu         sync.Mutex
+	results    []scriptedResult
+	queries    []string
+	commitErrs []error
+}
+
+type scriptedTx struct{ driver *scriptedDriver }
+
+type scriptedConn struct{ driver *scriptedDriver }
+
+type scriptedStmt struct {
+	driver *scriptedDriver
+	query  string
+}
+
+type scriptedRows struct {
+	columns []string
+	rows    [][]driver.Value
+	next    int
+}
+
+var scripted = &scriptedDriver{}
+
+// scriptedReplica answers and records the queries sent to a database's read pool
+var scriptedReplica = &scriptedDriver{}
+
+func init() {
+	sql.Register("scripted-test", scripted)
+	sql.Register("scripted-replica-test", scriptedReplica)
+}
+
+// openScriptedDatabase opens a database answering queries from results in order of matching
+func openScriptedDatabase(t *testing.T, results ...scriptedResult) *db.Database {
+	scripted.mu.Lock()
+	scripted.results = results
+	scripted.queries = nil
+	scripted.commitErrs = nil
+	scripted.mu.Unlock()
+
+	sqlDB, err := sql.Open("scripted-test", "")
+	require.NoError(t, err)
+	t.Cleanup(func() { sqlDB.Close() })
+	return &db.Database{DB: sqlDB}
+}
+
+// withScriptedReplica gives database a read pool answering queries from results, separate from its primary
+func withScriptedReplica(t *testing.T, database *db.Database, results ...scriptedResult) {
+	scriptedReplica.mu.Lock()
+	scriptedReplica.results = results
+	scriptedReplica.queries = nil
+	scriptedReplica.mu.Unlock()
+
+	readDB, err := sql.Open("scripted-replica-test", "")
+	require.NoError(t, err)
+	t.Cleanup(func() { readDB.Close() })
+	database.ReadDB = readDB
+}
+
+// failScriptedCommits makes the next commits on the scripted database fail with errs in order; nil commits succeed
+func failScriptedCommits(errs ...error) {
+	scripted.mu.Lock()
+	defer scripted.mu.Unlock()
+	scripted.commitErrs = errs
+}
+
+// scriptedQueries returns the queries run since the database was opened
+func scriptedQueries() []string {
+	return scripted.log()
+}
+
+// scriptedReplicaQueries returns the queries run on the read pool since it was added
+func scriptedReplicaQueries() []string {
+	return scriptedReplica.log()
+}
+
+// log returns a copy of the statements recorded so far
+func (d *scriptedDriver) log() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]string(nil), d.queries...)
+}
+
+func (d *scriptedDriver) Open(name string) (driver.Conn, error) { return scriptedConn{driver: d}, nil }
+
+func (c scriptedConn) Prepare(query string) (driver.Stmt, error) {
+	return scriptedStmt{driver: c.driver, query: query}, nil
+}
+func (scriptedConn) Close() error              { return nil }
+
+// Ping records health checks so tests can count them
+func (c scriptedConn) Ping(ctx context.Context) error {
+	c.driver.record("PING")
+	return nil
+}
+func (c scriptedConn) Begin() (driver.Tx, error) {
+	c.driver.record("BEGIN")
+	return scriptedTx{driver: c.driver}, nil
+}
+
+func (t scriptedTx) Commit() error {
+	t.driver.record("COMMIT")
+	t.driver.mu.Lock()
+	defer t.driver.mu.Unlock()
+	if len(t.driver.commitErrs) == 0 {
+		return nil
+	}
+	err := t.driver.commitErrs[0]
+	t.driver.commitErrs = t.driver.commitErrs[1:]
+	return err
+}
+
+func (t scriptedTx) Rollback() error {
+	t.driver.record("ROLLBACK")
+	return nil
+}
+
+// record appends a statement to the query log
+func (d *scriptedDriver) record(query string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.queries = append(d.queries, query)
+}
+
+func (scriptedStmt) Close() error  { return nil }
+func (scriptedStmt) NumInput() int { return -1 }
+func (s scriptedStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.driver.record(s.query)
+	return driver.RowsAffected(1), nil
+}
+func (s scriptedStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.driver.mu.Lock()
+	defer s.driver.mu.Unlock()
+	s.driver.queries = append(s.driver.queries, s.query)
+	for _, result := range s.driver.results {
+		if strings.Contains(s.query, result.match) {
+			return &scriptedRows{columns: result.columns, rows: result.rows}, nil
+		}
+	}
+	return nil, fmt.Errorf("no scripted result for query: %s", s.query)
+}
+
+func (r *scriptedRows) Columns() []string { return r.columns }
+func (r *scriptedRows) Close() error      { return nil }
+func (r *scriptedRows) Next(dest []driver.Value) error {
+	if r.next >= len(r.rows) {
+		return io.EOF
+	}
+	copy(dest, r.rows[r.next])
+	r.next++
+	return nil
+}
@@ -0,0 +1,83 @@
+package tests
+
+import (
+	"database/sql/driver"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"digital-recipes/api-service/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeMigrationDir creates a migrations directory holding the given up migrations
+func writeMigrationDir(t *testing.T, names ...string) string {
+	dir := t.TempDir()
+	for _, name := range names {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name+".up.sql"), []byte("SELECT 1;"), 0o644))
+	}
+	return dir
+}
+
+func TestStartupMigrations(t *testing.T) {
+	appliedVersions := scriptedResult{
+		match:   "SELECT version FROM schema_migrations",
+		columns: []string{"version"},
+		rows:    [][]driver.Value{{int64(1)}},
+	}
+
+	t.Run("MIGRATE_ON_START=false skips the database entirely", func(t *testing.T) {
+		t.Setenv("MIGRATE_ON_START", "false")
+		database := openScriptedDatabase(t)
+
+		skipped, applied, err := database.StartupMigrations("does-not-exist")
+		require.NoError(t, err)
+		assert.True(t, skipped)
+		assert.Zero(t, applied)
+		assert.Empty(t, scriptedQueries(), "No migration queries run when skipped")
+	})
+
+	t.Run("An up-to-date schema applies nothing", func(t *testing.T) {
+		t.Setenv("MIGRATE_ON_START", "")
+		database := openScriptedDatabase(t, appliedVersions)
+
+		skipped, applied, err := database.StartupMigrations(writeMigrationDir(t, "001_initial"))
+		require.NoError(t, err)
+		assert.False(t, skipped)
+		assert.Zero(t, applied)
+	})
+
+	t.Run("Pending migrations are applied and counted", func(t *testing.T) {
+		t.Setenv("MIGRATE_ON_START", "true")
+		database := openScriptedDatabase(t, appliedVersions)
+
+		skipped, applied, err := database.StartupMigrations(writeMigrationDir(t, "001_initial", "002_next"))
+		require.NoError(t, err)
+		assert.False(t, skipped)
+		assert.Equal(t, 1, applied)
+		assert.Contains(t, scriptedQueries(), "COMMIT")
+	})
+
+	t.Run("Real failures are reported", func(t *testing.T) {
+		t.Setenv("MIGRATE_ON_START", "true")
+		database := openScriptedDatabase(t, appliedVersions)
+
+		_, _, err := database.StartupMigrations(filepath.Join(t.TempDir(), "missing"))
+		assert.Error(t, err)
+	})
+}
+
+func TestMigrateOnStartEnabled(t *testing.T) {
+	for value, expected := range map[string]bool{
+		"":      true,
+		"true":  true,
+		"1":     true,
+		"false": false,
+		"0":     false,
+		"nope":  true,
+	} {
+		t.Setenv("MIGRATE_ON_START", value)
+		assert.Equal(t, expected, db.MigrateOnStartEnabled(), "MIGRATE_ON_START=%q", value)
+	}
+}
@@ -0,0 +1,138 @@
+package tests
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"sync/atomic"
+	"testing"
+
+	"digital-recipes/api-service/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// echoDriver is a database driver whose statements return their first argument as a single row,
+// counting prepares and closes so statement reuse can be observed
+type echoDriver struct {
+	prepared *int64
+	closed   *int64
+}
+
+type echoConn struct{ driver echoDriver }
+
+type echoStmt struct{ driver echoDriver }
+
+type echoRows struct {
+	value driver.Value
+	done  bool
+}
+
+var (
+	echoPrepared int64
+	echoClosed   int64
+)
+
+func (d echoDriver) Open(name string) (driver.Conn, error) { return echoConn{driver: d}, nil }
+
+func (c echoConn) Prepare(query string) (driver.Stmt, error) {
+	atomic.AddInt64(c.driver.prepared, 1)
+	return echoStmt{driver: c.driver}, nil
+}
+func (echoConn) Close() error              { return nil }
+func (echoConn) Begin() (driver.Tx, error) { return nil, errors.New("transactions not supported") }
+
+func (s echoStmt) Close() error {
+	atomic.AddInt64(s.driver.closed, 1)
+	return nil
+}
+func (echoStmt) NumInput() int { return 1 }
+func (echoStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return nil, errors.New("exec not supported")
+}
+func (echoStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return &echoRows{value: args[0]}, nil
+}
+
+func (*echoRows) Columns() []string { return []string{"value"} }
+func (*echoRows) Close() error      { return nil }
+func (r *echoRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	dest[0] = r.value
+	r.done = true
+	return nil
+}
+
+func init() {
+	sql.Register("echo-test", echoDriver{prepared: &echoPrepared, closed: &echoClosed})
+}
+
+// openEchoDatabase opens a single-connection database backed by echoDriver and resets its counters
+func openEchoDatabase(t *testing.T) *db.Database {
+	atomic.StoreInt64(&echoPrepared, 0)
+	atomic.StoreInt64(&echoClosed, 0)
+	sqlDB, err := sql.Open("echo-test", "")
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+	return &db.Database{DB: sqlDB}
+}
+
+func TestPreparedStatementCache(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Cached statements are prepared once and return correct results", func(t *testing.T) {
+		database := openEchoDatabase(t)
+		defer database.Close()
+
+		for _, id := range []int64{7, 42, 7} {
+			var got int64
+			err := database.QueryRowPrepared(ctx, "echo", "SELECT $1", id).Scan(&got)
+			require.NoError(t, err)
+			assert.Equal(t, id, got)
+		}
+
+		rows, err := database.QueryPrepared(ctx, "echo", "SELECT $1", int64(9))
+		require.NoError(t, err)
+		require.True(t, rows.Next())
+		var got int64
+		require.NoError(t, rows.Scan(&got))
+		assert.Equal(t, int64(9), got)
+		assert.False(t, rows.Next())
+		rows.Close()
+
+		assert.Equal(t, int64(1), atomic.LoadInt64(&echoPrepared), "Statement is parsed once and reused")
+	})
+
+	t.Run("PrepareCached returns the same statement for a name", func(t *testing.T) {
+		database := openEchoDatabase(t)
+		defer database.Close()
+
+		first, err := database.PrepareCached(ctx, "echo", "SELECT $1")
+		require.NoError(t, err)
+		second, err := database.PrepareCached(ctx, "echo", "SELECT $1")
+		require.NoError(t, err)
+		assert.Same(t, first, second)
+
+		_, err = database.PrepareCached(ctx, "echo", "SELECT $1 + 1")
+		assert.Error(t, err, "A name cannot be reused for different SQL")
+	})
+
+	t.Run("Close closes cached statements", func(t *testing.T) {
+		database := openEchoDatabase(t)
+
+		_, err := database.PrepareCached(ctx, "first", "SELECT $1")
+		require.NoError(t, err)
+		_, err = database.PrepareCached(ctx, "second", "SELECT $1 AS other")
+		require.NoError(t, err)
+
+		require.NoError(t, database.Close())
+		assert.Equal(t, int64(2), atomic.LoadInt64(&echoClosed))
+
+		_, err = database.PrepareCached(ctx, "first", "SELECT $1")
+		assert.Error(t, err, "No statements are prepared after Close")
+	})
+}
@@ -0,0 +1,53 @@
+package tests
+
+import (
+	"testing"
+
+	"digital-recipes/api-service/handlers"
+	"digital-recipes/api-service/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatusBroker(t *testing.T) {
+	t.Run("Events reach subscribers of the same recipe only", func(t *testing.T) {
+		broker := handlers.NewStatusBroker()
+		first, unsubscribeFirst := broker.Subscribe(1)
+		defer unsubscribeFirst()
+		other, unsubscribeOther := broker.Subscribe(2)
+		defer unsubscribeOther()
+
+		event := models.RecipeStatusEvent{RecipeID: 1, PreviousStatus: "processing", Status: "review_required"}
+		broker.Publish(event)
+
+		require.Len(t, first, 1)
+		assert.Equal(t, event, <-first)
+		assert.Empty(t, other)
+	})
+
+	t.Run("Unsubscribing releases the subscription", func(t *testing.T) {
+		broker := handlers.NewStatusBroker()
+		_, unsubscribeFirst := broker.Subscribe(1)
+		_, unsubscribeSecond := broker.Subscribe(1)
+		assert.Equal(t, 2, broker.SubscriberCount(1))
+
+		unsubscribeFirst()
+		assert.Equal(t, 1, broker.SubscriberCount(1))
+		unsubscribeSecond()
+		assert.Equal(t, 0, broker.SubscriberCount(1))
+
+		// Publishing with nobody listening is a no-op
+		broker.Publish(models.RecipeStatusEvent{RecipeID: 1, Status: "published"})
+	})
+
+	t.Run("Slow subscribers never block publishers", func(t *testing.T) {
+		broker := handlers.NewStatusBroker()
+		events, unsubscribe := broker.Subscribe(1)
+		defer unsubscribe()
+
+		for i := 0; i < 100; i++ {
+			broker.Publish(models.RecipeStatusEvent{RecipeID: 1, Status: "published"})
+		}
+		assert.Equal(t, cap(events), len(events), "Events beyond the buffer are dropped")
+	})
+}
@@ -0,0 +1,181 @@
+package tests
+
+import (
+	"context"
+	"crypto/md5"
+	"errors"
+	"hash/crc32"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"cloud.google.com/go/storage"
+	"digital-recipes/api-service/handlers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryBucket is an in-memory storage backend that records deletions
+// Objects stored with PutContent report checksums of their content like GCS does
+type memoryBucket struct {
+	mu        sync.Mutex
+	objects   map[string]bool
+	contents  map[string][]byte
+	deleted   []string
+	deleteErr map[string]error
+}
+
+func newMemoryBucket() *memoryBucket {
+	return &memoryBucket{objects: map[string]bool{}, contents: map[string][]byte{}, deleteErr: map[string]error{}}
+}
+
+// PutContent stores an object with the given content
+func (b *memoryBucket) PutContent(name string, content []byte) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.objects[name] = true
+	b.contents[name] = content
+}
+
+// Put stores an object name in the bucket
+func (b *memoryBucket) Put(name string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.objects[name] = true
+}
+
+// Has reports whether an object is still stored
+func (b *memoryBucket) Has(name string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.objects[name]
+}
+
+// Deleted returns the names of deleted objects in deletion order
+func (b *memoryBucket) Deleted() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]string(nil), b.deleted...)
+}
+
+// Reset empties the bucket and its deletion record
+func (b *memoryBucket) Reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.objects = map[string]bool{}
+	b.contents = map[string][]byte{}
+	b.deleted = nil
+	b.deleteErr = map[string]error{}
+}
+
+func (b *memoryBucket) SignedURL(object string, opts *storage.SignedURLOptions) (string, error) {
+	return "https://storage.example.com/" + object, nil
+}
+
+func (b *memoryBucket) Attrs(ctx context.Context) (*storage.BucketAttrs, error) {
+	return &storage.BucketAttrs{Name: "test-bucket"}, nil
+}
+
+func (b *memoryBucket) ListObjects(ctx context.Context, prefix string) ([]string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var names []string
+	for name := range b.objects {
+		if strings.HasPrefix(name, prefix) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func (b *memoryBucket) DeleteObject(ctx context.Context, name string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if err := b.deleteErr[name]; err != nil {
+		return err
+	}
+	delete(b.objects, name)
+	delete(b.contents, name)
+	b.deleted = append(b.deleted, name)
+	return nil
+}
+
+func (b *memoryBucket) ObjectAttrs(ctx context.Context, name string) (*storage.ObjectAttrs, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.objects[name] {
+		return nil, storage.ErrObjectNotExist
+	}
+	content := b.contents[name]
+	sum := md5.Sum(content)
+	return &storage.ObjectAttrs{
+		Name:        name,
+		ContentType: "image/jpeg",
+		Size:        int64(len(content)),
+		MD5:         sum[:],
+		CRC32C:      crc32.Checksum(content, crc32.MakeTable(crc32.Castagnoli)),
+	}, nil
+}
+
+func (b *memoryBucket) ReadObject(ctx context.Context, name string, limit int64) ([]byte, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.objects[name] {
+		return nil, storage.ErrObjectNotExist
+	}
+	content := b.contents[name]
+	if int64(len(content)) > limit {
+		content = content[:limit]
+	}
+	return content, nil
+}
+
+func (b *memoryBucket) WriteObject(ctx context.Context, name, contentType string, data []byte) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.objects[name] = true
+	b.contents[name] = data
+	return nil
+}
+
+// Content returns the stored content of an object
+func (b *memoryBucket) Content(name string) []byte {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.contents[name]
+}
+
+// TestDeleteRecipeObjects tests only objects under the recipe's prefix are removed
+func TestDeleteRecipeObjects(t *testing.T) {
+	bucket := newMemoryBucket()
+	bucket.Put("recipes/7/images/a.jpg")
+	bucket.Put("recipes/7/images/b.jpg")
+	bucket.Put("recipes/70/images/c.jpg")
+	service := handlers.NewStorageServiceWithBackend("test-bucket", bucket)
+
+	deleted, err := service.DeleteRecipeObjects(context.Background(), "7")
+	require.NoError(t, err)
+	assert.Equal(t, 2, deleted)
+	assert.Equal(t, []string{"recipes/7/images/a.jpg", "recipes/7/images/b.jpg"}, bucket.Deleted())
+	assert.True(t, bucket.Has("recipes/70/images/c.jpg"), "Prefix must not match other recipe IDs")
+}
+
+// TestDeleteRecipeObjectsPartialFailure tests one failing object does not stop the rest
+func TestDeleteRecipeObjectsPartialFailure(t *testing.T) {
+	bucket := newMemoryBucket()
+	bucket.Put("recipes/3/images/a.jpg")
+	bucket.Put("recipes/3/images/b.jpg")
+	bucket.deleteErr["recipes/3/images/a.jpg"] = errors.New("permission denied")
+	service := handlers.NewStorageServiceWithBackend("test-bucket", bucket)
+
+	deleted, err := service.DeleteRecipeObjects(context.Background(), "3")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "recipes/3/images/a.jpg")
+	assert.Equal(t, 1, deleted)
+	assert.False(t, bucket.Has("recipes/3/images/b.jpg"))
+
+	_, err = handlers.NewStorageServiceWithBackend("test-bucket", failingBucket{}).DeleteRecipeObjects(context.Background(), "3")
+	assert.Error(t, err, "List failures are reported")
+}
@@ -96,8 +96,8 @@ func TestGCSStorageService(t *testing.T) {
 			{
 				name: "Maximum allowed uploads",
 				uploadReq: &models.UploadRequest{
-					ImageCount:      10,
-					MaxFileSizeMB:   50,
+					ImageCount:      models.MaxImagesPerUpload,
+					MaxFileSizeMB:   models.MaxTotalUploadMB / models.MaxImagesPerUpload,
 					AllowedTypes:    []string{"image/jpeg", "image/png"},
 					ExpirationHours: 24,
 				},
@@ -110,7 +110,7 @@ func TestGCSStorageService(t *testing.T) {
 				recipeID := 12345
 				clientIP := "192.168.1.100"
 
-				uploadURLs, err := storageService.GenerateUploadURLs(ctx, recipeID, tc.uploadReq, clientIP)
+				uploadURLs, err := storageService.GenerateUploadURLs(ctx, recipeID, "0f3c9a6e2b7d4e1f8a5c3b9d7e6f1a2b", tc.uploadReq, clientIP)
 
 				if tc.expectError {
 					assert.Error(t, err)
@@ -175,7 +175,7 @@ func TestGCSStorageService(t *testing.T) {
 			ImageCount: 1,
 		}
 
-		uploadURLs, err := storageService.GenerateUploadURLs(ctx, 1, minimalReq, "127.0.0.1")
+		uploadURLs, err := storageService.GenerateUploadURLs(ctx, 1, "1", minimalReq, "127.0.0.1")
 		
 		// Might fail due to missing GCS setup, but shouldn't panic
 		if err == nil {
@@ -0,0 +1,149 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"cloud.google.com/go/storage"
+	"digital-recipes/api-service/handlers"
+	"digital-recipes/api-service/logctx"
+	"digital-recipes/api-service/middleware"
+	"digital-recipes/api-service/models"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// failingBucket is a storage backend whose operations always fail
+type failingBucket struct{}
+
+func (failingBucket) SignedURL(object string, opts *storage.SignedURLOptions) (string, error) {
+	return "", errors.New("signing key unavailable")
+}
+
+func (failingBucket) Attrs(ctx context.Context) (*storage.BucketAttrs, error) {
+	return nil, errors.New("bucket unreachable")
+}
+
+func (failingBucket) ListObjects(ctx context.Context, prefix string) ([]string, error) {
+	return nil, errors.New("bucket unreachable")
+}
+
+func (failingBucket) DeleteObject(ctx context.Context, name string) error {
+	return errors.New("bucket unreachable")
+}
+
+func (failingBucket) ObjectAttrs(ctx context.Context, name string) (*storage.ObjectAttrs, error) {
+	return nil, errors.New("bucket unreachable")
+}
+
+func (failingBucket) ReadObject(ctx context.Context, name string, limit int64) ([]byte, error) {
+	return nil, errors.New("bucket unreachable")
+}
+
+func (failingBucket) WriteObject(ctx context.Context, name, contentType string, data []byte) error {
+	return errors.New("bucket unreachable")
+}
+
+// findLogEntry returns the first captured entry with the given message
+func findLogEntry(hook *logtest.Hook, message string) *logrus.Entry {
+	for _, entry := range hook.AllEntries() {
+		if entry.Message == message {
+			return entry
+		}
+	}
+	return nil
+}
+
+func TestStorageLogsCarryRequestID(t *testing.T) {
+	hook := logtest.NewGlobal()
+	defer hook.Reset()
+
+	service := handlers.NewStorageServiceWithBackend("test-bucket", failingBucket{})
+	ctx := logctx.WithLogger(context.Background(), logrus.WithField("request_id", "req-1234"))
+
+	t.Run("Pre-signed URL failures", func(t *testing.T) {
+		_, err := service.GenerateUploadURLs(ctx, 42, "42", &models.UploadRequest{ImageCount: 1}, "127.0.0.1")
+		require.Error(t, err)
+
+		entry := findLogEntry(hook, "Failed to create pre-signed URL")
+		require.NotNil(t, entry, "Storage error should be logged")
+		assert.Equal(t, "req-1234", entry.Data["request_id"])
+		assert.Equal(t, 42, entry.Data["recipe_id"])
+	})
+
+	t.Run("Health check failures", func(t *testing.T) {
+		require.Error(t, service.HealthCheck(ctx))
+
+		entry := findLogEntry(hook, "GCS health check failed")
+		require.NotNil(t, entry)
+		assert.Equal(t, "req-1234", entry.Data["request_id"])
+	})
+}
+
+func TestGenerateUploadURLsLogsSummary(t *testing.T) {
+	hook := logtest.NewGlobal()
+	defer hook.Reset()
+
+	ctx := logctx.WithLogger(context.Background(), logrus.WithField("request_id", "req-5678"))
+
+	service := handlers.NewStorageServiceWithBackend("test-bucket", newMemoryBucket())
+	uploadURLs, err := service.GenerateUploadURLs(ctx, 42, "42", &models.UploadRequest{ImageCount: 3}, "127.0.0.1")
+	require.NoError(t, err)
+	require.Len(t, uploadURLs, 3)
+
+	entry := findLogEntry(hook, "Upload URLs generated")
+	require.NotNil(t, entry, "Each generation should end with a summary line")
+	assert.Equal(t, logrus.InfoLevel, entry.Level)
+	assert.Equal(t, "req-5678", entry.Data["request_id"])
+	assert.Equal(t, 42, entry.Data["recipe_id"])
+	assert.Equal(t, 3, entry.Data["requested_count"])
+	assert.Equal(t, 3, entry.Data["generated_count"])
+	assert.Equal(t, 0, entry.Data["failed_count"])
+	assert.IsType(t, int64(0), entry.Data["duration_ms"])
+
+	// A failure still summarises how far generation got
+	hook.Reset()
+	failing := handlers.NewStorageServiceWithBackend("test-bucket", failingBucket{})
+	_, err = failing.GenerateUploadURLs(ctx, 42, "42", &models.UploadRequest{ImageCount: 2}, "127.0.0.1")
+	require.Error(t, err)
+
+	entry = findLogEntry(hook, "Upload URL generation incomplete")
+	require.NotNil(t, entry)
+	assert.Equal(t, logrus.WarnLevel, entry.Level)
+	assert.Equal(t, 2, entry.Data["requested_count"])
+	assert.Equal(t, 0, entry.Data["generated_count"])
+	assert.Equal(t, 2, entry.Data["failed_count"])
+	assert.NotNil(t, entry.Data[logrus.ErrorKey])
+}
+
+func TestRequestIDMiddlewareSeedsContextLogger(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	hook := logtest.NewGlobal()
+	defer hook.Reset()
+
+	r := gin.New()
+	r.Use(middleware.RequestIDMiddleware())
+	r.GET("/work", func(c *gin.Context) {
+		// Code below the handler only sees the request context
+		logctx.FromContext(c.Request.Context()).Info("Deep work done")
+		c.Status(http.StatusNoContent)
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/work", nil)
+	req.Header.Set("X-Request-ID", "req-from-proxy")
+	r.ServeHTTP(w, req)
+
+	entry := findLogEntry(hook, "Deep work done")
+	require.NotNil(t, entry)
+	assert.Equal(t, "req-from-proxy", entry.Data["request_id"])
+
+	// Contexts without a logger fall back to the standard logger
+	assert.NotNil(t, logctx.FromContext(context.Background()))
+}
@@ -0,0 +1,72 @@
+package tests
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"cloud.google.com/go/storage"
+	"digital-recipes/api-service/handlers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// compositeBucket reports objects without an MD5, as GCS does for composed objects
+type compositeBucket struct {
+	*memoryBucket
+}
+
+func (b compositeBucket) ObjectAttrs(ctx context.Context, name string) (*storage.ObjectAttrs, error) {
+	attrs, err := b.memoryBucket.ObjectAttrs(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	attrs.MD5 = nil
+	return attrs, nil
+}
+
+func TestGetObjectHash(t *testing.T) {
+	content := []byte("the same photo")
+	sum := md5.Sum(content)
+
+	t.Run("MD5 is preferred", func(t *testing.T) {
+		bucket := newMemoryBucket()
+		bucket.PutContent("recipes/1/images/a.jpg", content)
+		bucket.PutContent("recipes/1/images/b.jpg", content)
+		bucket.PutContent("recipes/1/images/c.jpg", []byte("a different photo"))
+		service := handlers.NewStorageServiceWithBackend("test-bucket", bucket)
+
+		first, err := service.GetObjectHash(context.Background(), "recipes/1/images/a.jpg")
+		require.NoError(t, err)
+		assert.Equal(t, "md5:"+hex.EncodeToString(sum[:]), first.Hash)
+		assert.Equal(t, int64(len(content)), first.Size)
+		assert.Equal(t, "image/jpeg", first.ContentType)
+
+		second, err := service.GetObjectHash(context.Background(), "recipes/1/images/b.jpg")
+		require.NoError(t, err)
+		assert.Equal(t, first.Hash, second.Hash, "Identical content has identical hashes")
+
+		third, err := service.GetObjectHash(context.Background(), "recipes/1/images/c.jpg")
+		require.NoError(t, err)
+		assert.NotEqual(t, first.Hash, third.Hash)
+	})
+
+	t.Run("Composite objects fall back to CRC32C and size", func(t *testing.T) {
+		bucket := newMemoryBucket()
+		bucket.PutContent("recipes/1/images/a.jpg", content)
+		service := handlers.NewStorageServiceWithBackend("test-bucket", compositeBucket{bucket})
+
+		object, err := service.GetObjectHash(context.Background(), "recipes/1/images/a.jpg")
+		require.NoError(t, err)
+		assert.Regexp(t, `^crc32c:[0-9a-f]{8}:14$`, object.Hash)
+	})
+
+	t.Run("Missing objects are reported as not existing", func(t *testing.T) {
+		service := handlers.NewStorageServiceWithBackend("test-bucket", newMemoryBucket())
+
+		_, err := service.GetObjectHash(context.Background(), "recipes/1/images/missing.jpg")
+		assert.True(t, errors.Is(err, storage.ErrObjectNotExist))
+	})
+}
@@ -0,0 +1,136 @@
+package tests
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"digital-recipes/api-service/handlers"
+	"digital-recipes/api-service/middleware"
+	"digital-recipes/api-service/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// storagePrefix returns the key segment a recipe's stored files live under
+func (suite *RecipeAPITestSuite) storagePrefix(recipeID int) string {
+	var prefix string
+	err := suite.db.DB.QueryRow(`SELECT COALESCE(storage_prefix, id::text) FROM recipes WHERE id = $1`, recipeID).Scan(&prefix)
+	require.NoError(suite.T(), err)
+	return prefix
+}
+
+// TestRecipeStoragePrefixes tests new recipes get distinct random prefixes that resolve back to them
+func (suite *RecipeAPITestSuite) TestRecipeStoragePrefixes() {
+	firstID := suite.createTestRecipe("First", "draft")
+	secondID := suite.createTestRecipe("Second", "draft")
+
+	first, second := suite.storagePrefix(firstID), suite.storagePrefix(secondID)
+	assert.Regexp(suite.T(), `^[0-9a-f]{32}$`, first)
+	assert.Regexp(suite.T(), `^[0-9a-f]{32}$`, second)
+	assert.NotEqual(suite.T(), first, second)
+
+	for recipeID, prefix := range map[int]string{firstID: first, secondID: second} {
+		resolved, err := handlers.ResolveObjectRecipe(suite.db.DB, "recipes/"+prefix+"/images/photo.jpg")
+		require.NoError(suite.T(), err)
+		assert.Equal(suite.T(), recipeID, resolved)
+	}
+
+	_, err := handlers.ResolveObjectRecipe(suite.db.DB, "recipes/"+strconv.Itoa(firstID)+"/images/photo.jpg")
+	assert.ErrorIs(suite.T(), err, sql.ErrNoRows, "Recipes with a random prefix are not addressable by ID")
+
+	// Recipes from before prefixes existed keep resolving under their ID
+	_, err = suite.db.DB.Exec(`UPDATE recipes SET storage_prefix = NULL WHERE id = $1`, secondID)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), strconv.Itoa(secondID), suite.storagePrefix(secondID))
+	resolved, err := handlers.ResolveObjectRecipe(suite.db.DB, "recipes/"+strconv.Itoa(secondID)+"/images/photo.jpg")
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), secondID, resolved)
+}
+
+// TestUploadRequestUsesStoragePrefix tests upload URLs are keyed by the new recipe's prefix rather than its ID
+func (suite *RecipeAPITestSuite) TestUploadRequestUsesStoragePrefix() {
+	auth := suite.authHeader(suite.testUserID, middleware.RoleUser)
+
+	// The bodies differ so the second request is not answered with the first recipe as a duplicate
+	var objects []string
+	for _, body := range []string{`{"image_count": 1}`, `{"image_count": 1, "max_file_size_mb": 5}`} {
+		w := suite.performJSON("POST", "/api/v1/recipes/upload-request", body, auth)
+		require.Equal(suite.T(), http.StatusOK, w.Code, w.Body.String())
+
+		var response struct {
+			Data models.UploadResponse `json:"data"`
+		}
+		require.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &response))
+		require.Len(suite.T(), response.Data.UploadURLs, 1)
+
+		object := strings.TrimPrefix(response.Data.UploadURLs[0].UploadURL, "https://storage.example.com/")
+		prefix := suite.storagePrefix(response.Data.RecipeID)
+		assert.True(suite.T(), strings.HasPrefix(object, "recipes/"+prefix+"/images/"), object)
+
+		resolved, err := handlers.ResolveObjectRecipe(suite.db.DB, object)
+		require.NoError(suite.T(), err)
+		assert.Equal(suite.T(), response.Data.RecipeID, resolved)
+		objects = append(objects, object)
+	}
+
+	firstPrefix, _ := handlers.StoragePrefixFromObject(objects[0])
+	secondPrefix, _ := handlers.StoragePrefixFromObject(objects[1])
+	assert.NotEqual(suite.T(), firstPrefix, secondPrefix)
+}
+
+func TestStoragePrefixFromObject(t *testing.T) {
+	tests := []struct {
+		object string
+		prefix string
+		ok     bool
+	}{
+		{"recipes/0f3c9a6e2b7d4e1f8a5c3b9d7e6f1a2b/images/a.jpg", "0f3c9a6e2b7d4e1f8a5c3b9d7e6f1a2b", true},
+		{"recipes/42/thumbnails/a.jpg", "42", true},
+		{"recipes//images/a.jpg", "", false},
+		{"recipes/orphan", "", false},
+		{"avatars/7/a.jpg", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.object, func(t *testing.T) {
+			prefix, ok := handlers.StoragePrefixFromObject(tt.object)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.prefix, prefix)
+		})
+	}
+}
+
+func TestGenerateUploadURLsKeyedByStoragePrefix(t *testing.T) {
+	bucket := &signingBucket{memoryBucket: newMemoryBucket()}
+	service := handlers.NewStorageServiceWithBackend("test-bucket", bucket)
+
+	uploadURLs, err := service.GenerateUploadURLs(context.Background(), 7, "0f3c9a6e2b7d4e1f8a5c3b9d7e6f1a2b", &models.UploadRequest{ImageCount: 2}, "127.0.0.1")
+	require.NoError(t, err)
+	require.Len(t, bucket.objects, 2)
+	for i, object := range bucket.objects {
+		assert.True(t, strings.HasPrefix(object, "recipes/0f3c9a6e2b7d4e1f8a5c3b9d7e6f1a2b/images/"), object)
+		assert.NotContains(t, object, "recipes/7/")
+		assert.Equal(t, "7", uploadURLs[i].Fields["x-goog-meta-recipe-id"], "The recipe ID stays in the object metadata")
+	}
+}
+
+func TestResolveObjectRecipeWithoutDatabase(t *testing.T) {
+	database := openScriptedDatabase(t, scriptedResult{
+		match:   "WHERE storage_prefix = $1",
+		columns: []string{"id"},
+		rows:    [][]driver.Value{{int64(7)}},
+	})
+
+	recipeID, err := handlers.ResolveObjectRecipe(database.DB, "recipes/0f3c9a6e2b7d4e1f8a5c3b9d7e6f1a2b/images/a.jpg")
+	require.NoError(t, err)
+	assert.Equal(t, 7, recipeID)
+
+	_, err = handlers.ResolveObjectRecipe(database.DB, "avatars/7/a.jpg")
+	assert.ErrorIs(t, err, sql.ErrNoRows, "Names outside recipes/ are not looked up")
+}
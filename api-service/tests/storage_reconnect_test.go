@@ -0,0 +1,140 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/storage"
+	"digital-recipes/api-service/handlers"
+	"digital-recipes/api-service/models"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// staleBucket is an in-memory backend that fails signing and bucket checks while stale, like a client
+// whose credentials were rotated; it records whether it was closed
+type staleBucket struct {
+	*memoryBucket
+	stale  bool
+	closed bool
+}
+
+func (b *staleBucket) SignedURL(object string, opts *storage.SignedURLOptions) (string, error) {
+	if b.stale {
+		return "", errors.New("credentials expired")
+	}
+	return b.memoryBucket.SignedURL(object, opts)
+}
+
+func (b *staleBucket) Attrs(ctx context.Context) (*storage.BucketAttrs, error) {
+	if b.stale {
+		return nil, errors.New("credentials expired")
+	}
+	return b.memoryBucket.Attrs(ctx)
+}
+
+func (b *staleBucket) Close() error {
+	b.closed = true
+	return nil
+}
+
+// sequenceConnector hands out the given backends in order, one per connection
+type sequenceConnector struct {
+	mu       sync.Mutex
+	backends []*staleBucket
+	calls    int
+}
+
+func (s *sequenceConnector) connect(ctx context.Context) (handlers.StorageBackend, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.calls >= len(s.backends) {
+		return nil, errors.New("no more backends")
+	}
+	backend := s.backends[s.calls]
+	s.calls++
+	return backend, nil
+}
+
+// newStaleStorage creates a storage service connected to a stale client that reconnects to a healthy one
+func newStaleStorage(t *testing.T) (*handlers.StorageService, *sequenceConnector) {
+	connector := &sequenceConnector{backends: []*staleBucket{
+		{memoryBucket: newMemoryBucket(), stale: true},
+		{memoryBucket: newMemoryBucket()},
+	}}
+	service, err := handlers.NewStorageServiceWithConnector(context.Background(), "test-bucket", connector.connect)
+	require.NoError(t, err)
+	return service, connector
+}
+
+func TestStorageHealthCheckReconnectsAfterRepeatedFailures(t *testing.T) {
+	hook := logtest.NewGlobal()
+	defer hook.Reset()
+
+	service, connector := newStaleStorage(t)
+
+	// Isolated failures are not enough to replace the client
+	assert.Error(t, service.HealthCheck(context.Background()))
+	assert.Error(t, service.HealthCheck(context.Background()))
+	assert.Equal(t, 1, connector.calls)
+
+	// The third failure in a row reconnects, and the check is repeated on the new client
+	assert.NoError(t, service.HealthCheck(context.Background()))
+	assert.Equal(t, 2, connector.calls)
+	assert.True(t, connector.backends[0].closed, "The stale client is closed once replaced")
+	assert.NoError(t, service.HealthCheck(context.Background()))
+
+	attempt := findLogEntry(hook, "Reconnecting storage client")
+	require.NotNil(t, attempt)
+	assert.Equal(t, "test-bucket", attempt.Data["bucket"])
+	assert.Equal(t, 3, attempt.Data["health_failures"])
+	assert.NotNil(t, findLogEntry(hook, "Storage client reconnected"))
+}
+
+func TestGenerateUploadURLsReconnectsBeforeFailing(t *testing.T) {
+	service, connector := newStaleStorage(t)
+
+	uploadURLs, err := service.GenerateUploadURLs(context.Background(), 7, "7", &models.UploadRequest{ImageCount: 2}, "203.0.113.7")
+	require.NoError(t, err)
+	assert.Len(t, uploadURLs, 2)
+	assert.Equal(t, 2, connector.calls, "Only the first signing failure reconnects")
+}
+
+func TestStorageReconnectCooldown(t *testing.T) {
+	hook := logtest.NewGlobal()
+	defer hook.Reset()
+
+	connector := &sequenceConnector{backends: []*staleBucket{
+		{memoryBucket: newMemoryBucket(), stale: true},
+		{memoryBucket: newMemoryBucket(), stale: true},
+		{memoryBucket: newMemoryBucket()},
+	}}
+	service, err := handlers.NewStorageServiceWithConnector(context.Background(), "test-bucket", connector.connect)
+	require.NoError(t, err)
+	service.SetReconnectCooldown(50 * time.Millisecond)
+
+	// Reconnecting lands on another stale client, and further failures do not reconnect during the cooldown
+	_, err = service.GenerateUploadURLs(context.Background(), 7, "7", &models.UploadRequest{ImageCount: 1}, "203.0.113.7")
+	assert.Error(t, err)
+	_, err = service.GenerateUploadURLs(context.Background(), 7, "7", &models.UploadRequest{ImageCount: 1}, "203.0.113.7")
+	assert.Error(t, err)
+	assert.Equal(t, 2, connector.calls)
+	assert.ErrorIs(t, service.Reconnect(context.Background()), handlers.ErrStorageReconnectCooldown)
+
+	time.Sleep(60 * time.Millisecond)
+	_, err = service.GenerateUploadURLs(context.Background(), 7, "7", &models.UploadRequest{ImageCount: 1}, "203.0.113.7")
+	assert.NoError(t, err)
+	assert.Equal(t, 3, connector.calls)
+}
+
+func TestStorageReconnectUnsupportedWithoutConnector(t *testing.T) {
+	service := handlers.NewStorageServiceWithBackend("test-bucket", failingBucket{})
+	assert.ErrorIs(t, service.Reconnect(context.Background()), handlers.ErrStorageReconnectUnsupported)
+	for i := 0; i < 4; i++ {
+		assert.Error(t, service.HealthCheck(context.Background()))
+	}
+}
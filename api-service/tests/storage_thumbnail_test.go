@@ -0,0 +1,127 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"testing"
+
+	"digital-recipes/api-service/handlers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fixtureImage draws a width x height gradient so encoders have real content to work with
+func fixtureImage(width, height int) image.Image {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, color.NRGBA{R: uint8(x % 256), G: uint8(y % 256), B: 128, A: 255})
+		}
+	}
+	return img
+}
+
+// encodeFixture encodes a fixture image as JPEG or PNG
+func encodeFixture(t *testing.T, format string, width, height int) []byte {
+	var buf bytes.Buffer
+	switch format {
+	case "jpeg":
+		require.NoError(t, jpeg.Encode(&buf, fixtureImage(width, height), nil))
+	case "png":
+		require.NoError(t, png.Encode(&buf, fixtureImage(width, height)))
+	default:
+		t.Fatalf("unsupported fixture format %q", format)
+	}
+	return buf.Bytes()
+}
+
+// pngHeader returns the signature and header chunk of a grayscale PNG claiming the given size, with no pixel data
+func pngHeader(width, height uint32) []byte {
+	chunk := binary.BigEndian.AppendUint32([]byte("IHDR"), width)
+	chunk = binary.BigEndian.AppendUint32(chunk, height)
+	chunk = append(chunk, 8, 0, 0, 0, 0) // 8-bit grayscale, no interlacing
+
+	header := []byte("\x89PNG\r\n\x1a\n")
+	header = binary.BigEndian.AppendUint32(header, uint32(len(chunk)-4))
+	header = append(header, chunk...)
+	return binary.BigEndian.AppendUint32(header, crc32.ChecksumIEEE(chunk))
+}
+
+// decodeThumbnail checks a stored thumbnail is a JPEG and returns its size
+func decodeThumbnail(t *testing.T, data []byte) image.Point {
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	require.NoError(t, err)
+	assert.Equal(t, "jpeg", format, "Thumbnails are always JPEG")
+	return image.Point{X: config.Width, Y: config.Height}
+}
+
+// TestStoreThumbnail tests each supported upload type is scaled into a JPEG under thumbnails/
+func TestStoreThumbnail(t *testing.T) {
+	webp, err := os.ReadFile("testdata/recipe-photo.webp")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		object   string
+		content  []byte
+		expected image.Point
+	}{
+		{"Landscape JPEG is scaled to the max width", "recipes/3/images/landscape.jpg", encodeFixture(t, "jpeg", 640, 480), image.Point{X: 320, Y: 240}},
+		{"Portrait PNG is scaled to the max height", "recipes/3/images/portrait.png", encodeFixture(t, "png", 200, 800), image.Point{X: 80, Y: 320}},
+		{"Small WebP keeps its size", "recipes/3/images/small.webp", webp, image.Point{X: 150, Y: 103}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bucket := newMemoryBucket()
+			bucket.PutContent(tt.object, tt.content)
+			service := handlers.NewStorageServiceWithBackend("test-bucket", bucket)
+
+			name, err := service.StoreThumbnail(context.Background(), tt.object)
+			require.NoError(t, err)
+			assert.Regexp(t, `^recipes/3/thumbnails/[a-z]+\.jpg$`, name)
+			require.True(t, bucket.Has(name))
+			assert.Equal(t, tt.expected, decodeThumbnail(t, bucket.Content(name)))
+			assert.Equal(t, tt.content, bucket.Content(tt.object), "The original upload is left untouched")
+		})
+	}
+}
+
+// TestStoreThumbnailFailures tests undecodable content is distinguished from storage failures
+func TestStoreThumbnailFailures(t *testing.T) {
+	t.Run("Undecodable content", func(t *testing.T) {
+		bucket := newMemoryBucket()
+		bucket.PutContent("recipes/3/images/broken.jpg", []byte("not an image"))
+		service := handlers.NewStorageServiceWithBackend("test-bucket", bucket)
+
+		_, err := service.StoreThumbnail(context.Background(), "recipes/3/images/broken.jpg")
+		assert.ErrorIs(t, err, handlers.ErrThumbnailDecode)
+		assert.False(t, bucket.Has("recipes/3/thumbnails/broken.jpg"))
+	})
+
+	t.Run("Too many pixels", func(t *testing.T) {
+		bucket := newMemoryBucket()
+		bucket.PutContent("recipes/3/images/bomb.png", pngHeader(20000, 20000))
+		service := handlers.NewStorageServiceWithBackend("test-bucket", bucket)
+
+		_, err := service.StoreThumbnail(context.Background(), "recipes/3/images/bomb.png")
+		assert.ErrorIs(t, err, handlers.ErrThumbnailDecode)
+		assert.ErrorContains(t, err, "20000x20000", "The size is checked before any pixels are decoded")
+		assert.False(t, bucket.Has("recipes/3/thumbnails/bomb.jpg"))
+	})
+
+	t.Run("Unreachable bucket", func(t *testing.T) {
+		service := handlers.NewStorageServiceWithBackend("test-bucket", failingBucket{})
+
+		_, err := service.StoreThumbnail(context.Background(), "recipes/3/images/photo.jpg")
+		require.Error(t, err)
+		assert.NotErrorIs(t, err, handlers.ErrThumbnailDecode)
+	})
+}
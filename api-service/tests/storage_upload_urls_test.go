@@ -0,0 +1,325 @@
+package tests
+
+import (
+	"context"
+	"path"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/storage"
+	"digital-recipes/api-service/handlers"
+	"digital-recipes/api-service/models"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// signingBucket is an in-memory backend that records the objects, headers and expiry times it signs URLs for
+type signingBucket struct {
+	*memoryBucket
+	mu      sync.Mutex
+	objects []string
+	headers [][]string
+	expires []time.Time
+}
+
+func (b *signingBucket) SignedURL(object string, opts *storage.SignedURLOptions) (string, error) {
+	b.mu.Lock()
+	b.objects = append(b.objects, object)
+	b.headers = append(b.headers, append([]string(nil), opts.Headers...))
+	b.expires = append(b.expires, opts.Expires)
+	b.mu.Unlock()
+	return b.memoryBucket.SignedURL(object, opts)
+}
+
+func TestGenerateUploadURLsCanonicalizesJPEG(t *testing.T) {
+	hook := logtest.NewGlobal()
+	defer hook.Reset()
+
+	bucket := &signingBucket{memoryBucket: newMemoryBucket()}
+	service := handlers.NewStorageServiceWithBackend("test-bucket", bucket)
+
+	uploadURLs, err := service.GenerateUploadURLs(context.Background(), 7, "7", &models.UploadRequest{
+		ImageCount:   1,
+		AllowedTypes: []string{"image/jpg"},
+	}, "127.0.0.1")
+	require.NoError(t, err)
+	require.Len(t, uploadURLs, 1)
+
+	// The alias is canonicalized rather than rejected and replaced by the fallback
+	assert.Nil(t, findLogEntry(hook, "Invalid content type provided, using default"))
+
+	// Nothing signed or returned carries the alias
+	require.Len(t, bucket.objects, 1)
+	assert.True(t, strings.HasPrefix(bucket.objects[0], "recipes/7/images/"))
+	assert.True(t, strings.HasSuffix(bucket.objects[0], ".jpg"), bucket.objects[0])
+	assert.Contains(t, bucket.headers[0], "Content-Type:image/jpeg")
+	assert.Contains(t, bucket.headers[0], "x-goog-meta-content-type:image/jpeg")
+	for _, header := range bucket.headers[0] {
+		assert.NotContains(t, header, "image/jpg")
+	}
+
+	fields := uploadURLs[0].Fields
+	assert.Equal(t, "image/jpeg", fields["Content-Type"])
+	assert.Equal(t, "image/jpeg", fields["x-goog-meta-content-type"])
+	assert.Contains(t, uploadURLs[0].UploadURL, bucket.objects[0])
+}
+
+func TestGenerateUploadURLsMixedContentTypes(t *testing.T) {
+	bucket := &signingBucket{memoryBucket: newMemoryBucket()}
+	service := handlers.NewStorageServiceWithBackend("test-bucket", bucket)
+
+	uploadURLs, err := service.GenerateUploadURLs(context.Background(), 7, "7", &models.UploadRequest{
+		ImageCount:   3,
+		AllowedTypes: []string{"image/jpeg", "image/png", "image/webp"},
+		ContentTypes: []string{"image/png", "image/jpg", "image/webp"},
+	}, "127.0.0.1")
+	require.NoError(t, err)
+	require.Len(t, uploadURLs, 3)
+	require.Len(t, bucket.objects, 3)
+
+	// Each URL is signed for its own image's type, with a matching extension
+	expected := []struct{ contentType, extension string }{
+		{"image/png", ".png"},
+		{"image/jpeg", ".jpg"},
+		{"image/webp", ".webp"},
+	}
+	for i, want := range expected {
+		assert.True(t, strings.HasSuffix(bucket.objects[i], want.extension), bucket.objects[i])
+		assert.Contains(t, bucket.headers[i], "Content-Type:"+want.contentType)
+		assert.Contains(t, bucket.headers[i], "x-goog-meta-content-type:"+want.contentType)
+		assert.Equal(t, want.contentType, uploadURLs[i].Fields["Content-Type"])
+	}
+
+	// Without per-image types every URL still uses the first allowed type
+	bucket.objects, bucket.headers = nil, nil
+	_, err = service.GenerateUploadURLs(context.Background(), 7, "7", &models.UploadRequest{
+		ImageCount:   2,
+		AllowedTypes: []string{"image/png", "image/jpeg"},
+	}, "127.0.0.1")
+	require.NoError(t, err)
+	for i := range bucket.objects {
+		assert.True(t, strings.HasSuffix(bucket.objects[i], ".png"), bucket.objects[i])
+		assert.Contains(t, bucket.headers[i], "Content-Type:image/png")
+	}
+}
+
+// signedLifetime generates one upload URL and returns how long the signed URL stays valid
+func signedLifetime(t *testing.T, service *handlers.StorageService, bucket *signingBucket, requestedHours int) time.Duration {
+	start := time.Now()
+	_, err := service.GenerateUploadURLs(context.Background(), 7, "7", &models.UploadRequest{
+		ImageCount:      1,
+		ExpirationHours: requestedHours,
+	}, "127.0.0.1")
+	require.NoError(t, err)
+	require.NotEmpty(t, bucket.expires)
+	return bucket.expires[len(bucket.expires)-1].Sub(start).Round(time.Minute)
+}
+
+// TestImageTypesAgreeAcrossUploadPaths tests the extension an upload URL names its object with
+// passes filename validation for every supported content type
+func TestImageTypesAgreeAcrossUploadPaths(t *testing.T) {
+	types := handlers.SupportedImageTypes()
+	assert.Equal(t, []string{"image/jpeg", "image/png", "image/webp"}, types)
+
+	for _, contentType := range types {
+		t.Run(contentType, func(t *testing.T) {
+			bucket := &signingBucket{memoryBucket: newMemoryBucket()}
+			service := handlers.NewStorageServiceWithBackend("test-bucket", bucket)
+
+			_, err := service.GenerateUploadURLs(context.Background(), 7, "7", &models.UploadRequest{
+				ImageCount:   1,
+				AllowedTypes: []string{contentType},
+				ContentTypes: []string{contentType},
+			}, "203.0.113.7")
+			require.NoError(t, err)
+			require.Len(t, bucket.objects, 1)
+
+			object := bucket.objects[0]
+			extensions := handlers.ImageExtensions(contentType)
+			require.NotEmpty(t, extensions)
+			assert.Equal(t, extensions[0], path.Ext(object), "New objects use the first extension for their type")
+			assert.NoError(t, handlers.ValidateFileSignature(path.Base(object), contentType))
+
+			for _, extension := range extensions {
+				assert.NoError(t, handlers.ValidateFileSignature("photo"+strings.ToUpper(extension), contentType))
+			}
+			for _, other := range types {
+				if other != contentType {
+					assert.Error(t, handlers.ValidateFileSignature(path.Base(object), other))
+				}
+			}
+		})
+	}
+
+	assert.NoError(t, handlers.ValidateFileSignature("photo.jpeg", "image/jpg"), "The image/jpg alias shares the JPEG extensions")
+	assert.Error(t, handlers.ValidateFileSignature("photo.gif", "image/gif"))
+	assert.Nil(t, handlers.ImageExtensions("image/gif"))
+}
+
+func TestSetExtraImageExtensions(t *testing.T) {
+	t.Cleanup(func() { require.NoError(t, handlers.SetExtraImageExtensions(nil)) })
+
+	require.NoError(t, handlers.SetExtraImageExtensions(map[string][]string{"image/jpg": {"JFIF", ".jpe", ".jfif", ".jpg"}}))
+	assert.Equal(t, []string{".jpg", ".jpeg", ".jfif", ".jpe"}, handlers.ImageExtensions("image/jpeg"), "Extensions are listed once, after the built-in ones")
+	assert.NoError(t, handlers.ValidateFileSignature("scan.jfif", "image/jpeg"))
+	assert.Error(t, handlers.ValidateFileSignature("scan.jfif", "image/png"))
+
+	// New objects keep the first extension
+	bucket := &signingBucket{memoryBucket: newMemoryBucket()}
+	service := handlers.NewStorageServiceWithBackend("test-bucket", bucket)
+	_, err := service.GenerateUploadURLs(context.Background(), 7, "7", &models.UploadRequest{ImageCount: 1, AllowedTypes: []string{"image/jpeg"}}, "203.0.113.7")
+	require.NoError(t, err)
+	require.Len(t, bucket.objects, 1)
+	assert.Equal(t, ".jpg", path.Ext(bucket.objects[0]))
+
+	// Invalid entries are rejected without changing what is accepted
+	assert.Error(t, handlers.SetExtraImageExtensions(map[string][]string{"image/gif": {".gif"}}))
+	assert.Error(t, handlers.SetExtraImageExtensions(map[string][]string{"image/png": {"."}}))
+	assert.Error(t, handlers.SetExtraImageExtensions(map[string][]string{"image/png": {".apng", "../png"}}))
+	assert.Equal(t, []string{".jpg", ".jpeg", ".jfif", ".jpe"}, handlers.ImageExtensions("image/jpeg"))
+	assert.Equal(t, []string{".png"}, handlers.ImageExtensions("image/png"))
+
+	// Each call replaces the previous extras
+	require.NoError(t, handlers.SetExtraImageExtensions(nil))
+	assert.Equal(t, []string{".jpg", ".jpeg"}, handlers.ImageExtensions("image/jpeg"))
+	assert.Error(t, handlers.ValidateFileSignature("scan.jfif", "image/jpeg"))
+}
+
+func TestGetExtraImageExtensions(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		expected map[string][]string
+		wantErr  bool
+	}{
+		{"Unset", "", map[string][]string{}, false},
+		{"Pairs grouped by type", "image/jpeg:.jpe, image/png:.apng ,image/jpeg:.jfif", map[string][]string{"image/jpeg": {".jpe", ".jfif"}, "image/png": {".apng"}}, false},
+		{"Trailing comma", "image/jpeg:.jpe,", map[string][]string{"image/jpeg": {".jpe"}}, false},
+		{"Missing extension separator", "image/jpeg.jpe", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("EXTRA_IMAGE_EXTENSIONS", tt.value)
+			extra, err := handlers.GetExtraImageExtensions()
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, extra)
+		})
+	}
+}
+
+func TestGenerateUploadURLsExpiration(t *testing.T) {
+	hook := logtest.NewGlobal()
+	defer hook.Reset()
+
+	bucket := &signingBucket{memoryBucket: newMemoryBucket()}
+	service := handlers.NewStorageServiceWithBackend("test-bucket", bucket)
+
+	t.Run("Built-in bounds", func(t *testing.T) {
+		assert.Equal(t, time.Hour, signedLifetime(t, service, bucket, 0))
+		assert.Equal(t, 24*time.Hour, signedLifetime(t, service, bucket, 24))
+	})
+
+	service.SetUploadURLExpiry(handlers.UploadURLExpiryConfig{DefaultHours: 2, MaxHours: 4})
+
+	t.Run("Operator default applies when none is requested", func(t *testing.T) {
+		hook.Reset()
+		assert.Equal(t, 2*time.Hour, signedLifetime(t, service, bucket, 0))
+		assert.Nil(t, findLogEntry(hook, "Upload URL expiration clamped to the configured maximum"))
+	})
+
+	t.Run("Requests within the ceiling are honoured", func(t *testing.T) {
+		assert.Equal(t, 3*time.Hour, signedLifetime(t, service, bucket, 3))
+	})
+
+	t.Run("Requests above the ceiling are clamped and logged", func(t *testing.T) {
+		hook.Reset()
+		assert.Equal(t, 4*time.Hour, signedLifetime(t, service, bucket, 12))
+
+		entry := findLogEntry(hook, "Upload URL expiration clamped to the configured maximum")
+		require.NotNil(t, entry)
+		assert.Equal(t, 12, entry.Data["requested_hours"])
+		assert.Equal(t, 4, entry.Data["expiration_hours"])
+	})
+}
+
+func TestGetUploadURLExpiryConfig(t *testing.T) {
+	t.Setenv("UPLOAD_URL_DEFAULT_HOURS", "")
+	t.Setenv("UPLOAD_URL_MAX_HOURS", "")
+	config, err := handlers.GetUploadURLExpiryConfig()
+	require.NoError(t, err)
+	assert.Equal(t, handlers.DefaultUploadURLExpiryConfig(), config)
+	assert.Equal(t, 1, config.DefaultHours)
+	assert.Equal(t, 24, config.MaxHours)
+
+	t.Setenv("UPLOAD_URL_DEFAULT_HOURS", "2")
+	t.Setenv("UPLOAD_URL_MAX_HOURS", "4")
+	config, err = handlers.GetUploadURLExpiryConfig()
+	require.NoError(t, err)
+	assert.Equal(t, handlers.UploadURLExpiryConfig{DefaultHours: 2, MaxHours: 4}, config)
+
+	t.Setenv("UPLOAD_URL_MAX_HOURS", "soon")
+	config, err = handlers.GetUploadURLExpiryConfig()
+	require.NoError(t, err, "Unparseable values fall back to the built-in bound")
+	assert.Equal(t, 24, config.MaxHours)
+
+	t.Setenv("UPLOAD_URL_DEFAULT_HOURS", "6")
+	t.Setenv("UPLOAD_URL_MAX_HOURS", "4")
+	_, err = handlers.GetUploadURLExpiryConfig()
+	assert.Error(t, err, "Default above max is rejected")
+
+	t.Setenv("UPLOAD_URL_DEFAULT_HOURS", "1")
+	t.Setenv("UPLOAD_URL_MAX_HOURS", "48")
+	_, err = handlers.GetUploadURLExpiryConfig()
+	assert.Error(t, err, "Max above the request limit is rejected")
+}
+
+func TestGenerateUploadURLsStopsWhenContextDone(t *testing.T) {
+	bucket := &signingBucket{memoryBucket: newMemoryBucket()}
+	service := handlers.NewStorageServiceWithBackend("test-bucket", bucket)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	started := time.Now()
+	uploadURLs, err := service.GenerateUploadURLs(ctx, 7, "7", &models.UploadRequest{ImageCount: models.MaxImagesPerUpload}, "203.0.113.7")
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Nil(t, uploadURLs)
+	assert.Less(t, time.Since(started), time.Second)
+	assert.Empty(t, bucket.objects, "No URLs are signed for a cancelled request")
+
+	expired, cancelExpired := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancelExpired()
+	_, err = service.GenerateUploadURLs(expired, 7, "7", &models.UploadRequest{ImageCount: 2}, "203.0.113.7")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Empty(t, bucket.objects)
+}
+
+func TestGenerateUploadURLsReturnObjectKeys(t *testing.T) {
+	bucket := &signingBucket{memoryBucket: newMemoryBucket()}
+	service := handlers.NewStorageServiceWithBackend("test-bucket", bucket)
+	prefix := "0f3c9a6e2b7d4e1f8a5c3b9d7e6f1a2b"
+
+	for _, contentType := range handlers.SupportedImageTypes() {
+		t.Run(contentType, func(t *testing.T) {
+			uploadURLs, err := service.GenerateUploadURLs(context.Background(), 7, prefix, &models.UploadRequest{
+				ImageCount:   1,
+				AllowedTypes: []string{contentType},
+			}, "203.0.113.7")
+			require.NoError(t, err)
+			require.Len(t, uploadURLs, 1)
+
+			upload := uploadURLs[0]
+			extension := handlers.ImageExtensions(contentType)[0]
+			assert.Equal(t, "recipes/"+prefix+"/images/"+upload.ImageID+extension, upload.ObjectKey)
+			assert.Equal(t, bucket.objects[len(bucket.objects)-1], upload.ObjectKey, "The key is the object the URL was signed for")
+			assert.Equal(t, contentType, upload.Fields["Content-Type"])
+		})
+	}
+}
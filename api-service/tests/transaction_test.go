@@ -0,0 +1,218 @@
+package tests
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"digital-recipes/api-service/db"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithTx(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Commits when the callback succeeds", func(t *testing.T) {
+		database := openScriptedDatabase(t)
+
+		err := database.WithTx(ctx, func(tx *sql.Tx) error {
+			_, err := tx.Exec("UPDATE recipes SET title = $1", "Renamed")
+			return err
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"BEGIN", "UPDATE recipes SET title = $1", "COMMIT"}, scriptedQueries())
+	})
+
+	t.Run("Rolls back when the callback fails", func(t *testing.T) {
+		database := openScriptedDatabase(t)
+		failure := errors.New("validation failed")
+
+		err := database.WithTx(ctx, func(tx *sql.Tx) error {
+			if _, err := tx.Exec("DELETE FROM recipes"); err != nil {
+				return err
+			}
+			return failure
+		})
+		assert.ErrorIs(t, err, failure, "The callback error is returned unchanged")
+		assert.Equal(t, []string{"BEGIN", "DELETE FROM recipes", "ROLLBACK"}, scriptedQueries())
+	})
+
+	t.Run("Rolls back and re-panics when the callback panics", func(t *testing.T) {
+		database := openScriptedDatabase(t)
+
+		assert.PanicsWithValue(t, "boom", func() {
+			database.WithTx(ctx, func(tx *sql.Tx) error {
+				panic("boom")
+			})
+		})
+		assert.Equal(t, []string{"BEGIN", "ROLLBACK"}, scriptedQueries())
+	})
+}
+
+func TestWithRetryTx(t *testing.T) {
+	ctx := context.Background()
+	serializationFailure := &pq.Error{Code: "40001", Message: "could not serialize access due to concurrent update"}
+
+	t.Run("Retries a serialization failure until it succeeds", func(t *testing.T) {
+		database := openScriptedDatabase(t)
+		database.TxMaxAttempts = 3
+		database.TxRetryBackoff = time.Millisecond
+
+		attempts := 0
+		err := database.WithRetryTx(ctx, func(tx *sql.Tx) error {
+			attempts++
+			if _, err := tx.Exec("UPDATE recipes SET title = $1", "Renamed"); err != nil {
+				return err
+			}
+			if attempts == 1 {
+				return serializationFailure
+			}
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 2, attempts)
+		assert.Equal(t, []string{
+			"BEGIN", "UPDATE recipes SET title = $1", "ROLLBACK",
+			"BEGIN", "UPDATE recipes SET title = $1", "COMMIT",
+		}, scriptedQueries())
+	})
+
+	t.Run("Gives up after the last attempt", func(t *testing.T) {
+		database := openScriptedDatabase(t)
+		database.TxMaxAttempts = 3
+		database.TxRetryBackoff = time.Millisecond
+
+		attempts := 0
+		err := database.WithRetryTx(ctx, func(tx *sql.Tx) error {
+			attempts++
+			return fmt.Errorf("update recipe: %w", serializationFailure)
+		})
+		assert.ErrorIs(t, err, serializationFailure)
+		assert.Equal(t, 3, attempts)
+	})
+
+	t.Run("Returns other errors without retrying", func(t *testing.T) {
+		database := openScriptedDatabase(t)
+		database.TxMaxAttempts = 3
+		failure := &pq.Error{Code: "23505", Message: "duplicate key value violates unique constraint"}
+
+		attempts := 0
+		err := database.WithRetryTx(ctx, func(tx *sql.Tx) error {
+			attempts++
+			return failure
+		})
+		assert.ErrorIs(t, err, failure)
+		assert.Equal(t, 1, attempts)
+		assert.Equal(t, []string{"BEGIN", "ROLLBACK"}, scriptedQueries())
+	})
+
+	t.Run("Retries a commit that lost to a concurrent transaction", func(t *testing.T) {
+		database := openScriptedDatabase(t)
+		database.TxMaxAttempts = 3
+		database.TxRetryBackoff = time.Millisecond
+		failScriptedCommits(serializationFailure)
+
+		attempts := 0
+		err := database.WithRetryTx(ctx, func(tx *sql.Tx) error {
+			attempts++
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 2, attempts)
+	})
+
+	t.Run("Never retries a commit that lost its connection", func(t *testing.T) {
+		for _, failure := range []error{
+			&pq.Error{Code: "08006", Message: "connection failure"},
+			driver.ErrBadConn,
+		} {
+			database := openScriptedDatabase(t)
+			database.TxMaxAttempts = 3
+			database.TxRetryBackoff = time.Millisecond
+			failScriptedCommits(failure, failure, failure)
+
+			attempts := 0
+			err := database.WithRetryTx(ctx, func(tx *sql.Tx) error {
+				attempts++
+				_, err := tx.Exec("INSERT INTO recipe_revisions (recipe_id) VALUES ($1)", 5)
+				return err
+			})
+			assert.ErrorIs(t, err, failure)
+			assert.Equal(t, 1, attempts, "The transaction may have committed, so it is not run again")
+		}
+	})
+
+	t.Run("Retries a lost connection before the commit", func(t *testing.T) {
+		database := openScriptedDatabase(t)
+		database.TxMaxAttempts = 3
+		database.TxRetryBackoff = time.Millisecond
+
+		attempts := 0
+		err := database.WithRetryTx(ctx, func(tx *sql.Tx) error {
+			attempts++
+			if attempts == 1 {
+				return &pq.Error{Code: "08006", Message: "connection failure"}
+			}
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 2, attempts)
+	})
+
+	t.Run("Runs once when retries are disabled", func(t *testing.T) {
+		database := openScriptedDatabase(t)
+
+		attempts := 0
+		err := database.WithRetryTx(ctx, func(tx *sql.Tx) error {
+			attempts++
+			return serializationFailure
+		})
+		assert.ErrorIs(t, err, serializationFailure)
+		assert.Equal(t, 1, attempts)
+	})
+
+	t.Run("Stops waiting when the context ends", func(t *testing.T) {
+		database := openScriptedDatabase(t)
+		database.TxMaxAttempts = 3
+		database.TxRetryBackoff = time.Hour
+		cancelled, cancel := context.WithCancel(ctx)
+
+		attempts := 0
+		err := database.WithRetryTx(cancelled, func(tx *sql.Tx) error {
+			attempts++
+			cancel()
+			return serializationFailure
+		})
+		assert.ErrorIs(t, err, serializationFailure)
+		assert.Equal(t, 1, attempts)
+	})
+}
+
+func TestIsTransientError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"Serialization failure", &pq.Error{Code: "40001"}, true},
+		{"Deadlock", fmt.Errorf("merge: %w", &pq.Error{Code: "40P01"}), true},
+		{"Connection failure", &pq.Error{Code: "08006"}, true},
+		{"Bad connection", driver.ErrBadConn, true},
+		{"Unique violation", &pq.Error{Code: "23505"}, false},
+		{"Check violation", &pq.Error{Code: "23514"}, false},
+		{"No rows", sql.ErrNoRows, false},
+		{"Nil", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, db.IsTransientError(tt.err))
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"digital-recipes/api-service/webhooks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestDispatcher creates a dispatcher with fast retries that needs no database
+func newTestDispatcher() *webhooks.Dispatcher {
+	dispatcher := webhooks.NewDispatcher(nil)
+	dispatcher.MaxAttempts = 3
+	dispatcher.InitialBackoff = time.Millisecond
+	return dispatcher
+}
+
+func TestWebhookSign(t *testing.T) {
+	// Reference value from: printf '{"event":"recipe.published"}' | openssl dgst -sha256 -hmac secret
+	signature := webhooks.Sign("secret", []byte(`{"event":"recipe.published"}`))
+	assert.Equal(t, "sha256=e21ddf569179c5df95f17fc7576b1041be8e118cb1f68e5ec01d709399290ae4", signature)
+	assert.NotEqual(t, signature, webhooks.Sign("other-secret", []byte(`{"event":"recipe.published"}`)))
+}
+
+func TestWebhookDeliver(t *testing.T) {
+	payload := webhooks.Payload{
+		Event:      webhooks.EventRecipePublished,
+		DeliveryID: "delivery-1",
+		OccurredAt: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+		Data:       map[string]interface{}{"recipe_id": 42, "status": "published"},
+	}
+
+	t.Run("Signed payload is posted once on success", func(t *testing.T) {
+		var calls int32
+		var body []byte
+		var headers http.Header
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&calls, 1)
+			body, _ = io.ReadAll(r.Body)
+			headers = r.Header.Clone()
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer server.Close()
+
+		endpoint := webhooks.Endpoint{ID: 1, URL: server.URL, Secret: "receiver-secret"}
+		require.NoError(t, newTestDispatcher().Deliver(context.Background(), endpoint, payload))
+
+		assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+		assert.Equal(t, "application/json", headers.Get("Content-Type"))
+		assert.Equal(t, webhooks.EventRecipePublished, headers.Get(webhooks.EventHeader))
+		assert.Equal(t, "delivery-1", headers.Get(webhooks.DeliveryHeader))
+		assert.Equal(t, webhooks.Sign("receiver-secret", body), headers.Get(webhooks.SignatureHeader),
+			"Receivers recompute the signature over the raw body")
+
+		var received webhooks.Payload
+		require.NoError(t, json.Unmarshal(body, &received))
+		assert.Equal(t, webhooks.EventRecipePublished, received.Event)
+		assert.Equal(t, "delivery-1", received.DeliveryID)
+		assert.True(t, payload.OccurredAt.Equal(received.OccurredAt))
+		assert.Equal(t, map[string]interface{}{"recipe_id": float64(42), "status": "published"}, received.Data)
+	})
+
+	t.Run("Server errors are retried until success", func(t *testing.T) {
+		var calls int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt32(&calls, 1) < 3 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		endpoint := webhooks.Endpoint{ID: 1, URL: server.URL, Secret: "receiver-secret"}
+		require.NoError(t, newTestDispatcher().Deliver(context.Background(), endpoint, payload))
+		assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("Delivery gives up after the maximum attempts", func(t *testing.T) {
+		var calls int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&calls, 1)
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer server.Close()
+
+		endpoint := webhooks.Endpoint{ID: 1, URL: server.URL, Secret: "receiver-secret"}
+		err := newTestDispatcher().Deliver(context.Background(), endpoint, payload)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "500")
+		assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("Client errors are not retried", func(t *testing.T) {
+		var calls int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&calls, 1)
+			w.WriteHeader(http.StatusGone)
+		}))
+		defer server.Close()
+
+		endpoint := webhooks.Endpoint{ID: 1, URL: server.URL, Secret: "receiver-secret"}
+		require.Error(t, newTestDispatcher().Deliver(context.Background(), endpoint, payload))
+		assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	})
+}
+
+func TestWebhookEventForStatus(t *testing.T) {
+	event, ok := webhooks.EventForStatus("review_required")
+	assert.True(t, ok)
+	assert.Equal(t, webhooks.EventRecipeReviewRequired, event)
+
+	event, ok = webhooks.EventForStatus("published")
+	assert.True(t, ok)
+	assert.Equal(t, webhooks.EventRecipePublished, event)
+
+	_, ok = webhooks.EventForStatus("processing")
+	assert.False(t, ok, "Moving back to processing is not announced")
+}
@@ -0,0 +1,227 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"digital-recipes/api-service/db"
+	"digital-recipes/api-service/logctx"
+	"github.com/google/uuid"
+)
+
+// Headers sent with every delivery
+const (
+	SignatureHeader = "X-Webhook-Signature"
+	EventHeader     = "X-Webhook-Event"
+	DeliveryHeader  = "X-Webhook-Delivery"
+)
+
+// Events a webhook can subscribe to
+const (
+	EventRecipeReviewRequired = "recipe.review_required"
+	EventRecipePublished      = "recipe.published"
+)
+
+// statusEvents maps the recipe status a recipe moved into to its event name
+var statusEvents = map[string]string{
+	"review_required": EventRecipeReviewRequired,
+	"published":       EventRecipePublished,
+}
+
+// Delivery defaults; requests time out individually and retries back off exponentially
+const (
+	defaultMaxAttempts    = 5
+	defaultInitialBackoff = time.Second
+	defaultRequestTimeout = 10 * time.Second
+)
+
+// EventForStatus returns the event fired when a recipe moves into status
+func EventForStatus(status string) (string, bool) {
+	event, ok := statusEvents[status]
+	return event, ok
+}
+
+// IsKnownEvent reports whether event can be subscribed to
+func IsKnownEvent(event string) bool {
+	for _, known := range statusEvents {
+		if known == event {
+			return true
+		}
+	}
+	return false
+}
+
+// Sign returns the signature header value for body: "sha256=" followed by the hex HMAC-SHA256
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Endpoint is a registered receiver of webhook deliveries
+type Endpoint struct {
+	ID     int
+	URL    string
+	Secret string
+}
+
+// Payload is the JSON body POSTed to every endpoint
+type Payload struct {
+	Event      string      `json:"event"`
+	DeliveryID string      `json:"delivery_id"`
+	OccurredAt time.Time   `json:"occurred_at"`
+	Data       interface{} `json:"data"`
+}
+
+// Dispatcher delivers events to the webhooks subscribed to them
+type Dispatcher struct {
+	db             *db.Database
+	client         *http.Client
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	wg             sync.WaitGroup
+}
+
+// NewDispatcher creates a dispatcher that reads subscriptions from the webhooks table
+func NewDispatcher(database *db.Database) *Dispatcher {
+	return &Dispatcher{
+		db:             database,
+		client:         &http.Client{Timeout: defaultRequestTimeout},
+		MaxAttempts:    defaultMaxAttempts,
+		InitialBackoff: defaultInitialBackoff,
+	}
+}
+
+// Dispatch delivers an event to every subscribed webhook in the background
+// Failures are logged with the request fields of the logger carried by ctx; callers never wait on receivers
+func (d *Dispatcher) Dispatch(ctx context.Context, event string, data interface{}) {
+	logger := logctx.FromContext(ctx).WithField("event", event)
+
+	endpoints, err := d.subscribers(event)
+	if err != nil {
+		logger.WithError(err).Error("Failed to load webhook subscriptions")
+		return
+	}
+	if len(endpoints) == 0 {
+		return
+	}
+
+	payload := Payload{
+		Event:      event,
+		DeliveryID: uuid.New().String(),
+		OccurredAt: time.Now().UTC(),
+		Data:       data,
+	}
+
+	for _, endpoint := range endpoints {
+		d.wg.Add(1)
+		go func(endpoint Endpoint) {
+			defer d.wg.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), d.deliveryBudget())
+			defer cancel()
+			if err := d.Deliver(ctx, endpoint, payload); err != nil {
+				logger.WithError(err).WithField("webhook_id", endpoint.ID).Warn("Webhook delivery failed")
+			}
+		}(endpoint)
+	}
+}
+
+// Wait blocks until all background deliveries have finished
+func (d *Dispatcher) Wait() {
+	d.wg.Wait()
+}
+
+// Deliver POSTs a signed payload to one endpoint, retrying with exponential backoff
+// Network errors, 429 and 5xx responses are retried; other responses are final
+func (d *Dispatcher) Deliver(ctx context.Context, endpoint Endpoint, payload Payload) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook payload: %w", err)
+	}
+
+	backoff := d.InitialBackoff
+	var lastErr error
+	for attempt := 1; attempt <= d.MaxAttempts; attempt++ {
+		retry, err := d.send(ctx, endpoint, payload, body)
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+		if !retry || attempt == d.MaxAttempts {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("webhook delivery cancelled after %d attempts: %w", attempt, lastErr)
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+	return lastErr
+}
+
+// send performs a single delivery attempt and reports whether a failure may be retried
+func (d *Dispatcher) send(ctx context.Context, endpoint Endpoint, payload Payload, body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("failed to build webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "digital-recipes-webhooks/1.0")
+	req.Header.Set(EventHeader, payload.Event)
+	req.Header.Set(DeliveryHeader, payload.DeliveryID)
+	req.Header.Set(SignatureHeader, Sign(endpoint.Secret, body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return true, fmt.Errorf("webhook request failed: %w", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retry, fmt.Errorf("webhook endpoint responded with status %d", resp.StatusCode)
+}
+
+// deliveryBudget bounds the total time spent on one delivery including all retries
+func (d *Dispatcher) deliveryBudget() time.Duration {
+	budget := time.Duration(d.MaxAttempts) * defaultRequestTimeout
+	backoff := d.InitialBackoff
+	for attempt := 1; attempt < d.MaxAttempts; attempt++ {
+		budget += backoff
+		backoff *= 2
+	}
+	return budget
+}
+
+// subscribers loads the webhooks subscribed to event
+func (d *Dispatcher) subscribers(event string) ([]Endpoint, error) {
+	rows, err := d.db.DB.Query(`
+		SELECT id, url, secret FROM webhooks WHERE $1 = ANY(events) ORDER BY id
+	`, event)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var endpoints []Endpoint
+	for rows.Next() {
+		var endpoint Endpoint
+		if err := rows.Scan(&endpoint.ID, &endpoint.URL, &endpoint.Secret); err != nil {
+			return nil, err
+		}
+		endpoints = append(endpoints, endpoint)
+	}
+	return endpoints, rows.Err()
+}